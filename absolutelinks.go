@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// absoluteLinkAttrs는 absolute_links가 바꾸는 URL 속성입니다 (srcset은 따로 처리)
+var absoluteLinkAttrs = map[string]bool{"href": true, "src": true, "poster": true}
+
+// linkBaseURL은 상대 링크를 풀 때 쓰는 커뮤니티 주소입니다 (TOKEN_NETWORK_DOMAINS의 첫 번째 도메인)
+func linkBaseURL(cfg *Config) *url.URL {
+	domain := "www.gpters.org"
+	if len(cfg.TokenNetworkDomains) > 0 {
+		domain = cfg.TokenNetworkDomains[0]
+	}
+	return &url.URL{Scheme: "https", Host: domain, Path: "/"}
+}
+
+// absolutizeLinks는 본문의 상대 href/src/poster/srcset URL을 base 기준의 절대 URL로 바꿉니다.
+//   - "/post/123", "post/123" → https://도메인/post/123
+//   - "//cdn.example.com/a.png"(프로토콜 상대) → base의 scheme을 붙인 https://cdn.example.com/a.png
+//   - 이미 절대 URL이거나 mailto:, data: 같은 scheme이 있는 값, 본문 안 anchor("#section")는 그대로 둡니다
+//
+// URL로 해석할 수 없는 값도 그대로 둡니다.
+func absolutizeLinks(content string, base *url.URL) (string, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", err
+	}
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			if c.Type != html.ElementNode {
+				return true
+			}
+			for i, attr := range c.Attr {
+				key := strings.ToLower(attr.Key)
+				switch {
+				case absoluteLinkAttrs[key]:
+					c.Attr[i].Val = absoluteURL(attr.Val, base)
+				case key == "srcset":
+					c.Attr[i].Val = absoluteSrcset(attr.Val, base)
+				}
+			}
+			return true
+		})
+	}
+	return renderHTMLFragment(nodes)
+}
+
+// absoluteURL은 rawURL이 상대 URL이면 base 기준으로 풀어 반환하고, 아니면 그대로 반환합니다
+func absoluteURL(rawURL string, base *url.URL) string {
+	value := strings.TrimSpace(rawURL)
+	if value == "" || strings.HasPrefix(value, "#") {
+		return rawURL
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme != "" {
+		return rawURL
+	}
+	return base.ResolveReference(u).String()
+}
+
+// absoluteSrcset은 srcset의 각 후보("url 2x")의 URL을 절대 URL로 바꿉니다
+func absoluteSrcset(srcset string, base *url.URL) string {
+	candidates := strings.Split(srcset, ",")
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = absoluteURL(fields[0], base)
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAbsoluteURL(t *testing.T) {
+	base := &url.URL{Scheme: "https", Host: "community.example.com", Path: "/"}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"/post/123", "https://community.example.com/post/123"},
+		{"post/123", "https://community.example.com/post/123"},
+		{"//cdn.example.com/a.png", "https://cdn.example.com/a.png"},
+		{"https://other.example.com/a", "https://other.example.com/a"},
+		{"mailto:a@example.com", "mailto:a@example.com"},
+		{"data:image/png;base64,AAAA", "data:image/png;base64,AAAA"},
+		{"#section", "#section"},
+		{"", ""},
+		{"/a b?q=1#frag", "https://community.example.com/a%20b?q=1#frag"},
+		{"http://[::1", "http://[::1"}, // 해석할 수 없는 값은 그대로
+	}
+	for _, tt := range tests {
+		if got := absoluteURL(tt.in, base); got != tt.want {
+			t.Errorf("absoluteURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAbsoluteSrcset(t *testing.T) {
+	base := &url.URL{Scheme: "https", Host: "community.example.com", Path: "/"}
+	got := absoluteSrcset("/a.png 1x,  /a@2x.png 2x, https://cdn.example.com/a@3x.png 3x", base)
+	want := "https://community.example.com/a.png 1x, https://community.example.com/a@2x.png 2x, https://cdn.example.com/a@3x.png 3x"
+	if got != want {
+		t.Errorf("absoluteSrcset = %q, want %q", got, want)
+	}
+}
+
+func TestLinkBaseURL(t *testing.T) {
+	tests := []struct {
+		domains []string
+		want    string
+	}{
+		{nil, "https://www.gpters.org/"},
+		{[]string{"community.example.com", "other.example.com"}, "https://community.example.com/"},
+	}
+	for _, tt := range tests {
+		if got := linkBaseURL(&Config{TokenNetworkDomains: tt.domains}).String(); got != tt.want {
+			t.Errorf("linkBaseURL(%v) = %q, want %q", tt.domains, got, tt.want)
+		}
+	}
+}
+
+func TestRenderContentResponseAbsoluteLinks(t *testing.T) {
+	withConfig(t, func(cfg *Config) {
+		cfg.TokenNetworkDomains = []string{"community.example.com"}
+		cfg.ImageProxyAllowedHosts = []string{"community.example.com"}
+	})
+	post := newTestPost("제목", `<p><a href="/post/1">글</a><img src="/img/a.png"></p>`)
+
+	response := renderTestPost(t, ContentOptions{Format: "html", AbsoluteLinks: true}, post)
+	for _, want := range []string{`href="https://community.example.com/post/1"`, `src="https://community.example.com/img/a.png"`} {
+		if !strings.Contains(response.Content, want) {
+			t.Errorf("content = %s, want %s", response.Content, want)
+		}
+	}
+
+	// 상대 경로 이미지도 절대 URL로 바꾼 뒤 프록시 주소로 바뀝니다
+	proxied := renderTestPost(t, ContentOptions{Format: "html", AbsoluteLinks: true, ProxyImages: true}, post)
+	if want := imageProxyURL("https://community.example.com/img/a.png", ""); !strings.Contains(proxied.Content, want) {
+		t.Errorf("content = %s, want %s", proxied.Content, want)
+	}
+
+	unchanged := renderTestPost(t, ContentOptions{Format: "html"}, post)
+	if !strings.Contains(unchanged.Content, `href="/post/1"`) {
+		t.Errorf("content without absolute_links = %s", unchanged.Content)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// accessLogSampleRate는 성공 응답을 N개 중 하나만 로그에 남기는 비율입니다 (1 이하이면 모두 기록).
+// 서버 실행 중 /api/v1/logging/sample-rate로 바꿀 수 있습니다.
+var accessLogSampleRate atomic.Int64
+
+// sampledLogFormatter는 chi의 기본 요청 로그 형식을 그대로 쓰되
+// 4xx/5xx 응답과 패닉은 항상, 나머지 응답은 accessLogSampleRate 비율로만 기록합니다 (LOG_LEVEL=warn이면 기록하지 않음)
+type sampledLogFormatter struct {
+	base    middleware.LogFormatter
+	counter atomic.Uint64
+}
+
+type sampledLogEntry struct {
+	base      middleware.LogEntry
+	formatter *sampledLogFormatter
+}
+
+// newAccessLogger는 middleware.Logger를 대신하는 샘플링 요청 로그 미들웨어를 만듭니다
+func newAccessLogger() func(http.Handler) http.Handler {
+	return middleware.RequestLogger(&sampledLogFormatter{
+		base: &middleware.DefaultLogFormatter{Logger: log.New(os.Stdout, "", log.LstdFlags)},
+	})
+}
+
+func (f *sampledLogFormatter) NewLogEntry(r *http.Request) middleware.LogEntry {
+	return &sampledLogEntry{base: f.base.NewLogEntry(r), formatter: f}
+}
+
+// sample은 이번 성공 응답을 기록할 차례인지 반환합니다
+func (f *sampledLogFormatter) sample() bool {
+	rate := accessLogSampleRate.Load()
+	if rate <= 1 {
+		return true
+	}
+	return (f.counter.Add(1)-1)%uint64(rate) == 0
+}
+
+func (e *sampledLogEntry) Write(status, bytes int, header http.Header, elapsed time.Duration, extra interface{}) {
+	if status >= http.StatusBadRequest || (logEnabled(LogLevelInfo) && e.formatter.sample()) {
+		e.base.Write(status, bytes, header, elapsed, extra)
+	}
+}
+
+func (e *sampledLogEntry) Panic(v interface{}, stack []byte) {
+	e.base.Panic(v, stack)
+}
+
+// handleLogSampleRate는 요청 로그 샘플링 비율을 확인하거나(GET) 바꾸는(POST {"rate": N}) 엔드포인트입니다 (관리자용)
+func handleLogSampleRate(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		var req struct {
+			Rate *int64 `json:"rate"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Rate == nil {
+			writeError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if *req.Rate < 1 {
+			writeError(w, "rate must be at least 1", http.StatusBadRequest)
+			return
+		}
+		accessLogSampleRate.Store(*req.Rate)
+		log.Printf("Access log sample rate set to 1/%d", *req.Rate)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, map[string]int64{"rate": accessLogSampleRate.Load()})
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// countingLogFormatter는 기록된 로그 줄 수만 셉니다
+type countingLogFormatter struct{ writes int }
+
+type countingLogEntry struct{ f *countingLogFormatter }
+
+func (f *countingLogFormatter) NewLogEntry(r *http.Request) middleware.LogEntry {
+	return countingLogEntry{f}
+}
+func (e countingLogEntry) Write(status, bytes int, header http.Header, elapsed time.Duration, extra interface{}) {
+	e.f.writes++
+}
+func (e countingLogEntry) Panic(v interface{}, stack []byte) {}
+
+// withSampleRate는 테스트가 끝날 때까지 요청 로그 샘플링 비율을 rate로 둡니다
+func withSampleRate(t *testing.T, rate int64) {
+	prev := accessLogSampleRate.Load()
+	accessLogSampleRate.Store(rate)
+	t.Cleanup(func() { accessLogSampleRate.Store(prev) })
+}
+
+func TestSampledLogFormatter(t *testing.T) {
+	tests := []struct {
+		name      string
+		rate      int64
+		status    int
+		requests  int
+		wantLines int
+	}{
+		{"rate 1 logs everything", 1, http.StatusOK, 10, 10},
+		{"samples successes", 5, http.StatusOK, 10, 2},
+		{"errors always logged", 5, http.StatusInternalServerError, 10, 10},
+		{"client errors always logged", 100, http.StatusNotFound, 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withSampleRate(t, tt.rate)
+			base := &countingLogFormatter{}
+			f := &sampledLogFormatter{base: base}
+			for i := 0; i < tt.requests; i++ {
+				f.NewLogEntry(httptest.NewRequest("GET", "/", nil)).Write(tt.status, 0, nil, 0, nil)
+			}
+			if base.writes != tt.wantLines {
+				t.Errorf("logged %d lines, want %d", base.writes, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestHandleLogSampleRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantRate   int64
+	}{
+		{"get", "GET", "", http.StatusOK, 3},
+		{"set", "POST", `{"rate": 10}`, http.StatusOK, 10},
+		{"zero rejected", "POST", `{"rate": 0}`, http.StatusBadRequest, 3},
+		{"missing rate", "POST", `{}`, http.StatusBadRequest, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withSampleRate(t, 3)
+			w := httptest.NewRecorder()
+			handleLogSampleRate(w, httptest.NewRequest(tt.method, "/api/v1/logging/sample-rate", strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := accessLogSampleRate.Load(); got != tt.wantRate {
+				t.Errorf("rate = %d, want %d", got, tt.wantRate)
+			}
+		})
+	}
+}
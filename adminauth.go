@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 서명된 관리자 요청의 타임스탬프 허용 범위. 이보다 오래되었거나 앞선 요청은 거절합니다
+const adminSignatureMaxAge = 60 * time.Second
+
+// 서명을 확인하려고 읽는 관리자 요청 본문의 최대 크기
+const adminSignatureMaxBody = 1 << 20
+
+// nonceStore는 최근에 사용한 nonce를 기억해 같은 서명 요청의 재사용을 막습니다.
+// nonce는 그 요청의 타임스탬프가 검사를 통과할 수 있는 동안만 보관합니다.
+type nonceStore struct {
+	seen  map[string]time.Time // nonce별 보관 만료 시각
+	mutex sync.Mutex
+}
+
+func newNonceStore() *nonceStore {
+	return &nonceStore{seen: make(map[string]time.Time)}
+}
+
+// use는 nonce를 처음 보는 경우 expires까지 기록하고 true를, 이미 사용된 nonce이면 false를 반환합니다
+func (s *nonceStore) use(nonce string, now, expires time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for n, t := range s.seen {
+		if now.After(t) {
+			delete(s.seen, n)
+		}
+	}
+	if _, ok := s.seen[nonce]; ok {
+		return false
+	}
+	s.seen[nonce] = expires
+	return true
+}
+
+var adminNonces = newNonceStore()
+
+// adminAuth는 ADMIN_KEY가 설정되어 있으면 관리자 엔드포인트에 인증을 요구합니다.
+//
+// 서명 방식: X-Admin-Timestamp(유닉스 초), X-Admin-Nonce(임의 문자열), X-Admin-Signature 헤더를 보냅니다.
+// 서명은 "타임스탬프\nnonce\n메서드\n경로\n쿼리 문자열\n본문 SHA-256(hex)"를 ADMIN_KEY로 HMAC-SHA256한 hex 값입니다.
+// 쿼리 문자열은 URL에 쓴 그대로("?" 제외, 없으면 빈 문자열), 본문이 없으면 빈 본문의 SHA-256을 씁니다.
+// 60초보다 오래된 요청과 이미 사용된 nonce는 거절합니다.
+// ADMIN_REQUIRE_SIGNATURE가 false이면 X-Admin-Key 헤더에 키를 그대로 보내는 방식도 허용합니다.
+func adminAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := currentConfig()
+		if cfg.AdminKey == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Header.Get("X-Admin-Signature") != "" {
+			if msg, ok := verifyAdminSignature(r, cfg.AdminKey, time.Now()); !ok {
+				writeError(w, msg, http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := r.Header.Get("X-Admin-Key")
+		if cfg.AdminRequireSignature || key == "" || subtle.ConstantTimeCompare([]byte(key), []byte(cfg.AdminKey)) != 1 {
+			writeError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// verifyAdminSignature는 서명된 관리자 요청을 검증합니다. 실패하면 이유와 false를 반환합니다.
+func verifyAdminSignature(r *http.Request, key string, now time.Time) (string, bool) {
+	timestamp := r.Header.Get("X-Admin-Timestamp")
+	nonce := r.Header.Get("X-Admin-Nonce")
+	if timestamp == "" || nonce == "" {
+		return "X-Admin-Timestamp and X-Admin-Nonce are required", false
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "Invalid X-Admin-Timestamp", false
+	}
+	signedAt := time.Unix(unix, 0)
+	if age := now.Sub(signedAt); age > adminSignatureMaxAge || age < -adminSignatureMaxAge {
+		return "Request timestamp expired", false
+	}
+
+	// 서명을 확인한 뒤 핸들러가 같은 본문을 읽을 수 있도록 다시 채워 둡니다
+	var body []byte
+	if r.Body != nil {
+		if body, err = io.ReadAll(io.LimitReader(r.Body, adminSignatureMaxBody+1)); err != nil {
+			return "Error reading request body", false
+		}
+		if len(body) > adminSignatureMaxBody {
+			return "Request body too large to verify", false
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	expected := signAdminRequest(key, timestamp, nonce, r.Method, r.URL.Path, r.URL.RawQuery, body)
+	signature, err := hex.DecodeString(r.Header.Get("X-Admin-Signature"))
+	if err != nil || !hmac.Equal(signature, expected) {
+		return "Invalid signature", false
+	}
+
+	// 서명이 맞는 요청만 nonce를 기록해, 잘못된 요청으로 nonce를 소진시킬 수 없게 합니다.
+	// 앞선 타임스탬프(최대 adminSignatureMaxAge)는 그만큼 더 오래 유효하므로, nonce는 받은 시각과
+	// 타임스탬프 중 늦은 쪽에서 adminSignatureMaxAge가 지날 때까지 기억합니다.
+	expires := now
+	if signedAt.After(expires) {
+		expires = signedAt
+	}
+	if !adminNonces.use(nonce, now, expires.Add(adminSignatureMaxAge)) {
+		return "Nonce already used", false
+	}
+	return "", true
+}
+
+// signAdminRequest는 관리자 요청 서명(HMAC-SHA256)을 계산합니다.
+// 쿼리 문자열과 본문도 서명에 넣어, 가로챈 서명으로 파라미터나 본문을 바꾼 요청을 보낼 수 없게 합니다.
+func signAdminRequest(key, timestamp, nonce, method, path, rawQuery string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp + "\n" + nonce + "\n" + method + "\n" + path + "\n" + rawQuery + "\n" + hex.EncodeToString(bodyHash[:])))
+	return mac.Sum(nil)
+}
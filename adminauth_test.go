@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signedAdminRequest는 key로 서명한 관리자 요청을 만듭니다
+func signedAdminRequest(key, method, target, body, nonce string, at time.Time) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	r.Header.Set("X-Admin-Timestamp", timestamp)
+	r.Header.Set("X-Admin-Nonce", nonce)
+	r.Header.Set("X-Admin-Signature", hex.EncodeToString(
+		signAdminRequest(key, timestamp, nonce, method, r.URL.Path, r.URL.RawQuery, []byte(body))))
+	return r
+}
+
+func TestSignAdminRequest(t *testing.T) {
+	// 기대값은 README의 openssl 예시와 같은 방식으로 계산했습니다
+	tests := []struct {
+		name                                     string
+		timestamp, nonce, method, path, rawQuery string
+		body                                     string
+		want                                     string
+	}{
+		{
+			name:      "get without body",
+			timestamp: "1700000000", nonce: "n1", method: "GET", path: "/api/v1/token/status",
+			want: "668a790bcdbae327a104e05be9d9cad094ccedeaf8e6a7ddd432c81d270abe20",
+		},
+		{
+			name:      "post with query and body",
+			timestamp: "1700000000", nonce: "n2", method: "POST", path: "/api/v1/logging/sample-rate", rawQuery: "dry=1",
+			body: `{"rate": 10}`,
+			want: "899ceeaf2f247ceecae8508551d10d24d6a23fd15d6fc2c12b0918eaf7743b5c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hex.EncodeToString(signAdminRequest("secret", tt.timestamp, tt.nonce, tt.method, tt.path, tt.rawQuery, []byte(tt.body)))
+			if got != tt.want {
+				t.Errorf("signAdminRequest = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyAdminSignature(t *testing.T) {
+	const key = "secret"
+	now := time.Now()
+	tests := []struct {
+		name    string
+		request func(nonce string) *http.Request
+		wantOK  bool
+		wantMsg string
+	}{
+		{
+			name: "valid get",
+			request: func(nonce string) *http.Request {
+				return signedAdminRequest(key, http.MethodGet, "/api/v1/token/status", "", nonce, now)
+			},
+			wantOK: true,
+		},
+		{
+			name: "valid post with query and body",
+			request: func(nonce string) *http.Request {
+				return signedAdminRequest(key, http.MethodPost, "/api/v1/logging/sample-rate?dry=1", `{"rate":10}`, nonce, now)
+			},
+			wantOK: true,
+		},
+		{
+			name: "expired",
+			request: func(nonce string) *http.Request {
+				return signedAdminRequest(key, http.MethodGet, "/api/v1/token/status", "", nonce, now.Add(-2*adminSignatureMaxAge))
+			},
+			wantMsg: "Request timestamp expired",
+		},
+		{
+			name: "from the future",
+			request: func(nonce string) *http.Request {
+				return signedAdminRequest(key, http.MethodGet, "/api/v1/token/status", "", nonce, now.Add(2*adminSignatureMaxAge))
+			},
+			wantMsg: "Request timestamp expired",
+		},
+		{
+			name: "wrong key",
+			request: func(nonce string) *http.Request {
+				return signedAdminRequest("other", http.MethodGet, "/api/v1/token/status", "", nonce, now)
+			},
+			wantMsg: "Invalid signature",
+		},
+		{
+			name: "tampered query",
+			request: func(nonce string) *http.Request {
+				r := signedAdminRequest(key, http.MethodGet, "/api/v1/token/status?a=1", "", nonce, now)
+				r.URL.RawQuery = "a=2"
+				return r
+			},
+			wantMsg: "Invalid signature",
+		},
+		{
+			name: "tampered body",
+			request: func(nonce string) *http.Request {
+				r := signedAdminRequest(key, http.MethodPost, "/api/v1/logging/sample-rate", `{"rate":10}`, nonce, now)
+				r.Body = io.NopCloser(strings.NewReader(`{"rate":1}`))
+				return r
+			},
+			wantMsg: "Invalid signature",
+		},
+		{
+			name: "tampered path",
+			request: func(nonce string) *http.Request {
+				r := signedAdminRequest(key, http.MethodGet, "/api/v1/token/status", "", nonce, now)
+				r.URL.Path = "/api/v1/token/refresh"
+				return r
+			},
+			wantMsg: "Invalid signature",
+		},
+		{
+			name: "missing nonce",
+			request: func(nonce string) *http.Request {
+				r := signedAdminRequest(key, http.MethodGet, "/api/v1/token/status", "", nonce, now)
+				r.Header.Del("X-Admin-Nonce")
+				return r
+			},
+			wantMsg: "X-Admin-Timestamp and X-Admin-Nonce are required",
+		},
+		{
+			name: "invalid timestamp",
+			request: func(nonce string) *http.Request {
+				r := signedAdminRequest(key, http.MethodGet, "/api/v1/token/status", "", nonce, now)
+				r.Header.Set("X-Admin-Timestamp", "yesterday")
+				return r
+			},
+			wantMsg: "Invalid X-Admin-Timestamp",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nonce := "nonce-" + strconv.Itoa(i) + "-" + strconv.FormatInt(now.UnixNano(), 10)
+			msg, ok := verifyAdminSignature(tt.request(nonce), key, now)
+			if ok != tt.wantOK || msg != tt.wantMsg {
+				t.Errorf("verifyAdminSignature = (%q, %v), want (%q, %v)", msg, ok, tt.wantMsg, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestVerifyAdminSignatureRejectsReplayedNonce(t *testing.T) {
+	const key = "secret"
+	now := time.Now()
+	nonce := "replay-" + strconv.FormatInt(now.UnixNano(), 10)
+
+	if msg, ok := verifyAdminSignature(signedAdminRequest(key, http.MethodGet, "/api/v1/token/status", "", nonce, now), key, now); !ok {
+		t.Fatalf("first request rejected: %s", msg)
+	}
+	msg, ok := verifyAdminSignature(signedAdminRequest(key, http.MethodGet, "/api/v1/token/status", "", nonce, now), key, now)
+	if ok || msg != "Nonce already used" {
+		t.Errorf("replayed request = (%q, %v), want rejection for reused nonce", msg, ok)
+	}
+}
+
+func TestVerifyAdminSignatureRestoresBody(t *testing.T) {
+	now := time.Now()
+	r := signedAdminRequest("secret", http.MethodPost, "/api/v1/logging/sample-rate", `{"rate":10}`, "body-"+strconv.FormatInt(now.UnixNano(), 10), now)
+	if msg, ok := verifyAdminSignature(r, "secret", now); !ok {
+		t.Fatalf("verifyAdminSignature rejected valid request: %s", msg)
+	}
+	body, _ := io.ReadAll(r.Body)
+	if string(body) != `{"rate":10}` {
+		t.Errorf("body after verification = %q, want original body", body)
+	}
+}
+
+func TestNonceStoreForgetsExpiredNonces(t *testing.T) {
+	store := newNonceStore()
+	start := time.Now()
+	expires := start.Add(adminSignatureMaxAge)
+	if !store.use("n", start, expires) {
+		t.Fatal("first use rejected")
+	}
+	if store.use("n", start.Add(time.Second), start.Add(time.Second+adminSignatureMaxAge)) {
+		t.Error("reuse before expiry accepted")
+	}
+	if !store.use("n", expires.Add(time.Second), expires.Add(time.Second+adminSignatureMaxAge)) {
+		t.Error("nonce not forgotten after expiry")
+	}
+}
+
+func TestVerifyAdminSignatureRejectsReplayedFutureNonce(t *testing.T) {
+	const key = "secret"
+	now := time.Now()
+	nonce := "future-" + strconv.FormatInt(now.UnixNano(), 10)
+	// 허용 범위 끝의 앞선 타임스탬프로 서명한 요청은 받은 뒤 adminSignatureMaxAge가 지나도 타임스탬프 검사를 통과합니다
+	signedAt := now.Add(adminSignatureMaxAge - time.Second)
+
+	tests := []struct {
+		name   string
+		at     time.Time
+		wantOK bool
+	}{
+		{"first use", now, true},
+		{"replay after 61s", now.Add(adminSignatureMaxAge + time.Second), false},
+		{"replay just before the timestamp expires", signedAt.Add(adminSignatureMaxAge - time.Second), false},
+	}
+	for _, tt := range tests {
+		msg, ok := verifyAdminSignature(signedAdminRequest(key, http.MethodGet, "/api/v1/token/status", "", nonce, signedAt), key, tt.at)
+		if ok != tt.wantOK {
+			t.Errorf("%s: verifyAdminSignature = (%q, %v), want ok %v", tt.name, msg, ok, tt.wantOK)
+		}
+		if !tt.wantOK && msg != "Nonce already used" {
+			t.Errorf("%s: msg = %q, want nonce rejection", tt.name, msg)
+		}
+	}
+}
+
+func TestAdminAuth(t *testing.T) {
+	tests := []struct {
+		name             string
+		adminKey         string
+		requireSignature bool
+		header           string
+		wantStatus       int
+	}{
+		{name: "no admin key configured", wantStatus: http.StatusOK},
+		{name: "matching key", adminKey: "secret", header: "secret", wantStatus: http.StatusOK},
+		{name: "wrong key", adminKey: "secret", header: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "missing key", adminKey: "secret", wantStatus: http.StatusUnauthorized},
+		{name: "key rejected when signature required", adminKey: "secret", requireSignature: true, header: "secret", wantStatus: http.StatusUnauthorized},
+	}
+
+	handler := adminAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) {
+				cfg.AdminKey = tt.adminKey
+				cfg.AdminRequireSignature = tt.requireSignature
+			})
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/token/status", nil)
+			if tt.header != "" {
+				r.Header.Set("X-Admin-Key", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAdminAuthAcceptsSignatureWhenRequired(t *testing.T) {
+	withConfig(t, func(cfg *Config) {
+		cfg.AdminKey = "secret"
+		cfg.AdminRequireSignature = true
+	})
+	var gotBody string
+	handler := adminAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+	}))
+
+	now := time.Now()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, signedAdminRequest("secret", http.MethodPost, "/api/v1/logging/sample-rate", `{"rate":5}`, "signed-"+strconv.FormatInt(now.UnixNano(), 10), now))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if gotBody != `{"rate":5}` {
+		t.Errorf("handler body = %q, want original body", gotBody)
+	}
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// 자산을 동시에 내려받는 수
+const archiveDownloadConcurrency = 4
+
+// ArchiveManifest는 아카이브의 manifest.json입니다
+type ArchiveManifest struct {
+	PostID   string         `json:"post_id"`
+	Title    string         `json:"title,omitempty"`
+	Created  time.Time      `json:"created_at"`
+	Assets   []ArchiveAsset `json:"assets"`
+	Warnings []Warning      `json:"warnings,omitempty"`
+}
+
+// ArchiveAsset은 아카이브에 포함하려고 한 이미지/첨부 파일입니다
+type ArchiveAsset struct {
+	URL   string `json:"url"`
+	Path  string `json:"path,omitempty"`  // 아카이브 안의 경로 (포함하지 못했으면 생략)
+	Size  int64  `json:"size,omitempty"`  // 바이트 수
+	Error string `json:"error,omitempty"` // 포함하지 못한 이유 (HTML에는 원래 URL이 남습니다)
+
+	data []byte
+}
+
+// GetArchive godoc
+// @Summary Download a post as a self-contained tar.gz
+// @Description Returns a gzip-compressed tar containing index.html, the post's images under assets/ and attachments under attachments/, with links in index.html rewritten to the archived copies, plus manifest.json. Assets are capped by ARCHIVE_MAX_ASSETS and ARCHIVE_MAX_BYTES; assets that do not fit are left as remote links and listed in the manifest.
+// @Tags content
+// @Accept json
+// @Produce application/gzip
+// @Param request body ContentRequest true "Post ID and options"
+// @Success 200 {file} file "tar.gz archive"
+// @Failure 400 {string} string "Bad request"
+// @Failure 500 {string} string "Internal server error"
+// @Failure 502 {string} string "BetterMode unavailable (non-JSON response)"
+// @Router /archive [post]
+// @Router /archive [get]
+func getArchive(w http.ResponseWriter, r *http.Request) {
+	var req ContentRequest
+	if err := decodeContentRequest(r, &req, decodeLenient); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.PostID == "" {
+		writeError(w, "Post ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// 아카이브는 항상 원본 HTML과 첨부 파일을 담습니다
+	req.Format = "html"
+	req.IncludeAttachments = true
+	if req.Encoding != "" || req.TTS || req.MetadataOnly || req.ProxyImages {
+		writeError(w, "encoding, tts, metadata_only and proxy_images are not supported for archives", http.StatusBadRequest)
+		return
+	}
+	if err := req.ContentOptions.normalize(); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := buildContentResponse(r.Context(), req)
+	if err != nil {
+		writeFetchError(w, err)
+		return
+	}
+
+	page, assets, err := collectArchiveAssets(r.Context(), response)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Error building archive: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	manifest := ArchiveManifest{
+		PostID:   response.PostID,
+		Title:    response.Title,
+		Created:  time.Now().UTC(),
+		Assets:   assets,
+		Warnings: response.Warnings,
+	}
+
+	var buf bytes.Buffer
+	if err := writeArchive(&buf, page, manifest); err != nil {
+		writeError(w, fmt.Sprintf("Error building archive: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", response.PostID+".tar.gz"))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	setCacheControl(w, req.NoCache)
+	w.Write(buf.Bytes())
+}
+
+// collectArchiveAssets는 본문의 이미지와 첨부 파일을 내려받고, 내려받은 것은 아카이브 안의 상대 경로를 가리키도록
+// 링크를 바꾼 HTML 문서를 반환합니다.
+func collectArchiveAssets(ctx context.Context, response ContentResponse) (string, []ArchiveAsset, error) {
+	cfg := currentConfig()
+
+	nodes, err := parseHTMLFragment(response.Content)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// 같은 URL은 한 번만 내려받습니다
+	var assets []ArchiveAsset
+	index := make(map[string]int)
+	add := func(rawURL, dir, name string) {
+		if _, ok := index[rawURL]; ok || !isArchivableURL(rawURL) {
+			return
+		}
+		index[rawURL] = len(assets)
+		asset := ArchiveAsset{URL: rawURL}
+		if len(assets) >= cfg.ArchiveMaxAssets {
+			asset.Error = fmt.Sprintf("more than ARCHIVE_MAX_ASSETS (%d) assets", cfg.ArchiveMaxAssets)
+		} else {
+			asset.Path = dir + "/" + name
+		}
+		assets = append(assets, asset)
+	}
+
+	var images []*html.Node
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			if c.Type == html.ElementNode && c.Data == "img" {
+				images = append(images, c)
+			}
+			return true
+		})
+	}
+	for _, img := range images {
+		src := strings.TrimSpace(attrValue(img, "src"))
+		add(src, "assets", fmt.Sprintf("%03d%s", len(assets)+1, assetExtension(src)))
+	}
+	for i, a := range response.Attachments {
+		name := path.Base(a.Filename)
+		if name == "." || name == "/" || name == "" {
+			name = "attachment"
+		}
+		add(a.URL, "attachments", fmt.Sprintf("%03d-%s", i+1, name))
+	}
+
+	downloadArchiveAssets(ctx, assets, int64(cfg.ArchiveMaxBytes))
+
+	// 내려받은 자산은 아카이브 안의 경로로, 실패한 것은 원래 URL 그대로 둡니다
+	local := make(map[string]string)
+	for _, asset := range assets {
+		if asset.Error == "" {
+			local[asset.URL] = asset.Path
+		}
+	}
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			if c.Type != html.ElementNode {
+				return true
+			}
+			key := "src"
+			if c.Data == "a" {
+				key = "href"
+			} else if c.Data != "img" {
+				return true
+			}
+			for i, attr := range c.Attr {
+				if strings.EqualFold(attr.Key, key) {
+					if p, ok := local[strings.TrimSpace(attr.Val)]; ok {
+						c.Attr[i].Val = p
+					}
+				}
+			}
+			if c.Data == "img" {
+				// srcset이 남아 있으면 브라우저가 원격 이미지를 다시 불러옵니다
+				c.Attr = removeAttr(c.Attr, "srcset")
+			}
+			return true
+		})
+	}
+
+	body, err := renderHTMLFragment(nodes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var attachments strings.Builder
+	for _, a := range response.Attachments {
+		href := a.URL
+		if p, ok := local[a.URL]; ok {
+			href = p
+		}
+		fmt.Fprintf(&attachments, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(href), html.EscapeString(a.Filename))
+	}
+	page := "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>" + html.EscapeString(response.Title) + "</title>\n</head>\n<body>\n"
+	if response.Title != "" {
+		page += "<h1>" + html.EscapeString(response.Title) + "</h1>\n"
+	}
+	page += body + "\n"
+	if attachments.Len() > 0 {
+		page += "<h2>Attachments</h2>\n<ul>\n" + attachments.String() + "</ul>\n"
+	}
+	page += "</body>\n</html>\n"
+	return page, assets, nil
+}
+
+// downloadArchiveAssets는 Error가 없는 자산을 동시에 내려받습니다. 합계가 maxBytes를 넘는 자산은 포함하지 않습니다.
+func downloadArchiveAssets(ctx context.Context, assets []ArchiveAsset, maxBytes int64) {
+	var (
+		mutex sync.Mutex
+		total int64
+		wg    sync.WaitGroup
+	)
+	jobs := make(chan int)
+	for w := 0; w < archiveDownloadConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mutex.Lock()
+				remaining := maxBytes - total
+				mutex.Unlock()
+
+				data, err := downloadArchiveAsset(ctx, assets[i].URL, remaining)
+				mutex.Lock()
+				if err == nil && total+int64(len(data)) > maxBytes {
+					err = fmt.Errorf("archive would exceed ARCHIVE_MAX_BYTES (%d bytes)", maxBytes)
+				}
+				if err != nil {
+					assets[i].Error = err.Error()
+					assets[i].Path = ""
+				} else {
+					total += int64(len(data))
+					assets[i].data = data
+					assets[i].Size = int64(len(data))
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for i := range assets {
+		if assets[i].Error == "" {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// downloadArchiveAsset은 rawURL을 최대 limit 바이트까지 내려받습니다
+func downloadArchiveAsset(ctx context.Context, rawURL string, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return nil, errors.New("archive size limit (ARCHIVE_MAX_BYTES) reached")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "GPTers-Scraper/1.0")
+
+	resp, err := archiveClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("archive would exceed ARCHIVE_MAX_BYTES")
+	}
+	return data, nil
+}
+
+// writeArchive는 index.html, 내려받은 자산, manifest.json을 tar.gz로 씁니다
+func writeArchive(w io.Writer, page string, manifest ArchiveManifest) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	writeFile := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: manifest.Created, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := writeFile("index.html", []byte(page)); err != nil {
+		return err
+	}
+	for _, asset := range manifest.Assets {
+		if asset.Error != "" {
+			continue
+		}
+		if err := writeFile(asset.Path, asset.data); err != nil {
+			return err
+		}
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile("manifest.json", manifestJSON); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// isArchivableURL은 내려받을 수 있는 절대 http(s) URL인지 확인합니다
+func isArchivableURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// assetExtension은 URL 경로의 확장자를 반환합니다. 알 수 없는 확장자이면 빈 문자열입니다.
+func assetExtension(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	if ext == "" || len(ext) > 6 || mime.TypeByExtension(ext) == "" {
+		return ""
+	}
+	return ext
+}
+
+func removeAttr(attrs []html.Attribute, key string) []html.Attribute {
+	kept := attrs[:0]
+	for _, attr := range attrs {
+		if !strings.EqualFold(attr.Key, key) {
+			kept = append(kept, attr)
+		}
+	}
+	return kept
+}
+
+// archiveClient는 본문에 적힌 URL을 내려받으므로, 서버 내부망(루프백, 사설, 링크 로컬 주소)에는 연결하지 않습니다.
+// HTTP_PROXY를 거치면 연결 주소가 프록시가 되어 이 검사를 피해 가므로 프록시는 쓰지 않습니다.
+var archiveClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: refuseInternalAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+}
+
+// internalNetworks는 IsPrivate 등으로 잡히지 않는 내부 주소 대역입니다 (공유 주소 공간(CGNAT), "이 네트워크")
+var internalNetworks = []*net.IPNet{
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("0.0.0.0/8"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// isInternalIP는 ip가 서버 내부망 주소인지 확인합니다
+func isInternalIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range internalNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func refuseInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isInternalIP(ip) {
+		return fmt.Errorf("refusing to fetch asset from internal address %s", host)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withArchiveAssets는 archiveClient가 assets에 있는 URL은 그 내용으로, 없는 URL은 404로 응답하게 합니다
+func withArchiveAssets(t *testing.T, assets map[string]string) {
+	t.Helper()
+	prev := archiveClient
+	archiveClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		if body, ok := assets[r.URL.String()]; ok {
+			rec.WriteString(body)
+		} else {
+			rec.WriteHeader(http.StatusNotFound)
+		}
+		return rec.Result(), nil
+	})}
+	t.Cleanup(func() { archiveClient = prev })
+}
+
+func TestIsArchivableURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://cdn.example.com/a.png", true},
+		{"http://cdn.example.com/a.png", true},
+		{"/relative/a.png", false},
+		{"data:image/png;base64,AAAA", false},
+		{"ftp://cdn.example.com/a.png", false},
+		{"https://", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isArchivableURL(tt.url); got != tt.want {
+			t.Errorf("isArchivableURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestAssetExtension(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://cdn.example.com/a.PNG", ".png"},
+		{"https://cdn.example.com/a.jpg?w=100", ".jpg"},
+		{"https://cdn.example.com/image", ""},
+		{"https://cdn.example.com/a.unknownext", ""},
+		{"https://cdn.example.com/a.zzz", ""},
+	}
+	for _, tt := range tests {
+		if got := assetExtension(tt.url); got != tt.want {
+			t.Errorf("assetExtension(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCollectArchiveAssets(t *testing.T) {
+	withArchiveAssets(t, map[string]string{
+		"https://cdn.example.com/a.png":   "png-a",
+		"https://cdn.example.com/doc.pdf": "pdf",
+	})
+	response := ContentResponse{
+		PostID: "post-1",
+		Title:  "제목",
+		Content: `<p><img src="https://cdn.example.com/a.png" srcset="https://cdn.example.com/a@2x.png 2x">` +
+			`<img src="https://cdn.example.com/a.png"><img src="https://cdn.example.com/missing.png"></p>`,
+		Attachments: []Attachment{{Filename: "../doc.pdf", URL: "https://cdn.example.com/doc.pdf"}},
+	}
+
+	tests := []struct {
+		name       string
+		maxAssets  int
+		maxBytes   int
+		wantPaths  []string
+		wantErrors int
+	}{
+		{"all fit", 10, 1 << 20, []string{"assets/001.png", "", "attachments/001-doc.pdf"}, 1},
+		{"asset cap", 1, 1 << 20, []string{"assets/001.png", "", ""}, 2},
+		{"byte cap", 10, 2, []string{"", "", ""}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) {
+				cfg.ArchiveMaxAssets = tt.maxAssets
+				cfg.ArchiveMaxBytes = tt.maxBytes
+			})
+			page, assets, err := collectArchiveAssets(context.Background(), response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(assets) != len(tt.wantPaths) {
+				t.Fatalf("assets = %+v, want %d", assets, len(tt.wantPaths))
+			}
+			errorCount := 0
+			for i, asset := range assets {
+				if asset.Path != tt.wantPaths[i] {
+					t.Errorf("assets[%d].Path = %q, want %q", i, asset.Path, tt.wantPaths[i])
+				}
+				if asset.Error != "" {
+					errorCount++
+				} else if !strings.Contains(page, `"`+asset.Path+`"`) {
+					t.Errorf("page does not link %s:\n%s", asset.Path, page)
+				}
+			}
+			if errorCount != tt.wantErrors {
+				t.Errorf("assets with errors = %d, want %d", errorCount, tt.wantErrors)
+			}
+			if strings.Contains(page, "srcset") {
+				t.Errorf("page still has srcset:\n%s", page)
+			}
+			if !strings.Contains(page, "https://cdn.example.com/missing.png") {
+				t.Errorf("failed asset should keep its remote URL:\n%s", page)
+			}
+		})
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	manifest := ArchiveManifest{
+		PostID:  "post-1",
+		Created: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Assets: []ArchiveAsset{
+			{URL: "https://cdn.example.com/a.png", Path: "assets/001.png", Size: 3, data: []byte("png")},
+			{URL: "https://cdn.example.com/b.png", Error: "HTTP 404"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := writeArchive(&buf, "<p>page</p>", manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readTarGz(t, buf.Bytes())
+	want := map[string]string{"index.html": "<p>page</p>", "assets/001.png": "png"}
+	for name, content := range want {
+		if files[name] != content {
+			t.Errorf("%s = %q, want %q", name, files[name], content)
+		}
+	}
+	if len(files) != 3 {
+		t.Errorf("files = %v, want index.html, assets/001.png and manifest.json", files)
+	}
+	var got ArchiveManifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.PostID != "post-1" || len(got.Assets) != 2 || got.Assets[1].Error != "HTTP 404" {
+		t.Errorf("manifest = %+v", got)
+	}
+}
+
+func TestGetArchiveRejectsUnsupportedOptions(t *testing.T) {
+	for _, query := range []string{"encoding=base64", "tts=true", "metadata_only=true", "proxy_images=true"} {
+		rec := httptest.NewRecorder()
+		getArchive(rec, httptest.NewRequest(http.MethodGet, "/api/v1/archive?post_id=post-1&"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+// readTarGz는 tar.gz의 파일 이름과 내용을 반환합니다
+func readTarGz(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(content)
+	}
+}
+
+func TestRefuseInternalAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		refused bool
+	}{
+		{"127.0.0.1:80", true},
+		{"[::1]:443", true},
+		{"10.1.2.3:80", true},
+		{"172.16.0.1:80", true},
+		{"192.168.1.1:80", true},
+		{"169.254.169.254:80", true},
+		{"[fe80::1]:80", true},
+		{"[fc00::1]:80", true},
+		{"0.0.0.0:80", true},
+		{"0.1.2.3:80", true},
+		{"100.64.0.1:80", true},
+		{"100.127.255.254:80", true},
+		{"[::ffff:127.0.0.1]:80", true},
+		{"[::ffff:100.64.0.1]:80", true},
+		{"[::]:80", true},
+		{"100.63.255.255:80", false},
+		{"100.128.0.1:80", false},
+		{"93.184.216.34:443", false},
+		{"[2606:4700::1111]:443", false},
+	}
+	for _, tt := range tests {
+		err := refuseInternalAddress("tcp", tt.address, nil)
+		if refused := err != nil; refused != tt.refused {
+			t.Errorf("refuseInternalAddress(%q) = %v, want refused %v", tt.address, err, tt.refused)
+		}
+	}
+	if err := refuseInternalAddress("tcp", "no-port", nil); err == nil {
+		t.Error("address without port should be refused")
+	}
+}
+
+func TestArchiveClientIgnoresProxy(t *testing.T) {
+	transport, ok := archiveClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("archiveClient transport = %T, want *http.Transport", archiveClient.Transport)
+	}
+	if transport.Proxy != nil {
+		t.Error("archiveClient uses a proxy; a proxy would bypass refuseInternalAddress")
+	}
+}
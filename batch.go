@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// BatchRequest는 여러 게시물의 콘텐츠를 한 번에 가져오기 위한 요청입니다
+type BatchRequest struct {
+	PostIDs []string `json:"post_ids"`
+	// 게시물마다 여러 형식을 함께 받습니다 (예: ["html", "text"]). BetterMode에서는 한 번만 가져옵니다
+	Formats []string `json:"formats,omitempty"`
+	// true이면 (또는 Accept: application/x-ndjson) 결과를 모아 두지 않고 끝나는 대로 한 줄씩 NDJSON으로 보냅니다
+	Stream bool `json:"stream,omitempty"`
+	ContentOptions
+}
+
+// BatchItemResult는 배치 요청의 게시물 하나에 대한 결과입니다
+type BatchItemResult struct {
+	PostID  string           `json:"post_id"`
+	Result  *ContentResponse `json:"result,omitempty"`
+	Error   string           `json:"error,omitempty"`
+	Skipped bool             `json:"skipped,omitempty"` // 요청이 취소되어 가져오지 않은 경우
+
+	// formats 요청 시 형식별 content. result는 첫 번째 형식의 응답이고, warnings에는 모든 형식의 경고가 들어갑니다
+	Contents map[string]string `json:"contents,omitempty"`
+}
+
+// ndjsonContentType은 스트리밍 배치 응답의 Content-Type입니다 (한 줄에 BatchItemResult 하나)
+const ndjsonContentType = "application/x-ndjson"
+
+// BatchResponse는 배치 요청 전체의 결과입니다
+type BatchResponse struct {
+	Results   []BatchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Skipped   int               `json:"skipped"`
+	Cancelled bool              `json:"cancelled,omitempty"` // 처리 도중 요청이 취소되어 일부 결과만 있는 경우
+}
+
+// GetBatchContent godoc
+// @Summary Get content for multiple posts
+// @Description Fetches several posts with bounded concurrency. Stops fetching remaining posts when the client disconnects. With stream=true or Accept: application/x-ndjson, each BatchItemResult is written as one NDJSON line as soon as it completes (in completion order, not input order) instead of one BatchResponse at the end.
+// @Tags content
+// @Accept json
+// @Produce json
+// @Produce application/x-ndjson
+// @Param request body BatchRequest true "Post IDs and shared options"
+// @Success 200 {object} BatchResponse
+// @Failure 400 {string} string "Bad request"
+// @Router /batch [post]
+func getBatchContent(w http.ResponseWriter, r *http.Request) {
+	cfg := currentConfig()
+	var req BatchRequest
+	if err := decodeContentRequest(r, &req, decodeLenient); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.PostIDs) == 0 {
+		writeError(w, "post_ids is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.PostIDs) > cfg.BatchMaxItems {
+		writeError(w, fmt.Sprintf("Too many post_ids (max %d)", cfg.BatchMaxItems), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Formats) > 0 {
+		if err := validateBatchFormats(&req); err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := req.ContentOptions.normalize(); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Stream || acceptsNDJSON(r) {
+		streamBatch(w, r, req, cfg.BatchConcurrency)
+		return
+	}
+
+	results, cancelled := fetchBatch(r.Context(), req.PostIDs, req.Formats, req.ContentOptions, cfg.BatchConcurrency)
+
+	response := BatchResponse{Results: results, Cancelled: cancelled}
+	for _, item := range results {
+		switch {
+		case item.Skipped:
+			response.Skipped++
+		case item.Error != "":
+			response.Failed++
+		default:
+			response.Succeeded++
+		}
+	}
+
+	setCacheControl(w, req.NoCache)
+	render.JSON(w, r, response)
+}
+
+// acceptsNDJSON은 클라이언트가 Accept 헤더로 NDJSON 스트리밍 응답을 요청했는지 확인합니다
+func acceptsNDJSON(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, _, _ := strings.Cut(part, ";")
+			if strings.EqualFold(strings.TrimSpace(mediaType), ndjsonContentType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// streamBatch는 게시물마다 결과가 나오는 대로 BatchItemResult를 한 줄씩 쓰고 바로 내보냅니다(flush).
+// 결과를 모아 두지 않으므로 큰 배치도 메모리를 적게 쓰고, 클라이언트는 먼저 끝난 게시물부터 처리할 수 있습니다.
+// 응답을 쓰지 못하면(클라이언트 끊김 등) 남은 게시물은 가져오지 않습니다.
+func streamBatch(w http.ResponseWriter, r *http.Request, req BatchRequest, concurrency int) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	// 프록시가 응답을 모아 두지 않도록 합니다 (nginx)
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	var mutex sync.Mutex
+	runBatch(ctx, req.PostIDs, req.Formats, req.ContentOptions, concurrency, func(_ int, item BatchItemResult) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		if err := enc.Encode(item); err != nil {
+			cancel()
+			return
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			cancel()
+		}
+	})
+}
+
+// validateBatchFormats는 formats 값을 검증하고, 공통 옵션 검증(normalize)에 쓸 format을 정합니다
+func validateBatchFormats(req *BatchRequest) error {
+	if req.Format != "" {
+		return errors.New("use either format or formats, not both")
+	}
+	seen := make(map[string]bool, len(req.Formats))
+	for _, format := range req.Formats {
+		if !isValidFormat(format) {
+			return errors.New("formats must contain only 'html', 'text', 'xhtml' or 'jsonld'")
+		}
+		if seen[format] {
+			return fmt.Errorf("format %q is listed more than once", format)
+		}
+		seen[format] = true
+	}
+	// tts는 text 형식에만 적용됩니다
+	if req.TTS && !seen["text"] {
+		return errors.New("tts requires 'text' in formats")
+	}
+	req.Format = req.Formats[0]
+	if req.TTS {
+		req.Format = "text"
+	}
+	return nil
+}
+
+// fetchBatch는 최대 concurrency개의 워커로 게시물들을 가져와 입력 순서대로 결과를 반환합니다.
+// formats가 있으면 게시물마다 그 형식들을 모두 만듭니다 (fetchBatchItemFormats 참고).
+// ctx가 취소되면 대기 중인 게시물은 가져오지 않고 Skipped로 표시하며, 두 번째 반환값이 true가 됩니다.
+func fetchBatch(ctx context.Context, postIDs []string, formats []string, opts ContentOptions, concurrency int) ([]BatchItemResult, bool) {
+	results := make([]BatchItemResult, len(postIDs))
+	cancelled := runBatch(ctx, postIDs, formats, opts, concurrency, func(i int, item BatchItemResult) {
+		results[i] = item
+	})
+	return results, cancelled
+}
+
+// runBatch는 fetchBatch처럼 게시물들을 가져오되, 결과를 모으지 않고 끝나는 대로 done(입력 순서 번호, 결과)을 부릅니다.
+// done은 여러 워커에서 동시에 불릴 수 있고, 건너뛴 게시물도 Skipped 결과로 한 번씩 불립니다.
+func runBatch(ctx context.Context, postIDs []string, formats []string, opts ContentOptions, concurrency int, done func(int, BatchItemResult)) bool {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if len(formats) > 0 {
+					done(i, fetchBatchItemFormats(ctx, postIDs[i], formats, opts))
+				} else {
+					done(i, fetchBatchItem(ctx, postIDs[i], opts))
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range postIDs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			// 아직 워커에 넘기지 않은 게시물은 모두 건너뜁니다
+			for j := i; j < len(postIDs); j++ {
+				done(j, BatchItemResult{PostID: postIDs[j], Skipped: true})
+			}
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return ctx.Err() != nil
+}
+
+func fetchBatchItem(ctx context.Context, postID string, opts ContentOptions) BatchItemResult {
+	if ctx.Err() != nil {
+		return BatchItemResult{PostID: postID, Skipped: true}
+	}
+
+	response, err := buildContentResponse(ctx, ContentRequest{PostID: postID, ContentOptions: opts})
+	if err != nil {
+		return batchItemError(ctx, postID, err)
+	}
+	return BatchItemResult{PostID: postID, Result: &response}
+}
+
+// fetchBatchItemFormats는 게시물을 BetterMode에서 한 번만 가져와 formats의 각 형식으로 만듭니다.
+// 형식마다 응답 캐시를 거치면 캐시가 꺼져 있거나 항목이 만료된 경우 형식 수만큼 다시 가져올 수 있어 캐시를 쓰지 않습니다.
+func fetchBatchItemFormats(ctx context.Context, postID string, formats []string, opts ContentOptions) BatchItemResult {
+	if ctx.Err() != nil {
+		return BatchItemResult{PostID: postID, Skipped: true}
+	}
+	if opts.RevisionID != "" {
+		return batchItemError(ctx, postID, errRevisionsUnsupported)
+	}
+
+	start := time.Now()
+	req := ContentRequest{PostID: postID, ContentOptions: opts}
+	if containsString(formats, FormatJSONLD) {
+		// jsonld에 필요한 작성자와 게시 시각도 한 번에 가져옵니다
+		req.Format = FormatJSONLD
+	}
+	fetchCtx, cancel := withFetchTimeout(ctx, opts)
+	defer cancel()
+	post, err := fetchPost(fetchCtx, req)
+	if err != nil {
+		return batchItemError(ctx, postID, err)
+	}
+
+	item := BatchItemResult{PostID: postID, Contents: make(map[string]string, len(formats))}
+	seenWarnings := make(map[Warning]bool)
+	for _, format := range formats {
+		formatReq := req
+		formatReq.Format = format
+		formatReq.TTS = opts.TTS && format == "text"
+		response, err := renderContentResponse(fetchCtx, formatReq, post)
+		if err == nil {
+			err = checkStrict(formatReq, response)
+		}
+		if err != nil {
+			return batchItemError(ctx, postID, err)
+		}
+
+		item.Contents[format] = response.Content
+		if item.Result == nil {
+			item.Result = &response
+			for _, warning := range response.Warnings {
+				seenWarnings[warning] = true
+			}
+			continue
+		}
+		for _, warning := range response.Warnings {
+			if !seenWarnings[warning] {
+				seenWarnings[warning] = true
+				item.Result.Warnings = append(item.Result.Warnings, warning)
+			}
+		}
+	}
+	item.Result.FetchLatencyMs = time.Since(start).Milliseconds()
+	return item
+}
+
+// batchItemError는 에러를 배치 항목 결과로 바꿉니다. 클라이언트가 끊어 취소된 경우는 Skipped로 표시합니다.
+func batchItemError(ctx context.Context, postID string, err error) BatchItemResult {
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return BatchItemResult{PostID: postID, Skipped: true}
+	}
+	return BatchItemResult{PostID: postID, Error: err.Error()}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// batchUpstream은 요청 변수의 id로 게시물을 돌려주고, "missing"이면 NOT_FOUND 에러를 돌려줍니다
+func batchUpstream(t *testing.T, onCall func()) *fakeUpstream {
+	return withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		if onCall != nil {
+			onCall()
+		}
+		req := readGraphQLRequest(t, r)
+		if req.Variables["id"] == "missing" {
+			writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+				"data":   map[string]interface{}{"post": nil},
+				"errors": []map[string]interface{}{{"message": "Not found", "extensions": map[string]string{"code": "NOT_FOUND"}}},
+			})
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목 "+req.Variables["id"].(string), "<p>본문</p>")))
+	})
+}
+
+func TestFetchBatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		postIDs     []string
+		concurrency int
+		wantErrors  []bool
+	}{
+		{"all found", []string{"a", "b", "c"}, 2, []bool{false, false, false}},
+		{"one missing keeps order", []string{"a", "missing", "c"}, 3, []bool{false, true, false}},
+		{"concurrency below one", []string{"a"}, 0, []bool{false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			batchUpstream(t, nil)
+
+			results, cancelled := fetchBatch(context.Background(), tt.postIDs, nil, ContentOptions{Format: "html"}, tt.concurrency)
+			if cancelled {
+				t.Error("cancelled = true, want false")
+			}
+			for i, item := range results {
+				if item.PostID != tt.postIDs[i] {
+					t.Errorf("results[%d].post_id = %q, want %q", i, item.PostID, tt.postIDs[i])
+				}
+				if (item.Error != "") != tt.wantErrors[i] {
+					t.Errorf("results[%d].error = %q, want error %v", i, item.Error, tt.wantErrors[i])
+				}
+				if !tt.wantErrors[i] && (item.Result == nil || item.Result.Title != "제목 "+tt.postIDs[i]) {
+					t.Errorf("results[%d].result = %+v", i, item.Result)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchBatchStopsWhenCancelled(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// 첫 게시물을 가져오는 동안 클라이언트가 끊깁니다
+	upstream := batchUpstream(t, cancel)
+
+	postIDs := []string{"a", "b", "c", "d"}
+	results, cancelled := fetchBatch(ctx, postIDs, nil, ContentOptions{Format: "html"}, 1)
+	waitForContentFetch(ContentRequest{PostID: "a", ContentOptions: ContentOptions{Format: "html"}})
+	if !cancelled {
+		t.Error("cancelled = false, want true")
+	}
+	if got := upstream.count(); got != 1 {
+		t.Errorf("upstream calls = %d, want 1", got)
+	}
+	for i, item := range results {
+		if !item.Skipped {
+			t.Errorf("results[%d] = %+v, want skipped", i, item)
+		}
+	}
+}
+
+func TestValidateBatchFormats(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        BatchRequest
+		wantFormat string
+		wantErr    bool
+	}{
+		{"first format", BatchRequest{Formats: []string{"xhtml", "text"}}, "xhtml", false},
+		{"tts uses text", BatchRequest{Formats: []string{"html", "text"}, ContentOptions: ContentOptions{TTS: true}}, "text", false},
+		{"format and formats", BatchRequest{Formats: []string{"html"}, ContentOptions: ContentOptions{Format: "text"}}, "", true},
+		{"unknown format", BatchRequest{Formats: []string{"pdf"}}, "", true},
+		{"duplicate", BatchRequest{Formats: []string{"html", "html"}}, "", true},
+		{"tts without text", BatchRequest{Formats: []string{"html"}, ContentOptions: ContentOptions{TTS: true}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBatchFormats(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && tt.req.Format != tt.wantFormat {
+				t.Errorf("format = %q, want %q", tt.req.Format, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestFetchBatchFormats(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	fake := batchUpstream(t, nil)
+
+	results, _ := fetchBatch(context.Background(), []string{"a", "missing"}, []string{"html", "text"}, ContentOptions{Format: "html", NoCache: true}, 1)
+	if fake.count() != 2 {
+		t.Errorf("upstream calls = %d, want one per post", fake.count())
+	}
+	want := map[string]string{"html": "<p>본문</p>", "text": "본문"}
+	if !reflect.DeepEqual(results[0].Contents, want) {
+		t.Errorf("contents = %v, want %v", results[0].Contents, want)
+	}
+	if results[0].Result == nil || results[0].Result.Format != "html" {
+		t.Errorf("result = %+v, want the first format's response", results[0].Result)
+	}
+	if results[1].Error == "" || results[1].Contents != nil {
+		t.Errorf("missing post = %+v, want an error without contents", results[1])
+	}
+}
+
+func TestAcceptsNDJSON(t *testing.T) {
+	tests := []struct {
+		accept []string
+		want   bool
+	}{
+		{nil, false},
+		{[]string{"application/json"}, false},
+		{[]string{"application/x-ndjson"}, true},
+		{[]string{"application/json, Application/X-NDJSON; q=0.9"}, true},
+		{[]string{"text/html", "application/x-ndjson"}, true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/batch", nil)
+		for _, value := range tt.accept {
+			r.Header.Add("Accept", value)
+		}
+		if got := acceptsNDJSON(r); got != tt.want {
+			t.Errorf("acceptsNDJSON(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestGetBatchContentStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		accept string
+	}{
+		{"stream field", `{"post_ids":["a","missing","c"],"format":"html","stream":true}`, ""},
+		{"accept header", `{"post_ids":["a","missing","c"],"format":"html"}`, "application/x-ndjson"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			batchUpstream(t, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			getBatchContent(rec, req)
+
+			if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != ndjsonContentType {
+				t.Fatalf("status = %d, content type = %q: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+			}
+			if !rec.Flushed {
+				t.Error("results were not flushed")
+			}
+			got := map[string]bool{}
+			for _, line := range strings.Split(strings.TrimSpace(rec.Body.String()), "\n") {
+				var item BatchItemResult
+				if err := json.Unmarshal([]byte(line), &item); err != nil {
+					t.Fatalf("line %q: %v", line, err)
+				}
+				got[item.PostID] = item.Error != ""
+			}
+			if want := map[string]bool{"a": false, "missing": true, "c": false}; !reflect.DeepEqual(got, want) {
+				t.Errorf("post IDs with error = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// failingWriter는 응답 본문을 쓸 때마다 실패합니다 (끊긴 클라이언트)
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("client disconnected")
+}
+
+func TestStreamBatchStopsWhenWriteFails(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	upstream := batchUpstream(t, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/batch", nil)
+	streamBatch(failingWriter{httptest.NewRecorder()}, req, BatchRequest{PostIDs: []string{"a", "b", "c", "d"}, ContentOptions: ContentOptions{Format: "html"}}, 1)
+	waitForContentFetch(ContentRequest{PostID: "a", ContentOptions: ContentOptions{Format: "html"}})
+	if got := upstream.count(); got != 1 {
+		t.Errorf("upstream calls = %d, want 1", got)
+	}
+}
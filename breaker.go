@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// circuitBreaker는 BetterMode 호출이 연속으로 실패하면 잠시 호출을 막아 장애가 번지지 않게 합니다.
+// threshold번 연속 실패하면 openFor 동안 열리고(호출 거절), 그 뒤 들어온 호출이 성공하면 닫힙니다.
+// 열린 뒤 첫 호출이 다시 실패하면 곧바로 openFor만큼 다시 열립니다.
+type circuitBreaker struct {
+	service   string // 열렸을 때 에러 메시지에 표시할 호출 대상
+	threshold int
+	openFor   time.Duration
+	failures  int
+	openUntil time.Time
+	mutex     sync.Mutex
+}
+
+func newCircuitBreaker(service string, threshold int, openFor time.Duration) *circuitBreaker {
+	return &circuitBreaker{service: service, threshold: threshold, openFor: openFor}
+}
+
+// allow는 지금 호출해도 되는지와, 막혀 있다면 다시 열릴 때까지 남은 시간을 반환합니다
+func (b *circuitBreaker) allow(now time.Time) (bool, time.Duration) {
+	if b == nil || b.threshold <= 0 {
+		return true, 0
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if now.Before(b.openUntil) {
+		return false, b.openUntil.Sub(now)
+	}
+	return true, 0
+}
+
+// check는 호출이 막혀 있으면 circuitOpenError를 반환합니다
+func (b *circuitBreaker) check(now time.Time) error {
+	if ok, retryAfter := b.allow(now); !ok {
+		return &circuitOpenError{service: b.service, retryAfter: retryAfter}
+	}
+	return nil
+}
+
+// record는 호출 결과를 기록합니다
+func (b *circuitBreaker) record(success bool, now time.Time) {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = now.Add(b.openFor)
+	}
+}
+
+// BreakerStatus는 /healthz와 /token/status에 표시하는 서킷 브레이커 상태입니다
+type BreakerStatus struct {
+	State               string     `json:"state"` // closed, open, disabled
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Threshold           int        `json:"threshold"`
+	OpenUntil           *time.Time `json:"open_until,omitempty"`
+}
+
+// status는 지금의 브레이커 상태를 반환합니다
+func (b *circuitBreaker) status(now time.Time) BreakerStatus {
+	if b == nil || b.threshold <= 0 {
+		return BreakerStatus{State: "disabled"}
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	status := BreakerStatus{State: "closed", ConsecutiveFailures: b.failures, Threshold: b.threshold}
+	if now.Before(b.openUntil) {
+		openUntil := b.openUntil
+		status.State = "open"
+		status.OpenUntil = &openUntil
+	}
+	return status
+}
+
+// circuitOpenError는 서킷 브레이커가 열려 있어 업스트림을 호출하지 않았음을 나타냅니다
+type circuitOpenError struct {
+	service    string
+	retryAfter time.Duration
+}
+
+func (e *circuitOpenError) Error() string {
+	return fmt.Sprintf("%s is temporarily unavailable (retry in %v)", e.service, e.retryAfter.Round(time.Second))
+}
+
+var (
+	// BetterMode 콘텐츠 조회에 사용하는 서킷 브레이커 (CIRCUIT_BREAKER_THRESHOLD가 0이면 nil)
+	upstreamBreaker *circuitBreaker
+	// 게스트 토큰 발급에 사용하는 서킷 브레이커 (TOKEN_CIRCUIT_BREAKER_THRESHOLD가 0이면 nil).
+	// 토큰 발급과 콘텐츠 조회는 따로 장애가 날 수 있으므로 서로의 실패를 세지 않습니다.
+	tokenBreaker *circuitBreaker
+)
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		results        []bool // 차례로 기록할 호출 결과
+		at             time.Duration
+		wantAllow      bool
+		wantRetryAfter time.Duration
+	}{
+		{"no failures", nil, 0, true, 0},
+		{"below threshold", []bool{false, false}, 0, true, 0},
+		{"success resets failures", []bool{false, false, true, false, false}, 0, true, 0},
+		{"opens at threshold", []bool{false, false, false}, 10 * time.Second, false, 20 * time.Second},
+		{"closes after open duration", []bool{false, false, false}, 30 * time.Second, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newCircuitBreaker("BetterMode", 3, 30*time.Second)
+			for _, ok := range tt.results {
+				b.record(ok, start)
+			}
+			allow, retryAfter := b.allow(start.Add(tt.at))
+			if allow != tt.wantAllow || retryAfter != tt.wantRetryAfter {
+				t.Errorf("allow = (%v, %v), want (%v, %v)", allow, retryAfter, tt.wantAllow, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerReopensOnFailedProbe(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker("BetterMode", 2, time.Minute)
+	b.record(false, start)
+	b.record(false, start)
+
+	probe := start.Add(time.Minute)
+	if ok, _ := b.allow(probe); !ok {
+		t.Fatal("breaker still open after open duration")
+	}
+	b.record(false, probe)
+	if ok, retryAfter := b.allow(probe); ok || retryAfter != time.Minute {
+		t.Errorf("after failed probe allow = (%v, %v), want (false, 1m)", ok, retryAfter)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	for _, b := range []*circuitBreaker{nil, newCircuitBreaker("BetterMode", 0, time.Minute)} {
+		b.record(false, time.Now())
+		if err := b.check(time.Now()); err != nil {
+			t.Errorf("disabled breaker check = %v, want nil", err)
+		}
+		if got := b.status(time.Now()).State; got != "disabled" {
+			t.Errorf("status = %q, want disabled", got)
+		}
+	}
+}
+
+func TestSetRetryAfter(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "1"},
+		{300 * time.Millisecond, "1"},
+		{time.Second, "1"},
+		{1500 * time.Millisecond, "2"},
+		{30 * time.Second, "30"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		setRetryAfter(rec, tt.d)
+		if got := rec.Header().Get("Retry-After"); got != tt.want {
+			t.Errorf("setRetryAfter(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestWriteFetchErrorCircuitOpen(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeFetchError(rec, &circuitOpenError{service: "BetterMode", retryAfter: 12 * time.Second})
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "12" {
+		t.Errorf("Retry-After = %q, want 12", got)
+	}
+}
+
+func TestBuildContentResponseCircuitOpen(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	upstream := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	prev := upstreamBreaker
+	upstreamBreaker = newCircuitBreaker("BetterMode", 1, time.Minute)
+	t.Cleanup(func() { upstreamBreaker = prev })
+	upstreamBreaker.record(false, time.Now())
+
+	req := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html", NoCache: true}}
+	_, err := buildContentResponse(context.Background(), req)
+	var openErr *circuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("err = %v, want circuitOpenError", err)
+	}
+	if openErr.retryAfter <= 0 || openErr.retryAfter > time.Minute {
+		t.Errorf("retryAfter = %v, want within the open duration", openErr.retryAfter)
+	}
+	if got := upstream.count(); got != 0 {
+		t.Errorf("upstream calls = %d, want 0 while open", got)
+	}
+}
+
+func TestCircuitBreakerStatus(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	openUntil := start.Add(time.Minute)
+	tests := []struct {
+		name     string
+		breaker  *circuitBreaker
+		failures int
+		want     BreakerStatus
+	}{
+		{"nil", nil, 0, BreakerStatus{State: "disabled"}},
+		{"zero threshold", newCircuitBreaker("BetterMode", 0, time.Minute), 3, BreakerStatus{State: "disabled"}},
+		{"closed", newCircuitBreaker("BetterMode", 2, time.Minute), 1, BreakerStatus{State: "closed", ConsecutiveFailures: 1, Threshold: 2}},
+		{"open", newCircuitBreaker("BetterMode", 2, time.Minute), 2, BreakerStatus{State: "open", ConsecutiveFailures: 2, Threshold: 2, OpenUntil: &openUntil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < tt.failures; i++ {
+				tt.breaker.record(false, start)
+			}
+			got := tt.breaker.status(start)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("status = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenCircuitBreaker(t *testing.T) {
+	withTestToken(t)
+	tokenCalls := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	prevToken, prevContent := tokenBreaker, upstreamBreaker
+	tokenBreaker = newCircuitBreaker("BetterMode token endpoint", 2, time.Minute)
+	upstreamBreaker = newCircuitBreaker("BetterMode API", 2, time.Minute)
+	t.Cleanup(func() { tokenBreaker, upstreamBreaker = prevToken, prevContent })
+
+	for i := 0; i < 2; i++ {
+		if err := tokenManager.RefreshToken(); err == nil || errors.As(err, new(*circuitOpenError)) {
+			t.Fatalf("refresh %d: err = %v, want the token endpoint error", i+1, err)
+		}
+	}
+	calls := tokenCalls.count()
+
+	var openErr *circuitOpenError
+	if err := tokenManager.RefreshToken(); !errors.As(err, &openErr) || openErr.service != "BetterMode token endpoint" {
+		t.Fatalf("err = %v, want the token breaker to be open", err)
+	}
+	if tokenCalls.count() != calls {
+		t.Errorf("token endpoint called %d more times while the breaker was open", tokenCalls.count()-calls)
+	}
+	if state := upstreamBreaker.status(time.Now()).State; state != "closed" {
+		t.Errorf("content breaker state = %q, want closed; token failures must not count against it", state)
+	}
+}
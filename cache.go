@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	response  ContentResponse
+	expiresAt time.Time
+	staleAt   time.Time // 이 시각이 지나면 응답은 계속 쓰되 백그라운드에서 새로 가져옵니다
+}
+
+// postCacheEntry는 BetterMode에서 가져온 가공 전 게시물입니다.
+// 선택적으로 조회하는 필드(본문, 첨부 파일, 반응 수)를 포함해 가져왔는지도 기록합니다.
+type postCacheEntry struct {
+	post        *Post
+	content     bool // metadata_only로 가져왔으면 false (mappingFields 없음)
+	attachments bool
+	engagement  bool
+	author      bool
+	expiresAt   time.Time
+}
+
+// covers는 이 항목으로 opts 요청을 처리할 수 있는지(필요한 필드를 모두 가져왔는지) 확인합니다
+func (e postCacheEntry) covers(opts ContentOptions) bool {
+	return (e.content || opts.MetadataOnly) && (e.attachments || !opts.IncludeAttachments) && (e.engagement || !opts.IncludeEngagement) &&
+		(e.author || !opts.includesAuthor())
+}
+
+// ContentCache는 가공된 콘텐츠 응답을 TTL 동안 메모리에 보관합니다.
+// 가공 전 게시물도 post ID별로 함께 보관해, 같은 게시물을 다른 형식/필드로 요청하면 BetterMode를 다시 호출하지 않습니다.
+// 응답과 게시물은 각각 최대 maxEntries개까지 보관하며, 넘치면 가장 오래 사용하지 않은 항목부터 지웁니다.
+type ContentCache struct {
+	entries    *lruMap[cacheEntry]
+	posts      *lruMap[postCacheEntry]
+	ttl        time.Duration
+	softTTL    time.Duration // 0이면 백그라운드 갱신을 하지 않습니다
+	maxEntries int
+	evictions  int64 // 최대 항목 수를 넘어 지운 항목 수 (만료로 지운 항목은 제외)
+	mutex      sync.Mutex
+}
+
+// CacheStats는 캐시 설정과 현재 크기입니다
+type CacheStats struct {
+	MaxEntries int    `json:"max_entries"` // 응답/게시물 각각의 최대 항목 수 (0이면 제한 없음)
+	Entries    int    `json:"entries"`     // 저장된 가공된 응답 수
+	Posts      int    `json:"posts"`       // 저장된 가공 전 게시물 수
+	Evictions  int64  `json:"evictions"`   // 최대 항목 수를 넘어 지운 항목 수
+	TTL        string `json:"ttl"`
+	SoftTTL    string `json:"soft_ttl"`
+}
+
+// NewContentCache는 주어진 TTL을 사용하는 ContentCache를 생성합니다. ttl이 0이면 아무것도 저장하지 않습니다.
+// softTTL이 0보다 크고 ttl보다 작으면, softTTL이 지난 항목은 만료 전까지 그대로 반환하되 갱신 대상으로 표시합니다.
+// maxEntries가 0 이하이면 항목 수를 제한하지 않습니다.
+func NewContentCache(ttl, softTTL time.Duration, maxEntries int) *ContentCache {
+	if softTTL >= ttl {
+		softTTL = 0
+	}
+	return &ContentCache{
+		entries:    newLRUMap[cacheEntry](maxEntries),
+		posts:      newLRUMap[postCacheEntry](maxEntries),
+		ttl:        ttl,
+		softTTL:    softTTL,
+		maxEntries: maxEntries,
+	}
+}
+
+// SetMaxEntries는 최대 항목 수를 바꿉니다. 줄어들었으면 가장 오래 사용하지 않은 항목부터 바로 지웁니다.
+func (c *ContentCache) SetMaxEntries(maxEntries int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.maxEntries = maxEntries
+	c.evictions += int64(c.entries.setMaxEntries(maxEntries) + c.posts.setMaxEntries(maxEntries))
+}
+
+// Stats는 캐시 설정과 현재 크기를 반환합니다. 만료됐지만 아직 정리되지 않은 항목도 크기에 포함됩니다.
+func (c *ContentCache) Stats() CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return CacheStats{
+		MaxEntries: c.maxEntries,
+		Entries:    c.entries.len(),
+		Posts:      c.posts.len(),
+		Evictions:  c.evictions,
+		TTL:        c.ttl.String(),
+		SoftTTL:    c.softTTL.String(),
+	}
+}
+
+// SetTTL은 이후 저장하는 항목의 TTL을 바꿉니다. 이미 저장된 항목은 원래 만료 시각을 유지합니다.
+func (c *ContentCache) SetTTL(ttl, softTTL time.Duration) {
+	if softTTL >= ttl {
+		softTTL = 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.ttl = ttl
+	c.softTTL = softTTL
+}
+
+// contentCacheKey는 같은 결과를 내는 요청이 같은 키를 갖도록 캐시 키를 만듭니다.
+// 옵션 전체를 키에 포함하므로 ContentOptions에 필드를 추가해도 따로 수정할 필요가 없습니다.
+func contentCacheKey(req ContentRequest) string {
+	opts := req.ContentOptions
+	opts.NoCache = false       // nocache 요청도 같은 항목을 갱신하도록 키에서 제외합니다
+	opts.CacheTTLSeconds = nil // 유지 시간은 결과를 바꾸지 않으므로 같은 항목을 덮어씁니다
+	opts.TimeoutMs = 0         // 제한 시간도 결과를 바꾸지 않습니다
+	options, _ := json.Marshal(opts)
+	return req.PostID + "|" + string(options)
+}
+
+// Get은 만료되지 않은 캐시 항목을 반환합니다
+func (c *ContentCache) Get(key string) (ContentResponse, bool) {
+	response, ok, _ := c.Lookup(key)
+	return response, ok
+}
+
+// Lookup은 만료되지 않은 캐시 항목과 함께, soft TTL이 지나 백그라운드에서 갱신해야 하는지를 반환합니다
+func (c *ContentCache) Lookup(key string) (response ContentResponse, ok, stale bool) {
+	// 조회도 사용 순서를 바꾸므로 쓰기 잠금을 잡습니다
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries.get(key)
+	now := time.Now()
+	if !ok || now.After(entry.expiresAt) {
+		return ContentResponse{}, false, false
+	}
+	return entry.response, true, !entry.staleAt.IsZero() && now.After(entry.staleAt)
+}
+
+// entryTTL은 opts 요청으로 저장하는 항목의 유지 시간입니다. c.mutex를 잡은 상태에서 호출해야 합니다.
+// cache_ttl_seconds가 있으면 그 시간을(normalize에서 CACHE_MAX_TTL로 제한), 없으면 CACHE_TTL을 사용하며,
+// CACHE_TTL이 0이면 요청과 관계없이 저장하지 않습니다.
+func (c *ContentCache) entryTTL(opts ContentOptions) time.Duration {
+	if c.ttl <= 0 || opts.CacheTTLSeconds == nil {
+		return c.ttl
+	}
+	return time.Duration(*opts.CacheTTLSeconds) * time.Second
+}
+
+// Set은 opts 요청의 응답을 캐시에 저장합니다
+func (c *ContentCache) Set(key string, response ContentResponse, opts ContentOptions) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ttl := c.entryTTL(opts)
+	if ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	entry := cacheEntry{
+		response:  response,
+		expiresAt: now.Add(ttl),
+	}
+	// 유지 시간을 soft TTL보다 짧게 지정한 항목은 백그라운드에서 갱신하지 않고 그냥 만료시킵니다
+	if c.softTTL > 0 && c.softTTL < ttl {
+		// 같은 시각에 저장된 항목들이 한꺼번에 갱신되지 않도록 soft TTL을 최대 10% 앞당깁니다
+		jitter := time.Duration(rand.Int63n(int64(c.softTTL)/10 + 1))
+		entry.staleAt = now.Add(c.softTTL - jitter)
+	}
+	c.evictions += int64(c.entries.set(key, entry))
+}
+
+// GetPost는 opts 요청에 필요한 필드를 모두 담은, 만료되지 않은 가공 전 게시물을 반환합니다.
+// 반환된 Post는 다른 요청과 공유되므로 수정하면 안 됩니다.
+func (c *ContentCache) GetPost(postID string, opts ContentOptions) (*Post, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.posts.get(postID)
+	if !ok || time.Now().After(entry.expiresAt) || !entry.covers(opts) {
+		return nil, false
+	}
+	return entry.post, true
+}
+
+// SetPost는 opts로 가져온 가공 전 게시물을 저장합니다
+func (c *ContentCache) SetPost(postID string, post *Post, opts ContentOptions) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ttl := c.entryTTL(opts)
+	if ttl <= 0 {
+		return
+	}
+	c.evictions += int64(c.posts.set(postID, postCacheEntry{
+		post:        post,
+		content:     !opts.MetadataOnly,
+		attachments: opts.IncludeAttachments,
+		engagement:  opts.IncludeEngagement,
+		author:      opts.includesAuthor(),
+		expiresAt:   time.Now().Add(ttl),
+	}))
+}
+
+// InvalidatePost는 postID 게시물의 가공 전 게시물과 모든 옵션의 응답 항목을 지우고 지운 개수를 반환합니다.
+// 다시 가져올 때 예전 데이터를 받지 않도록 BetterMode 원본 응답 캐시의 항목도 함께 지웁니다 (개수에는 넣지 않음).
+func (c *ContentCache) InvalidatePost(postID string) int {
+	upstreamResponses.invalidatePost(postID)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	prefix := postID + "|"
+	removed := c.entries.removeKeysIf(func(key string) bool { return strings.HasPrefix(key, prefix) })
+	if c.posts.remove(postID) {
+		removed++
+	}
+	return removed
+}
+
+// deleteExpired는 만료된 항목을 삭제하고 삭제한 개수를 반환합니다
+func (c *ContentCache) deleteExpired() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	removed := c.entries.removeIf(func(entry cacheEntry) bool { return now.After(entry.expiresAt) })
+	removed += c.posts.removeIf(func(entry postCacheEntry) bool { return now.After(entry.expiresAt) })
+	return removed
+}
+
+// StartJanitor는 interval마다 만료된 항목을 정리하는 고루틴을 시작합니다.
+// 고루틴은 wg에 등록되며 ctx가 취소되면 즉시 종료되므로, 호출자는 종료 시 wg.Wait()로 기다릴 수 있습니다.
+func (c *ContentCache) StartJanitor(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if removed := c.deleteExpired(); removed > 0 {
+					log.Printf("Cache janitor removed %d expired entries", removed)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestContentCacheGetSet(t *testing.T) {
+	tests := []struct {
+		name   string
+		ttl    time.Duration
+		wait   time.Duration
+		wantOK bool
+	}{
+		{"fresh entry", time.Minute, 0, true},
+		{"expired entry", 10 * time.Millisecond, 30 * time.Millisecond, false},
+		{"zero ttl stores nothing", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewContentCache(tt.ttl, 0, 0)
+			cache.Set("post-1|{}", ContentResponse{PostID: "post-1", Content: "본문"}, ContentOptions{})
+			time.Sleep(tt.wait)
+			response, ok := cache.Get("post-1|{}")
+			if ok != tt.wantOK {
+				t.Fatalf("Get ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && response.Content != "본문" {
+				t.Errorf("Get content = %q, want %q", response.Content, "본문")
+			}
+		})
+	}
+}
+
+func TestContentCacheDeleteExpired(t *testing.T) {
+	cache := NewContentCache(10*time.Millisecond, 0, 0)
+	cache.Set("a|{}", ContentResponse{PostID: "a"}, ContentOptions{})
+	cache.Set("b|{}", ContentResponse{PostID: "b"}, ContentOptions{})
+	time.Sleep(30 * time.Millisecond)
+	if removed := cache.deleteExpired(); removed != 2 {
+		t.Errorf("deleteExpired removed %d, want 2", removed)
+	}
+	if stats := cache.Stats(); stats.Entries != 0 {
+		t.Errorf("entries after cleanup = %d, want 0", stats.Entries)
+	}
+}
+
+func TestStartJanitorRemovesExpiredEntries(t *testing.T) {
+	cache := NewContentCache(5*time.Millisecond, 0, 0)
+	cache.Set("a|{}", ContentResponse{PostID: "a"}, ContentOptions{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	cache.StartJanitor(ctx, &wg, 10*time.Millisecond)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for cache.Stats().Entries > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("janitor did not remove the expired entry")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStartJanitorExitsPromptlyOnShutdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+	}{
+		// 정리 주기가 길어도 다음 주기를 기다리지 않고 끝나야 합니다
+		{"long interval", time.Hour},
+		{"short interval", time.Millisecond},
+		{"disabled", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewContentCache(time.Minute, 0, 0)
+			ctx, cancel := context.WithCancel(context.Background())
+			var wg sync.WaitGroup
+			cache.StartJanitor(ctx, &wg, tt.interval)
+
+			cancel()
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("janitor did not exit within 1s of shutdown")
+			}
+		})
+	}
+}
+
+func TestContentCacheLookupStale(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		softTTL   time.Duration
+		wait      time.Duration
+		wantStale bool
+	}{
+		{"before soft ttl", time.Minute, time.Minute / 2, 0, false},
+		{"after soft ttl", time.Minute, 10 * time.Millisecond, 30 * time.Millisecond, true},
+		{"soft ttl disabled", time.Minute, 0, 30 * time.Millisecond, false},
+		{"soft ttl not below ttl is ignored", 50 * time.Millisecond, time.Minute, 30 * time.Millisecond, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewContentCache(tt.ttl, tt.softTTL, 0)
+			cache.Set("post-1|{}", ContentResponse{PostID: "post-1"}, ContentOptions{})
+			time.Sleep(tt.wait)
+			_, ok, stale := cache.Lookup("post-1|{}")
+			if !ok {
+				t.Fatal("entry missing before hard ttl")
+			}
+			if stale != tt.wantStale {
+				t.Errorf("stale = %v, want %v", stale, tt.wantStale)
+			}
+		})
+	}
+}
+
+func TestBuildContentResponseServesStaleWhileRevalidating(t *testing.T) {
+	withTestToken(t)
+	prevCache := contentCache
+	contentCache = NewContentCache(time.Minute, 10*time.Millisecond, 100)
+	t.Cleanup(func() { contentCache = prevCache })
+
+	var version int64
+	release := make(chan struct{})
+	upstream := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&version, 1) > 1 {
+			<-release // 백그라운드 갱신은 응답을 늦춥니다
+		}
+		writeJSONResponse(w, http.StatusOK, postData(testPostJSON(fmt.Sprintf("v%d", atomic.LoadInt64(&version)), "<p>본문</p>")))
+	})
+	req := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html"}}
+
+	first, err := buildContentResponse(context.Background(), req)
+	if err != nil || first.Title != "v1" {
+		t.Fatalf("first response = %q, %v", first.Title, err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	// soft TTL이 지났으므로 업스트림을 기다리지 않고 기존 항목을 돌려주고 갱신을 시작합니다
+	stale, err := buildContentResponse(context.Background(), req)
+	if err != nil || stale.Title != "v1" {
+		t.Fatalf("stale response = %q, %v, want cached v1", stale.Title, err)
+	}
+	close(release)
+	waitForContentFetch(req)
+
+	if got := upstream.count(); got != 2 {
+		t.Errorf("upstream calls = %d, want 2 (initial fetch and one refresh)", got)
+	}
+	refreshed, ok := contentCache.Get(contentCacheKey(req))
+	if !ok || refreshed.Title != "v2" {
+		t.Errorf("cached title after refresh = %q (ok %v), want v2", refreshed.Title, ok)
+	}
+}
+
+func TestRawPostCacheServesDifferentFormats(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	upstream := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>본문 <b>굵게</b></p>")))
+	})
+
+	tests := []struct {
+		opts ContentOptions
+		want string
+	}{
+		{ContentOptions{Format: "html"}, "<p>본문 <b>굵게</b></p>"},
+		{ContentOptions{Format: "text"}, "본문 굵게"},
+		{ContentOptions{Format: "xhtml"}, "<p>본문 <b>굵게</b></p>"},
+		{ContentOptions{Format: "text", Fields: []string{"content"}}, "본문 굵게"},
+	}
+	for _, tt := range tests {
+		response, err := buildContentResponse(context.Background(), ContentRequest{PostID: "post-1", ContentOptions: tt.opts})
+		if err != nil {
+			t.Fatalf("buildContentResponse(%+v): %v", tt.opts, err)
+		}
+		if response.Content != tt.want {
+			t.Errorf("%s content = %q, want %q", tt.opts.Format, response.Content, tt.want)
+		}
+	}
+	if got := upstream.count(); got != 1 {
+		t.Errorf("upstream calls = %d, want 1 for all formats", got)
+	}
+}
+
+func TestContentCacheMaxEntries(t *testing.T) {
+	cache := NewContentCache(time.Minute, 0, 2)
+	for _, key := range []string{"a", "b", "c"} {
+		cache.Set(key, ContentResponse{PostID: key}, ContentOptions{})
+		cache.SetPost(key, newTestPost(key, "<p>본문</p>"), ContentOptions{})
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Error("oldest response should have been evicted")
+	}
+	if _, ok := cache.GetPost("a", ContentOptions{}); ok {
+		t.Error("oldest post should have been evicted")
+	}
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Posts != 2 || stats.Evictions != 2 || stats.MaxEntries != 2 {
+		t.Errorf("stats = %+v, want 2 entries, 2 posts, 2 evictions", stats)
+	}
+
+	cache.SetMaxEntries(1)
+	if stats := cache.Stats(); stats.Entries != 1 || stats.Posts != 1 || stats.Evictions != 4 {
+		t.Errorf("stats after shrinking = %+v, want 1 entry, 1 post, 4 evictions", stats)
+	}
+	if _, ok := cache.Get("c"); !ok {
+		t.Error("most recent response should be kept")
+	}
+}
+
+func TestPostCacheEntryCovers(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry postCacheEntry
+		opts  ContentOptions
+		want  bool
+	}{
+		{"full post serves metadata_only", postCacheEntry{content: true}, ContentOptions{MetadataOnly: true}, true},
+		{"metadata_only post serves metadata_only", postCacheEntry{}, ContentOptions{MetadataOnly: true}, true},
+		{"metadata_only post cannot serve content", postCacheEntry{}, ContentOptions{}, false},
+		{"missing attachments", postCacheEntry{content: true}, ContentOptions{IncludeAttachments: true}, false},
+		{"attachments fetched", postCacheEntry{content: true, attachments: true}, ContentOptions{IncludeAttachments: true}, true},
+		{"missing engagement", postCacheEntry{content: true}, ContentOptions{IncludeEngagement: true}, false},
+		{"missing author for jsonld", postCacheEntry{content: true}, ContentOptions{Format: FormatJSONLD}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.covers(tt.opts); got != tt.want {
+				t.Errorf("covers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentCacheInvalidatePost(t *testing.T) {
+	cache := NewContentCache(time.Minute, 0, 0)
+	html := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html"}}
+	text := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "text"}}
+	other := ContentRequest{PostID: "post-10", ContentOptions: ContentOptions{Format: "html"}}
+	for _, req := range []ContentRequest{html, text, other} {
+		cache.Set(contentCacheKey(req), ContentResponse{PostID: req.PostID}, req.ContentOptions)
+	}
+	cache.SetPost("post-1", newTestPost("제목", "<p>본문</p>"), html.ContentOptions)
+
+	if removed := cache.InvalidatePost("post-1"); removed != 3 {
+		t.Errorf("removed = %d, want 3 (two responses and the post)", removed)
+	}
+	for _, req := range []ContentRequest{html, text} {
+		if _, ok := cache.Get(contentCacheKey(req)); ok {
+			t.Errorf("%s still cached", contentCacheKey(req))
+		}
+	}
+	if _, ok := cache.GetPost("post-1", html.ContentOptions); ok {
+		t.Error("post still cached")
+	}
+	if _, ok := cache.Get(contentCacheKey(other)); !ok {
+		t.Error("post-10 was purged with post-1")
+	}
+	if removed := cache.InvalidatePost("post-1"); removed != 0 {
+		t.Errorf("second invalidate removed %d, want 0", removed)
+	}
+}
+
+func TestRevalidationPurgesDeletedPost(t *testing.T) {
+	withTestToken(t)
+	prevCache := contentCache
+	contentCache = NewContentCache(time.Minute, 10*time.Millisecond, 100)
+	t.Cleanup(func() { contentCache = prevCache })
+
+	var calls int64
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&calls, 1) > 1 {
+			writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+				"data":   map[string]interface{}{"post": nil},
+				"errors": []map[string]interface{}{{"message": "Not found", "extensions": map[string]string{"code": "NOT_FOUND"}}},
+			})
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>본문</p>")))
+	})
+	req := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html"}}
+	other := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "text"}}
+
+	if _, err := buildContentResponse(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := buildContentResponse(context.Background(), other); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	// 오래된 항목을 돌려주면서 시작한 갱신이 삭제를 발견합니다
+	if _, err := buildContentResponse(context.Background(), req); err != nil {
+		t.Fatalf("stale response: %v", err)
+	}
+	waitForContentFetch(req)
+
+	if _, ok := contentCache.Get(contentCacheKey(other)); ok {
+		t.Error("other options of the deleted post are still cached")
+	}
+	_, err := buildContentResponse(context.Background(), req)
+	if !errors.Is(err, errPostNotFound) {
+		t.Fatalf("err = %v, want errPostNotFound", err)
+	}
+	rec := httptest.NewRecorder()
+	writeFetchError(rec, err)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestContentCacheEntryTTL(t *testing.T) {
+	seconds := func(n int) *int { return &n }
+	tests := []struct {
+		name      string
+		cacheTTL  time.Duration
+		softTTL   time.Duration
+		requested *int
+		wantTTL   time.Duration // 0이면 저장하지 않아야 합니다
+		wantStale bool          // 백그라운드 갱신 시각이 정해지는지
+	}{
+		{"default ttl", time.Minute, 0, nil, time.Minute, false},
+		{"requested ttl", time.Minute, 0, seconds(300), 5 * time.Minute, false},
+		{"shorter than soft ttl is not revalidated", time.Minute, 30 * time.Second, seconds(10), 10 * time.Second, false},
+		{"longer than soft ttl is revalidated", time.Minute, 30 * time.Second, seconds(120), 2 * time.Minute, true},
+		{"cache disabled ignores request", 0, 0, seconds(300), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewContentCache(tt.cacheTTL, tt.softTTL, 0)
+			opts := ContentOptions{Format: "html", CacheTTLSeconds: tt.requested}
+			before := time.Now()
+			cache.Set("post-1|{}", ContentResponse{PostID: "post-1"}, opts)
+			cache.SetPost("post-1", newTestPost("제목", "<p>본문</p>"), opts)
+
+			entry, ok := cache.entries.get("post-1|{}")
+			post, postOK := cache.posts.get("post-1")
+			if tt.wantTTL == 0 {
+				if ok || postOK {
+					t.Error("entry stored with caching disabled")
+				}
+				return
+			}
+			if !ok || !postOK {
+				t.Fatal("entry not stored")
+			}
+			for name, expiresAt := range map[string]time.Time{"response": entry.expiresAt, "post": post.expiresAt} {
+				if ttl := expiresAt.Sub(before); ttl < tt.wantTTL || ttl > tt.wantTTL+time.Second {
+					t.Errorf("%s ttl = %v, want %v", name, ttl, tt.wantTTL)
+				}
+			}
+			if stale := !entry.staleAt.IsZero(); stale != tt.wantStale {
+				t.Errorf("staleAt set = %v, want %v", stale, tt.wantStale)
+			}
+		})
+	}
+}
+
+func TestContentCacheKeyIgnoresCacheTTL(t *testing.T) {
+	ttl := 30
+	plain := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html"}}
+	withTTL := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html", CacheTTLSeconds: &ttl}}
+	if contentCacheKey(plain) != contentCacheKey(withTTL) {
+		t.Errorf("cache keys differ: %q vs %q", contentCacheKey(plain), contentCacheKey(withTTL))
+	}
+}
+
+func TestContentCacheKeyIgnoresTimeoutMs(t *testing.T) {
+	plain := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html"}}
+	withTimeout := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html", TimeoutMs: 500}}
+	if contentCacheKey(plain) != contentCacheKey(withTimeout) {
+		t.Errorf("cache keys differ: %q vs %q", contentCacheKey(plain), contentCacheKey(withTimeout))
+	}
+}
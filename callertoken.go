@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// callerTokenHeader로 호출자가 자기 BetterMode 토큰을 보내면 관리 중인 게스트 토큰 대신 사용합니다
+const callerTokenHeader = "X-BetterMode-Token"
+
+// errCallerTokenRejected는 BetterMode가 호출자가 보낸 토큰을 거절했음을 나타냅니다 (401로 응답).
+// 호출자 토큰은 서버가 관리하지 않으므로 갱신해서 다시 시도하지 않습니다.
+var errCallerTokenRejected = errors.New("BetterMode rejected the token supplied in " + callerTokenHeader)
+
+type callerTokenKey struct{}
+
+// callerTokenMiddleware는 X-BetterMode-Token 헤더의 토큰을 요청 컨텍스트에 담습니다
+func callerTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get(callerTokenHeader), "Bearer "))
+		if token != "" {
+			r = r.WithContext(context.WithValue(r.Context(), callerTokenKey{}, token))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// callerToken은 요청에 호출자 토큰이 있으면 반환합니다
+func callerToken(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(callerTokenKey{}).(string)
+	return token, ok
+}
+
+// upstreamToken은 BetterMode 호출에 쓸 토큰을 반환합니다.
+// 호출자 토큰이 있으면 그것을, 없으면 관리 중인 토큰을 사용하며, 두 번째 값은 호출자 토큰인지 여부입니다.
+func upstreamToken(ctx context.Context) (string, bool, error) {
+	if token, ok := callerToken(ctx); ok {
+		return token, true, nil
+	}
+	if tokenManager == nil {
+		return "", false, errServiceInitializing
+	}
+	token, err := tokenManager.GetToken()
+	if err != nil {
+		return "", false, fmt.Errorf("error getting access token: %w", err)
+	}
+	return token, false, nil
+}
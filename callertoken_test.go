@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestFetchContentFromBetterModeUnauthorized(t *testing.T) {
+	tests := []struct {
+		name          string
+		callerToken   string
+		rejectAll     bool // false이면 처음 토큰만 거절하고 갱신한 토큰은 받아들입니다
+		wantErr       error
+		wantPostCalls int
+		wantRefreshes int
+	}{
+		{"refreshed token accepted", "", false, nil, 2, 1},
+		{"refreshed token rejected retries once", "", true, errTokenRejectedAfterRefresh, 2, 1},
+		{"caller token is not refreshed", "caller-token", true, errCallerTokenRejected, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			var mu sync.Mutex
+			postCalls, refreshes := 0, 0
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				req := readGraphQLRequest(t, r)
+				mu.Lock()
+				defer mu.Unlock()
+				if _, ok := req.Variables["networkDomain"]; ok {
+					refreshes++
+					writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+						"data": map[string]interface{}{"tokens": map[string]string{"accessToken": "refreshed-token"}},
+					})
+					return
+				}
+				postCalls++
+				if tt.rejectAll || r.Header.Get("Authorization") != "Bearer refreshed-token" {
+					writeJSONResponse(w, http.StatusUnauthorized, map[string]interface{}{"errors": []map[string]string{{"message": "Unauthorized"}}})
+					return
+				}
+				writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>본문</p>")))
+			})
+
+			ctx := context.Background()
+			if tt.callerToken != "" {
+				ctx = context.WithValue(ctx, callerTokenKey{}, tt.callerToken)
+			}
+			post, err := fetchContentFromBetterMode(ctx, "post-1", ContentOptions{Format: "html", NoCache: true})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && post.Title != "제목" {
+				t.Errorf("title = %q", post.Title)
+			}
+			if postCalls != tt.wantPostCalls || refreshes != tt.wantRefreshes {
+				t.Errorf("post calls = %d, refreshes = %d, want %d and %d", postCalls, refreshes, tt.wantPostCalls, tt.wantRefreshes)
+			}
+		})
+	}
+}
+
+func TestCallerTokenMiddleware(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		wantOK bool
+	}{
+		{"", "", false},
+		{"abc", "abc", true},
+		{"Bearer abc", "abc", true},
+		{"  ", "", false},
+	}
+	for _, tt := range tests {
+		var got string
+		var ok bool
+		handler := callerTokenMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok = callerToken(r.Context())
+		}))
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/content", nil)
+		r.Header.Set(callerTokenHeader, tt.header)
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("header %q: callerToken = (%q, %v), want (%q, %v)", tt.header, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestWriteFetchErrorCallerTokenRejected(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeFetchError(rec, errCallerTokenRejected)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestWriteFetchErrorServiceInitializing(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeFetchError(rec, fmt.Errorf("fetching post: %w", errServiceInitializing))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After")
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// canonicalBlockElements는 앞뒤 공백이 렌더링에 영향을 주지 않는 블록 요소입니다
+var canonicalBlockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true, "dd": true,
+	"details": true, "div": true, "dl": true, "dt": true, "figcaption": true, "figure": true,
+	"footer": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "section": true, "summary": true, "table": true, "tbody": true, "td": true,
+	"tfoot": true, "th": true, "thead": true, "tr": true, "ul": true,
+}
+
+// contentHash는 본문을 canonicalHTML로 바꾼 뒤 algorithm(hashHex 참고)으로 해시한 hex 값입니다.
+// 공백, 속성 순서, 엔티티 표기만 다른 본문은 같은 값이 나오므로 변경 감지에 사용할 수 있습니다.
+// 파싱에 실패하면 원문 그대로 해시합니다.
+func contentHash(content, algorithm string) string {
+	canonical, err := canonicalHTML(content)
+	if err != nil {
+		canonical = content
+	}
+	return hashHex(algorithm, []byte(canonical))
+}
+
+// canonicalHTML은 HTML을 의미가 같으면 항상 같은 문자열이 되도록 다시 직렬화합니다.
+//   - 태그와 속성 이름은 소문자로, 속성은 이름순으로 정렬 (class 값은 공백을 정리해 정렬)
+//   - 엔티티는 파서가 푼 뒤 필요한 문자만 다시 이스케이프 (&#39;, &apos;, ' 모두 같은 결과)
+//   - 연속된 공백은 하나로 줄이고, 블록 요소 사이와 경계의 공백은 제거 (<pre> 안은 그대로)
+//   - 주석은 제거
+func canonicalHTML(content string) (string, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	writeCanonicalChildren(&b, nodes, true, false)
+	return b.String(), nil
+}
+
+// writeCanonicalChildren은 주석을 뺀 형제 노드들을 씁니다. inBlock은 부모가 블록 요소인지 여부입니다.
+func writeCanonicalChildren(b *strings.Builder, nodes []*html.Node, inBlock, pre bool) {
+	var children []*html.Node
+	for _, c := range nodes {
+		if c.Type != html.CommentNode {
+			children = append(children, c)
+		}
+	}
+	for i, c := range children {
+		// 텍스트 앞뒤가 블록 경계이면 그 공백은 렌더링에 영향이 없습니다
+		blockBefore := inBlock
+		if i > 0 {
+			blockBefore = isCanonicalBlock(children[i-1])
+		}
+		blockAfter := inBlock
+		if i < len(children)-1 {
+			blockAfter = isCanonicalBlock(children[i+1])
+		}
+		writeCanonical(b, c, blockBefore, blockAfter, pre)
+	}
+}
+
+// writeCanonical은 n을 canonical 형식으로 씁니다
+func writeCanonical(b *strings.Builder, n *html.Node, blockBefore, blockAfter, pre bool) {
+	switch n.Type {
+	case html.TextNode:
+		if pre {
+			b.WriteString(html.EscapeString(n.Data))
+			return
+		}
+		text := strings.Join(strings.Fields(n.Data), " ")
+		if text == "" {
+			// 공백만 있는 노드는 인라인 요소 사이에 있을 때만 의미가 있습니다
+			if !blockBefore && !blockAfter {
+				b.WriteString(" ")
+			}
+			return
+		}
+		if startsWithSpace(n.Data) && !blockBefore {
+			text = " " + text
+		}
+		if endsWithSpace(n.Data) && !blockAfter {
+			text += " "
+		}
+		b.WriteString(html.EscapeString(text))
+	case html.ElementNode:
+		tag := strings.ToLower(n.Data)
+		b.WriteString("<")
+		b.WriteString(tag)
+
+		attrs := make([]html.Attribute, 0, len(n.Attr))
+		for _, attr := range n.Attr {
+			attr.Key = strings.ToLower(attr.Key)
+			if attr.Namespace != "" {
+				attr.Key = attr.Namespace + ":" + attr.Key
+			}
+			if attr.Key == "class" {
+				classes := strings.Fields(attr.Val)
+				sort.Strings(classes)
+				attr.Val = strings.Join(classes, " ")
+			}
+			attrs = append(attrs, attr)
+		}
+		sort.SliceStable(attrs, func(a, c int) bool { return attrs[a].Key < attrs[c].Key })
+		for _, attr := range attrs {
+			b.WriteString(" ")
+			b.WriteString(attr.Key)
+			b.WriteString(`="`)
+			b.WriteString(html.EscapeString(attr.Val))
+			b.WriteString(`"`)
+		}
+		b.WriteString(">")
+		if voidElements[tag] {
+			return
+		}
+
+		var children []*html.Node
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			children = append(children, c)
+		}
+		writeCanonicalChildren(b, children, canonicalBlockElements[tag], pre || tag == "pre" || tag == "textarea")
+		b.WriteString("</")
+		b.WriteString(tag)
+		b.WriteString(">")
+	}
+}
+
+func isCanonicalBlock(n *html.Node) bool {
+	return n.Type == html.ElementNode && canonicalBlockElements[strings.ToLower(n.Data)]
+}
+
+func startsWithSpace(s string) bool {
+	return s != "" && strings.TrimLeft(s, " \t\n\r\f") != s
+}
+
+func endsWithSpace(s string) bool {
+	return s != "" && strings.TrimRight(s, " \t\n\r\f") != s
+}
@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestCanonicalHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"sorted attributes", `<a title="t" HREF="/x">링크</a>`, `<a href="/x" title="t">링크</a>`},
+		{"sorted classes", `<p class="  b a ">x</p>`, `<p class="a b">x</p>`},
+		{"entities", `<p>It&#39;s &apos;a&apos; &amp; b</p>`, `<p>It&#39;s &#39;a&#39; &amp; b</p>`},
+		{"block whitespace", "<div>\n  <p> a  b </p>\n</div>", `<div><p>a b</p></div>`},
+		{"inline whitespace kept", `<p><b>a</b> <i>b</i></p>`, `<p><b>a</b> <i>b</i></p>`},
+		{"pre kept", "<pre>  a\n  b</pre>", "<pre>  a\n  b</pre>"},
+		{"comments removed", `<p>a<!-- note -->b</p>`, `<p>ab</p>`},
+		{"void elements", `<p>a<br/>b<img src="x.png"></p>`, `<p>a<br>b<img src="x.png"></p>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canonicalHTML(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("canonicalHTML(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      string
+		wantEqual bool
+	}{
+		{"attribute order", `<a href="/x" title="t">x</a>`, `<a title="t" href="/x">x</a>`, true},
+		{"whitespace between blocks", "<p>a</p><p>b</p>", "<p>a</p>\n\n<p>b</p>", true},
+		{"entity spelling", `<p>&#39;</p>`, `<p>'</p>`, true},
+		{"text change", "<p>a</p>", "<p>b</p>", false},
+		{"inline space matters", "<b>a</b> <i>b</i>", "<b>a</b><i>b</i>", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, algorithm := range []string{HashSHA256, HashXXHash} {
+				a, b := contentHash(tt.a, algorithm), contentHash(tt.b, algorithm)
+				if (a == b) != tt.wantEqual {
+					t.Errorf("%s: hashes equal = %v, want %v (%s, %s)", algorithm, a == b, tt.wantEqual, a, b)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseContentHash(t *testing.T) {
+	post := newTestPost("제목", "<p>본문</p>")
+	html := renderTestPost(t, ContentOptions{Format: "html"}, post)
+	text := renderTestPost(t, ContentOptions{Format: "text"}, post)
+	if html.ContentHash == "" || html.ContentHash != text.ContentHash {
+		t.Errorf("content_hash = %q (html), %q (text); want the same hash for every format", html.ContentHash, text.ContentHash)
+	}
+	if want := contentHash("<p>본문</p>", html.ContentHashAlgorithm); html.ContentHash != want {
+		t.Errorf("content_hash = %q, want %q", html.ContentHash, want)
+	}
+}
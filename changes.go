@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// PostChange는 since 이후 수정된 게시물입니다
+type PostChange struct {
+	ID        string `json:"id"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// ChangesResponse는 스페이스에서 since 이후 수정된 게시물 목록입니다 (최근 수정순)
+type ChangesResponse struct {
+	SpaceID    string       `json:"space_id"`
+	Since      string       `json:"since"` // RFC 3339로 정규화한 since
+	Changes    []PostChange `json:"changes"`
+	NextCursor string       `json:"next_cursor,omitempty"` // 더 남아 있으면 다음 페이지를 cursor로 요청
+}
+
+// spaceChangesQuery는 스페이스 게시물을 최근 수정순(updatedAt 내림차순)으로 가져옵니다.
+// listSpaceChanges가 since보다 오래된 게시물에서 멈추려면 이 순서여야 합니다.
+var spaceChangesQuery = buildPostListQuery("ListSpaceChanges", "updatedAt", newestFirst, gqlFields("id", "updatedAt"))
+
+// GetSpaceChanges godoc
+// @Summary List posts modified since a timestamp
+// @Description Returns IDs and updatedAt of posts in a space with updatedAt >= since, most recently modified first. Use next_cursor to fetch the next page.
+// @Tags content
+// @Produce json
+// @Param spaceID path string true "Space ID"
+// @Param space_id query string false "Space ID for the route without spaceID (default: DEFAULT_SPACE_ID)"
+// @Param since query string true "RFC 3339 timestamp or Unix seconds (inclusive)"
+// @Param limit query int false "Maximum posts per page (default 50, max BATCH_MAX_ITEMS)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} ChangesResponse
+// @Failure 400 {string} string "Bad request"
+// @Failure 502 {string} string "BetterMode API error, or posts not returned most recently updated first"
+// @Router /spaces/{spaceID}/changes [get]
+// @Router /changes [get]
+func getSpaceChanges(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	since, err := parseSince(query.Get("since"))
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit := listPageSize
+	if value := query.Get("limit"); value != "" {
+		maxItems := currentConfig().BatchMaxItems
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > maxItems {
+			writeError(w, fmt.Sprintf("limit must be between 1 and %d", maxItems), http.StatusBadRequest)
+			return
+		}
+	}
+
+	spaceID := requestSpaceID(r)
+	if spaceID == "" {
+		writeError(w, errNoSpaceID, http.StatusBadRequest)
+		return
+	}
+	changes, next, err := listSpaceChanges(r.Context(), spaceID, since, limit, query.Get("cursor"))
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.As(err, new(*inputError)) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, errCallerTokenRejected) {
+			status = http.StatusUnauthorized
+		}
+		writeError(w, "Error fetching changes: "+err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, ChangesResponse{
+		SpaceID:    spaceID,
+		Since:      since.Format(time.RFC3339Nano),
+		Changes:    changes,
+		NextCursor: next,
+	})
+}
+
+// errChangesOutOfOrder는 BetterMode가 게시물을 최근 수정순으로 주지 않았음을 나타냅니다 (502로 응답)
+var errChangesOutOfOrder = errors.New("BetterMode did not return posts most recently updated first")
+
+// parseSince는 RFC 3339 시각이나 Unix 초를 받습니다
+func parseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("since is required")
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	return time.Time{}, errors.New("since must be an RFC 3339 timestamp or Unix seconds")
+}
+
+// listSpaceChanges는 스페이스의 게시물을 최근 수정순으로 훑어 updatedAt >= since인 게시물을 최대 limit개 반환합니다.
+// since보다 오래된 게시물이 나오면 멈추므로, 바뀐 게시물이 적으면 BetterMode를 한 번만 호출합니다.
+// limit개를 채웠고 더 남아 있으면 다음 페이지용 커서를 반환합니다.
+//
+// 멈춘 뒤의 페이지는 보지 않으므로 BetterMode가 최근 수정순으로 주지 않으면 바뀐 게시물을 빠뜨릴 수 있습니다.
+// 그래서 받은 게시물의 updatedAt이 앞 게시물보다 최근이면 빠뜨린 채 응답하지 않고 errChangesOutOfOrder를 반환합니다.
+func listSpaceChanges(ctx context.Context, spaceID string, since time.Time, limit int, after string) ([]PostChange, string, error) {
+	changes := []PostChange{}
+	var previous time.Time
+	for {
+		// 받은 페이지를 모두 쓰거나 멈추도록 남은 개수만 요청해, BetterMode 커서를 그대로 다음 페이지 커서로 씁니다
+		pageSize := limit - len(changes)
+		if pageSize > listPageSize {
+			pageSize = listPageSize
+		}
+		variables := map[string]interface{}{
+			"spaceIds": []string{spaceID},
+			"limit":    pageSize,
+		}
+		if after != "" {
+			variables["after"] = after
+		}
+
+		var data struct {
+			Posts struct {
+				Nodes []struct {
+					ID        string `json:"id"`
+					UpdatedAt string `json:"updatedAt"`
+				} `json:"nodes"`
+				PageInfo pageInfo `json:"pageInfo"`
+			} `json:"posts"`
+		}
+		if err := queryBetterMode(ctx, spaceChangesQuery, variables, &data); err != nil {
+			return nil, "", err
+		}
+
+		// 순서를 확인할 수 있도록 since보다 오래된 게시물이 나와도 받은 페이지는 끝까지 봅니다
+		reachedSince := false
+		for _, node := range data.Posts.Nodes {
+			updated, err := time.Parse(time.RFC3339Nano, node.UpdatedAt)
+			if err != nil {
+				return nil, "", fmt.Errorf("post %s has an invalid updatedAt %q", node.ID, node.UpdatedAt)
+			}
+			if !previous.IsZero() && updated.After(previous) {
+				return nil, "", fmt.Errorf("%w: post %s (updatedAt %s) came after a post updated at %s",
+					errChangesOutOfOrder, node.ID, node.UpdatedAt, previous.Format(time.RFC3339Nano))
+			}
+			previous = updated
+			if updated.Before(since) {
+				reachedSince = true
+				continue
+			}
+			changes = append(changes, PostChange{ID: node.ID, UpdatedAt: node.UpdatedAt})
+		}
+		if reachedSince {
+			return changes, "", nil
+		}
+
+		page := data.Posts.PageInfo
+		if !page.HasNextPage || page.EndCursor == "" {
+			return changes, "", nil
+		}
+		if len(changes) >= limit {
+			return changes, page.EndCursor, nil
+		}
+		after = page.EndCursor
+	}
+}
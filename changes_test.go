@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// changesUpstream은 posts를 받은 순서 그대로 limit개씩 돌려주는 가짜 BetterMode입니다. 호출 수를 반환합니다.
+func changesUpstream(t *testing.T, posts []PostChange) *fakeUpstream {
+	return withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		req := readGraphQLRequest(t, r)
+		if !strings.Contains(req.Query, `orderByString: "updatedAt", reverse: true`) {
+			t.Errorf("changes query is not most recently updated first:\n%s", req.Query)
+		}
+		start := 0
+		if after, ok := req.Variables["after"].(string); ok {
+			fmt.Sscan(after, &start)
+		}
+		end := start + int(req.Variables["limit"].(float64))
+		if end > len(posts) {
+			end = len(posts)
+		}
+		nodes := []map[string]string{}
+		for _, p := range posts[start:end] {
+			nodes = append(nodes, map[string]string{"id": p.ID, "updatedAt": p.UpdatedAt})
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{
+			"posts": map[string]interface{}{
+				"nodes":    nodes,
+				"pageInfo": map[string]interface{}{"hasNextPage": end < len(posts), "endCursor": fmt.Sprint(end)},
+			},
+		}})
+	})
+}
+
+func TestListSpaceChanges(t *testing.T) {
+	since := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	newestFirst := []PostChange{
+		{ID: "p4", UpdatedAt: "2024-05-04T00:00:00Z"},
+		{ID: "p3", UpdatedAt: "2024-05-02T00:00:00Z"}, // since와 같음 (포함)
+		{ID: "p2", UpdatedAt: "2024-05-01T23:59:59Z"}, // since 직전
+		{ID: "p1", UpdatedAt: "2024-04-30T00:00:00Z"},
+	}
+	tests := []struct {
+		name      string
+		posts     []PostChange
+		limit     int
+		after     string
+		wantIDs   []string
+		wantNext  string
+		wantCalls int
+		wantErr   error
+	}{
+		{"posts on both sides of since", newestFirst, 50, "", []string{"p4", "p3"}, "", 1, nil},
+		{"page boundary at since", newestFirst, 2, "", []string{"p4", "p3"}, "2", 1, nil},
+		{"first page", newestFirst, 1, "", []string{"p4"}, "1", 1, nil},
+		{"cursor continues", newestFirst, 1, "1", []string{"p3"}, "2", 1, nil},
+		{"cursor past since", newestFirst, 1, "2", []string{}, "", 1, nil},
+		{"nothing changed", newestFirst[2:], 50, "", []string{}, "", 1, nil},
+		{"oldest first is rejected", []PostChange{newestFirst[3], newestFirst[2], newestFirst[1], newestFirst[0]}, 50, "", nil, "", 1, errChangesOutOfOrder},
+		{"out of order after since is rejected", []PostChange{newestFirst[1], newestFirst[0]}, 50, "", nil, "", 1, errChangesOutOfOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			fake := changesUpstream(t, tt.posts)
+
+			changes, next, err := listSpaceChanges(context.Background(), "space-1", since, tt.limit, tt.after)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if fake.count() != tt.wantCalls {
+				t.Errorf("upstream calls = %d, want %d", fake.count(), tt.wantCalls)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			ids := []string{}
+			for _, c := range changes {
+				ids = append(ids, c.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) || next != tt.wantNext {
+				t.Errorf("changes = %v, next = %q; want %v, %q", ids, next, tt.wantIDs, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2024-05-02T09:00:00+09:00", time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), false},
+		{"1714608000", time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), false},
+		{"", time.Time{}, true},
+		{"2024-05-02", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.value)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v, wantErr %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestGetSpaceChanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		defaultID  string
+		wantStatus int
+	}{
+		{"space from query", "/api/v1/changes?since=2024-05-02T00:00:00Z&space_id=space-1", "", http.StatusOK},
+		{"default space", "/api/v1/changes?since=2024-05-02T00:00:00Z", "space-1", http.StatusOK},
+		{"no space", "/api/v1/changes?since=2024-05-02T00:00:00Z", "", http.StatusBadRequest},
+		{"missing since", "/api/v1/changes?space_id=space-1", "", http.StatusBadRequest},
+		{"limit too large", "/api/v1/changes?since=2024-05-02T00:00:00Z&space_id=space-1&limit=100000", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withConfig(t, func(cfg *Config) { cfg.DefaultSpaceID = tt.defaultID })
+			changesUpstream(t, []PostChange{{ID: "p1", UpdatedAt: "2024-05-03T00:00:00Z"}})
+
+			rec := httptest.NewRecorder()
+			getSpaceChanges(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
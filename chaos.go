@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// 장애 주입을 허용하지 않는 APP_ENV 값
+const productionEnv = "production"
+
+// chaosKey는 장애를 주입할 업스트림 요청을 표시하는 컨텍스트 키입니다
+type chaosKey struct{}
+
+// withChaos는 ctx로 보내는 BetterMode 요청을 장애 주입 대상으로 표시합니다.
+// 같은 upstreamClient를 쓰는 웹훅, 번역, 내보내기 요청에는 주입하지 않도록 콘텐츠 조회에서만 사용합니다.
+func withChaos(ctx context.Context) context.Context {
+	return context.WithValue(ctx, chaosKey{}, true)
+}
+
+// checkChaosConfig는 CHAOS_ENABLED를 운영 환경(APP_ENV=production, 기본값)에서 켤 수 없게 합니다
+func checkChaosConfig(cfg *Config) error {
+	if cfg.ChaosEnabled && cfg.AppEnv == productionEnv {
+		return errors.New("CHAOS_ENABLED cannot be used with APP_ENV=production; set APP_ENV to e.g. staging")
+	}
+	return nil
+}
+
+// chaosTransport는 장애 테스트용으로 콘텐츠 조회 요청에 CHAOS_*_RATE 확률로
+// 네트워크 에러, 429, 지연을 주입합니다. 주입한 장애는 실제 장애처럼 재시도, 서킷 브레이커, 제한 시간을 거칩니다.
+type chaosTransport struct {
+	base   http.RoundTripper
+	random func() float64 // [0, 1) 난수
+}
+
+func newChaosTransport(base http.RoundTripper) *chaosTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &chaosTransport{base: base, random: rand.Float64}
+}
+
+func (t *chaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if marked, _ := req.Context().Value(chaosKey{}).(bool); !marked {
+		return t.base.RoundTrip(req)
+	}
+	cfg := currentConfig()
+
+	if cfg.ChaosLatency > 0 && t.random() < cfg.ChaosLatencyRate {
+		if err := sleepContext(req.Context(), cfg.ChaosLatency); err != nil {
+			return nil, err
+		}
+	}
+
+	// 에러와 429는 한 번의 난수로 정해 둘의 확률이 겹치지 않게 합니다
+	roll := t.random()
+	switch {
+	case roll < cfg.ChaosErrorRate:
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		// 연결이 끊긴 것처럼 보여 네트워크 재시도 대상이 됩니다
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: fmt.Errorf("chaos: injected upstream error: %w", syscall.ECONNRESET)}
+	case roll < cfg.ChaosErrorRate+cfg.ChaosRateLimitRate:
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		body := "chaos: injected rate limit"
+		return &http.Response{
+			Status:        "429 Too Many Requests",
+			StatusCode:    http.StatusTooManyRequests,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"text/plain"}, "Retry-After": {"1"}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCheckChaosConfig(t *testing.T) {
+	tests := []struct {
+		enabled bool
+		appEnv  string
+		wantErr bool
+	}{
+		{false, productionEnv, false},
+		{true, productionEnv, true},
+		{true, "staging", false},
+	}
+	for _, tt := range tests {
+		err := checkChaosConfig(&Config{ChaosEnabled: tt.enabled, AppEnv: tt.appEnv})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("enabled=%v APP_ENV=%s: err = %v, wantErr %v", tt.enabled, tt.appEnv, err, tt.wantErr)
+		}
+	}
+}
+
+func TestChaosTransport(t *testing.T) {
+	tests := []struct {
+		name          string
+		marked        bool
+		errorRate     float64
+		rateLimitRate float64
+		roll          float64
+		wantStatus    int // 0이면 네트워크 에러
+	}{
+		{"unmarked request passes", false, 1, 0, 0, http.StatusOK},
+		{"injected error", true, 0.5, 0.2, 0.4, 0},
+		{"injected rate limit", true, 0.5, 0.2, 0.6, http.StatusTooManyRequests},
+		{"no injection", true, 0.5, 0.2, 0.8, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) {
+				cfg.ChaosErrorRate = tt.errorRate
+				cfg.ChaosRateLimitRate = tt.rateLimitRate
+				cfg.ChaosLatency = 0
+			})
+			transport := &chaosTransport{
+				base: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+					return httptest.NewRecorder().Result(), nil
+				}),
+				random: func() float64 { return tt.roll },
+			}
+			ctx := context.Background()
+			if tt.marked {
+				ctx = withChaos(ctx)
+			}
+			req := httptest.NewRequest(http.MethodPost, betterModeAPIURL, nil).WithContext(ctx)
+
+			resp, err := transport.RoundTrip(req)
+			if tt.wantStatus == 0 {
+				if !errors.Is(err, syscall.ECONNRESET) || !isRetryableNetworkError(err) {
+					t.Errorf("err = %v, want a retryable connection reset", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestChaosTransportLatency(t *testing.T) {
+	withConfig(t, func(cfg *Config) {
+		cfg.ChaosErrorRate, cfg.ChaosRateLimitRate = 0, 0
+		cfg.ChaosLatency = time.Hour
+		cfg.ChaosLatencyRate = 1
+	})
+	transport := &chaosTransport{
+		base: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return httptest.NewRecorder().Result(), nil
+		}),
+		random: func() float64 { return 0 },
+	}
+	ctx, cancel := context.WithTimeout(withChaos(context.Background()), 10*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodPost, betterModeAPIURL, nil).WithContext(ctx)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the injected latency to be cut off by the deadline", err)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// runFetchCommand는 "fetch" 서브커맨드를 실행하고 프로세스 종료 코드를 반환합니다.
+// 서버와 같은 가져오기/정리 파이프라인을 사용하며 결과를 stdout에 출력합니다.
+//
+//	bettermode-api fetch --post-id=XwcaTuNaJoPnfg1 --format=text
+//	bettermode-api fetch --url=https://www.gpters.org/dev/post/... --json
+func runFetchCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	postID := fs.String("post-id", "", "BetterMode post ID")
+	postURL := fs.String("url", "", "BetterMode post URL (used when --post-id is empty)")
+	format := fs.String("format", "html", "output format: html, text, xhtml or jsonld")
+	codeLineNumbers := fs.Bool("code-line-numbers", false, "prefix code block lines with line numbers (text format)")
+	asJSON := fs.Bool("json", false, "print the full JSON response instead of the content only")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *postID == "" && *postURL != "" {
+		id, err := extractPostIDFromURL(*postURL)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error extracting post ID: %v\n", err)
+			return 2
+		}
+		*postID = id
+	}
+	if *postID == "" {
+		fmt.Fprintln(stderr, "Either --post-id or --url is required")
+		fs.Usage()
+		return 2
+	}
+
+	opts := ContentOptions{Format: *format, CodeLineNumbers: *codeLineNumbers}
+	if err := opts.normalize(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	// 한 번만 실행하므로 캐시는 사용하지 않고, 토큰 발급 실패는 첫 요청에서 다시 시도합니다
+	var err error
+	tokenManager, err = NewTokenManager(currentConfig().TokenNetworkDomains, false)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to initialize token manager: %v\n", err)
+		return 1
+	}
+	contentCache = NewContentCache(0, 0, 0)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	response, err := buildContentResponse(ctx, ContentRequest{PostID: *postID, ContentOptions: opts})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error fetching content: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(response); err != nil {
+			fmt.Fprintf(stderr, "Error encoding response: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintln(stdout, response.Content)
+	return 0
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRunFetchCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{"html by post id", []string{"--post-id=p1"}, 0, "<p>안녕 <b>세계</b></p>\n", ""},
+		{"text format", []string{"--post-id=p1", "--format=text"}, 0, "안녕 세계\n", ""},
+		{"json output", []string{"--post-id=p1", "--json"}, 0, `"post_id": "p1"`, ""},
+		{"post id from url", []string{"--url=https://www.gpters.org/dev/post/hello-p1"}, 0, "<p>", ""},
+		{"missing post id", nil, 2, "", "Either --post-id or --url is required"},
+		{"invalid format", []string{"--post-id=p1", "--format=pdf"}, 2, "", "Format must be"},
+		{"unknown flag", []string{"--bogus"}, 2, "", "flag provided but not defined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// runFetchCommand가 바꾸는 전역 토큰 관리자와 캐시를 테스트 뒤에 되돌립니다
+			withTestToken(t)
+			withContentCache(t)
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				if req := readGraphQLRequest(t, r); strings.Contains(req.Query, "tokens(") {
+					writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+						"data": map[string]interface{}{"tokens": map[string]string{"accessToken": "guest-token"}},
+					})
+					return
+				}
+				writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>안녕 <b>세계</b></p>")))
+			})
+
+			var stdout, stderr bytes.Buffer
+			code := runFetchCommand(tt.args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+			if tt.wantStdout != "" && !strings.Contains(stdout.String(), tt.wantStdout) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), tt.wantStdout)
+			}
+			if tt.wantStderr != "" && !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
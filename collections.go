@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// listPageSize는 목록 쿼리에서 한 번에 요청하는 항목 수입니다
+const listPageSize = 50
+
+// PostSummary는 목록 쿼리로 받은 게시물의 기본 정보입니다
+type PostSummary struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	SpaceID   string `json:"space_id,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// CollectionRequest는 컬렉션(시리즈)에 속한 게시물들을 가져오기 위한 요청입니다
+type CollectionRequest struct {
+	CollectionID string `json:"-"`
+	ContentOptions
+}
+
+// CollectionResponse는 컬렉션의 게시물 콘텐츠를 순서대로 담은 응답입니다
+type CollectionResponse struct {
+	CollectionID string            `json:"collection_id"`
+	Name         string            `json:"name"`
+	Results      []BatchItemResult `json:"results"`
+	Truncated    bool              `json:"truncated,omitempty"` // 게시물이 BATCH_MAX_ITEMS보다 많아 앞부분만 가져온 경우
+	Cancelled    bool              `json:"cancelled,omitempty"`
+}
+
+// pageInfo는 BetterMode의 커서 기반 페이지 정보입니다
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+const collectionQuery = `
+	query GetCollection($id: ID!, $limit: Int!, $after: String) {
+		collection(id: $id) {
+			id
+			name
+			spaces(limit: $limit, after: $after) {
+				nodes {
+					id
+				}
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+			}
+		}
+	}
+`
+
+// spacePostsQuery는 컬렉션 안의 순서대로 읽도록 스페이스 게시물을 오래된 순으로 가져옵니다
+var spacePostsQuery = buildPostListQuery("ListSpacePosts", "createdAt", oldestFirst, gqlFields("id", "title", "spaceId", "createdAt"))
+
+// GetCollectionPosts godoc
+// @Summary Get content for all posts in a collection
+// @Description Fetches every post in a BetterMode collection (series) in order: spaces in collection order, posts oldest first.
+// @Tags content
+// @Produce json
+// @Param collectionID path string true "Collection ID"
+// @Param format query string false "Response format (html, text, xhtml or jsonld)"
+// @Success 200 {object} CollectionResponse
+// @Failure 400 {string} string "Bad request"
+// @Failure 502 {string} string "BetterMode API error"
+// @Router /collections/{collectionID}/posts [get]
+func getCollectionPosts(w http.ResponseWriter, r *http.Request) {
+	var req CollectionRequest
+	if err := decodeContentRequest(r, &req, decodeLenient); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.CollectionID = chi.URLParam(r, "collectionID")
+
+	if err := req.ContentOptions.normalize(); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name, posts, truncated, err := listCollectionPosts(r.Context(), req.CollectionID, currentConfig().BatchMaxItems)
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.As(err, new(*inputError)) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, errCallerTokenRejected) {
+			status = http.StatusUnauthorized
+		}
+		writeError(w, "Error fetching collection: "+err.Error(), status)
+		return
+	}
+
+	postIDs := make([]string, len(posts))
+	for i, p := range posts {
+		postIDs[i] = p.ID
+	}
+	results, cancelled := fetchBatch(r.Context(), postIDs, nil, req.ContentOptions, currentConfig().BatchConcurrency)
+
+	setCacheControl(w, req.NoCache)
+	render.JSON(w, r, CollectionResponse{
+		CollectionID: req.CollectionID,
+		Name:         name,
+		Results:      results,
+		Truncated:    truncated,
+		Cancelled:    cancelled,
+	})
+}
+
+// listCollectionPosts는 컬렉션에 속한 게시물을 스페이스 순서, 스페이스 안에서는 오래된 순으로 반환합니다.
+// 최대 max개까지만 모으며, 더 남아 있으면 세 번째 반환값이 true가 됩니다.
+func listCollectionPosts(ctx context.Context, collectionID string, max int) (string, []PostSummary, bool, error) {
+	var name string
+	var spaceIDs []string
+
+	after := ""
+	for {
+		variables := map[string]interface{}{"id": collectionID, "limit": listPageSize}
+		if after != "" {
+			variables["after"] = after
+		}
+
+		var data struct {
+			Collection struct {
+				ID     string `json:"id"`
+				Name   string `json:"name"`
+				Spaces struct {
+					Nodes []struct {
+						ID string `json:"id"`
+					} `json:"nodes"`
+					PageInfo pageInfo `json:"pageInfo"`
+				} `json:"spaces"`
+			} `json:"collection"`
+		}
+		if err := queryBetterMode(ctx, collectionQuery, variables, &data); err != nil {
+			return "", nil, false, err
+		}
+		if data.Collection.ID == "" {
+			return "", nil, false, fmt.Errorf("collection %s not found", collectionID)
+		}
+
+		name = data.Collection.Name
+		for _, space := range data.Collection.Spaces.Nodes {
+			spaceIDs = append(spaceIDs, space.ID)
+		}
+		if !data.Collection.Spaces.PageInfo.HasNextPage || data.Collection.Spaces.PageInfo.EndCursor == "" {
+			break
+		}
+		after = data.Collection.Spaces.PageInfo.EndCursor
+	}
+
+	var posts []PostSummary
+	for _, spaceID := range spaceIDs {
+		if len(posts) >= max {
+			// 남은 스페이스는 확인하지 않으므로 잘렸을 수 있다고 표시합니다
+			return name, posts, true, nil
+		}
+		spacePosts, more, err := listSpacePosts(ctx, spaceID, max-len(posts))
+		if err != nil {
+			return "", nil, false, err
+		}
+		posts = append(posts, spacePosts...)
+		if more {
+			return name, posts, true, nil
+		}
+	}
+	return name, posts, false, nil
+}
+
+// listSpacePosts는 스페이스의 게시물을 오래된 순으로 최대 max개까지 페이지를 넘기며 가져옵니다.
+// 가져오지 못한 게시물이 남아 있으면 두 번째 반환값이 true가 됩니다.
+func listSpacePosts(ctx context.Context, spaceID string, max int) ([]PostSummary, bool, error) {
+	var posts []PostSummary
+	after := ""
+	for {
+		if len(posts) >= max {
+			return posts, true, nil
+		}
+
+		variables := map[string]interface{}{
+			"spaceIds": []string{spaceID},
+			"limit":    listPageSize,
+		}
+		if after != "" {
+			variables["after"] = after
+		}
+
+		var data struct {
+			Posts struct {
+				Nodes []struct {
+					ID        string `json:"id"`
+					Title     string `json:"title"`
+					SpaceID   string `json:"spaceId"`
+					CreatedAt string `json:"createdAt"`
+				} `json:"nodes"`
+				PageInfo pageInfo `json:"pageInfo"`
+			} `json:"posts"`
+		}
+		if err := queryBetterMode(ctx, spacePostsQuery, variables, &data); err != nil {
+			return nil, false, err
+		}
+
+		for _, node := range data.Posts.Nodes {
+			if len(posts) >= max {
+				return posts, true, nil
+			}
+			posts = append(posts, PostSummary{ID: node.ID, Title: node.Title, SpaceID: node.SpaceID, CreatedAt: node.CreatedAt})
+		}
+		if !data.Posts.PageInfo.HasNextPage || data.Posts.PageInfo.EndCursor == "" {
+			return posts, false, nil
+		}
+		after = data.Posts.PageInfo.EndCursor
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// collectionUpstream은 컬렉션 하나와, 스페이스별로 오래된 순 게시물을 pageSize개씩 돌려주는 가짜 BetterMode입니다
+func collectionUpstream(t *testing.T, spaces map[string][]string, spaceOrder []string, pageSize int) {
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		req := readGraphQLRequest(t, r)
+		if strings.Contains(req.Query, "GetCollection") {
+			nodes := []map[string]string{}
+			for _, id := range spaceOrder {
+				nodes = append(nodes, map[string]string{"id": id})
+			}
+			writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{
+				"collection": map[string]interface{}{"id": "col-1", "name": "시리즈", "spaces": map[string]interface{}{"nodes": nodes}},
+			}})
+			return
+		}
+		// 컬렉션은 오래된 순으로 읽어야 합니다
+		if !strings.Contains(req.Query, "reverse: false") {
+			t.Errorf("space posts query is not oldest first:\n%s", req.Query)
+		}
+		spaceID := req.Variables["spaceIds"].([]interface{})[0].(string)
+		start := 0
+		if after, ok := req.Variables["after"].(string); ok {
+			fmt.Sscan(after, &start)
+		}
+		posts := spaces[spaceID]
+		end := start + pageSize
+		if end > len(posts) {
+			end = len(posts)
+		}
+		nodes := []map[string]string{}
+		for _, id := range posts[start:end] {
+			nodes = append(nodes, map[string]string{"id": id, "spaceId": spaceID})
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{
+			"posts": map[string]interface{}{
+				"nodes":    nodes,
+				"pageInfo": map[string]interface{}{"hasNextPage": end < len(posts), "endCursor": fmt.Sprint(end)},
+			},
+		}})
+	})
+}
+
+func TestListCollectionPosts(t *testing.T) {
+	spaces := map[string][]string{
+		"s1": {"a1", "a2", "a3"},
+		"s2": {"b1", "b2"},
+	}
+	tests := []struct {
+		name          string
+		max           int
+		want          []string
+		wantTruncated bool
+	}{
+		{"all posts in order", 10, []string{"a1", "a2", "a3", "b1", "b2"}, false},
+		{"exactly max", 5, []string{"a1", "a2", "a3", "b1", "b2"}, false},
+		{"truncated inside a space", 2, []string{"a1", "a2"}, true},
+		{"truncated at a space boundary", 3, []string{"a1", "a2", "a3"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			collectionUpstream(t, spaces, []string{"s1", "s2"}, 2)
+
+			name, posts, truncated, err := listCollectionPosts(context.Background(), "col-1", tt.max)
+			if err != nil {
+				t.Fatalf("listCollectionPosts: %v", err)
+			}
+			if name != "시리즈" {
+				t.Errorf("name = %q, want 시리즈", name)
+			}
+			var ids []string
+			for _, p := range posts {
+				ids = append(ids, p.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("posts = %v, want %v", ids, tt.want)
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
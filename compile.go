@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/render"
+)
+
+// CompileRequest는 여러 게시물을 순서대로 이어 붙여 하나의 문서로 만들기 위한 요청입니다
+type CompileRequest struct {
+	PostIDs       []string `json:"post_ids"`
+	Separator     *string  `json:"separator,omitempty"`      // 게시물 사이 구분자 (없으면 형식별 기본값)
+	IncludeTitles bool     `json:"include_titles,omitempty"` // 각 게시물 앞에 제목 헤딩 추가
+	ContentOptions
+}
+
+// CompileResponse는 합쳐진 문서입니다
+type CompileResponse struct {
+	Content   string   `json:"content"`
+	Format    string   `json:"format"`
+	Encoding  string   `json:"encoding,omitempty"` // encoding: "base64" 요청 시 합쳐진 문서 전체를 인코딩
+	PostIDs   []string `json:"post_ids"`
+	CharCount int64    `json:"char_count"` // 합쳐진 문서의 문자(rune) 수
+	WordCount int64    `json:"word_count"` // 태그를 제외한 본문의 단어 수
+	// 합쳐진 문서를 그대로 LLM에 넣을 때의 어림 토큰 수 (estimateTokens 참고)
+	TokenCount int64 `json:"token_count"`
+
+	Warnings []Warning `json:"warnings,omitempty"` // 각 게시물에서 나온 경고 (메시지 앞에 post ID 표시)
+}
+
+// defaultCompileSeparators는 separator를 지정하지 않았을 때 형식별 구분자입니다
+var defaultCompileSeparators = map[string]string{
+	"html":  "\n<hr>\n",
+	"xhtml": "\n<hr />\n",
+	"text":  "\n\n",
+}
+
+// CompileContent godoc
+// @Summary Compile several posts into one document
+// @Description Fetches the posts in the given order and concatenates them with a separator, optionally adding a title heading before each post. Fails if any post cannot be fetched.
+// @Tags content
+// @Accept json
+// @Produce json
+// @Param request body CompileRequest true "Ordered post IDs, separator and shared options"
+// @Success 200 {object} CompileResponse
+// @Failure 400 {string} string "Bad request"
+// @Failure 502 {string} string "One or more posts could not be fetched"
+// @Router /compile [post]
+func compileContent(w http.ResponseWriter, r *http.Request) {
+	cfg := currentConfig()
+	var req CompileRequest
+	if err := decodeContentRequest(r, &req, decodeLenient); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.PostIDs) == 0 {
+		writeError(w, "post_ids is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.PostIDs) > cfg.BatchMaxItems {
+		writeError(w, fmt.Sprintf("Too many post_ids (max %d)", cfg.BatchMaxItems), http.StatusBadRequest)
+		return
+	}
+
+	if err := req.ContentOptions.normalize(); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.MetadataOnly || req.Format == FormatJSONLD {
+		writeError(w, "metadata_only and format 'jsonld' are not supported for compile", http.StatusBadRequest)
+		return
+	}
+
+	// base64는 게시물마다가 아니라 합쳐진 문서 전체에 적용합니다
+	opts := req.ContentOptions
+	opts.Encoding = ""
+
+	results, cancelled := fetchBatch(r.Context(), req.PostIDs, nil, opts, cfg.BatchConcurrency)
+	if cancelled {
+		// 클라이언트가 떠났으므로 일부만 합친 문서는 보내지 않습니다
+		writeError(w, "Request cancelled", http.StatusServiceUnavailable)
+		return
+	}
+
+	var failed []string
+	for _, item := range results {
+		if item.Error != "" {
+			failed = append(failed, fmt.Sprintf("%s: %s", item.PostID, item.Error))
+		}
+	}
+	if len(failed) > 0 {
+		writeError(w, "Error fetching posts: "+strings.Join(failed, "; "), http.StatusBadGateway)
+		return
+	}
+
+	separator := defaultCompileSeparators[req.Format]
+	if req.Separator != nil {
+		separator = *req.Separator
+	}
+
+	response := compileDocument(results, req.Format, separator, req.IncludeTitles)
+	if req.Encoding == EncodingBase64 {
+		response.Content = base64.StdEncoding.EncodeToString([]byte(response.Content))
+		response.Encoding = EncodingBase64
+	}
+
+	setCacheControl(w, req.NoCache)
+	render.JSON(w, r, response)
+}
+
+// compileDocument는 성공한 배치 결과들을 입력 순서대로 하나의 문서로 합칩니다
+func compileDocument(results []BatchItemResult, format, separator string, includeTitles bool) CompileResponse {
+	response := CompileResponse{Format: format, PostIDs: make([]string, 0, len(results))}
+
+	parts := make([]string, 0, len(results))
+	var words int64
+	for _, item := range results {
+		post := item.Result
+		part := post.Content
+		if includeTitles && post.Title != "" {
+			part = titleHeading(post.Title, format) + part
+		}
+		parts = append(parts, part)
+
+		if format == "text" {
+			words += int64(len(strings.Fields(part)))
+		} else {
+			words += int64(len(strings.Fields(html.UnescapeString(stripHTMLTags(part)))))
+		}
+
+		response.PostIDs = append(response.PostIDs, item.PostID)
+		for _, warning := range post.Warnings {
+			warning.Message = item.PostID + ": " + warning.Message
+			response.Warnings = append(response.Warnings, warning)
+		}
+	}
+
+	response.Content = strings.Join(parts, separator)
+	response.CharCount = int64(utf8.RuneCountInString(response.Content))
+	response.WordCount = words
+	response.TokenCount = estimateTokens(response.Content)
+	return response
+}
+
+// titleHeading은 형식에 맞는 제목 헤딩을 만듭니다
+func titleHeading(title, format string) string {
+	if format == "text" {
+		return title + "\n\n"
+	}
+	if format == "xhtml" {
+		return "<h1>" + escapeXML(title) + "</h1>\n"
+	}
+	return "<h1>" + html.EscapeString(title) + "</h1>\n"
+}
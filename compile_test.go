@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCompileDocument(t *testing.T) {
+	results := []BatchItemResult{
+		{PostID: "a", Result: &ContentResponse{Title: "첫 글", Content: "<p>하나 둘</p>"}},
+		{PostID: "b", Result: &ContentResponse{Title: "A & B", Content: "<p>셋</p>", Warnings: []Warning{{Code: WarningMissingTitle, Message: "post has no title"}}}},
+	}
+	tests := []struct {
+		name          string
+		format        string
+		separator     string
+		includeTitles bool
+		wantContent   string
+		wantWords     int64
+	}{
+		{"html", "html", "\n<hr>\n", false, "<p>하나 둘</p>\n<hr>\n<p>셋</p>", 3},
+		{"html with titles", "html", "|", true, "<h1>첫 글</h1>\n<p>하나 둘</p>|<h1>A &amp; B</h1>\n<p>셋</p>", 8},
+		{"xhtml with titles", "xhtml", "", true, "<h1>첫 글</h1>\n<p>하나 둘</p><h1>A &amp; B</h1>\n<p>셋</p>", 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compileDocument(results, tt.format, tt.separator, tt.includeTitles)
+			if got.Content != tt.wantContent {
+				t.Errorf("content = %q, want %q", got.Content, tt.wantContent)
+			}
+			if got.WordCount != tt.wantWords {
+				t.Errorf("word_count = %d, want %d", got.WordCount, tt.wantWords)
+			}
+			if got.CharCount != int64(len([]rune(tt.wantContent))) {
+				t.Errorf("char_count = %d, want rune count of content", got.CharCount)
+			}
+			if !reflect.DeepEqual(got.PostIDs, []string{"a", "b"}) {
+				t.Errorf("post_ids = %v", got.PostIDs)
+			}
+			if len(got.Warnings) != 1 || got.Warnings[0].Message != "b: post has no title" {
+				t.Errorf("warnings = %+v, want one prefixed with the post ID", got.Warnings)
+			}
+		})
+	}
+}
+
+func TestCompileContent(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"text with default separator", `{"post_ids":["a","b"],"format":"text"}`, http.StatusOK, `"content":"본문\n\n본문"`},
+		{"missing post fails", `{"post_ids":["a","missing"]}`, http.StatusBadGateway, "missing"},
+		{"no post ids", `{}`, http.StatusBadRequest, "post_ids is required"},
+		{"metadata_only rejected", `{"post_ids":["a"],"metadata_only":true}`, http.StatusBadRequest, "not supported for compile"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			batchUpstream(t, nil)
+
+			w := httptest.NewRecorder()
+			compileContent(w, httptest.NewRequest("POST", "/api/v1/compile", strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCompileContentBase64EncodesWholeDocument(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	batchUpstream(t, nil)
+
+	w := httptest.NewRecorder()
+	body := `{"post_ids":["a","b"],"format":"text","separator":" | ","encoding":"base64"}`
+	compileContent(w, httptest.NewRequest("POST", "/api/v1/compile", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var response CompileResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(response.Content)
+	if err != nil {
+		t.Fatalf("content is not base64: %v", err)
+	}
+	// 게시물마다 인코딩하면 구분자가 인코딩된 조각 사이에 남습니다
+	if string(decoded) != "본문 | 본문" || response.Encoding != EncodingBase64 {
+		t.Errorf("decoded = %q, encoding = %q; want the whole document encoded once", decoded, response.Encoding)
+	}
+}
+
+func TestCompileDocumentLargeCounts(t *testing.T) {
+	// 한 글자 3바이트인 한글 1MiB 분량의 게시물 여러 개를 합칩니다
+	const posts, wordsPerPost = 4, 1 << 18
+	part := strings.Repeat("가나 ", wordsPerPost)
+	results := make([]BatchItemResult, posts)
+	for i := range results {
+		results[i] = BatchItemResult{PostID: strconv.Itoa(i), Result: &ContentResponse{Content: part}}
+	}
+
+	got := compileDocument(results, "text", "", false)
+	if want := int64(posts * wordsPerPost * 3); got.CharCount != want {
+		t.Errorf("char_count = %d, want %d", got.CharCount, want)
+	}
+	if want := int64(posts * wordsPerPost); got.WordCount != want {
+		t.Errorf("word_count = %d, want %d", got.WordCount, want)
+	}
+}
+
+func TestContentCountsMarshalBeyondInt32(t *testing.T) {
+	const large = int64(1)<<33 + 7
+	data, err := json.Marshal(CompileResponse{CharCount: large, WordCount: large})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"char_count":8589934599`) {
+		t.Errorf("marshalled = %s, want exact char_count", data)
+	}
+
+	var decoded ContentResponse
+	if err := json.Unmarshal([]byte(`{"char_count":8589934599,"byte_count":8589934599}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.CharCount != large || decoded.ByteCount != large {
+		t.Errorf("decoded counts = %d/%d, want %d", decoded.CharCount, decoded.ByteCount, large)
+	}
+}
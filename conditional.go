@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// checkNotModified는 게시물의 updatedAt으로 Last-Modified 헤더를 설정하고,
+// GET 요청의 If-Modified-Since가 그 시각 이후이면 304를 보낸 뒤 true를 반환합니다.
+// updatedAt을 알 수 없으면 아무것도 하지 않습니다.
+func checkNotModified(w http.ResponseWriter, r *http.Request, updatedAt string) bool {
+	modified, err := time.Parse(time.RFC3339, updatedAt)
+	if err != nil {
+		return false
+	}
+	// HTTP 날짜는 초 단위이므로 비교도 초 단위로 합니다
+	modified = modified.UTC().Truncate(time.Second)
+	w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modified.After(since) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
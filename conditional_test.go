@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckNotModified(t *testing.T) {
+	const updatedAt = "2024-05-01T10:00:00.750Z"
+	tests := []struct {
+		name             string
+		method           string
+		updatedAt        string
+		ifModifiedSince  string
+		wantNotModified  bool
+		wantLastModified string
+	}{
+		{"no header", "GET", updatedAt, "", false, "Wed, 01 May 2024 10:00:00 GMT"},
+		{"same second", "GET", updatedAt, "Wed, 01 May 2024 10:00:00 GMT", true, "Wed, 01 May 2024 10:00:00 GMT"},
+		{"later", "GET", updatedAt, "Wed, 01 May 2024 11:00:00 GMT", true, "Wed, 01 May 2024 10:00:00 GMT"},
+		{"modified since", "GET", updatedAt, "Wed, 01 May 2024 09:59:59 GMT", false, "Wed, 01 May 2024 10:00:00 GMT"},
+		{"post ignores header", "POST", updatedAt, "Wed, 01 May 2024 11:00:00 GMT", false, "Wed, 01 May 2024 10:00:00 GMT"},
+		{"invalid header", "GET", updatedAt, "yesterday", false, "Wed, 01 May 2024 10:00:00 GMT"},
+		{"unknown updatedAt", "GET", "", "Wed, 01 May 2024 11:00:00 GMT", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/v1/content", nil)
+			if tt.ifModifiedSince != "" {
+				r.Header.Set("If-Modified-Since", tt.ifModifiedSince)
+			}
+			w := httptest.NewRecorder()
+			if got := checkNotModified(w, r, tt.updatedAt); got != tt.wantNotModified {
+				t.Errorf("checkNotModified = %v, want %v", got, tt.wantNotModified)
+			}
+			if tt.wantNotModified && w.Code != http.StatusNotModified {
+				t.Errorf("status = %d, want 304", w.Code)
+			}
+			if got := w.Header().Get("Last-Modified"); got != tt.wantLastModified {
+				t.Errorf("Last-Modified = %q, want %q", got, tt.wantLastModified)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config는 환경 변수에서 읽어 온 서버 설정입니다
+type Config struct {
+	Port                 string
+	CacheTTL             time.Duration // 0이면 캐시를 사용하지 않습니다
+	CacheSoftTTL         time.Duration // 지나면 캐시된 응답을 주면서 백그라운드에서 갱신 (0이면 사용 안 함)
+	CacheCleanupInterval time.Duration
+	CacheMaxEntries      int           // 응답/게시물 각각의 최대 항목 수, 넘치면 가장 오래 사용하지 않은 항목부터 삭제 (0이면 제한 없음)
+	CacheMaxTTL          time.Duration // 요청의 cache_ttl_seconds가 이보다 길면 이 값으로 줄입니다
+	CacheControlMaxAge   time.Duration // 응답 Cache-Control max-age (기본값: CacheTTL)
+	ShutdownTimeout      time.Duration
+	FetchTimeout         time.Duration // 게시물 하나를 가져와 가공하는 최대 시간 (0이면 제한 없음)
+	FetchMaxTimeout      time.Duration // 요청의 timeout_ms가 이보다 길면 이 값으로 줄입니다 (0이면 timeout_ms를 무시)
+
+	// 게스트 토큰을 발급받을 네트워크 도메인 목록 (우선순위 순서, 첫 번째가 기본 소스)
+	TokenNetworkDomains []string
+	// 활성 토큰 소스가 이 횟수만큼 연속 실패하면 다음 소스로 넘어갑니다
+	TokenSourceFailureThreshold int
+	// 기본 소스가 마지막으로 실패한 뒤 이 시간이 지나면 다시 기본 소스를 시도합니다
+	TokenSourceFailbackAfter time.Duration
+	// 게스트 토큰을 발급받을 때 요청할 권한 범위 (비어 있으면 BetterMode 기본 범위)
+	TokenScopes []string
+
+	// true이면 시작 시 토큰을 받지 못할 경우 서버를 시작하지 않습니다
+	FailOnInitialTokenError bool
+	// true이면 시작 시 토큰으로 BetterMode API를 호출해 실제로 유효한지 확인합니다
+	ValidateTokenOnStartup bool
+
+	// 게시물에 "content" 필드가 여러 개일 때 선택 정책 (prefer_html, longest, first)
+	ContentFieldPolicy string
+	// 본문에 잘못된 UTF-8 바이트가 있을 때의 처리 (repair: U+FFFD로 바꾸고 경고, strict: 502)
+	InvalidUTF8Policy string
+	// BetterMode가 errors와 함께 일부 데이터만 보냈을 때의 처리 (allow: 받은 데이터로 응답하고 경고, reject: 본문이 없으면 실패)
+	GraphQLPartialData string
+	// hash_algorithm을 지정하지 않은 요청의 content_hash 알고리즘 (sha256, sha1, md5, xxhash)
+	ContentHashAlgorithm string
+
+	// 본문에 적용할 기본 정리 프로필 (strict, embed-friendly, permissive, 빈 값이면 정리하지 않음)
+	SanitizeDefaultProfile string
+	// 스페이스 ID별 정리 프로필 ("spaceA=strict,spaceB=embed-friendly")
+	SanitizeSpaceProfiles map[string]string
+
+	// 요청과 관계없이 응답(fields, summary)에서 항상 빼는 매핑 필드 key 목록 (내부 메모 등)
+	FieldDenyList []string
+	// 설정되어 있으면 이 목록의 매핑 필드만 응답에 포함합니다 (FIELD_DENY_LIST가 우선)
+	FieldAllowList []string
+
+	// 스페이스를 지정하지 않은 스페이스 기준 조회(변경 목록 등)에 사용할 스페이스 ID (시작 시 BetterMode에서 확인)
+	DefaultSpaceID string
+
+	BatchMaxItems    int // 배치 요청 하나에 허용하는 최대 게시물 수
+	BatchConcurrency int // 배치 처리 시 동시에 가져오는 게시물 수
+
+	// 성공 응답 요청 로그를 N개 중 하나만 남깁니다 (1이면 모두 기록, 에러는 항상 기록)
+	AccessLogSampleRate int
+	// 로그 수준 (debug, info, warn)
+	LogLevel string
+
+	// 설정되어 있으면 정리된 콘텐츠를 이 URL로 POST하고 응답의 content로 바꿉니다 (실패하면 원본 사용)
+	PostProcessWebhookURL     string
+	PostProcessWebhookTimeout time.Duration
+
+	// translate_to 요청에 사용할 LibreTranslate 호환 번역 API (없으면 번역하지 않고 원문을 그대로 반환)
+	TranslatorURL    string
+	TranslatorAPIKey string
+
+	// 내보내기 작업 하나에 허용하는 최대 게시물 수
+	ExportMaxItems int
+	// 설정되어 있으면 "file:" 내보내기를 이 디렉터리 아래에만 허용합니다 (없으면 file: 사용 불가)
+	ExportLocalDir string
+	// "s3://" 내보내기에 사용하는 S3 호환 스토리지 설정 (path-style URL 사용)
+	S3Endpoint        string
+	S3Region          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+
+	// BetterMode 웹훅 서명 검증에 쓰는 비밀 값 (비어 있으면 /webhooks/bettermode를 받지 않음)
+	BetterModeWebhookSecret string
+	// 웹훅으로 받은 게시물 미리 가져오기 큐 크기 (시작 시에만 적용)
+	PrefetchQueueSize int
+
+	// /archive에 담는 이미지/첨부 파일의 최대 개수와 합계 바이트 수 (넘는 자산은 원격 링크로 남깁니다)
+	ArchiveMaxAssets int
+	ArchiveMaxBytes  int
+
+	// proxy_images와 /image-proxy가 허용하는 이미지 호스트 ("*.example.com"은 하위 도메인). 비어 있으면 이미지 프록시를 쓸 수 없습니다
+	ImageProxyAllowedHosts []string
+	// 설정되어 있으면 바꾼 이미지 주소 앞에 붙입니다 (예: https://api.example.com). 비어 있으면 /api/v1/image-proxy 상대 경로
+	ImageProxyBaseURL string
+	// 프록시로 전달하는 이미지의 최대 바이트 수
+	ImageProxyMaxBytes int
+	// 프록시 응답의 Cache-Control max-age이자 메모리 캐시 유지 시간, 메모리에 보관하는 이미지 수 (0이면 메모리 캐시 안 함)
+	ImageProxyCacheTTL     time.Duration
+	ImageProxyCacheEntries int
+
+	// 0보다 크면 응답의 제목을 이 글자(rune) 수로 자르고 "…"를 붙입니다 (0이면 자르지 않음)
+	MaxTitleLength int
+
+	// 설정되어 있으면 관리자 엔드포인트(/token/*, /logging/*, /config, /cache/stats)에 인증을 요구합니다
+	AdminKey string
+	// true이면 X-Admin-Key 방식은 거절하고 서명된 요청(타임스탬프+nonce)만 허용합니다
+	AdminRequireSignature bool
+
+	// 초당 허용 요청 수 (0이면 제한하지 않음)와 순간 허용량
+	RateLimitRPS   float64
+	RateLimitBurst int
+	// 한도를 넘은 요청을 429 전에 기다리게 하는 최대 시간 (0이면 바로 429). BetterMode의 429에도 적용됩니다
+	RateLimitQueueWait time.Duration
+	// 동시에 기다릴 수 있는 요청 수. 넘으면 바로 429
+	RateLimitQueueDepth int
+
+	// 한 클라이언트 IP가 동시에 처리 중일 수 있는 요청 수. 넘으면 429 (0이면 제한하지 않음)
+	PerIPMaxConcurrent int
+
+	// 처리 중인 요청이 이 수를 넘으면 새 요청을 503으로 거절합니다 (0이면 사용 안 함)
+	ShedMaxInFlight int
+	// 최근 BetterMode 평균 응답 시간이 이 값을 넘으면 새 요청을 503으로 거절합니다 (0이면 사용 안 함)
+	ShedUpstreamLatency time.Duration
+
+	// BetterMode 연결이 끊기는 등 네트워크 에러가 나면 이 횟수까지 다시 보냅니다 (0이면 다시 보내지 않음)
+	UpstreamNetworkRetries int
+	// BetterMode 응답이 중간에 끊기면(연결 끊김으로 본문이 잘림) 이 횟수까지 다시 요청합니다 (0이면 다시 요청하지 않음)
+	UpstreamTruncatedRetries int
+	// BetterMode가 이 시간 안에 응답하지 않으면 같은 요청을 한 번 더 보내 먼저 온 응답을 사용합니다 (0이면 사용 안 함)
+	UpstreamHedgeDelay time.Duration
+	// BetterMode 등 외부 호출에 허용하는 최소 TLS 버전 ("1.2", "1.3")
+	UpstreamTLSMinVersion string
+	// 설정되어 있으면 이 PEM 파일의 CA 인증서를 시스템 루트 인증서와 함께 신뢰합니다 (TLS 검사 프록시 환경용)
+	UpstreamCAFile string
+	// 같은 게시물 조회의 BetterMode 원본 응답을 재사용하는 시간 (0이면 사용 안 함)과 보관하는 응답 수 (시작 시에만 적용)
+	UpstreamResponseCacheTTL     time.Duration
+	UpstreamResponseCacheEntries int
+	// 게시물은 있는데 본문이 비어 있으면 EmptyContentRetryDelay 간격으로 이 횟수까지 다시 가져옵니다 (0이면 다시 가져오지 않음)
+	EmptyContentRetries    int
+	EmptyContentRetryDelay time.Duration
+
+	// BetterMode 호출이 이 횟수만큼 연속 실패하면 CircuitBreakerOpenDuration 동안 호출을 막고 503을 반환합니다 (0이면 사용 안 함)
+	CircuitBreakerThreshold    int
+	CircuitBreakerOpenDuration time.Duration
+	// 게스트 토큰 발급이 이 횟수만큼 연속 실패하면 TokenCircuitBreakerOpenDuration 동안 발급을 시도하지 않습니다 (0이면 사용 안 함).
+	// 콘텐츠 조회 브레이커와 따로 셉니다.
+	TokenCircuitBreakerThreshold    int
+	TokenCircuitBreakerOpenDuration time.Duration
+
+	// true이면 /content, /url 요청/응답 본문을 로그에 남깁니다 (디버깅용, 운영 환경에서는 끄세요)
+	DebugLogBodies bool
+	// /content, /url 응답의 본문이 이 바이트 수 이상이면 JSON을 한 번에 만들지 않고 스트리밍합니다 (0이면 항상 한 번에)
+	ResponseStreamThreshold int
+
+	// 실행 환경 (production, staging 등). production에서는 ChaosEnabled를 켤 수 없습니다
+	AppEnv string
+	// 장애 테스트용: true이면 콘텐츠 조회에 ChaosErrorRate 확률로 네트워크 에러, ChaosRateLimitRate 확률로 429,
+	// ChaosLatencyRate 확률로 ChaosLatency만큼의 지연을 주입합니다 (확률은 0~1)
+	ChaosEnabled       bool
+	ChaosErrorRate     float64
+	ChaosRateLimitRate float64
+	ChaosLatencyRate   float64
+	ChaosLatency       time.Duration
+}
+
+// loadConfig는 환경 변수에서 설정을 읽고, 값이 없으면 기본값을 사용합니다
+func loadConfig() *Config {
+	cacheTTL := getEnvDuration("CACHE_TTL", 10*time.Minute)
+
+	return &Config{
+		Port:                 getEnv("PORT", "8080"),
+		CacheTTL:             cacheTTL,
+		CacheSoftTTL:         getEnvDuration("CACHE_SOFT_TTL", 0),
+		CacheCleanupInterval: getEnvDuration("CACHE_CLEANUP_INTERVAL", time.Minute),
+		CacheMaxEntries:      getEnvInt("CACHE_MAX_ENTRIES", 10000),
+		CacheMaxTTL:          getEnvDuration("CACHE_MAX_TTL", time.Hour),
+		CacheControlMaxAge:   getEnvDuration("CACHE_CONTROL_MAX_AGE", cacheTTL),
+		ShutdownTimeout:      getEnvDuration("SHUTDOWN_TIMEOUT", 15*time.Second),
+		FetchTimeout:         getEnvDuration("FETCH_TIMEOUT", 30*time.Second),
+		FetchMaxTimeout:      getEnvDuration("FETCH_MAX_TIMEOUT", 2*time.Minute),
+
+		TokenNetworkDomains:         getEnvList("TOKEN_NETWORK_DOMAINS", []string{"www.gpters.org"}),
+		TokenSourceFailureThreshold: getEnvInt("TOKEN_SOURCE_FAILURE_THRESHOLD", 3),
+		TokenSourceFailbackAfter:    getEnvDuration("TOKEN_SOURCE_FAILBACK_AFTER", 5*time.Minute),
+		TokenScopes:                 getEnvList("TOKEN_SCOPES", nil),
+
+		FailOnInitialTokenError: getEnvBool("FAIL_ON_INITIAL_TOKEN_ERROR", false),
+		ValidateTokenOnStartup:  getEnvBool("VALIDATE_TOKEN_ON_STARTUP", false),
+		ContentFieldPolicy:      getEnvChoice("CONTENT_FIELD_POLICY", ContentFieldPreferHTML, ContentFieldPreferHTML, ContentFieldLongest, ContentFieldFirst),
+		InvalidUTF8Policy:       getEnvChoice("INVALID_UTF8", InvalidUTF8Repair, InvalidUTF8Repair, InvalidUTF8Strict),
+		GraphQLPartialData:      getEnvChoice("GRAPHQL_PARTIAL_DATA", PartialDataAllow, PartialDataAllow, PartialDataReject),
+		ContentHashAlgorithm:    getEnvChoice("CONTENT_HASH_ALGORITHM", HashSHA256, HashSHA256, HashSHA1, HashMD5, HashXXHash),
+
+		SanitizeDefaultProfile: getEnvChoice("SANITIZE_DEFAULT_PROFILE", "", SanitizeStrict, SanitizeEmbedFriendly, SanitizePermissive),
+		SanitizeSpaceProfiles:  getEnvProfileMap("SANITIZE_SPACE_PROFILES"),
+
+		FieldDenyList:  getEnvList("FIELD_DENY_LIST", nil),
+		FieldAllowList: getEnvList("FIELD_ALLOW_LIST", nil),
+
+		DefaultSpaceID: os.Getenv("DEFAULT_SPACE_ID"),
+
+		BatchMaxItems:    getEnvInt("BATCH_MAX_ITEMS", 50),
+		BatchConcurrency: getEnvInt("BATCH_CONCURRENCY", 4),
+
+		AdminKey:              os.Getenv("ADMIN_KEY"),
+		AdminRequireSignature: getEnvBool("ADMIN_REQUIRE_SIGNATURE", false),
+
+		RateLimitRPS:        getEnvFloat("RATE_LIMIT_RPS", 0),
+		RateLimitBurst:      getEnvInt("RATE_LIMIT_BURST", 10),
+		RateLimitQueueWait:  getEnvDuration("RATE_LIMIT_QUEUE_WAIT", 0),
+		RateLimitQueueDepth: getEnvInt("RATE_LIMIT_QUEUE_DEPTH", 20),
+
+		PerIPMaxConcurrent:  getEnvInt("PER_IP_MAX_CONCURRENT", 0),
+		ShedMaxInFlight:     getEnvInt("SHED_MAX_IN_FLIGHT", 0),
+		ShedUpstreamLatency: getEnvDuration("SHED_UPSTREAM_LATENCY", 0),
+
+		UpstreamNetworkRetries:       getEnvInt("UPSTREAM_NETWORK_RETRIES", 2),
+		UpstreamTruncatedRetries:     getEnvInt("UPSTREAM_TRUNCATED_RETRIES", 1),
+		UpstreamHedgeDelay:           getEnvDuration("UPSTREAM_HEDGE_DELAY", 0),
+		UpstreamTLSMinVersion:        getEnvChoice("UPSTREAM_TLS_MIN_VERSION", "1.2", "1.2", "1.3"),
+		UpstreamCAFile:               os.Getenv("UPSTREAM_CA_FILE"),
+		UpstreamResponseCacheTTL:     getEnvDuration("UPSTREAM_RESPONSE_CACHE_TTL", 0),
+		UpstreamResponseCacheEntries: getEnvInt("UPSTREAM_RESPONSE_CACHE_ENTRIES", 500),
+		EmptyContentRetries:          getEnvInt("EMPTY_CONTENT_RETRIES", 0),
+		EmptyContentRetryDelay:       getEnvDuration("EMPTY_CONTENT_RETRY_DELAY", 500*time.Millisecond),
+
+		CircuitBreakerThreshold:    getEnvInt("CIRCUIT_BREAKER_THRESHOLD", 5),
+		CircuitBreakerOpenDuration: getEnvDuration("CIRCUIT_BREAKER_OPEN_DURATION", 30*time.Second),
+
+		TokenCircuitBreakerThreshold:    getEnvInt("TOKEN_CIRCUIT_BREAKER_THRESHOLD", 5),
+		TokenCircuitBreakerOpenDuration: getEnvDuration("TOKEN_CIRCUIT_BREAKER_OPEN_DURATION", 30*time.Second),
+
+		PostProcessWebhookURL:     os.Getenv("POSTPROCESS_WEBHOOK_URL"),
+		PostProcessWebhookTimeout: getEnvDuration("POSTPROCESS_WEBHOOK_TIMEOUT", 5*time.Second),
+
+		TranslatorURL:    os.Getenv("TRANSLATOR_URL"),
+		TranslatorAPIKey: os.Getenv("TRANSLATOR_API_KEY"),
+
+		ExportMaxItems:    getEnvInt("EXPORT_MAX_ITEMS", 1000),
+		ExportLocalDir:    os.Getenv("EXPORT_LOCAL_DIR"),
+		S3Endpoint:        os.Getenv("S3_ENDPOINT"),
+		S3Region:          getEnv("S3_REGION", "us-east-1"),
+		S3AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+
+		BetterModeWebhookSecret: os.Getenv("BETTERMODE_WEBHOOK_SECRET"),
+		PrefetchQueueSize:       getEnvInt("PREFETCH_QUEUE_SIZE", 100),
+
+		ArchiveMaxAssets: getEnvInt("ARCHIVE_MAX_ASSETS", 50),
+		ArchiveMaxBytes:  getEnvInt("ARCHIVE_MAX_BYTES", 50<<20),
+
+		ImageProxyAllowedHosts: getEnvList("IMAGE_PROXY_ALLOWED_HOSTS", nil),
+		ImageProxyBaseURL:      getEnv("IMAGE_PROXY_BASE_URL", ""),
+		ImageProxyMaxBytes:     getEnvInt("IMAGE_PROXY_MAX_BYTES", 10<<20),
+		ImageProxyCacheTTL:     getEnvDuration("IMAGE_PROXY_CACHE_TTL", time.Hour),
+		ImageProxyCacheEntries: getEnvInt("IMAGE_PROXY_CACHE_ENTRIES", 200),
+
+		AccessLogSampleRate:     getEnvInt("ACCESS_LOG_SAMPLE_RATE", 1),
+		LogLevel:                getEnvChoice("LOG_LEVEL", LogLevelInfo, LogLevelDebug, LogLevelInfo, LogLevelWarn),
+		MaxTitleLength:          getEnvInt("MAX_TITLE_LENGTH", 0),
+		DebugLogBodies:          getEnvBool("DEBUG_LOG_BODIES", false),
+		ResponseStreamThreshold: getEnvInt("RESPONSE_STREAM_THRESHOLD", 1<<20),
+
+		AppEnv:             getEnv("APP_ENV", productionEnv),
+		ChaosEnabled:       getEnvBool("CHAOS_ENABLED", false),
+		ChaosErrorRate:     getEnvFloat("CHAOS_ERROR_RATE", 0),
+		ChaosRateLimitRate: getEnvFloat("CHAOS_RATE_LIMIT_RATE", 0),
+		ChaosLatencyRate:   getEnvFloat("CHAOS_LATENCY_RATE", 0),
+		ChaosLatency:       getEnvDuration("CHAOS_LATENCY", 2*time.Second),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvChoice는 허용된 값 중 하나만 받고, 그 외의 값이면 기본값을 사용합니다
+func getEnvChoice(key, fallback string, allowed ...string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	for _, a := range allowed {
+		if value == a {
+			return value
+		}
+	}
+	log.Printf("Invalid %s value %q, using default %q", key, value, fallback)
+	return fallback
+}
+
+// getEnvList는 "a,b,c" 형식의 쉼표로 구분된 값을 읽습니다. 빈 항목은 무시합니다.
+func getEnvList(key string, fallback []string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return fallback
+	}
+	return items
+}
+
+// getEnvProfileMap은 "스페이스ID=프로필" 쌍을 쉼표로 구분한 값을 읽습니다. 알 수 없는 프로필은 무시합니다.
+func getEnvProfileMap(key string) map[string]string {
+	profiles := make(map[string]string)
+	for _, item := range getEnvList(key, nil) {
+		spaceID, profile, ok := strings.Cut(item, "=")
+		spaceID, profile = strings.TrimSpace(spaceID), strings.TrimSpace(profile)
+		if !ok || spaceID == "" || !isSanitizeProfile(profile) {
+			log.Printf("Invalid %s entry %q, ignoring", key, item)
+			continue
+		}
+		profiles[spaceID] = profile
+	}
+	return profiles
+}
+
+// getEnvInt는 정수 값을 읽습니다
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid %s value %q, using default %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+// getEnvFloat는 실수 값을 읽습니다
+func getEnvFloat(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Invalid %s value %q, using default %v", key, value, fallback)
+		return fallback
+	}
+	return f
+}
+
+// getEnvBool은 strconv.ParseBool이 허용하는 값("true", "1" 등)을 읽습니다
+func getEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid %s value %q, using default %v", key, value, fallback)
+		return fallback
+	}
+	return b
+}
+
+// getEnvDuration은 "30s", "5m" 같은 time.ParseDuration 형식의 값을 읽습니다
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid %s value %q, using default %v", key, value, fallback)
+		return fallback
+	}
+	return d
+}
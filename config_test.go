@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetEnvBool(t *testing.T) {
+	tests := []struct {
+		value    string
+		fallback bool
+		want     bool
+	}{
+		{"", false, false},
+		{"", true, true},
+		{"true", false, true},
+		{"1", false, true},
+		{"false", true, false},
+		{"yes", true, true}, // 잘못된 값은 기본값
+	}
+	for _, tt := range tests {
+		t.Setenv("TEST_BOOL", tt.value)
+		if got := getEnvBool("TEST_BOOL", tt.fallback); got != tt.want {
+			t.Errorf("getEnvBool(%q, %v) = %v, want %v", tt.value, tt.fallback, got, tt.want)
+		}
+	}
+}
+
+func TestGetEnvDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", time.Minute},
+		{"30s", 30 * time.Second},
+		{"0", 0},
+		{"soon", time.Minute},
+	}
+	for _, tt := range tests {
+		t.Setenv("TEST_DURATION", tt.value)
+		if got := getEnvDuration("TEST_DURATION", time.Minute); got != tt.want {
+			t.Errorf("getEnvDuration(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGetEnvProfileMap(t *testing.T) {
+	t.Setenv("TEST_PROFILES", "marketing=embed-friendly, docs = strict,broken,unknown=loose,=strict")
+	got := getEnvProfileMap("TEST_PROFILES")
+	want := map[string]string{"marketing": SanitizeEmbedFriendly, "docs": SanitizeStrict}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getEnvProfileMap = %v, want %v", got, want)
+	}
+}
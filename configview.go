@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-chi/render"
+)
+
+// redactedValue는 값이 설정된 비밀 설정 대신 보여 주는 문자열입니다 (설정되지 않았으면 빈 문자열)
+const redactedValue = "[REDACTED]"
+
+// secretSettings는 값을 절대 보여 주지 않는 설정입니다.
+// 이름이 Key, Secret, Password, Credential로 끝나거나 포함하는 설정도 비밀로 취급합니다 (isSecretSetting 참고).
+var secretSettings = map[string]bool{
+	"AdminKey":          true,
+	"S3AccessKeyID":     true,
+	"S3SecretAccessKey": true,
+	"TranslatorAPIKey":  true,
+}
+
+func isSecretSetting(name string) bool {
+	if secretSettings[name] {
+		return true
+	}
+	return strings.HasSuffix(name, "Key") || strings.Contains(name, "Secret") ||
+		strings.Contains(name, "Password") || strings.Contains(name, "Credential")
+}
+
+// handleConfig는 적용 중인 설정을 비밀 값을 가려서 보여 주는 엔드포인트입니다 (관리자용)
+func handleConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, redactConfig(currentConfig()))
+}
+
+// redactConfig는 설정을 snake_case 키의 맵으로 만들고 비밀 값을 가립니다
+func redactConfig(cfg *Config) map[string]interface{} {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	out := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value := v.Field(i).Interface()
+		key := snakeCase(field.Name)
+
+		if isSecretSetting(field.Name) {
+			if v.Field(i).IsZero() {
+				out[key] = ""
+			} else {
+				out[key] = redactedValue
+			}
+			continue
+		}
+		switch value := value.(type) {
+		case time.Duration:
+			out[key] = value.String()
+		case string:
+			out[key] = redactURL(value)
+		default:
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// redactURL은 URL 형태의 값에서 비밀번호와 쿼리 문자열(토큰이 들어 있는 경우가 많음)을 가립니다.
+// URL이 아닌 값은 그대로 반환합니다.
+func redactURL(value string) string {
+	if !strings.Contains(value, "://") {
+		return value
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return redactedValue
+	}
+	// URL 안에서는 대괄호가 인코딩되므로 "REDACTED"를 씁니다
+	if u.User != nil {
+		u.User = url.User("REDACTED")
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "REDACTED"
+	}
+	u.Fragment = ""
+	return u.String()
+}
+
+// snakeCase는 Go 필드 이름을 snake_case로 바꿉니다 (예: RateLimitRPS → rate_limit_rps, S3AccessKeyID → s3_access_key_id)
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// 중복으로 볼 문단의 최소 글자 수. "네", "---"처럼 짧은 문단은 일부러 반복했을 수 있으므로 지우지 않습니다
+const dedupeMinParagraphRunes = 20
+
+// dedupeParagraphs는 바로 앞 문단과 텍스트가 같은 <p> 문단을 지우고, 지운 문단 수를 반환합니다.
+// 공백 차이만 있는 문단은 같은 것으로 보며, 같은 부모 안에서 공백 텍스트만 사이에 둔 채 연달아 나온 문단만
+// 비교합니다 (다른 요소가 사이에 있거나 목록 항목, 헤딩, 코드 블록이면 그대로 둠).
+func dedupeParagraphs(content string) (string, int, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", 0, err
+	}
+	removed := 0
+	// 최상위 노드들도 같은 부모의 형제처럼 비교합니다
+	nodes = dedupeSiblingParagraphs(nodes, &removed)
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			if c.Type != html.ElementNode || c.FirstChild == nil {
+				return true
+			}
+			var children []*html.Node
+			for child := c.FirstChild; child != nil; child = child.NextSibling {
+				children = append(children, child)
+			}
+			if kept := dedupeSiblingParagraphs(children, &removed); len(kept) != len(children) {
+				for _, child := range children {
+					c.RemoveChild(child)
+				}
+				for _, child := range kept {
+					c.AppendChild(child)
+				}
+			}
+			return true
+		})
+	}
+	if removed == 0 {
+		return content, 0, nil
+	}
+	out, err := renderHTMLFragment(nodes)
+	if err != nil {
+		return "", 0, err
+	}
+	return out, removed, nil
+}
+
+// dedupeSiblingParagraphs는 형제 노드 목록에서 바로 앞 문단과 같은 문단을 뺀 목록을 반환하고 removed를 늘립니다
+func dedupeSiblingParagraphs(siblings []*html.Node, removed *int) []*html.Node {
+	kept := make([]*html.Node, 0, len(siblings))
+	previous := ""
+	for _, n := range siblings {
+		if n.Type == html.TextNode && strings.TrimSpace(n.Data) == "" {
+			kept = append(kept, n)
+			continue
+		}
+		if n.Type != html.ElementNode || n.DataAtom != atom.P {
+			previous = ""
+			kept = append(kept, n)
+			continue
+		}
+		text := collapseSpaces(textContent(n))
+		if text == previous && utf8.RuneCountInString(text) >= dedupeMinParagraphRunes {
+			// 지운 문단 앞의 공백 텍스트가 겹쳐 빈 줄이 늘지 않도록 함께 뺍니다
+			if last := len(kept) - 1; last >= 0 && kept[last].Type == html.TextNode {
+				kept = kept[:last]
+			}
+			*removed++
+			continue
+		}
+		previous = text
+		kept = append(kept, n)
+	}
+	return kept
+}
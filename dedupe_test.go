@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDedupeParagraphs(t *testing.T) {
+	long := "이 문단은 스무 글자를 넘는 충분히 긴 문단입니다."
+	other := "이 문단은 앞 문단과 내용이 다른 긴 문단입니다."
+	p := func(text string) string { return "<p>" + text + "</p>" }
+	tests := []struct {
+		name        string
+		content     string
+		want        string
+		wantRemoved int
+	}{
+		{"no duplicates", p(long) + p(other), p(long) + p(other), 0},
+		{"adjacent duplicate", p(long) + p(long), p(long), 1},
+		{"three in a row", p(long) + "\n" + p(long) + "\n" + p(long), p(long), 2},
+		{"whitespace differences", p(long) + "<p>  " + strings.ReplaceAll(long, " ", "\n ") + " </p>", p(long), 1},
+		{"short paragraphs kept", p("네") + p("네"), p("네") + p("네"), 0},
+		{"separated by other paragraph", p(long) + p(other) + p(long), p(long) + p(other) + p(long), 0},
+		{"separated by heading", p(long) + "<h2>제목</h2>" + p(long), p(long) + "<h2>제목</h2>" + p(long), 0},
+		{"list items kept", "<ul><li>" + long + "</li><li>" + long + "</li></ul>", "<ul><li>" + long + "</li><li>" + long + "</li></ul>", 0},
+		{"nested in div", "<div>" + p(long) + p(long) + "</div>", "<div>" + p(long) + "</div>", 1},
+		{"same text in different parents", "<div>" + p(long) + "</div><div>" + p(long) + "</div>", "<div>" + p(long) + "</div><div>" + p(long) + "</div>", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, removed, err := dedupeParagraphs(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if removed != tt.wantRemoved {
+				t.Errorf("removed = %d, want %d", removed, tt.wantRemoved)
+			}
+			if got != tt.want {
+				t.Errorf("dedupeParagraphs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseDedupeParagraphs(t *testing.T) {
+	paragraph := "<p>이 문단은 스무 글자를 넘는 충분히 긴 문단입니다.</p>"
+	post := newTestPost("제목", paragraph+paragraph)
+	tests := []struct {
+		name        string
+		opts        ContentOptions
+		wantCount   int
+		wantWarning bool
+	}{
+		{"disabled", ContentOptions{Format: "text"}, 2, false},
+		{"enabled", ContentOptions{Format: "text", DedupeParagraphs: true}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := renderTestPost(t, tt.opts, post)
+			if got := strings.Count(response.Content, "충분히 긴 문단"); got != tt.wantCount {
+				t.Errorf("paragraph appears %d times, want %d:\n%s", got, tt.wantCount, response.Content)
+			}
+			if got := hasWarning(response.Warnings, WarningDuplicateParagraphs); got != tt.wantWarning {
+				t.Errorf("duplicate_paragraphs_removed warning = %v, want %v", got, tt.wantWarning)
+			}
+		})
+	}
+
+	opts := ContentOptions{Format: "html", DedupeParagraphs: true}
+	if err := opts.normalize(); err == nil {
+		t.Error("normalize() should reject dedupe_paragraphs without format text")
+	}
+}
@@ -15,13 +15,284 @@ var doc = `{
     "schemes": ["https"],
     "paths": {
         "/content": {
+            "get": {
+                "description": "Same as POST /content, with options passed as query parameters",
+                "produces": ["application/json", "application/ld+json"],
+                "tags": ["content"],
+                "summary": "Get content from BetterMode API (query parameters)",
+                "parameters": [
+                    {
+                        "name": "X-BetterMode-Token",
+                        "in": "header",
+                        "type": "string",
+                        "description": "Use this BetterMode access token instead of the server's guest token. Responses are not cached, and a rejected token returns 401 without a refresh"
+                    },
+                    {
+                        "name": "post_id",
+                        "in": "query",
+                        "type": "string",
+                        "required": true,
+                        "description": "The BetterMode post ID to retrieve"
+                    },
+                    {
+                        "name": "format",
+                        "in": "query",
+                        "type": "string",
+                        "enum": ["html", "text", "xhtml", "jsonld"],
+                        "default": "html",
+                        "description": "Format of the returned content"
+                    },
+                    {
+                        "name": "fields",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Comma-separated mapping field keys to return"
+                    },
+                    {
+                        "name": "field_types",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Comma-separated mapping field types to return"
+                    },
+                    {
+                        "name": "code_line_numbers",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Prefix lines inside code blocks with line numbers (text format only)"
+                    },
+                    {
+                        "name": "include_attachments",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Include the post attachments in the response"
+                    },
+                    {
+                        "name": "nocache",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Bypass the server cache and fetch fresh content (response gets Cache-Control: no-store)"
+                    },
+                    {
+                        "name": "encoding",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Set to \"base64\" to return content base64-encoded (char_count is still computed on the decoded content)"
+                    },
+                    {
+                        "name": "tts",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "With format=text, replace URLs with \"link\", strip markdown artifacts and normalize whitespace for text-to-speech"
+                    },
+                    {
+                        "name": "dedupe_paragraphs",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "With format=text, remove paragraphs that repeat the paragraph right before them (whitespace-insensitive, at least 20 characters); the number removed is reported in a duplicate_paragraphs_removed warning"
+                    },
+                    {
+                        "name": "include_engagement",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Include reaction and reply counts"
+                    },
+                    {
+                        "name": "translate_to",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Language code (e.g. \"en\"); returns the content text translated into it as translated_text"
+                    },
+                    {
+                        "name": "include_embeds",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Include embedded videos and oembed blocks found in the content"
+                    },
+                    {
+                        "name": "include_toc",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Add stable id attributes to headings and return a table of contents"
+                    },
+                    {
+                        "name": "revision_id",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Historical revision to fetch. Not supported by the BetterMode API; requests with revision_id return 501"
+                    },
+                    {
+                        "name": "min_chars",
+                        "in": "query",
+                        "type": "integer",
+                        "description": "Warn with content_too_short when the content has fewer visible characters (tags excluded)"
+                    },
+                    {
+                        "name": "strict",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "With min_chars, return 422 instead of a warning when the content is too short"
+                    },
+                    {
+                        "name": "metadata_only",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Return only title, space, updated_at (and requested attachments/engagement) without content; skips fetching mapping fields from BetterMode"
+                    },
+                    {
+                        "name": "extract_footnotes",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Return footnotes found in the content as footnotes"
+                    },
+                    {
+                        "name": "remove_footnotes",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "With extract_footnotes, remove footnote definitions and reference markers from content"
+                    },
+                    {
+                        "name": "auto_preview",
+                        "in": "query",
+                        "type": "integer",
+                        "description": "If the content has more visible characters than this, return only a preview of this many characters and set has_more"
+                    },
+                    {
+                        "name": "include_summary",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Include the post's summary/excerpt field as plain text in summary, or the first paragraph of the content if there is none (summary_derived is then true)"
+                    },
+                    {
+                        "name": "proxy_images",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Rewrite <img src> URLs on IMAGE_PROXY_ALLOWED_HOSTS to /api/v1/image-proxy so original image URLs are not exposed (html and xhtml only)"
+                    },
+                    {
+                        "name": "extract_tables",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Parse the content's tables into tables (rows of cell text) and table_headers (the header rows of each table). Cells spanning rows or columns are repeated in every slot they cover"
+                    },
+                    {
+                        "name": "title_key",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Use the value of this mapping field (tags stripped) as the title, falling back to the post's title when the field is missing or empty"
+                    },
+                    {
+                        "name": "hash_algorithm",
+                        "in": "query",
+                        "type": "string",
+                        "enum": ["sha256", "sha1", "md5", "xxhash"],
+                        "description": "Algorithm for content_hash (default: CONTENT_HASH_ALGORITHM, sha256)"
+                    },
+                    {
+                        "name": "include_links",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Return every <a href> in the content as links (url, text, internal), in document order with duplicate URLs removed. internal is true for relative URLs and links to TOKEN_NETWORK_DOMAINS"
+                    },
+                    {
+                        "name": "thumbnail",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Return the URL of the content's first image in thumbnail (src, or the first srcset candidate). Images whose width or height attribute is under 64px are skipped as icons; images of unknown size are used"
+                    },
+                    {
+                        "name": "absolute_links",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Rewrite relative href, src, poster and srcset URLs to absolute URLs on the community domain (first TOKEN_NETWORK_DOMAINS entry). Protocol-relative URLs get https; absolute URLs, other schemes and #anchors are left alone"
+                    },
+                    {
+                        "name": "strip_styles",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Remove inline style attributes from every element, keeping tags and text (html/xhtml)"
+                    },
+                    {
+                        "name": "strip_classes",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Remove class attributes from every element, keeping tags and text (html/xhtml)"
+                    },
+                    {
+                        "name": "cache_ttl_seconds",
+                        "in": "query",
+                        "type": "integer",
+                        "description": "Cache lifetime in seconds for the entries stored by this request, capped at CACHE_MAX_TTL; 0 disables caching like nocache"
+                    },
+                    {
+                        "name": "timeout_ms",
+                        "in": "query",
+                        "type": "integer",
+                        "description": "Time limit in milliseconds for fetching and processing this post, used instead of FETCH_TIMEOUT and capped at FETCH_MAX_TIMEOUT; exceeding it returns 504"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {"$ref": "#/definitions/ContentResponse"}
+                    },
+                    "304": {
+                        "description": "Not Modified (If-Modified-Since is not older than the post's updatedAt)"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {"type": "string"}
+                    },
+                    "401": {
+                        "description": "BetterMode rejected the token supplied in X-BetterMode-Token (it is not refreshed or retried)",
+                        "schema": {"type": "string"}
+                    },
+                    "404": {
+                        "description": "Post not found (deleted or not visible)",
+                        "schema": {"type": "string"}
+                    },
+                    "422": {
+                        "description": "strict was set and the content is shorter than min_chars",
+                        "schema": {"type": "string"}
+                    },
+                    "429": {
+                        "description": "Too many requests (RATE_LIMIT_RPS or PER_IP_MAX_CONCURRENT exceeded); see Retry-After",
+                        "schema": {"type": "string"}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {"type": "string"}
+                    },
+                    "501": {
+                        "description": "revision_id was requested; the BetterMode API does not expose post revision history",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode returned a non-JSON (e.g. maintenance page) response",
+                        "schema": {"type": "string"}
+                    },
+                    "503": {
+                        "description": "BetterMode API temporarily unavailable (circuit breaker open); see Retry-After",
+                        "schema": {"type": "string"}
+                    },
+                    "504": {
+                        "description": "Timed out fetching content from BetterMode (FETCH_TIMEOUT)",
+                        "schema": {"type": "string"}
+                    }
+                }
+            },
             "post": {
                 "description": "Retrieves content value from mappingFields where key is \"content\"",
                 "consumes": ["application/json"],
-                "produces": ["application/json"],
+                "produces": ["application/json", "application/ld+json"],
                 "tags": ["content"],
                 "summary": "Get content from BetterMode API",
                 "parameters": [
+                    {
+                        "name": "X-BetterMode-Token",
+                        "in": "header",
+                        "type": "string",
+                        "description": "Use this BetterMode access token instead of the server's guest token. Responses are not cached, and a rejected token returns 401 without a refresh"
+                    },
                     {
                         "description": "Post ID and optional format",
                         "name": "request",
@@ -37,41 +308,146 @@ var doc = `{
                                 "format": {
                                     "type": "string",
                                     "description": "Format of the returned content",
-                                    "enum": ["html", "text"],
+                                    "enum": ["html", "text", "xhtml", "jsonld"],
                                     "default": "html"
-                                }
-                            },
-                            "required": ["post_id"]
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "object",
-                            "properties": {
-                                "content": {
+                                },
+                                "fields": {
+                                    "type": "array",
+                                    "items": {"type": "string"},
+                                    "description": "Mapping field keys to return in the fields array"
+                                },
+                                "field_types": {
+                                    "type": "array",
+                                    "items": {"type": "string"},
+                                    "description": "Only return mapping fields of these types (e.g. html, text)"
+                                },
+                                "code_line_numbers": {
+                                    "type": "boolean",
+                                    "description": "Prefix lines inside code blocks with line numbers (text format only)"
+                                },
+                                "include_attachments": {
+                                    "type": "boolean",
+                                    "description": "Include the post attachments in the response"
+                                },
+                                "nocache": {
+                                    "type": "boolean",
+                                    "description": "Bypass the server cache and fetch fresh content (response gets Cache-Control: no-store)"
+                                },
+                                "encoding": {
                                     "type": "string",
-                                    "description": "The content of the post"
+                                    "description": "Set to \"base64\" to return content base64-encoded (char_count is still computed on the decoded content)"
                                 },
-                                "format": {
+                                "tts": {
+                                    "type": "boolean",
+                                    "description": "With format=text, replace URLs with \"link\", strip markdown artifacts and normalize whitespace for text-to-speech"
+                                },
+                                "dedupe_paragraphs": {
+                                    "type": "boolean",
+                                    "description": "With format=text, remove paragraphs that repeat the paragraph right before them (whitespace-insensitive, at least 20 characters); the number removed is reported in a duplicate_paragraphs_removed warning"
+                                },
+                                "include_engagement": {
+                                    "type": "boolean",
+                                    "description": "Include reaction and reply counts"
+                                },
+                                "translate_to": {
                                     "type": "string",
-                                    "description": "The format of the content (html or text)"
+                                    "description": "Language code (e.g. \"en\"); returns the content text translated into it as translated_text"
                                 },
-                                "post_id": {
+                                "include_embeds": {
+                                    "type": "boolean",
+                                    "description": "Include embedded videos and oembed blocks found in the content"
+                                },
+                                "include_toc": {
+                                    "type": "boolean",
+                                    "description": "Add stable id attributes to headings and return a table of contents"
+                                },
+                                "revision_id": {
+                                    "type": "string",
+                                    "description": "Historical revision to fetch. Not supported by the BetterMode API; requests with revision_id return 501"
+                                },
+                                "min_chars": {
+                                    "type": "integer",
+                                    "description": "Warn with content_too_short when the content has fewer visible characters (tags excluded)"
+                                },
+                                "strict": {
+                                    "type": "boolean",
+                                    "description": "With min_chars, return 422 instead of a warning when the content is too short"
+                                },
+                                "metadata_only": {
+                                    "type": "boolean",
+                                    "description": "Return only title, space, updated_at (and requested attachments/engagement) without content; skips fetching mapping fields from BetterMode"
+                                },
+                                "extract_footnotes": {
+                                    "type": "boolean",
+                                    "description": "Return footnotes found in the content as footnotes"
+                                },
+                                "remove_footnotes": {
+                                    "type": "boolean",
+                                    "description": "With extract_footnotes, remove footnote definitions and reference markers from content"
+                                },
+                                "auto_preview": {
+                                    "type": "integer",
+                                    "description": "If the content has more visible characters than this, return only a preview of this many characters and set has_more"
+                                },
+                                "include_summary": {
+                                    "type": "boolean",
+                                    "description": "Include the post's summary/excerpt field as plain text in summary, or the first paragraph of the content if there is none (summary_derived is then true)"
+                                },
+                                "proxy_images": {
+                                    "type": "boolean",
+                                    "description": "Rewrite <img src> URLs on IMAGE_PROXY_ALLOWED_HOSTS to /api/v1/image-proxy so original image URLs are not exposed (html and xhtml only)"
+                                },
+                                "extract_tables": {
+                                    "type": "boolean",
+                                    "description": "Parse the content's tables into tables (rows of cell text) and table_headers (the header rows of each table). Cells spanning rows or columns are repeated in every slot they cover"
+                                },
+                                "title_key": {
                                     "type": "string",
-                                    "description": "The ID of the post"
+                                    "description": "Use the value of this mapping field (tags stripped) as the title, falling back to the post's title when the field is missing or empty"
                                 },
-                                "title": {
+                                "hash_algorithm": {
                                     "type": "string",
-                                    "description": "The title of the post"
+                                    "enum": ["sha256", "sha1", "md5", "xxhash"],
+                                    "description": "Algorithm for content_hash (default: CONTENT_HASH_ALGORITHM, sha256)"
+                                },
+                                "include_links": {
+                                    "type": "boolean",
+                                    "description": "Return every <a href> in the content as links (url, text, internal), in document order with duplicate URLs removed. internal is true for relative URLs and links to TOKEN_NETWORK_DOMAINS"
+                                },
+                                "thumbnail": {
+                                    "type": "boolean",
+                                    "description": "Return the URL of the content's first image in thumbnail (src, or the first srcset candidate). Images whose width or height attribute is under 64px are skipped as icons; images of unknown size are used"
+                                },
+                                "absolute_links": {
+                                    "type": "boolean",
+                                    "description": "Rewrite relative href, src, poster and srcset URLs to absolute URLs on the community domain (first TOKEN_NETWORK_DOMAINS entry). Protocol-relative URLs get https; absolute URLs, other schemes and #anchors are left alone"
                                 },
-                                "char_count": {
+                                "strip_styles": {
+                                    "type": "boolean",
+                                    "description": "Remove inline style attributes from every element, keeping tags and text (html/xhtml)"
+                                },
+                                "strip_classes": {
+                                    "type": "boolean",
+                                    "description": "Remove class attributes from every element, keeping tags and text (html/xhtml)"
+                                },
+                                "cache_ttl_seconds": {
+                                    "type": "integer",
+                                    "description": "Cache lifetime in seconds for the entries stored by this request, capped at CACHE_MAX_TTL; 0 disables caching like nocache"
+                                },
+                                "timeout_ms": {
                                     "type": "integer",
-                                    "description": "The character count of the content"
+                                    "description": "Time limit in milliseconds for fetching and processing this post, used instead of FETCH_TIMEOUT and capped at FETCH_MAX_TIMEOUT; exceeding it returns 504"
                                 }
-                            }
+                            },
+                            "required": ["post_id"]
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ContentResponse"
                         }
                     },
                     "400": {
@@ -80,23 +456,326 @@ var doc = `{
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "BetterMode rejected the token supplied in X-BetterMode-Token (it is not refreshed or retried)",
+                        "schema": {"type": "string"}
+                    },
+                    "404": {
+                        "description": "Post not found (deleted or not visible)",
+                        "schema": {"type": "string"}
+                    },
+                    "422": {
+                        "description": "strict was set and the content is shorter than min_chars",
+                        "schema": {"type": "string"}
+                    },
+                    "429": {
+                        "description": "Too many requests (RATE_LIMIT_RPS or PER_IP_MAX_CONCURRENT exceeded); see Retry-After",
+                        "schema": {"type": "string"}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "501": {
+                        "description": "revision_id was requested; the BetterMode API does not expose post revision history",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode returned a non-JSON (e.g. maintenance page) response",
+                        "schema": {"type": "string"}
+                    },
+                    "503": {
+                        "description": "BetterMode API temporarily unavailable (circuit breaker open); see Retry-After",
+                        "schema": {"type": "string"}
+                    },
+                    "504": {
+                        "description": "Timed out fetching content from BetterMode (FETCH_TIMEOUT)",
+                        "schema": {"type": "string"}
                     }
                 }
             }
         },
         "/url": {
+            "get": {
+                "description": "Same as POST /url, with options passed as query parameters",
+                "produces": ["application/json", "application/ld+json"],
+                "tags": ["content"],
+                "summary": "Get content from BetterMode URL (query parameters)",
+                "parameters": [
+                    {
+                        "name": "X-BetterMode-Token",
+                        "in": "header",
+                        "type": "string",
+                        "description": "Use this BetterMode access token instead of the server's guest token. Responses are not cached, and a rejected token returns 401 without a refresh"
+                    },
+                    {
+                        "name": "url",
+                        "in": "query",
+                        "type": "string",
+                        "required": true,
+                        "description": "The BetterMode post URL to retrieve"
+                    },
+                    {
+                        "name": "format",
+                        "in": "query",
+                        "type": "string",
+                        "enum": ["html", "text", "xhtml", "jsonld"],
+                        "default": "html",
+                        "description": "Format of the returned content"
+                    },
+                    {
+                        "name": "fields",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Comma-separated mapping field keys to return"
+                    },
+                    {
+                        "name": "field_types",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Comma-separated mapping field types to return"
+                    },
+                    {
+                        "name": "code_line_numbers",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Prefix lines inside code blocks with line numbers (text format only)"
+                    },
+                    {
+                        "name": "include_attachments",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Include the post attachments in the response"
+                    },
+                    {
+                        "name": "nocache",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Bypass the server cache and fetch fresh content (response gets Cache-Control: no-store)"
+                    },
+                    {
+                        "name": "encoding",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Set to \"base64\" to return content base64-encoded (char_count is still computed on the decoded content)"
+                    },
+                    {
+                        "name": "tts",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "With format=text, replace URLs with \"link\", strip markdown artifacts and normalize whitespace for text-to-speech"
+                    },
+                    {
+                        "name": "dedupe_paragraphs",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "With format=text, remove paragraphs that repeat the paragraph right before them (whitespace-insensitive, at least 20 characters); the number removed is reported in a duplicate_paragraphs_removed warning"
+                    },
+                    {
+                        "name": "include_engagement",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Include reaction and reply counts"
+                    },
+                    {
+                        "name": "translate_to",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Language code (e.g. \"en\"); returns the content text translated into it as translated_text"
+                    },
+                    {
+                        "name": "include_embeds",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Include embedded videos and oembed blocks found in the content"
+                    },
+                    {
+                        "name": "include_toc",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Add stable id attributes to headings and return a table of contents"
+                    },
+                    {
+                        "name": "revision_id",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Historical revision to fetch. Not supported by the BetterMode API; requests with revision_id return 501"
+                    },
+                    {
+                        "name": "min_chars",
+                        "in": "query",
+                        "type": "integer",
+                        "description": "Warn with content_too_short when the content has fewer visible characters (tags excluded)"
+                    },
+                    {
+                        "name": "strict",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "With min_chars, return 422 instead of a warning when the content is too short"
+                    },
+                    {
+                        "name": "metadata_only",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Return only title, space, updated_at (and requested attachments/engagement) without content; skips fetching mapping fields from BetterMode"
+                    },
+                    {
+                        "name": "extract_footnotes",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Return footnotes found in the content as footnotes"
+                    },
+                    {
+                        "name": "remove_footnotes",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "With extract_footnotes, remove footnote definitions and reference markers from content"
+                    },
+                    {
+                        "name": "auto_preview",
+                        "in": "query",
+                        "type": "integer",
+                        "description": "If the content has more visible characters than this, return only a preview of this many characters and set has_more"
+                    },
+                    {
+                        "name": "include_summary",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Include the post's summary/excerpt field as plain text in summary, or the first paragraph of the content if there is none (summary_derived is then true)"
+                    },
+                    {
+                        "name": "proxy_images",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Rewrite <img src> URLs on IMAGE_PROXY_ALLOWED_HOSTS to /api/v1/image-proxy so original image URLs are not exposed (html and xhtml only)"
+                    },
+                    {
+                        "name": "extract_tables",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Parse the content's tables into tables (rows of cell text) and table_headers (the header rows of each table). Cells spanning rows or columns are repeated in every slot they cover"
+                    },
+                    {
+                        "name": "title_key",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Use the value of this mapping field (tags stripped) as the title, falling back to the post's title when the field is missing or empty"
+                    },
+                    {
+                        "name": "hash_algorithm",
+                        "in": "query",
+                        "type": "string",
+                        "enum": ["sha256", "sha1", "md5", "xxhash"],
+                        "description": "Algorithm for content_hash (default: CONTENT_HASH_ALGORITHM, sha256)"
+                    },
+                    {
+                        "name": "include_links",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Return every <a href> in the content as links (url, text, internal), in document order with duplicate URLs removed. internal is true for relative URLs and links to TOKEN_NETWORK_DOMAINS"
+                    },
+                    {
+                        "name": "thumbnail",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Return the URL of the content's first image in thumbnail (src, or the first srcset candidate). Images whose width or height attribute is under 64px are skipped as icons; images of unknown size are used"
+                    },
+                    {
+                        "name": "absolute_links",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Rewrite relative href, src, poster and srcset URLs to absolute URLs on the community domain (first TOKEN_NETWORK_DOMAINS entry). Protocol-relative URLs get https; absolute URLs, other schemes and #anchors are left alone"
+                    },
+                    {
+                        "name": "strip_styles",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Remove inline style attributes from every element, keeping tags and text (html/xhtml)"
+                    },
+                    {
+                        "name": "strip_classes",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Remove class attributes from every element, keeping tags and text (html/xhtml)"
+                    },
+                    {
+                        "name": "cache_ttl_seconds",
+                        "in": "query",
+                        "type": "integer",
+                        "description": "Cache lifetime in seconds for the entries stored by this request, capped at CACHE_MAX_TTL; 0 disables caching like nocache"
+                    },
+                    {
+                        "name": "timeout_ms",
+                        "in": "query",
+                        "type": "integer",
+                        "description": "Time limit in milliseconds for fetching and processing this post, used instead of FETCH_TIMEOUT and capped at FETCH_MAX_TIMEOUT; exceeding it returns 504"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {"$ref": "#/definitions/ContentResponse"}
+                    },
+                    "304": {
+                        "description": "Not Modified (If-Modified-Since is not older than the post's updatedAt)"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {"type": "string"}
+                    },
+                    "401": {
+                        "description": "BetterMode rejected the token supplied in X-BetterMode-Token (it is not refreshed or retried)",
+                        "schema": {"type": "string"}
+                    },
+                    "404": {
+                        "description": "Post not found (deleted or not visible)",
+                        "schema": {"type": "string"}
+                    },
+                    "422": {
+                        "description": "strict was set and the content is shorter than min_chars",
+                        "schema": {"type": "string"}
+                    },
+                    "429": {
+                        "description": "Too many requests (RATE_LIMIT_RPS or PER_IP_MAX_CONCURRENT exceeded); see Retry-After",
+                        "schema": {"type": "string"}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {"type": "string"}
+                    },
+                    "501": {
+                        "description": "revision_id was requested; the BetterMode API does not expose post revision history",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode returned a non-JSON (e.g. maintenance page) response",
+                        "schema": {"type": "string"}
+                    },
+                    "503": {
+                        "description": "BetterMode API temporarily unavailable (circuit breaker open); see Retry-After",
+                        "schema": {"type": "string"}
+                    },
+                    "504": {
+                        "description": "Timed out fetching content from BetterMode (FETCH_TIMEOUT)",
+                        "schema": {"type": "string"}
+                    }
+                }
+            },
             "post": {
                 "description": "Extracts post ID from URL and retrieves content",
                 "consumes": ["application/json"],
-                "produces": ["application/json"],
+                "produces": ["application/json", "application/ld+json"],
                 "tags": ["content"],
                 "summary": "Get content from BetterMode URL",
                 "parameters": [
+                    {
+                        "name": "X-BetterMode-Token",
+                        "in": "header",
+                        "type": "string",
+                        "description": "Use this BetterMode access token instead of the server's guest token. Responses are not cached, and a rejected token returns 401 without a refresh"
+                    },
                     {
                         "description": "BetterMode URL and optional format",
                         "name": "request",
@@ -112,55 +791,1375 @@ var doc = `{
                                 "format": {
                                     "type": "string",
                                     "description": "Format of the returned content",
-                                    "enum": ["html", "text"],
+                                    "enum": ["html", "text", "xhtml", "jsonld"],
                                     "default": "html"
-                                }
-                            },
-                            "required": ["url"]
+                                },
+                                "fields": {
+                                    "type": "array",
+                                    "items": {"type": "string"},
+                                    "description": "Mapping field keys to return in the fields array"
+                                },
+                                "field_types": {
+                                    "type": "array",
+                                    "items": {"type": "string"},
+                                    "description": "Only return mapping fields of these types (e.g. html, text)"
+                                },
+                                "code_line_numbers": {
+                                    "type": "boolean",
+                                    "description": "Prefix lines inside code blocks with line numbers (text format only)"
+                                },
+                                "include_attachments": {
+                                    "type": "boolean",
+                                    "description": "Include the post attachments in the response"
+                                },
+                                "nocache": {
+                                    "type": "boolean",
+                                    "description": "Bypass the server cache and fetch fresh content (response gets Cache-Control: no-store)"
+                                },
+                                "encoding": {
+                                    "type": "string",
+                                    "description": "Set to \"base64\" to return content base64-encoded (char_count is still computed on the decoded content)"
+                                },
+                                "tts": {
+                                    "type": "boolean",
+                                    "description": "With format=text, replace URLs with \"link\", strip markdown artifacts and normalize whitespace for text-to-speech"
+                                },
+                                "dedupe_paragraphs": {
+                                    "type": "boolean",
+                                    "description": "With format=text, remove paragraphs that repeat the paragraph right before them (whitespace-insensitive, at least 20 characters); the number removed is reported in a duplicate_paragraphs_removed warning"
+                                },
+                                "include_engagement": {
+                                    "type": "boolean",
+                                    "description": "Include reaction and reply counts"
+                                },
+                                "translate_to": {
+                                    "type": "string",
+                                    "description": "Language code (e.g. \"en\"); returns the content text translated into it as translated_text"
+                                },
+                                "include_embeds": {
+                                    "type": "boolean",
+                                    "description": "Include embedded videos and oembed blocks found in the content"
+                                },
+                                "include_toc": {
+                                    "type": "boolean",
+                                    "description": "Add stable id attributes to headings and return a table of contents"
+                                },
+                                "revision_id": {
+                                    "type": "string",
+                                    "description": "Historical revision to fetch. Not supported by the BetterMode API; requests with revision_id return 501"
+                                },
+                                "min_chars": {
+                                    "type": "integer",
+                                    "description": "Warn with content_too_short when the content has fewer visible characters (tags excluded)"
+                                },
+                                "strict": {
+                                    "type": "boolean",
+                                    "description": "With min_chars, return 422 instead of a warning when the content is too short"
+                                },
+                                "metadata_only": {
+                                    "type": "boolean",
+                                    "description": "Return only title, space, updated_at (and requested attachments/engagement) without content; skips fetching mapping fields from BetterMode"
+                                },
+                                "extract_footnotes": {
+                                    "type": "boolean",
+                                    "description": "Return footnotes found in the content as footnotes"
+                                },
+                                "remove_footnotes": {
+                                    "type": "boolean",
+                                    "description": "With extract_footnotes, remove footnote definitions and reference markers from content"
+                                },
+                                "auto_preview": {
+                                    "type": "integer",
+                                    "description": "If the content has more visible characters than this, return only a preview of this many characters and set has_more"
+                                },
+                                "include_summary": {
+                                    "type": "boolean",
+                                    "description": "Include the post's summary/excerpt field as plain text in summary, or the first paragraph of the content if there is none (summary_derived is then true)"
+                                },
+                                "proxy_images": {
+                                    "type": "boolean",
+                                    "description": "Rewrite <img src> URLs on IMAGE_PROXY_ALLOWED_HOSTS to /api/v1/image-proxy so original image URLs are not exposed (html and xhtml only)"
+                                },
+                                "extract_tables": {
+                                    "type": "boolean",
+                                    "description": "Parse the content's tables into tables (rows of cell text) and table_headers (the header rows of each table). Cells spanning rows or columns are repeated in every slot they cover"
+                                },
+                                "title_key": {
+                                    "type": "string",
+                                    "description": "Use the value of this mapping field (tags stripped) as the title, falling back to the post's title when the field is missing or empty"
+                                },
+                                "hash_algorithm": {
+                                    "type": "string",
+                                    "enum": ["sha256", "sha1", "md5", "xxhash"],
+                                    "description": "Algorithm for content_hash (default: CONTENT_HASH_ALGORITHM, sha256)"
+                                },
+                                "include_links": {
+                                    "type": "boolean",
+                                    "description": "Return every <a href> in the content as links (url, text, internal), in document order with duplicate URLs removed. internal is true for relative URLs and links to TOKEN_NETWORK_DOMAINS"
+                                },
+                                "thumbnail": {
+                                    "type": "boolean",
+                                    "description": "Return the URL of the content's first image in thumbnail (src, or the first srcset candidate). Images whose width or height attribute is under 64px are skipped as icons; images of unknown size are used"
+                                },
+                                "absolute_links": {
+                                    "type": "boolean",
+                                    "description": "Rewrite relative href, src, poster and srcset URLs to absolute URLs on the community domain (first TOKEN_NETWORK_DOMAINS entry). Protocol-relative URLs get https; absolute URLs, other schemes and #anchors are left alone"
+                                },
+                                "strip_styles": {
+                                    "type": "boolean",
+                                    "description": "Remove inline style attributes from every element, keeping tags and text (html/xhtml)"
+                                },
+                                "strip_classes": {
+                                    "type": "boolean",
+                                    "description": "Remove class attributes from every element, keeping tags and text (html/xhtml)"
+                                },
+                                "cache_ttl_seconds": {
+                                    "type": "integer",
+                                    "description": "Cache lifetime in seconds for the entries stored by this request, capped at CACHE_MAX_TTL; 0 disables caching like nocache"
+                                },
+                                "timeout_ms": {
+                                    "type": "integer",
+                                    "description": "Time limit in milliseconds for fetching and processing this post, used instead of FETCH_TIMEOUT and capped at FETCH_MAX_TIMEOUT; exceeding it returns 504"
+                                }
+                            },
+                            "required": ["url"]
                         }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ContentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "BetterMode rejected the token supplied in X-BetterMode-Token (it is not refreshed or retried)",
+                        "schema": {"type": "string"}
+                    },
+                    "404": {
+                        "description": "Post not found (deleted or not visible)",
+                        "schema": {"type": "string"}
+                    },
+                    "422": {
+                        "description": "strict was set and the content is shorter than min_chars",
+                        "schema": {"type": "string"}
+                    },
+                    "429": {
+                        "description": "Too many requests (RATE_LIMIT_RPS or PER_IP_MAX_CONCURRENT exceeded); see Retry-After",
+                        "schema": {"type": "string"}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "501": {
+                        "description": "revision_id was requested; the BetterMode API does not expose post revision history",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode returned a non-JSON (e.g. maintenance page) response",
+                        "schema": {"type": "string"}
+                    },
+                    "503": {
+                        "description": "BetterMode API temporarily unavailable (circuit breaker open); see Retry-After",
+                        "schema": {"type": "string"}
+                    },
+                    "504": {
+                        "description": "Timed out fetching content from BetterMode (FETCH_TIMEOUT)",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/batch": {
+            "post": {
+                "description": "Fetches several posts with bounded concurrency. Stops fetching remaining posts when the client disconnects. With stream=true or Accept: application/x-ndjson, each BatchItemResult is written as one NDJSON line as soon as it completes (in completion order, not input order) instead of one BatchResponse at the end.",
+                "consumes": ["application/json"],
+                "produces": ["application/json", "application/x-ndjson"],
+                "tags": ["content"],
+                "summary": "Get content for multiple posts",
+                "parameters": [
+                    {
+                        "description": "Post IDs and shared options (same options as /content)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
                             "type": "object",
                             "properties": {
-                                "content": {
-                                    "type": "string",
-                                    "description": "The content of the post"
+                                "post_ids": {
+                                    "type": "array",
+                                    "items": {"type": "string"},
+                                    "description": "The BetterMode post IDs to retrieve"
                                 },
                                 "format": {
                                     "type": "string",
-                                    "description": "The format of the content (html or text)"
+                                    "enum": ["html", "text", "xhtml", "jsonld"],
+                                    "default": "html",
+                                    "description": "Format of the returned content"
                                 },
-                                "post_id": {
+                                "formats": {
+                                    "type": "array",
+                                    "items": {"type": "string", "enum": ["html", "text", "xhtml", "jsonld"]},
+                                    "description": "Return each post in all of these formats (contents map) from a single upstream fetch; cannot be combined with format"
+                                },
+                                "stream": {
+                                    "type": "boolean",
+                                    "description": "Stream results as NDJSON (one BatchItemResult with its post_id per line, in completion order) instead of a single BatchResponse. Same as Accept: application/x-ndjson"
+                                }
+                            },
+                            "required": ["post_ids"]
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {"$ref": "#/definitions/BatchResponse"}
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/collections/{collectionID}/posts": {
+            "get": {
+                "description": "Fetches every post in a BetterMode collection (series) in order: spaces in collection order, posts oldest first. At most BATCH_MAX_ITEMS posts are fetched.",
+                "produces": ["application/json"],
+                "tags": ["content"],
+                "summary": "Get content for all posts in a collection",
+                "parameters": [
+                    {
+                        "name": "collectionID",
+                        "in": "path",
+                        "type": "string",
+                        "required": true,
+                        "description": "The BetterMode collection ID"
+                    },
+                    {
+                        "name": "format",
+                        "in": "query",
+                        "type": "string",
+                        "enum": ["html", "text", "xhtml", "jsonld"],
+                        "default": "html",
+                        "description": "Format of the returned content"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {"$ref": "#/definitions/CollectionResponse"}
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode API error",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/compile": {
+            "post": {
+                "description": "Fetches the posts in the given order and concatenates them with a separator, optionally adding a title heading before each post. Fails if any post cannot be fetched.",
+                "consumes": ["application/json"],
+                "produces": ["application/json"],
+                "tags": ["content"],
+                "summary": "Compile several posts into one document",
+                "parameters": [
+                    {
+                        "description": "Ordered post IDs, separator and shared options (same options as /content)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "post_ids": {
+                                    "type": "array",
+                                    "items": {"type": "string"},
+                                    "description": "The BetterMode post IDs, in document order"
+                                },
+                                "format": {
                                     "type": "string",
-                                    "description": "The ID of the post"
+                                    "enum": ["html", "text", "xhtml"],
+                                    "default": "html",
+                                    "description": "Format of the returned content"
                                 },
-                                "title": {
+                                "separator": {
                                     "type": "string",
-                                    "description": "The title of the post"
+                                    "description": "Inserted between posts (default: <hr> for html/xhtml, a blank line for text)"
                                 },
-                                "char_count": {
-                                    "type": "integer",
-                                    "description": "The character count of the content"
+                                "include_titles": {
+                                    "type": "boolean",
+                                    "description": "Add each post's title as a heading before its content"
                                 }
-                            }
+                            },
+                            "required": ["post_ids"]
                         }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {"$ref": "#/definitions/CompileResponse"}
                     },
                     "400": {
                         "description": "Bad Request",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "One or more posts could not be fetched",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/exports": {
+            "post": {
+                "description": "Starts a background job that fetches the posts and writes them as NDJSON (one batch item per line) to a local file under EXPORT_LOCAL_DIR or an S3-compatible bucket. Returns the job ID immediately; poll GET /exports/{jobID} for progress.",
+                "consumes": ["application/json"],
+                "produces": ["application/json"],
+                "tags": ["export"],
+                "summary": "Export posts to storage",
+                "parameters": [
+                    {
+                        "description": "Post IDs, destination and shared options (same options as /content)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "string"
+                            "type": "object",
+                            "properties": {
+                                "post_ids": {
+                                    "type": "array",
+                                    "items": {"type": "string"},
+                                    "description": "The BetterMode post IDs to export"
+                                },
+                                "destination": {
+                                    "type": "string",
+                                    "description": "file:relative/path.ndjson (under EXPORT_LOCAL_DIR) or s3://bucket/key"
+                                },
+                                "format": {
+                                    "type": "string",
+                                    "enum": ["html", "text", "xhtml", "jsonld"],
+                                    "default": "html",
+                                    "description": "Format of the exported content"
+                                }
+                            },
+                            "required": ["post_ids", "destination"]
                         }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {"$ref": "#/definitions/ExportJob"}
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/exports/{jobID}": {
+            "get": {
+                "produces": ["application/json"],
+                "tags": ["export"],
+                "summary": "Get export job status",
+                "parameters": [
+                    {
+                        "name": "jobID",
+                        "in": "path",
+                        "type": "string",
+                        "required": true,
+                        "description": "Job ID returned by POST /exports"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {"$ref": "#/definitions/ExportJob"}
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/webhooks/bettermode": {
+            "post": {
+                "description": "Verifies the webhook signature (X-Bettermode-Signature: hex HMAC-SHA256 of \"timestamp:body\" with BETTERMODE_WEBHOOK_SECRET; X-Bettermode-Request-Timestamp in Unix milliseconds, at most 5 minutes off). post.published and post.updated events queue a background refetch of the post's cache entry; post.deleted purges it. TEST requests are answered with their challenge.",
+                "consumes": ["application/json"],
+                "produces": ["application/json"],
+                "tags": ["webhooks"],
+                "summary": "Receive BetterMode webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK (queued is false if the prefetch queue was full)",
+                        "schema": {"type": "object"}
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {"type": "string"}
+                    },
+                    "401": {
+                        "description": "Missing or invalid signature, or expired timestamp",
+                        "schema": {"type": "string"}
+                    },
+                    "404": {
+                        "description": "Webhook receiver is not configured",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/archive": {
+            "get": {
+                "description": "Same as POST /archive with options as query parameters.",
+                "produces": ["application/gzip"],
+                "tags": ["content"],
+                "summary": "Download a post as a self-contained tar.gz (query parameters)",
+                "parameters": [
+                    {
+                        "name": "post_id",
+                        "in": "query",
+                        "type": "string",
+                        "required": true,
+                        "description": "The BetterMode post ID to archive"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "tar.gz archive (Content-Disposition: attachment)",
+                        "schema": {"type": "file"}
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {"type": "string"}
                     },
                     "500": {
                         "description": "Internal Server Error",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode returned a non-JSON (e.g. maintenance page) response",
+                        "schema": {"type": "string"}
+                    }
+                }
+            },
+            "post": {
+                "description": "Returns a gzip-compressed tar containing index.html, the post's images under assets/ and attachments under attachments/, with links in index.html rewritten to the archived copies, plus manifest.json. Assets are capped by ARCHIVE_MAX_ASSETS and ARCHIVE_MAX_BYTES; assets that do not fit are left as remote links and listed in the manifest.",
+                "consumes": ["application/json"],
+                "produces": ["application/gzip"],
+                "tags": ["content"],
+                "summary": "Download a post as a self-contained tar.gz",
+                "parameters": [
+                    {
+                        "description": "Post ID and options (same options as /content; format is always html)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "string"
+                            "type": "object",
+                            "properties": {
+                                "post_id": {
+                                    "type": "string",
+                                    "description": "The BetterMode post ID to archive"
+                                },
+                                "nocache": {"type": "boolean"}
+                            },
+                            "required": ["post_id"]
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "tar.gz archive (Content-Disposition: attachment)",
+                        "schema": {"type": "file"}
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {"type": "string"}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode returned a non-JSON (e.g. maintenance page) response",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/spaces/{spaceID}/changes": {
+            "get": {
+                "description": "Returns IDs and updatedAt of posts in a space with updatedAt >= since, most recently modified first. Use next_cursor to fetch the next page.",
+                "produces": ["application/json"],
+                "tags": ["content"],
+                "summary": "List posts modified since a timestamp",
+                "parameters": [
+                    {
+                        "name": "spaceID",
+                        "in": "path",
+                        "type": "string",
+                        "required": true,
+                        "description": "The BetterMode space ID"
+                    },
+                    {
+                        "name": "since",
+                        "in": "query",
+                        "type": "string",
+                        "required": true,
+                        "description": "RFC 3339 timestamp or Unix seconds (inclusive)"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Maximum posts per page (max BATCH_MAX_ITEMS)"
+                    },
+                    {
+                        "name": "cursor",
+                        "in": "query",
+                        "type": "string",
+                        "description": "next_cursor from the previous page"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {"$ref": "#/definitions/ChangesResponse"}
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {"type": "string"}
+                    },
+                    "401": {
+                        "description": "BetterMode rejected the token supplied in X-BetterMode-Token",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode API error, or BetterMode did not return posts most recently updated first",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/spaces/{spaceID}/posts": {
+            "get": {
+                "description": "Returns posts of a space newest first, one page at a time. fields selects which post fields to return (and to query from BetterMode); by default all of id, title, spaceId, createdAt, updatedAt, publishedAt, author, reactionsCount and repliesCount are returned. Use next_cursor to fetch the next page.",
+                "produces": ["application/json"],
+                "tags": ["content"],
+                "summary": "List posts of a space",
+                "parameters": [
+                    {
+                        "name": "spaceID",
+                        "in": "path",
+                        "type": "string",
+                        "required": true,
+                        "description": "The BetterMode space ID"
+                    },
+                    {
+                        "name": "fields",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Comma-separated fields to return: id, title, spaceId, createdAt, updatedAt, publishedAt, author, reactionsCount, repliesCount (default: all)"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Maximum posts per page (max BATCH_MAX_ITEMS)"
+                    },
+                    {
+                        "name": "cursor",
+                        "in": "query",
+                        "type": "string",
+                        "description": "next_cursor from the previous page"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {"$ref": "#/definitions/SpacePostsResponse"}
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {"type": "string"}
+                    },
+                    "401": {
+                        "description": "BetterMode rejected the token supplied in X-BetterMode-Token",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode API error",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/image-proxy": {
+            "get": {
+                "description": "Fetches an image from a host allowed by IMAGE_PROXY_ALLOWED_HOSTS and streams it back with caching. Used by the URLs that proxy_images writes into content. Internal addresses, other hosts and non-image responses are refused.",
+                "produces": ["image/*", "application/json"],
+                "tags": ["content"],
+                "summary": "Proxy an image from an allowed host",
+                "parameters": [
+                    {
+                        "name": "url",
+                        "in": "query",
+                        "type": "string",
+                        "required": true,
+                        "description": "Original image URL"
+                    }
+                ],
+                "responses": {
+                    "200": {"description": "The image"},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {"type": "string"}
+                    },
+                    "403": {
+                        "description": "Host not allowed by IMAGE_PROXY_ALLOWED_HOSTS",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "The image could not be fetched, is too large or is not an image",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/changes": {
+            "get": {
+                "description": "Same as /spaces/{spaceID}/changes, for the space given by space_id or, if omitted, DEFAULT_SPACE_ID.",
+                "produces": ["application/json"],
+                "tags": ["content"],
+                "summary": "List posts modified since a timestamp in the default space",
+                "parameters": [
+                    {
+                        "name": "space_id",
+                        "in": "query",
+                        "type": "string",
+                        "description": "The BetterMode space ID (defaults to DEFAULT_SPACE_ID)"
+                    },
+                    {
+                        "name": "since",
+                        "in": "query",
+                        "type": "string",
+                        "required": true,
+                        "description": "RFC 3339 timestamp or Unix seconds (inclusive)"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Maximum posts per page (max BATCH_MAX_ITEMS)"
+                    },
+                    {
+                        "name": "cursor",
+                        "in": "query",
+                        "type": "string",
+                        "description": "next_cursor from the previous page"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {"$ref": "#/definitions/ChangesResponse"}
+                    },
+                    "400": {
+                        "description": "Bad request, or no space_id and no DEFAULT_SPACE_ID configured",
+                        "schema": {"type": "string"}
+                    },
+                    "401": {
+                        "description": "BetterMode rejected the token supplied in X-BetterMode-Token",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode API error, or BetterMode did not return posts most recently updated first",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/spaces/{spaceID}/export.csv": {
+            "get": {
+                "description": "Pages through the posts of a space (newest first, at most EXPORT_MAX_ITEMS) and streams one CSV row per post. char_count and word_count are counted on the text content of each post, fetched BATCH_CONCURRENCY at a time; they are empty with metadata_only or when a post could not be fetched. Titles with commas, quotes or line breaks are quoted per RFC 4180.",
+                "produces": ["text/csv"],
+                "tags": ["export"],
+                "summary": "Export post metadata of a space as CSV",
+                "parameters": [
+                    {
+                        "name": "spaceID",
+                        "in": "path",
+                        "type": "string",
+                        "required": true,
+                        "description": "The BetterMode space ID"
+                    },
+                    {
+                        "name": "columns",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Comma-separated columns to include, in order (default: post_id,title,author,created_at,char_count,word_count)"
+                    },
+                    {
+                        "name": "metadata_only",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Do not fetch content; char_count and word_count are left empty"
+                    },
+                    {
+                        "name": "nocache",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Fetch content from BetterMode instead of the cache"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV with a header row",
+                        "schema": {"type": "string"}
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode API error",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/spaces/{spaceID}/pinned": {
+            "get": {
+                "description": "Fetches the posts pinned (featured) in a BetterMode space, in pin order, with the same options as /content. A space without pinned posts returns an empty results array. At most BATCH_MAX_ITEMS posts are fetched.",
+                "produces": ["application/json"],
+                "tags": ["content"],
+                "summary": "Get content for the pinned posts of a space",
+                "parameters": [
+                    {
+                        "name": "spaceID",
+                        "in": "path",
+                        "type": "string",
+                        "required": true,
+                        "description": "The BetterMode space ID"
+                    },
+                    {
+                        "name": "format",
+                        "in": "query",
+                        "type": "string",
+                        "enum": ["html", "text", "xhtml", "jsonld"],
+                        "default": "html",
+                        "description": "Format of the returned content"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {"$ref": "#/definitions/PinnedPostsResponse"}
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode API error",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/posts": {
+            "get": {
+                "description": "Same as /spaces/{spaceID}/posts, for the space given by space_id or, if omitted, DEFAULT_SPACE_ID.",
+                "produces": ["application/json"],
+                "tags": ["content"],
+                "summary": "List posts of the default space",
+                "parameters": [
+                    {
+                        "name": "space_id",
+                        "in": "query",
+                        "type": "string",
+                        "description": "The BetterMode space ID (defaults to DEFAULT_SPACE_ID)"
+                    },
+                    {
+                        "name": "fields",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Comma-separated fields to return: id, title, spaceId, createdAt, updatedAt, publishedAt, author, reactionsCount, repliesCount (default: all)"
+                    },
+                    {
+                        "name": "limit",
+                        "in": "query",
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Maximum posts per page (max BATCH_MAX_ITEMS)"
+                    },
+                    {
+                        "name": "cursor",
+                        "in": "query",
+                        "type": "string",
+                        "description": "next_cursor from the previous page"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {"$ref": "#/definitions/SpacePostsResponse"}
+                    },
+                    "400": {
+                        "description": "Bad request, or no space_id and no DEFAULT_SPACE_ID configured",
+                        "schema": {"type": "string"}
+                    },
+                    "401": {
+                        "description": "BetterMode rejected the token supplied in X-BetterMode-Token",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode API error",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/export.csv": {
+            "get": {
+                "description": "Same as /spaces/{spaceID}/export.csv, for the space given by space_id or, if omitted, DEFAULT_SPACE_ID.",
+                "produces": ["text/csv"],
+                "tags": ["export"],
+                "summary": "Export post metadata of the default space as CSV",
+                "parameters": [
+                    {
+                        "name": "space_id",
+                        "in": "query",
+                        "type": "string",
+                        "description": "The BetterMode space ID (defaults to DEFAULT_SPACE_ID)"
+                    },
+                    {
+                        "name": "columns",
+                        "in": "query",
+                        "type": "string",
+                        "description": "Comma-separated columns to include, in order (default: post_id,title,author,created_at,char_count,word_count)"
+                    },
+                    {
+                        "name": "metadata_only",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Do not fetch content; char_count and word_count are left empty"
+                    },
+                    {
+                        "name": "nocache",
+                        "in": "query",
+                        "type": "boolean",
+                        "description": "Fetch content from BetterMode instead of the cache"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV with a header row",
+                        "schema": {"type": "string"}
+                    },
+                    "400": {
+                        "description": "Bad request, or no space_id and no DEFAULT_SPACE_ID configured",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode API error",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        },
+        "/pinned": {
+            "get": {
+                "description": "Same as /spaces/{spaceID}/pinned, for the space given by space_id or, if omitted, DEFAULT_SPACE_ID.",
+                "produces": ["application/json"],
+                "tags": ["content"],
+                "summary": "Get content for the pinned posts of the default space",
+                "parameters": [
+                    {
+                        "name": "space_id",
+                        "in": "query",
+                        "type": "string",
+                        "description": "The BetterMode space ID (defaults to DEFAULT_SPACE_ID)"
+                    },
+                    {
+                        "name": "format",
+                        "in": "query",
+                        "type": "string",
+                        "enum": ["html", "text", "xhtml", "jsonld"],
+                        "default": "html",
+                        "description": "Format of the returned content"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {"$ref": "#/definitions/PinnedPostsResponse"}
+                    },
+                    "400": {
+                        "description": "Bad request, or no space_id and no DEFAULT_SPACE_ID configured",
+                        "schema": {"type": "string"}
+                    },
+                    "502": {
+                        "description": "BetterMode API error",
+                        "schema": {"type": "string"}
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "ContentResponse": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "description": "The content of the post"
+                },
+                "format": {
+                    "type": "string",
+                    "description": "The format of the content (html, text, xhtml or jsonld)"
+                },
+                "post_id": {
+                    "type": "string",
+                    "description": "The ID of the post"
+                },
+                "title": {
+                    "type": "string",
+                    "description": "The title of the post"
+                },
+                "char_count": {
+                    "type": "integer",
+                    "format": "int64",
+                    "description": "The character (rune) count of the content"
+                },
+                "byte_count": {
+                    "type": "integer",
+                    "format": "int64",
+                    "description": "The UTF-8 byte length of the content"
+                },
+                "token_count": {
+                    "type": "integer",
+                    "format": "int64",
+                    "description": "Approximate LLM token count of the content as returned: 1 token per Hangul/CJK/kana character plus 1 per 4 other characters, rounded up. Real counts depend on the model's tokenizer"
+                },
+                "fields": {
+                    "type": "array",
+                    "description": "Selected mapping fields (when fields or field_types is set)",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "key": {"type": "string"},
+                            "type": {"type": "string"},
+                            "value": {"type": "string"}
+                        }
+                    }
+                },
+                "attachments": {
+                    "type": "array",
+                    "description": "Post attachments (when include_attachments is set)",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "filename": {"type": "string"},
+                            "url": {"type": "string"},
+                            "size": {"type": "integer"},
+                            "mime_type": {"type": "string"}
+                        }
+                    }
+                },
+                "warnings": {
+                    "type": "array",
+                    "description": "Non-fatal issues detected while building the response",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "code": {
+                                "type": "string",
+                                "enum": ["duplicate_content_fields", "missing_title", "empty_text", "code_line_numbers_failed", "no_fields_matched", "postprocess_failed", "translation_failed", "content_too_short", "text_fallback", "invalid_utf8_repaired", "partial_data", "duplicate_paragraphs_removed"]
+                            },
+                            "message": {"type": "string"}
+                        }
+                    }
+                },
+                "title_truncated": {
+                    "type": "boolean",
+                    "description": "The title was longer than MAX_TITLE_LENGTH and was truncated"
+                },
+                "encoding": {
+                    "type": "string",
+                    "description": "\"base64\" when content is base64-encoded; omitted for plain content"
+                },
+                "space_id": {
+                    "type": "string",
+                    "description": "ID of the space the post belongs to; omitted if none"
+                },
+                "space_name": {"type": "string"},
+                "updated_at": {
+                    "type": "string",
+                    "description": "When the post was last updated (RFC 3339); also sent as the Last-Modified header"
+                },
+                "fetch_latency_ms": {
+                    "type": "integer",
+                    "description": "Milliseconds spent obtaining the content for this request (near zero on a cache hit); also sent as Server-Timing: fetch;dur=N"
+                },
+                "engagement": {
+                    "type": "object",
+                    "description": "Present when include_engagement is set; counts BetterMode does not return are omitted",
+                    "properties": {
+                        "reactions": {
+                            "type": "integer",
+                            "format": "int64"
+                        },
+                        "replies": {
+                            "type": "integer",
+                            "format": "int64"
+                        },
+                        "by_reaction": {
+                            "type": "object",
+                            "additionalProperties": {"type": "integer"}
+                        }
+                    }
+                },
+                "translated_text": {
+                    "type": "string",
+                    "description": "Content text translated into translate_to (content stays in the original language)"
+                },
+                "translated_to": {"type": "string"},
+                "sanitize_profile": {
+                    "type": "string",
+                    "enum": ["strict", "embed-friendly", "permissive"],
+                    "description": "Sanitization profile applied for the post's space; omitted if none"
+                },
+                "embeds": {
+                    "type": "array",
+                    "description": "Present when include_embeds is set; embeds in document order, each URL once",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "type": {
+                                "type": "string",
+                                "enum": ["iframe", "video", "embed", "oembed"]
+                            },
+                            "url": {"type": "string"},
+                            "provider": {
+                                "type": "string",
+                                "description": "Known provider such as youtube or vimeo; omitted if unrecognized"
+                            }
+                        }
+                    }
+                },
+                "toc": {
+                    "type": "array",
+                    "description": "Present when include_toc is set; headings in document order. Anchors are derived from the heading text only, so they are stable across requests",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "level": {"type": "integer"},
+                            "text": {"type": "string"},
+                            "anchor": {
+                                "type": "string",
+                                "description": "Heading id without the leading #"
+                            }
+                        }
+                    }
+                },
+                "footnotes": {
+                    "type": "array",
+                    "description": "Footnotes found in the content (extract_footnotes)",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "marker": {"type": "string"},
+                            "text": {"type": "string"}
+                        }
+                    }
+                },
+                "content_hash": {
+                    "type": "string",
+                    "description": "Hash (hex, SHA-256 by default) of the canonicalized source content; ignores whitespace, attribute order and entity encoding, independent of format"
+                },
+                "content_hash_algorithm": {
+                    "type": "string",
+                    "enum": ["sha256", "sha1", "md5", "xxhash"],
+                    "description": "Algorithm used for content_hash (xxhash is XXH64 with seed 0)"
+                },
+                "has_more": {
+                    "type": "boolean",
+                    "description": "Content was cut to an auto_preview preview"
+                },
+                "summary": {
+                    "type": "string",
+                    "description": "Summary field (summary, excerpt or description) as plain text, or the first paragraph when derived"
+                },
+                "summary_derived": {
+                    "type": "boolean",
+                    "description": "True if the post has no summary field and summary was derived from the first paragraph"
+                },
+                "tables": {
+                    "type": "array",
+                    "description": "Present when extract_tables is set; one entry per table in document order, each a list of rows of cell text (header rows excluded)",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "array",
+                            "items": {"type": "string"}
+                        }
+                    }
+                },
+                "table_headers": {
+                    "type": "array",
+                    "description": "Header rows of tables[i] (rows in <thead>, or leading rows of only <th> cells); empty when the table has none",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "array",
+                            "items": {"type": "string"}
+                        }
+                    }
+                },
+                "thumbnail": {
+                    "type": "string",
+                    "description": "Present when thumbnail is set and the content has a suitable image; URL of the first image not smaller than 64px"
+                },
+                "links": {
+                    "type": "array",
+                    "description": "Present when include_links is set; links in document order, duplicate URLs removed",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "url": {"type": "string"},
+                            "text": {"type": "string"},
+                            "internal": {"type": "boolean", "description": "Relative URL or a link to a TOKEN_NETWORK_DOMAINS host"}
+                        }
+                    }
+                }
+            }
+        },
+        "BatchResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "post_id": {"type": "string"},
+                            "result": {"$ref": "#/definitions/ContentResponse"},
+                            "error": {"type": "string"},
+                            "skipped": {
+                                "type": "boolean",
+                                "description": "Not fetched because the request was cancelled"
+                            },
+                            "contents": {
+                                "type": "object",
+                                "additionalProperties": {"type": "string"},
+                                "description": "Content per requested format (formats); result holds the first format's response"
+                            }
+                        }
+                    }
+                },
+                "succeeded": {"type": "integer"},
+                "failed": {"type": "integer"},
+                "skipped": {"type": "integer"},
+                "cancelled": {
+                    "type": "boolean",
+                    "description": "The request was cancelled before all posts were fetched"
+                }
+            }
+        },
+        "CollectionResponse": {
+            "type": "object",
+            "properties": {
+                "collection_id": {"type": "string"},
+                "name": {"type": "string"},
+                "results": {
+                    "type": "array",
+                    "description": "Per-post results in collection order (same shape as BatchResponse.results)",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "post_id": {"type": "string"},
+                            "result": {"$ref": "#/definitions/ContentResponse"},
+                            "error": {"type": "string"},
+                            "skipped": {"type": "boolean"}
+                        }
+                    }
+                },
+                "truncated": {
+                    "type": "boolean",
+                    "description": "The collection has more posts than BATCH_MAX_ITEMS"
+                },
+                "cancelled": {
+                    "type": "boolean",
+                    "description": "The request was cancelled before all posts were fetched"
+                }
+            }
+        },
+        "CompileResponse": {
+            "type": "object",
+            "properties": {
+                "content": {"type": "string"},
+                "format": {"type": "string"},
+                "post_ids": {
+                    "type": "array",
+                    "items": {"type": "string"}
+                },
+                "char_count": {
+                    "type": "integer",
+                    "format": "int64",
+                    "description": "Number of characters (runes) in the compiled document"
+                },
+                "word_count": {
+                    "type": "integer",
+                    "format": "int64",
+                    "description": "Number of words, excluding markup"
+                },
+                "token_count": {
+                    "type": "integer",
+                    "format": "int64",
+                    "description": "Approximate LLM token count of the compiled document (same estimate as ContentResponse.token_count)"
+                },
+                "warnings": {
+                    "type": "array",
+                    "description": "Warnings from the individual posts, prefixed with the post ID",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "code": {"type": "string"},
+                            "message": {"type": "string"}
+                        }
+                    }
+                }
+            }
+        },
+        "ExportJob": {
+            "type": "object",
+            "properties": {
+                "job_id": {"type": "string"},
+                "status": {
+                    "type": "string",
+                    "enum": ["running", "succeeded", "failed"]
+                },
+                "destination": {"type": "string"},
+                "total": {"type": "integer"},
+                "succeeded": {"type": "integer"},
+                "failed": {"type": "integer"},
+                "error": {"type": "string"},
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "SpacePostsResponse": {
+            "type": "object",
+            "properties": {
+                "space_id": {"type": "string"},
+                "fields": {
+                    "type": "array",
+                    "items": {"type": "string"},
+                    "description": "Fields present on each post"
+                },
+                "posts": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "description": "Only the fields listed in fields",
+                        "properties": {
+                            "id": {"type": "string"},
+                            "title": {"type": "string"},
+                            "spaceId": {"type": "string"},
+                            "createdAt": {"type": "string"},
+                            "updatedAt": {"type": "string"},
+                            "publishedAt": {"type": "string"},
+                            "author": {"type": "string"},
+                            "reactionsCount": {"type": "integer"},
+                            "repliesCount": {"type": "integer"}
+                        }
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "description": "Pass as cursor to get the next page; omitted on the last page"
+                }
+            }
+        },
+        "ChangesResponse": {
+            "type": "object",
+            "properties": {
+                "space_id": {"type": "string"},
+                "since": {
+                    "type": "string",
+                    "description": "since normalized to RFC 3339"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "id": {"type": "string"},
+                            "updated_at": {"type": "string"}
+                        }
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "description": "Pass as cursor to get the next page; omitted on the last page"
+                }
+            }
+        },
+        "PinnedPostsResponse": {
+            "type": "object",
+            "properties": {
+                "space_id": {"type": "string"},
+                "results": {
+                    "type": "array",
+                    "description": "Per-post results in pin order (same shape as BatchResponse.results); empty when nothing is pinned",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "post_id": {"type": "string"},
+                            "result": {"$ref": "#/definitions/ContentResponse"},
+                            "error": {"type": "string"},
+                            "skipped": {"type": "boolean"}
                         }
                     }
+                },
+                "truncated": {
+                    "type": "boolean",
+                    "description": "The space has more pinned posts than BATCH_MAX_ITEMS"
+                },
+                "cancelled": {
+                    "type": "boolean",
+                    "description": "The request was cancelled before all posts were fetched"
                 }
             }
         }
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Embed는 본문에 삽입된 동영상 등 외부 콘텐츠입니다
+type Embed struct {
+	Type     string `json:"type"`               // "iframe", "video", "embed", "oembed"
+	URL      string `json:"url"`                // 임베드 원본 URL
+	Provider string `json:"provider,omitempty"` // 알려진 제공자 (예: "youtube", "vimeo"), 모르면 생략
+}
+
+// embedProviders는 호스트 이름으로 제공자를 판별합니다. 하위 도메인(www., player. 등)도 같은 제공자로 봅니다.
+var embedProviders = []struct {
+	host     string
+	provider string
+}{
+	{"youtube.com", "youtube"},
+	{"youtube-nocookie.com", "youtube"},
+	{"youtu.be", "youtube"},
+	{"vimeo.com", "vimeo"},
+	{"loom.com", "loom"},
+	{"wistia.com", "wistia"},
+	{"wistia.net", "wistia"},
+	{"dailymotion.com", "dailymotion"},
+	{"twitch.tv", "twitch"},
+	{"twitter.com", "twitter"},
+	{"x.com", "twitter"},
+	{"instagram.com", "instagram"},
+	{"tiktok.com", "tiktok"},
+	{"spotify.com", "spotify"},
+	{"soundcloud.com", "soundcloud"},
+	{"figma.com", "figma"},
+	{"codepen.io", "codepen"},
+	{"docs.google.com", "google_docs"},
+	{"tv.naver.com", "naver_tv"},
+}
+
+// extractEmbeds는 HTML 본문에서 iframe, video/embed/object 요소와 oembed 블록(<oembed url>, data-embed-url 등)을 찾아
+// 나온 순서대로 반환합니다. 같은 URL은 한 번만 포함합니다.
+func extractEmbeds(content string) ([]Embed, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return nil, err
+	}
+
+	embeds := []Embed{}
+	seen := make(map[string]bool)
+	add := func(embedType, rawURL string) {
+		rawURL = strings.TrimSpace(rawURL)
+		if rawURL == "" || seen[rawURL] {
+			return
+		}
+		seen[rawURL] = true
+		embeds = append(embeds, Embed{Type: embedType, URL: rawURL, Provider: embedProvider(rawURL)})
+	}
+
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			if c.Type != html.ElementNode {
+				return true
+			}
+			switch strings.ToLower(c.Data) {
+			case "iframe":
+				add("iframe", attrValue(c, "src"))
+				return false
+			case "video":
+				src := attrValue(c, "src")
+				if src == "" {
+					// <video><source src="..."></video>
+					for child := c.FirstChild; child != nil && src == ""; child = child.NextSibling {
+						if child.Type == html.ElementNode && strings.EqualFold(child.Data, "source") {
+							src = attrValue(child, "src")
+						}
+					}
+				}
+				add("video", src)
+				return false
+			case "embed":
+				add("embed", attrValue(c, "src"))
+				return false
+			case "object":
+				add("embed", attrValue(c, "data"))
+				return false
+			case "oembed":
+				add("oembed", attrValue(c, "url"))
+				return false
+			}
+			// 에디터가 남기는 임베드 표시 (예: <div data-type="embed" data-embed-url="...">)
+			for _, key := range []string{"data-embed-url", "data-oembed-url", "data-oembed"} {
+				if v := attrValue(c, key); v != "" {
+					add("oembed", v)
+					return false
+				}
+			}
+			if strings.EqualFold(attrValue(c, "data-type"), "embed") {
+				add("oembed", attrValue(c, "data-url"))
+				return false
+			}
+			return true
+		})
+	}
+	return embeds, nil
+}
+
+// embedProvider는 URL의 호스트로 알려진 제공자를 찾습니다
+func embedProvider(rawURL string) string {
+	if strings.HasPrefix(rawURL, "//") {
+		rawURL = "https:" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, p := range embedProviders {
+		if host == p.host || strings.HasSuffix(host, "."+p.host) {
+			return p.provider
+		}
+	}
+	return ""
+}
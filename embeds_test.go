@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractEmbeds(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Embed
+	}{
+		{
+			"youtube iframe",
+			`<p>영상</p><iframe src="https://www.youtube.com/embed/abc" allowfullscreen></iframe>`,
+			[]Embed{{Type: "iframe", URL: "https://www.youtube.com/embed/abc", Provider: "youtube"}},
+		},
+		{
+			"protocol-relative vimeo",
+			`<iframe src="//player.vimeo.com/video/1"></iframe>`,
+			[]Embed{{Type: "iframe", URL: "//player.vimeo.com/video/1", Provider: "vimeo"}},
+		},
+		{
+			"generic embed without provider",
+			`<div data-type="embed" data-url="https://example.com/widget"></div>`,
+			[]Embed{{Type: "oembed", URL: "https://example.com/widget"}},
+		},
+		{
+			"oembed block and marker",
+			`<figure><oembed url="https://youtu.be/xyz"></oembed></figure><div data-embed-url="https://www.loom.com/share/1"></div>`,
+			[]Embed{
+				{Type: "oembed", URL: "https://youtu.be/xyz", Provider: "youtube"},
+				{Type: "oembed", URL: "https://www.loom.com/share/1", Provider: "loom"},
+			},
+		},
+		{
+			"video with source and object",
+			`<video><source src="https://cdn.example.com/a.mp4"></video><object data="https://example.com/a.swf"></object>`,
+			[]Embed{
+				{Type: "video", URL: "https://cdn.example.com/a.mp4"},
+				{Type: "embed", URL: "https://example.com/a.swf"},
+			},
+		},
+		{
+			"duplicates and empty src skipped",
+			`<iframe src="https://youtu.be/a"></iframe><iframe src="https://youtu.be/a"></iframe><iframe></iframe>`,
+			[]Embed{{Type: "iframe", URL: "https://youtu.be/a", Provider: "youtube"}},
+		},
+		{"no embeds", `<p>본문</p>`, []Embed{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractEmbeds(tt.content)
+			if err != nil {
+				t.Fatalf("extractEmbeds: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractEmbeds = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbedProvider(t *testing.T) {
+	tests := map[string]string{
+		"https://m.youtube.com/watch?v=1": "youtube",
+		"https://tv.naver.com/v/1":        "naver_tv",
+		"https://notyoutube.com/v":        "",
+		"https://x.com/user/status/1":     "twitter",
+		"not a url %":                     "",
+	}
+	for rawURL, want := range tests {
+		if got := embedProvider(rawURL); got != want {
+			t.Errorf("embedProvider(%q) = %q, want %q", rawURL, got, want)
+		}
+	}
+}
+
+func TestRenderContentResponseEmbedsBeforeSanitize(t *testing.T) {
+	withConfig(t, func(cfg *Config) { cfg.SanitizeDefaultProfile = SanitizeStrict })
+	post := newTestPost("제목", `<p>영상</p><iframe src="https://www.youtube.com/embed/abc"></iframe>`)
+
+	response := renderTestPost(t, ContentOptions{Format: "html", IncludeEmbeds: true}, post)
+	want := []Embed{{Type: "iframe", URL: "https://www.youtube.com/embed/abc", Provider: "youtube"}}
+	if !reflect.DeepEqual(response.Embeds, want) {
+		t.Errorf("embeds = %+v, want %+v", response.Embeds, want)
+	}
+	if response.Content != "<p>영상</p>" {
+		t.Errorf("content = %q, want iframe removed by strict profile", response.Content)
+	}
+
+	if response := renderTestPost(t, ContentOptions{Format: "html"}, post); response.Embeds != nil {
+		t.Errorf("embeds without include_embeds = %+v, want nil", response.Embeds)
+	}
+}
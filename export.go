@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// ExportRequest는 게시물들을 가져와 HTTP 응답 대신 저장소(Sink)에 쓰는 내보내기 작업 요청입니다
+type ExportRequest struct {
+	PostIDs     []string `json:"post_ids"`
+	Destination string   `json:"destination"` // "file:상대경로" 또는 "s3://버킷/키"
+	ContentOptions
+}
+
+// 끝난 작업의 상태를 조회할 수 있도록 보관하는 시간
+const exportJobRetention = time.Hour
+
+// 내보내기 작업 상태
+const (
+	ExportStatusRunning   = "running"
+	ExportStatusSucceeded = "succeeded"
+	ExportStatusFailed    = "failed"
+)
+
+// ExportJob은 내보내기 작업의 진행 상태입니다
+type ExportJob struct {
+	ID          string     `json:"job_id"`
+	Status      string     `json:"status"`
+	Destination string     `json:"destination"`
+	Total       int        `json:"total"`
+	Succeeded   int        `json:"succeeded"`
+	Failed      int        `json:"failed"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// exportManager는 백그라운드 내보내기 작업을 실행하고 상태를 보관합니다.
+// 작업은 서버 종료 컨텍스트를 따르며, 종료 시 진행 중인 작업이 끝날 때까지 wg로 기다립니다.
+type exportManager struct {
+	ctx   context.Context
+	wg    *sync.WaitGroup
+	jobs  map[string]*ExportJob
+	mutex sync.RWMutex
+}
+
+func newExportManager(ctx context.Context, wg *sync.WaitGroup) *exportManager {
+	return &exportManager{ctx: ctx, wg: wg, jobs: make(map[string]*ExportJob)}
+}
+
+// 전역 내보내기 작업 관리자
+var exports *exportManager
+
+// start는 작업을 등록하고 백그라운드에서 실행합니다
+func (m *exportManager) start(req ExportRequest, sink Sink) (ExportJob, error) {
+	id, err := newJobID()
+	if err != nil {
+		return ExportJob{}, err
+	}
+	job := &ExportJob{
+		ID:          id,
+		Status:      ExportStatusRunning,
+		Destination: sink.String(),
+		Total:       len(req.PostIDs),
+		CreatedAt:   time.Now(),
+	}
+
+	m.mutex.Lock()
+	m.pruneLocked(job.CreatedAt)
+	m.jobs[id] = job
+	snapshot := *job
+	m.mutex.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.run(job, req, sink)
+	}()
+	return snapshot, nil
+}
+
+// pruneLocked는 끝난 지 exportJobRetention이 지난 작업을 지웁니다. m.mutex를 잡은 상태에서 호출해야 합니다.
+func (m *exportManager) pruneLocked(now time.Time) {
+	for id, job := range m.jobs {
+		if job.FinishedAt != nil && now.Sub(*job.FinishedAt) > exportJobRetention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// get은 작업 상태의 복사본을 반환합니다
+func (m *exportManager) get(id string) (ExportJob, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return ExportJob{}, false
+	}
+	return *job, true
+}
+
+// run은 게시물을 BATCH_MAX_ITEMS개씩 가져와 한 줄에 하나씩 JSON(NDJSON)으로 sink에 씁니다.
+// 가져오지 못한 게시물도 post_id와 error가 담긴 줄로 남깁니다.
+func (m *exportManager) run(job *ExportJob, req ExportRequest, sink Sink) {
+	pr, pw := io.Pipe()
+	go func() {
+		enc := json.NewEncoder(pw)
+		cfg := currentConfig()
+		chunk := cfg.BatchMaxItems
+		if chunk < 1 {
+			chunk = 1
+		}
+		for start := 0; start < len(req.PostIDs); start += chunk {
+			end := start + chunk
+			if end > len(req.PostIDs) {
+				end = len(req.PostIDs)
+			}
+
+			results, cancelled := fetchBatch(m.ctx, req.PostIDs[start:end], nil, req.ContentOptions, cfg.BatchConcurrency)
+			if cancelled {
+				pw.CloseWithError(m.ctx.Err())
+				return
+			}
+			for _, item := range results {
+				m.mutex.Lock()
+				if item.Error != "" {
+					job.Failed++
+				} else {
+					job.Succeeded++
+				}
+				m.mutex.Unlock()
+
+				if err := enc.Encode(item); err != nil {
+					pw.CloseWithError(err)
+					return
+				}
+			}
+		}
+		pw.Close()
+	}()
+
+	err := sink.Write(m.ctx, pr)
+	pr.CloseWithError(err) // 쓰기가 실패하면 생성 고루틴도 멈춥니다
+
+	finished := time.Now()
+	m.mutex.Lock()
+	job.FinishedAt = &finished
+	if err != nil {
+		job.Status = ExportStatusFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = ExportStatusSucceeded
+	}
+	snapshot := *job
+	m.mutex.Unlock()
+
+	if err != nil {
+		log.Printf("Export %s to %s failed: %v", snapshot.ID, snapshot.Destination, err)
+	} else {
+		log.Printf("Export %s to %s finished (%d succeeded, %d failed)", snapshot.ID, snapshot.Destination, snapshot.Succeeded, snapshot.Failed)
+	}
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating job ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// StartExport godoc
+// @Summary Export posts to storage
+// @Description Starts a background job that fetches the posts and writes them as NDJSON (one batch item per line) to a local file or S3-compatible bucket. Returns the job ID immediately.
+// @Tags export
+// @Accept json
+// @Produce json
+// @Param request body ExportRequest true "Post IDs, destination and shared options"
+// @Success 202 {object} ExportJob
+// @Failure 400 {string} string "Bad request"
+// @Router /exports [post]
+func startExport(w http.ResponseWriter, r *http.Request) {
+	cfg := currentConfig()
+	var req ExportRequest
+	if err := decodeContentRequest(r, &req, decodeLenient); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.PostIDs) == 0 {
+		writeError(w, "post_ids is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.PostIDs) > cfg.ExportMaxItems {
+		writeError(w, fmt.Sprintf("Too many post_ids (max %d)", cfg.ExportMaxItems), http.StatusBadRequest)
+		return
+	}
+	if req.Destination == "" {
+		writeError(w, "destination is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.ContentOptions.normalize(); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sink, err := newSink(req.Destination)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	job, err := exports.start(req, sink)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Location", "/api/v1/exports/"+job.ID)
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, job)
+}
+
+// GetExport godoc
+// @Summary Get export job status
+// @Tags export
+// @Produce json
+// @Param jobID path string true "Job ID"
+// @Success 200 {object} ExportJob
+// @Failure 404 {string} string "Job not found"
+// @Router /exports/{jobID} [get]
+func getExport(w http.ResponseWriter, r *http.Request) {
+	job, ok := exports.get(chi.URLParam(r, "jobID"))
+	if !ok {
+		writeError(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, job)
+}
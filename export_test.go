@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// failingSink는 항상 쓰기에 실패하는 Sink입니다
+type failingSink struct{}
+
+func (failingSink) Write(ctx context.Context, r io.Reader) error {
+	io.Copy(io.Discard, r)
+	return errors.New("disk full")
+}
+
+func (failingSink) String() string { return "test:failing" }
+
+func TestExportManagerRun(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	batchUpstream(t, nil)
+
+	dir := t.TempDir()
+	local, err := newLocalFileSink(dir, "posts.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name          string
+		sink          Sink
+		wantStatus    string
+		wantSucceeded int
+		wantFailed    int
+	}{
+		{"local file", local, ExportStatusSucceeded, 2, 1},
+		{"sink failure", failingSink{}, ExportStatusFailed, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			m := newExportManager(context.Background(), &wg)
+			req := ExportRequest{PostIDs: []string{"a", "missing", "b"}, ContentOptions: ContentOptions{Format: "html"}}
+			started, err := m.start(req, tt.sink)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if started.Status != ExportStatusRunning || started.ID == "" || started.Total != 3 {
+				t.Errorf("started job = %+v", started)
+			}
+			wg.Wait()
+
+			job, ok := m.get(started.ID)
+			if !ok {
+				t.Fatal("job not found")
+			}
+			if job.Status != tt.wantStatus || job.Succeeded != tt.wantSucceeded || job.Failed != tt.wantFailed || job.FinishedAt == nil {
+				t.Errorf("job = %+v, want status %s with %d succeeded and %d failed", job, tt.wantStatus, tt.wantSucceeded, tt.wantFailed)
+			}
+		})
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "posts.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], `"post_id":"missing"`) || !strings.Contains(lines[1], `"error"`) {
+		t.Errorf("exported lines = %q, want one line per post with the error kept", lines)
+	}
+}
+
+func TestStartExport(t *testing.T) {
+	dir := t.TempDir()
+	withConfig(t, func(cfg *Config) {
+		cfg.ExportLocalDir = dir
+		cfg.ExportMaxItems = 2
+	})
+	withTestToken(t)
+	withContentCache(t)
+	batchUpstream(t, nil)
+	var wg sync.WaitGroup
+	prev := exports
+	exports = newExportManager(context.Background(), &wg)
+	t.Cleanup(func() { exports = prev })
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"accepted", `{"post_ids":["a"],"destination":"file:out.ndjson"}`, http.StatusAccepted},
+		{"no post ids", `{"destination":"file:out.ndjson"}`, http.StatusBadRequest},
+		{"too many post ids", `{"post_ids":["a","b","c"],"destination":"file:out.ndjson"}`, http.StatusBadRequest},
+		{"no destination", `{"post_ids":["a"]}`, http.StatusBadRequest},
+		{"bad destination", `{"post_ids":["a"],"destination":"ftp://host/x"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			startExport(rec, httptest.NewRequest(http.MethodPost, "/api/v1/exports", strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusAccepted && !strings.HasPrefix(rec.Header().Get("Location"), "/api/v1/exports/") {
+				t.Errorf("Location = %q", rec.Header().Get("Location"))
+			}
+		})
+	}
+	wg.Wait()
+}
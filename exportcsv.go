@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// spaceExportColumns는 CSV 내보내기에서 고를 수 있는 열이며, columns를 지정하지 않으면 이 순서로 모두 씁니다
+var spaceExportColumns = []string{"post_id", "title", "author", "created_at", "char_count", "word_count"}
+
+// spaceExportPostsQuery는 스페이스 게시물을 최신순(createdAt 내림차순)으로 가져옵니다
+var spaceExportPostsQuery = buildPostListQuery("ExportSpacePosts", "createdAt", newestFirst, []gqlField{
+	{name: "id"},
+	{name: "title"},
+	{name: "createdAt"},
+	{name: "owner", children: []gqlField{{name: "member", children: gqlFields("name")}}},
+})
+
+// spaceExportPost는 CSV 한 행의 게시물 정보입니다 (글자/단어 수는 본문을 가져온 경우에만 채움)
+type spaceExportPost struct {
+	PostSummary
+	Author    string
+	CharCount *int64
+	WordCount *int64
+}
+
+// value는 column 열에 쓸 값을 반환합니다
+func (p spaceExportPost) value(column string) string {
+	switch column {
+	case "post_id":
+		return p.ID
+	case "title":
+		return p.Title
+	case "author":
+		return p.Author
+	case "created_at":
+		return p.CreatedAt
+	case "char_count":
+		if p.CharCount != nil {
+			return strconv.FormatInt(*p.CharCount, 10)
+		}
+	case "word_count":
+		if p.WordCount != nil {
+			return strconv.FormatInt(*p.WordCount, 10)
+		}
+	}
+	return ""
+}
+
+// spaceExportParams는 CSV 내보내기의 쿼리 파라미터입니다
+type spaceExportParams struct {
+	Columns      []string `json:"columns"`       // 쓸 열과 순서 (비어 있으면 모든 열)
+	MetadataOnly bool     `json:"metadata_only"` // 본문을 가져오지 않음 (char_count, word_count는 빈 값)
+	NoCache      bool     `json:"nocache"`
+}
+
+// validateExportColumns는 요청한 열 목록을 검증합니다 (비어 있으면 모든 열)
+func validateExportColumns(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return spaceExportColumns, nil
+	}
+	seen := make(map[string]bool, len(requested))
+	for _, column := range requested {
+		if !containsString(spaceExportColumns, column) {
+			return nil, fmt.Errorf("columns must contain only %s", strings.Join(spaceExportColumns, ", "))
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("column %q is listed more than once", column)
+		}
+		seen[column] = true
+	}
+	return requested, nil
+}
+
+// ExportSpaceCSV godoc
+// @Summary Export post metadata of a space as CSV
+// @Description Pages through the posts of a space (newest first, at most EXPORT_MAX_ITEMS) and streams one CSV row per post. char_count and word_count come from the text content of each post, fetched BATCH_CONCURRENCY at a time; they are empty with metadata_only or when a post could not be fetched.
+// @Tags export
+// @Produce text/csv
+// @Param spaceID path string true "Space ID"
+// @Param space_id query string false "Space ID for the route without spaceID (default: DEFAULT_SPACE_ID)"
+// @Param columns query string false "Comma-separated columns to include, in order (default: post_id,title,author,created_at,char_count,word_count)"
+// @Param metadata_only query bool false "Do not fetch content; char_count and word_count are left empty"
+// @Param nocache query bool false "Fetch content from BetterMode instead of the cache"
+// @Success 200 {string} string "CSV"
+// @Failure 400 {string} string "Bad request"
+// @Failure 502 {string} string "BetterMode API error"
+// @Router /spaces/{spaceID}/export.csv [get]
+// @Router /export.csv [get]
+func exportSpaceCSV(w http.ResponseWriter, r *http.Request) {
+	var params spaceExportParams
+	if err := applyQueryParams(r.URL.Query(), &params); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	columns, err := validateExportColumns(params.Columns)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	needsContent := !params.MetadataOnly && (containsString(columns, "char_count") || containsString(columns, "word_count"))
+	// 글자/단어 수는 태그를 뺀 본문으로 셉니다
+	opts := ContentOptions{Format: "text", NoCache: params.NoCache}
+
+	spaceID := requestSpaceID(r)
+	if spaceID == "" {
+		writeError(w, errNoSpaceID, http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	cfg := currentConfig()
+	var writer *csv.Writer
+	// 첫 페이지를 받은 뒤에 응답을 시작해, 스페이스 조회가 실패하면 에러 상태 코드로 응답할 수 있게 합니다
+	startCSV := func() error {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", spaceID+".csv"))
+		w.Header().Set("Cache-Control", "no-store")
+		// 프록시가 응답을 모아 두지 않도록 합니다 (nginx)
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		writer = csv.NewWriter(w)
+		return writer.Write(columns)
+	}
+	rc := http.NewResponseController(w)
+	written := 0
+	err = listSpaceExportPosts(ctx, spaceID, cfg.ExportMaxItems, func(page []spaceExportPost) error {
+		if needsContent {
+			fillExportCounts(ctx, page, opts, cfg.BatchConcurrency)
+		}
+		if writer == nil {
+			if err := startCSV(); err != nil {
+				return err
+			}
+		}
+		row := make([]string, len(columns))
+		for _, post := range page {
+			for i, column := range columns {
+				row[i] = post.value(column)
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+			written++
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	})
+
+	if writer == nil {
+		if err != nil {
+			status := http.StatusBadGateway
+			if errors.As(err, new(*inputError)) {
+				status = http.StatusBadRequest
+			} else if errors.Is(err, errCallerTokenRejected) {
+				status = http.StatusUnauthorized
+			}
+			writeError(w, "Error fetching space posts: "+err.Error(), status)
+			return
+		}
+		// 게시물이 없는 스페이스도 헤더 행만 있는 CSV로 응답합니다
+		if err := startCSV(); err == nil {
+			writer.Flush()
+		}
+		return
+	}
+	if err != nil {
+		// 상태 코드를 이미 보냈으므로 로그만 남깁니다 (클라이언트는 잘린 CSV를 받음)
+		log.Printf("CSV export of space %s stopped after %d rows: %v", spaceID, written, err)
+	}
+}
+
+// fillExportCounts는 page 게시물들의 text 본문을 concurrency개씩 가져와 글자/단어 수를 채웁니다.
+// 가져오지 못한 게시물은 비워 둡니다.
+func fillExportCounts(ctx context.Context, page []spaceExportPost, opts ContentOptions, concurrency int) {
+	postIDs := make([]string, len(page))
+	for i, post := range page {
+		postIDs[i] = post.ID
+	}
+	results, _ := fetchBatch(ctx, postIDs, nil, opts, concurrency)
+	for i, item := range results {
+		if item.Result == nil {
+			if item.Error != "" {
+				log.Printf("CSV export could not fetch post %s: %s", item.PostID, item.Error)
+			}
+			continue
+		}
+		chars := item.Result.CharCount
+		words := int64(len(strings.Fields(item.Result.Content)))
+		page[i].CharCount, page[i].WordCount = &chars, &words
+	}
+}
+
+// listSpaceExportPosts는 스페이스의 게시물을 최신순으로 최대 max개까지 페이지 단위로 emit에 넘깁니다.
+// emit이 에러를 반환하면 멈추고 그 에러를 반환합니다.
+func listSpaceExportPosts(ctx context.Context, spaceID string, max int, emit func([]spaceExportPost) error) error {
+	after := ""
+	for count := 0; count < max; {
+		pageSize := max - count
+		if pageSize > listPageSize {
+			pageSize = listPageSize
+		}
+		variables := map[string]interface{}{
+			"spaceIds": []string{spaceID},
+			"limit":    pageSize,
+		}
+		if after != "" {
+			variables["after"] = after
+		}
+
+		var data struct {
+			Posts struct {
+				Nodes []struct {
+					ID        string     `json:"id"`
+					Title     string     `json:"title"`
+					CreatedAt string     `json:"createdAt"`
+					Owner     *PostOwner `json:"owner"`
+				} `json:"nodes"`
+				PageInfo pageInfo `json:"pageInfo"`
+			} `json:"posts"`
+		}
+		if err := queryBetterMode(ctx, spaceExportPostsQuery, variables, &data); err != nil {
+			return err
+		}
+
+		page := make([]spaceExportPost, 0, len(data.Posts.Nodes))
+		for _, node := range data.Posts.Nodes {
+			if count+len(page) >= max {
+				break
+			}
+			post := spaceExportPost{PostSummary: PostSummary{ID: node.ID, Title: node.Title, SpaceID: spaceID, CreatedAt: node.CreatedAt}}
+			if node.Owner != nil && node.Owner.Member != nil {
+				post.Author = node.Owner.Member.Name
+			}
+			page = append(page, post)
+		}
+		if len(page) > 0 {
+			if err := emit(page); err != nil {
+				return err
+			}
+			count += len(page)
+		}
+		if !data.Posts.PageInfo.HasNextPage || data.Posts.PageInfo.EndCursor == "" {
+			return nil
+		}
+		after = data.Posts.PageInfo.EndCursor
+	}
+	return nil
+}
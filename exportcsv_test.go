@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpaceExportPostsQueryNewestFirst(t *testing.T) {
+	for _, want := range []string{
+		`orderByString: "createdAt", reverse: true`,
+		"owner {\n\t\t\t\tmember {\n\t\t\t\t\tname",
+	} {
+		if !strings.Contains(spaceExportPostsQuery, want) {
+			t.Errorf("query missing %q:\n%s", want, spaceExportPostsQuery)
+		}
+	}
+}
+
+func TestValidateExportColumns(t *testing.T) {
+	tests := []struct {
+		requested []string
+		want      []string
+		wantErr   bool
+	}{
+		{nil, spaceExportColumns, false},
+		{[]string{"title", "post_id"}, []string{"title", "post_id"}, false},
+		{[]string{"title", "body"}, nil, true},
+		{[]string{"title", "title"}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := validateExportColumns(tt.requested)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateExportColumns(%v) err = %v, wantErr %v", tt.requested, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("validateExportColumns(%v) = %v, want %v", tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestSpaceExportPostValue(t *testing.T) {
+	chars, words := int64(12), int64(3)
+	post := spaceExportPost{
+		PostSummary: PostSummary{ID: "post-1", Title: "제목", CreatedAt: "2024-05-01T10:00:00Z"},
+		Author:      "작성자",
+		CharCount:   &chars,
+		WordCount:   &words,
+	}
+	tests := []struct {
+		column string
+		post   spaceExportPost
+		want   string
+	}{
+		{"post_id", post, "post-1"},
+		{"title", post, "제목"},
+		{"author", post, "작성자"},
+		{"created_at", post, "2024-05-01T10:00:00Z"},
+		{"char_count", post, "12"},
+		{"word_count", post, "3"},
+		{"char_count", spaceExportPost{}, ""},
+		{"word_count", spaceExportPost{}, ""},
+		{"unknown", post, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.post.value(tt.column); got != tt.want {
+			t.Errorf("value(%q) = %q, want %q", tt.column, got, tt.want)
+		}
+	}
+}
+
+// exportCSVUpstream은 스페이스 목록을 pageSize개씩 나눠 돌려주고, 게시물 본문은 "<p>one two</p>"로 응답합니다.
+// 제목은 "Post N"이고 2번 게시물 제목에는 쉼표와 따옴표가 들어 있습니다.
+func exportCSVUpstream(t *testing.T, total, pageSize int, listStatus int) *fakeUpstream {
+	t.Helper()
+	return withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		req := readGraphQLRequest(t, r)
+		if !strings.Contains(req.Query, "ExportSpacePosts") {
+			writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>one two</p>")))
+			return
+		}
+		if listStatus != http.StatusOK {
+			writeJSONResponse(w, listStatus, map[string]interface{}{"errors": []map[string]string{{"message": "boom"}}})
+			return
+		}
+		start := 0
+		if after, ok := req.Variables["after"].(string); ok {
+			fmt.Sscanf(after, "cursor-%d", &start)
+		}
+		var nodes []map[string]interface{}
+		for i := start; i < total && i < start+pageSize; i++ {
+			title := fmt.Sprintf("Post %d", i+1)
+			if i == 1 {
+				title = `Hello, "world"`
+			}
+			nodes = append(nodes, map[string]interface{}{
+				"id": fmt.Sprintf("post-%d", i+1), "title": title, "createdAt": "2024-05-01T10:00:00Z",
+				"owner": map[string]interface{}{"member": map[string]string{"name": "작성자"}},
+			})
+		}
+		end := start + len(nodes)
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"posts": map[string]interface{}{
+			"nodes":    nodes,
+			"pageInfo": map[string]interface{}{"hasNextPage": end < total, "endCursor": fmt.Sprintf("cursor-%d", end)},
+		}}})
+	})
+}
+
+func TestExportSpaceCSV(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		total      int
+		maxItems   int
+		listStatus int
+		wantStatus int
+		wantRows   [][]string
+	}{
+		{
+			name: "all columns", query: "", total: 2, maxItems: 10, listStatus: http.StatusOK, wantStatus: http.StatusOK,
+			wantRows: [][]string{
+				spaceExportColumns,
+				{"post-1", "Post 1", "작성자", "2024-05-01T10:00:00Z", "7", "2"},
+				{"post-2", `Hello, "world"`, "작성자", "2024-05-01T10:00:00Z", "7", "2"},
+			},
+		},
+		{
+			name: "metadata only", query: "&metadata_only=true&columns=post_id,word_count", total: 1, maxItems: 10,
+			listStatus: http.StatusOK, wantStatus: http.StatusOK,
+			wantRows: [][]string{{"post_id", "word_count"}, {"post-1", ""}},
+		},
+		{
+			name: "max items", query: "&columns=post_id&metadata_only=true", total: listPageSize + 10, maxItems: 3,
+			listStatus: http.StatusOK, wantStatus: http.StatusOK,
+			wantRows: [][]string{{"post_id"}, {"post-1"}, {"post-2"}, {"post-3"}},
+		},
+		{
+			name: "empty space", query: "", total: 0, maxItems: 10, listStatus: http.StatusOK, wantStatus: http.StatusOK,
+			wantRows: [][]string{spaceExportColumns},
+		},
+		{name: "unknown column", query: "&columns=body", total: 1, maxItems: 10, listStatus: http.StatusOK, wantStatus: http.StatusBadRequest},
+		{name: "upstream error", query: "", total: 1, maxItems: 10, listStatus: http.StatusInternalServerError, wantStatus: http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			withConfig(t, func(cfg *Config) {
+				cfg.ExportMaxItems = tt.maxItems
+				cfg.BatchConcurrency = 2
+			})
+			exportCSVUpstream(t, tt.total, listPageSize, tt.listStatus)
+
+			rec := httptest.NewRecorder()
+			exportSpaceCSV(rec, httptest.NewRequest(http.MethodGet, "/api/v1/export.csv?space_id=space-1"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="space-1.csv"` {
+				t.Errorf("Content-Disposition = %q", got)
+			}
+			rows, err := csv.NewReader(rec.Body).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("rows = %q, want %q", rows, tt.wantRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Footnote는 본문의 각주 하나입니다
+type Footnote struct {
+	Marker string `json:"marker"` // 본문에서 각주를 가리키는 표시 (예: "1")
+	Text   string `json:"text"`   // 각주 내용 (되돌아가기 링크 제외)
+}
+
+var (
+	// 각주 정의의 id (예: fn1, fn:1, footnote-2). fnref1 같은 참조 id는 따로 걸러냅니다
+	footnoteIDPattern = regexp.MustCompile(`(?i)^(?:fn|footnote)[-:_]?([0-9a-z][\w-]*)$`)
+	// 직접 입력한 각주 표시 (예: "[1]")
+	footnoteTextMarkerPattern = regexp.MustCompile(`\[(\d{1,3})\]`)
+	// 문단 앞의 각주 표시 (예: "[1] 내용", "1. 내용")
+	footnoteLeadingMarkerPattern = regexp.MustCompile(`^\s*(?:\[\d{1,3}\]|\d{1,3}[.)])\s*`)
+)
+
+// footnoteContainerClasses는 각주 목록을 감싸는 요소의 class입니다 (markdown-it, Pandoc, Ghost 등)
+var footnoteContainerClasses = []string{"footnotes", "footnotes-list", "footnote-list", "endnotes"}
+
+// extractFootnotes는 HTML 본문에서 각주 정의를 찾아 문서 순서대로 반환합니다.
+// 다음 형태를 각주로 봅니다:
+//   - class="footnotes" 등의 각주 목록 또는 role="doc-endnotes" 안의 <li>
+//   - id가 fn1, fn:1, footnote-1 같은 요소, role="doc-footnote" 요소
+//   - 본문에서 [1]로 먼저 가리킨 뒤 "[1] 내용"으로 시작하는 문단 (직접 입력한 각주)
+//
+// remove가 true이면 각주 정의와 본문의 각주 참조(<sup>, [1] 표시)를 지운 본문을 함께 반환합니다.
+func extractFootnotes(content string, remove bool) (string, []Footnote, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", nil, err
+	}
+	// 맨 위 노드도 다른 노드처럼 지울 수 있도록 임시 부모에 붙입니다
+	root := &html.Node{Type: html.ElementNode, Data: "body"}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+
+	footnotes := []Footnote{}
+	var definitions []*html.Node        // remove일 때 지울 요소 (각주 목록이면 목록 전체)
+	referenced := make(map[string]bool) // 본문에서 [n] 또는 <sup>n</sup>으로 가리킨 표시
+	add := func(n *html.Node, marker string) {
+		if marker == "" {
+			marker = strconv.Itoa(len(footnotes) + 1)
+		}
+		footnotes = append(footnotes, Footnote{Marker: marker, Text: footnoteText(n)})
+	}
+
+	walkHTML(root, func(c *html.Node) bool {
+		switch c.Type {
+		case html.TextNode:
+			for _, m := range footnoteTextMarkerPattern.FindAllStringSubmatch(c.Data, -1) {
+				referenced[m[1]] = true
+			}
+			return false
+		case html.ElementNode:
+		default:
+			return true
+		}
+
+		if isFootnoteContainer(c) {
+			items := footnoteItems(c)
+			if len(items) > 0 {
+				for _, item := range items {
+					add(item, footnoteIDMarker(attrValue(item, "id")))
+				}
+				definitions = append(definitions, c)
+				return false
+			}
+		}
+		if marker := footnoteIDMarker(attrValue(c, "id")); marker != "" || isFootnoteRole(c) {
+			add(c, marker)
+			definitions = append(definitions, c)
+			return false
+		}
+		if c.Data == "sup" {
+			if text := strings.Trim(strings.TrimSpace(textContent(c)), "[]"); text != "" {
+				referenced[text] = true
+			}
+			return false
+		}
+		if c.Data == "p" || c.Data == "li" {
+			text := textContent(c)
+			if m := footnoteTextMarkerPattern.FindStringSubmatchIndex(text); m != nil && strings.TrimSpace(text[:m[0]]) == "" {
+				if marker := text[m[2]:m[3]]; referenced[marker] {
+					add(c, marker)
+					definitions = append(definitions, c)
+					return false
+				}
+			}
+		}
+		return true
+	})
+
+	if !remove || len(footnotes) == 0 {
+		return content, footnotes, nil
+	}
+
+	for _, n := range definitions {
+		removeFootnoteSeparator(n)
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+	markers := make(map[string]bool, len(footnotes))
+	for _, f := range footnotes {
+		markers[f.Marker] = true
+	}
+	removeFootnoteReferences(root, markers)
+
+	var kept []*html.Node
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		kept = append(kept, c)
+	}
+	for _, c := range kept {
+		root.RemoveChild(c)
+	}
+	rendered, err := renderHTMLFragment(kept)
+	if err != nil {
+		return "", nil, err
+	}
+	return rendered, footnotes, nil
+}
+
+// footnoteIDMarker는 각주 정의 id에서 표시를 꺼냅니다 (예: "fn:1" → "1"). 각주 id가 아니면 ""를 반환합니다.
+func footnoteIDMarker(id string) string {
+	m := footnoteIDPattern.FindStringSubmatch(id)
+	if m == nil || strings.HasPrefix(strings.ToLower(m[1]), "ref") {
+		return ""
+	}
+	return m[1]
+}
+
+func isFootnoteRole(n *html.Node) bool {
+	role := attrValue(n, "role")
+	return role == "doc-footnote" || role == "doc-endnote"
+}
+
+func isFootnoteContainer(n *html.Node) bool {
+	if attrValue(n, "role") == "doc-endnotes" {
+		return true
+	}
+	for _, class := range strings.Fields(attrValue(n, "class")) {
+		if containsString(footnoteContainerClasses, strings.ToLower(class)) {
+			return true
+		}
+	}
+	return false
+}
+
+// footnoteItems는 각주 목록 안의 <li>를 반환합니다 (중첩된 목록의 항목은 제외)
+func footnoteItems(container *html.Node) []*html.Node {
+	var items []*html.Node
+	walkHTML(container, func(c *html.Node) bool {
+		if c.Type == html.ElementNode && c.Data == "li" {
+			items = append(items, c)
+			return false
+		}
+		return true
+	})
+	return items
+}
+
+// footnoteText는 각주 정의의 텍스트에서 앞의 표시와 되돌아가기 링크(↩)를 빼고 공백을 정리합니다
+func footnoteText(n *html.Node) string {
+	var b strings.Builder
+	walkHTML(n, func(c *html.Node) bool {
+		if c.Type == html.ElementNode && isFootnoteBackref(c) {
+			return false
+		}
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+			b.WriteByte(' ')
+		}
+		return true
+	})
+	text := strings.NewReplacer("↩︎", "", "↩", "").Replace(b.String())
+	text = footnoteLeadingMarkerPattern.ReplaceAllString(text, "")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func isFootnoteBackref(n *html.Node) bool {
+	if n.Data != "a" {
+		return false
+	}
+	href := strings.ToLower(attrValue(n, "href"))
+	return attrValue(n, "role") == "doc-backlink" || strings.Contains(attrValue(n, "class"), "backref") ||
+		strings.HasPrefix(href, "#fnref") || strings.HasPrefix(href, "#footnote-ref")
+}
+
+// removeFootnoteSeparator는 각주 목록 바로 앞의 구분선(<hr class="footnotes-sep">)을 지웁니다
+func removeFootnoteSeparator(n *html.Node) {
+	prev := n.PrevSibling
+	for prev != nil && prev.Type == html.TextNode && strings.TrimSpace(prev.Data) == "" {
+		prev = prev.PrevSibling
+	}
+	if prev != nil && prev.Type == html.ElementNode && prev.Data == "hr" &&
+		strings.Contains(attrValue(prev, "class"), "footnote") {
+		prev.Parent.RemoveChild(prev)
+	}
+}
+
+// removeFootnoteReferences는 본문에서 markers를 가리키는 <sup>, 각주 링크, [n] 표시를 지웁니다
+func removeFootnoteReferences(root *html.Node, markers map[string]bool) {
+	walkHTML(root, func(c *html.Node) bool {
+		switch c.Type {
+		case html.TextNode:
+			c.Data = footnoteTextMarkerPattern.ReplaceAllStringFunc(c.Data, func(m string) string {
+				if markers[strings.Trim(m, "[]")] {
+					return ""
+				}
+				return m
+			})
+			return false
+		case html.ElementNode:
+			if isFootnoteLink(c) || (c.Data == "sup" && isFootnoteSup(c, markers)) {
+				c.Parent.RemoveChild(c)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// isFootnoteLink는 각주 정의로 가는 링크(<a href="#fn1">)인지 확인합니다
+func isFootnoteLink(n *html.Node) bool {
+	if n.Data != "a" {
+		return false
+	}
+	href := attrValue(n, "href")
+	return attrValue(n, "role") == "doc-noteref" ||
+		(strings.HasPrefix(href, "#") && footnoteIDMarker(strings.TrimPrefix(href, "#")) != "")
+}
+
+// isFootnoteSup은 <sup>이 각주 참조인지 확인합니다.
+// x<sup>2</sup> 같은 위첨자를 지우지 않도록 각주 링크를 담았거나, class에 footnote가 있거나, "[n]" 형태일 때만 참조로 봅니다.
+func isFootnoteSup(n *html.Node, markers map[string]bool) bool {
+	if strings.Contains(attrValue(n, "class"), "footnote") {
+		return true
+	}
+	text := strings.TrimSpace(textContent(n))
+	if m := footnoteTextMarkerPattern.FindStringSubmatch(text); m != nil && m[0] == text && markers[m[1]] {
+		return true
+	}
+	link := false
+	walkHTML(n, func(c *html.Node) bool {
+		if c.Type == html.ElementNode && isFootnoteLink(c) {
+			link = true
+		}
+		return !link
+	})
+	return link
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractFootnotes(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		want        []Footnote
+		wantRemoved string // remove가 true일 때의 본문
+	}{
+		{
+			name: "markdown-it footnotes list",
+			content: `<p>본문<sup class="footnote-ref"><a href="#fn1" id="fnref1">[1]</a></sup></p>` +
+				`<hr class="footnotes-sep"><section class="footnotes"><ol class="footnotes-list">` +
+				`<li id="fn1" class="footnote-item"><p>첫 각주 <a href="#fnref1" class="footnote-backref">↩︎</a></p></li>` +
+				`</ol></section>`,
+			want:        []Footnote{{Marker: "1", Text: "첫 각주"}},
+			wantRemoved: `<p>본문</p>`,
+		},
+		{
+			name:        "footnote ids",
+			content:     `<p>본문<a href="#fn:a">a</a></p><div id="fn:a">설명</div><div id="fnref:a">참조</div>`,
+			want:        []Footnote{{Marker: "a", Text: "설명"}},
+			wantRemoved: `<p>본문</p><div id="fnref:a">참조</div>`,
+		},
+		{
+			name:        "typed markers",
+			content:     `<p>본문 [1] 계속</p><p>[1] 직접 쓴 각주</p>`,
+			want:        []Footnote{{Marker: "1", Text: "직접 쓴 각주"}},
+			wantRemoved: `<p>본문  계속</p>`,
+		},
+		{
+			name:        "unreferenced bracket paragraph is not a footnote",
+			content:     `<p>[2] 목록 항목</p>`,
+			want:        []Footnote{},
+			wantRemoved: `<p>[2] 목록 항목</p>`,
+		},
+		{
+			name:        "superscript is kept",
+			content:     `<p>x<sup>2</sup> 참고 [1]</p><p>[1] 각주</p>`,
+			want:        []Footnote{{Marker: "1", Text: "각주"}},
+			wantRemoved: `<p>x<sup>2</sup> 참고 </p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, footnotes, err := extractFootnotes(tt.content, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if content != tt.content {
+				t.Errorf("content changed without remove: %q", content)
+			}
+			if !reflect.DeepEqual(footnotes, tt.want) {
+				t.Errorf("footnotes = %+v, want %+v", footnotes, tt.want)
+			}
+
+			removed, _, err := extractFootnotes(tt.content, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if removed != tt.wantRemoved {
+				t.Errorf("removed content = %q, want %q", removed, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestFootnoteIDMarker(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"fn1", "1"},
+		{"fn:1", "1"},
+		{"footnote-2", "2"},
+		{"FN_note", "note"},
+		{"fnref1", ""},
+		{"fnref:1", ""},
+		{"content", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := footnoteIDMarker(tt.id); got != tt.want {
+			t.Errorf("footnoteIDMarker(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeFootnotes(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ContentOptions
+		wantErr bool
+	}{
+		{"extract", ContentOptions{ExtractFootnotes: true}, false},
+		{"extract and remove", ContentOptions{ExtractFootnotes: true, RemoveFootnotes: true}, false},
+		{"remove without extract", ContentOptions{RemoveFootnotes: true}, true},
+		{"metadata_only", ContentOptions{ExtractFootnotes: true, MetadataOnly: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.normalize(); (err != nil) != tt.wantErr {
+				t.Errorf("normalize = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
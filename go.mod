@@ -8,6 +8,8 @@ require (
 	github.com/go-chi/render v1.0.3
 	github.com/swaggo/http-swagger v1.3.4
 	github.com/swaggo/swag v1.8.12
+	golang.org/x/net v0.8.0
+	golang.org/x/sync v0.3.0
 )
 
 require (
@@ -20,7 +22,6 @@ require (
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/mailru/easyjson v0.7.6 // indirect
 	github.com/swaggo/files v0.0.0-20220610200504-28940afbdbfe // indirect
-	golang.org/x/net v0.8.0 // indirect
 	golang.org/x/sys v0.6.0 // indirect
 	golang.org/x/tools v0.7.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
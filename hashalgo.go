@@ -0,0 +1,110 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math/bits"
+)
+
+// content_hash 계산에 쓸 수 있는 알고리즘 (hash_algorithm, CONTENT_HASH_ALGORITHM)
+const (
+	HashSHA256 = "sha256"
+	HashSHA1   = "sha1"
+	HashMD5    = "md5"
+	HashXXHash = "xxhash" // XXH64, seed 0
+)
+
+// isValidHashAlgorithm은 지원하는 해시 알고리즘인지 확인합니다
+func isValidHashAlgorithm(algorithm string) bool {
+	return algorithm == HashSHA256 || algorithm == HashSHA1 || algorithm == HashMD5 || algorithm == HashXXHash
+}
+
+// hashHex는 data를 algorithm으로 해시한 hex 값을 반환합니다. 알 수 없는 알고리즘이면 SHA-256을 씁니다.
+func hashHex(algorithm string, data []byte) string {
+	switch algorithm {
+	case HashSHA1:
+		sum := sha1.Sum(data)
+		return hex.EncodeToString(sum[:])
+	case HashMD5:
+		sum := md5.Sum(data)
+		return hex.EncodeToString(sum[:])
+	case HashXXHash:
+		var sum [8]byte
+		// xxHash 표준 표기(big-endian)와 같게 씁니다
+		binary.BigEndian.PutUint64(sum[:], xxh64(data))
+		return hex.EncodeToString(sum[:])
+	default:
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+}
+
+// XXH64 상수
+const (
+	xxhPrime1 uint64 = 11400714785074694791
+	xxhPrime2 uint64 = 14029467366897019727
+	xxhPrime3 uint64 = 1609587929392839161
+	xxhPrime4 uint64 = 9650029242287828579
+	xxhPrime5 uint64 = 2870177450012600261
+)
+
+// xxh64는 seed 0의 XXH64 값입니다. 암호학적 해시가 아니며, xxHash를 키로 쓰는 외부 시스템과 맞추기 위해서만 씁니다.
+func xxh64(data []byte) uint64 {
+	n := len(data)
+	var h uint64
+	if n >= 32 {
+		// 상수식은 넘침을 허용하지 않으므로 변수에서 더하고 뺍니다
+		v1, v2, v3, v4 := xxhPrime1, xxhPrime2, uint64(0), uint64(0)
+		v1 += xxhPrime2
+		v4 -= xxhPrime1
+		for ; len(data) >= 32; data = data[32:] {
+			v1 = xxh64Round(v1, binary.LittleEndian.Uint64(data[0:8]))
+			v2 = xxh64Round(v2, binary.LittleEndian.Uint64(data[8:16]))
+			v3 = xxh64Round(v3, binary.LittleEndian.Uint64(data[16:24]))
+			v4 = xxh64Round(v4, binary.LittleEndian.Uint64(data[24:32]))
+		}
+		h = bits.RotateLeft64(v1, 1) + bits.RotateLeft64(v2, 7) + bits.RotateLeft64(v3, 12) + bits.RotateLeft64(v4, 18)
+		h = xxh64MergeRound(h, v1)
+		h = xxh64MergeRound(h, v2)
+		h = xxh64MergeRound(h, v3)
+		h = xxh64MergeRound(h, v4)
+	} else {
+		h = xxhPrime5
+	}
+	h += uint64(n)
+
+	for ; len(data) >= 8; data = data[8:] {
+		h ^= xxh64Round(0, binary.LittleEndian.Uint64(data))
+		h = bits.RotateLeft64(h, 27)*xxhPrime1 + xxhPrime4
+	}
+	if len(data) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(data)) * xxhPrime1
+		h = bits.RotateLeft64(h, 23)*xxhPrime2 + xxhPrime3
+		data = data[4:]
+	}
+	for _, b := range data {
+		h ^= uint64(b) * xxhPrime5
+		h = bits.RotateLeft64(h, 11) * xxhPrime1
+	}
+
+	h ^= h >> 33
+	h *= xxhPrime2
+	h ^= h >> 29
+	h *= xxhPrime3
+	h ^= h >> 32
+	return h
+}
+
+func xxh64Round(acc, input uint64) uint64 {
+	acc += input * xxhPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxhPrime1
+}
+
+func xxh64MergeRound(acc, val uint64) uint64 {
+	acc ^= xxh64Round(0, val)
+	return acc*xxhPrime1 + xxhPrime4
+}
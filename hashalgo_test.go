@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashHexKnownVectors(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		input     string
+		want      string
+	}{
+		{HashSHA256, "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{HashSHA256, "abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{HashSHA1, "", "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+		{HashSHA1, "abc", "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{HashMD5, "", "d41d8cd98f00b204e9800998ecf8427e"},
+		{HashMD5, "abc", "900150983cd24fb0d6963f7d28e17f72"},
+		{HashXXHash, "", "ef46db3751d8e999"},
+		{HashXXHash, "abc", "44bc2cf5ad770999"},
+		// 32바이트 이상이면 네 개의 누산기를 쓰는 경로를 거칩니다
+		{HashXXHash, "Nobody inspects the spammish repetition", "fbcea83c8a378bf1"},
+		// 알 수 없는 알고리즘은 SHA-256으로 계산합니다
+		{"crc32", "abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"", "", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+	for _, tt := range tests {
+		if got := hashHex(tt.algorithm, []byte(tt.input)); got != tt.want {
+			t.Errorf("hashHex(%q, %q) = %s, want %s", tt.algorithm, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestXXH64Lengths(t *testing.T) {
+	// 길이에 따라 8바이트, 4바이트, 1바이트 단위 처리와 32바이트 블록 처리가 섞이므로
+	// 경계 길이마다 값이 서로 다르고 같은 입력에는 항상 같은 값이 나오는지 확인합니다
+	input := []byte(strings.Repeat("0123456789abcdef", 5))
+	seen := make(map[uint64]int)
+	for _, n := range []int{1, 3, 4, 7, 8, 12, 31, 32, 33, 63, 64, 65, 80} {
+		sum := xxh64(input[:n])
+		if sum != xxh64(input[:n]) {
+			t.Errorf("xxh64 of %d bytes is not deterministic", n)
+		}
+		if prev, ok := seen[sum]; ok {
+			t.Errorf("xxh64 of %d and %d bytes collide", prev, n)
+		}
+		seen[sum] = n
+	}
+}
+
+func TestIsValidHashAlgorithm(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		want      bool
+	}{
+		{HashSHA256, true},
+		{HashSHA1, true},
+		{HashMD5, true},
+		{HashXXHash, true},
+		{"SHA256", false},
+		{"crc32", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isValidHashAlgorithm(tt.algorithm); got != tt.want {
+			t.Errorf("isValidHashAlgorithm(%q) = %v, want %v", tt.algorithm, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeHashAlgorithm(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		configDef string
+		want      string
+		wantErr   bool
+	}{
+		{"config default", "", HashMD5, HashMD5, false},
+		{"requested", HashXXHash, HashSHA256, HashXXHash, false},
+		{"unknown", "crc32", HashSHA256, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.ContentHashAlgorithm = tt.configDef })
+			opts := ContentOptions{Format: "html", HashAlgorithm: tt.requested}
+			err := opts.normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalize() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && opts.HashAlgorithm != tt.want {
+				t.Errorf("HashAlgorithm = %q, want %q", opts.HashAlgorithm, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseHashAlgorithm(t *testing.T) {
+	post := newTestPost("제목", "<p>본문</p>")
+	sha := renderTestPost(t, ContentOptions{Format: "html", HashAlgorithm: HashSHA256}, post)
+	for _, algorithm := range []string{HashSHA1, HashMD5, HashXXHash} {
+		response := renderTestPost(t, ContentOptions{Format: "html", HashAlgorithm: algorithm}, post)
+		if response.ContentHashAlgorithm != algorithm {
+			t.Errorf("content_hash_algorithm = %q, want %q", response.ContentHashAlgorithm, algorithm)
+		}
+		if response.ContentHash == sha.ContentHash {
+			t.Errorf("%s content_hash equals the sha256 hash", algorithm)
+		}
+		if again := renderTestPost(t, ContentOptions{Format: "html", HashAlgorithm: algorithm}, post); again.ContentHash != response.ContentHash {
+			t.Errorf("%s content_hash is not stable: %s vs %s", algorithm, response.ContentHash, again.ContentHash)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// 준비 상태 확인에 쓰는 업스트림 요청 제한 시간
+const readinessTimeout = 5 * time.Second
+
+// Validate는 현재 토큰으로 가벼운 인증 쿼리를 보내 BetterMode가 토큰을 받아들이는지 확인합니다.
+// 만료 시간만 보는 GetToken과 달리 폐기되었거나 잘못된 토큰도 잡아냅니다.
+func (tm *TokenManager) Validate(ctx context.Context) error {
+	token, err := tm.GetToken()
+	if err != nil {
+		return fmt.Errorf("error getting access token: %w", err)
+	}
+
+	resp, err := sendGraphQLRequest(ctx, token, `query { network { id } }`, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token rejected by BetterMode: HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
+	var validateResp struct {
+		Data struct {
+			Network struct {
+				ID string `json:"id"`
+			} `json:"network"`
+		} `json:"data"`
+		Errors []graphQLError `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &validateResp); err != nil {
+		return fmt.Errorf("error parsing response: %w", err)
+	}
+	if len(validateResp.Errors) > 0 {
+		return fmt.Errorf("token rejected by BetterMode: %s", validateResp.Errors[0].Message)
+	}
+	if validateResp.Data.Network.ID == "" {
+		return fmt.Errorf("token validation returned no network")
+	}
+	return nil
+}
+
+// handleHealthz는 프로세스가 살아 있는지만 확인하는 가벼운 헬스 체크입니다.
+// 과부하로 /api/v1 요청을 거절하는 중에도 항상 200을 반환합니다.
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, map[string]interface{}{
+		"status":    "ok",
+		"in_flight": inFlightRequests.Load(),
+		"circuit_breakers": map[string]BreakerStatus{
+			"content": upstreamBreaker.status(time.Now()),
+			"token":   tokenBreaker.status(time.Now()),
+		},
+	})
+}
+
+// handleReadyz는 토큰이 BetterMode에서 실제로 유효한지 확인해 준비 상태를 알려줍니다.
+// 준비되지 않았으면 503을 반환합니다. verbose=true이면 항목별 결과를 함께 반환합니다.
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if verbose, _ := strconv.ParseBool(r.URL.Query().Get("verbose")); verbose {
+		handleReadyzVerbose(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	if tokenManager == nil {
+		setRetryAfter(w, serviceInitializingRetryAfter)
+		render.Status(r, http.StatusServiceUnavailable)
+		render.JSON(w, r, map[string]string{
+			"status": "not_ready",
+			"error":  errServiceInitializing.Error(),
+		})
+		return
+	}
+	if err := tokenManager.Validate(ctx); err != nil {
+		log.Printf("Readiness check failed: %v", err)
+		setRetryAfter(w, readinessTimeout)
+		render.Status(r, http.StatusServiceUnavailable)
+		render.JSON(w, r, map[string]string{
+			"status": "not_ready",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	render.JSON(w, r, map[string]string{
+		"status": "ready",
+	})
+}
+
+// verbose 준비 상태 확인에서 항목마다 주는 제한 시간
+const readinessCheckTimeout = 2 * time.Second
+
+// readinessCheck는 verbose 준비 상태 확인의 한 항목입니다. 실패하면 이유를 담은 에러를 반환합니다.
+type readinessCheck struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// readinessChecks는 verbose=true일 때 확인하는 항목들입니다
+var readinessChecks = []readinessCheck{
+	{name: "token_valid", run: checkTokenValid},
+	{name: "upstream_reachable", run: checkUpstreamReachable},
+	{name: "cache_initialized", run: checkCacheInitialized},
+}
+
+// checkTokenValid는 현재 토큰을 BetterMode가 받아들이는지 확인합니다
+func checkTokenValid(ctx context.Context) error {
+	if tokenManager == nil {
+		return errServiceInitializing
+	}
+	return tokenManager.Validate(ctx)
+}
+
+// checkUpstreamReachable은 토큰 없이 BetterMode API에 요청을 보내 응답이 오는지 확인합니다.
+// 인증 에러(4xx)도 API가 응답한 것이므로 성공으로 보고, 5xx와 네트워크 에러만 실패로 봅니다.
+func checkUpstreamReachable(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", betterModeAPIURL, strings.NewReader(`{"query":"query { __typename }"}`))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GPTers-Scraper/1.0")
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("BetterMode returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// checkCacheInitialized는 콘텐츠 캐시가 만들어졌는지 확인합니다
+func checkCacheInitialized(ctx context.Context) error {
+	if contentCache == nil {
+		return errors.New("content cache is not initialized")
+	}
+	return nil
+}
+
+// runReadinessChecks는 checks를 동시에 실행하고 항목별 통과 여부와 실패 이유를 반환합니다.
+// 항목마다 timeout을 따로 주므로 느린 항목이 다른 항목의 결과를 막지 않습니다.
+func runReadinessChecks(ctx context.Context, checks []readinessCheck, timeout time.Duration) (map[string]bool, map[string]string) {
+	passed := make(map[string]bool, len(checks))
+	failures := make(map[string]string)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check readinessCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			err := check.run(checkCtx)
+			mutex.Lock()
+			defer mutex.Unlock()
+			passed[check.name] = err == nil
+			if err != nil {
+				failures[check.name] = err.Error()
+			}
+		}(check)
+	}
+	wg.Wait()
+	return passed, failures
+}
+
+// handleReadyzVerbose는 readinessChecks의 항목별 결과를 반환합니다. 하나라도 실패하면 503입니다.
+func handleReadyzVerbose(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	checks, failures := runReadinessChecks(r.Context(), readinessChecks, readinessCheckTimeout)
+	result := map[string]interface{}{
+		"status": "ready",
+		"checks": checks,
+	}
+	if len(failures) > 0 {
+		log.Printf("Readiness check failed: %v", failures)
+		result["status"] = "not_ready"
+		result["errors"] = failures
+		if tokenManager == nil {
+			setRetryAfter(w, serviceInitializingRetryAfter)
+		} else {
+			setRetryAfter(w, readinessTimeout)
+		}
+		render.Status(r, http.StatusServiceUnavailable)
+	}
+	render.JSON(w, r, result)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// networkUpstream은 토큰 확인 쿼리에 status와 body로 응답합니다
+func networkUpstream(t *testing.T, status int, body interface{}) {
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, status, body)
+	})
+}
+
+var validNetworkResponse = map[string]interface{}{"data": map[string]interface{}{"network": map[string]string{"id": "net-1"}}}
+
+func TestTokenManagerValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    interface{}
+		wantErr bool
+	}{
+		{"valid", http.StatusOK, validNetworkResponse, false},
+		{"rejected", http.StatusUnauthorized, map[string]interface{}{}, true},
+		{"graphql error", http.StatusOK, map[string]interface{}{"errors": []map[string]string{{"message": "forbidden"}}}, true},
+		{"no network", http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"network": nil}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			networkUpstream(t, tt.status, tt.body)
+			err := tokenManager.Validate(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleReadyz(t *testing.T) {
+	tests := []struct {
+		name       string
+		noManager  bool
+		status     int
+		wantStatus int
+	}{
+		{"ready", false, http.StatusOK, http.StatusOK},
+		{"token rejected", false, http.StatusUnauthorized, http.StatusServiceUnavailable},
+		{"initializing", true, http.StatusOK, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			if tt.noManager {
+				tokenManager = nil
+			}
+			networkUpstream(t, tt.status, validNetworkResponse)
+
+			w := httptest.NewRecorder()
+			handleReadyz(w, httptest.NewRequest("GET", "/readyz", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK && w.Header().Get("Retry-After") == "" {
+				t.Error("missing Retry-After on not ready response")
+			}
+		})
+	}
+}
+
+func TestHandleHealthzCircuitBreakers(t *testing.T) {
+	prevToken, prevContent := tokenBreaker, upstreamBreaker
+	tokenBreaker = newCircuitBreaker("BetterMode token endpoint", 1, time.Minute)
+	upstreamBreaker = nil
+	t.Cleanup(func() { tokenBreaker, upstreamBreaker = prevToken, prevContent })
+	tokenBreaker.record(false, time.Now())
+
+	rec := httptest.NewRecorder()
+	handleHealthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	var body struct {
+		CircuitBreakers map[string]BreakerStatus `json:"circuit_breakers"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if got := body.CircuitBreakers["token"].State; got != "open" {
+		t.Errorf("token breaker = %q, want open", got)
+	}
+	if got := body.CircuitBreakers["content"].State; got != "disabled" {
+		t.Errorf("content breaker = %q, want disabled", got)
+	}
+}
+
+func TestRunReadinessChecks(t *testing.T) {
+	checks := []readinessCheck{
+		{name: "ok", run: func(ctx context.Context) error { return nil }},
+		{name: "failing", run: func(ctx context.Context) error { return errors.New("broken") }},
+		{name: "slow", run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	}
+	start := time.Now()
+	passed, failures := runReadinessChecks(context.Background(), checks, 30*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("checks took %v, want the per-check timeout to stop the slow check", elapsed)
+	}
+
+	tests := []struct {
+		name        string
+		wantPassed  bool
+		wantFailure string
+	}{
+		{"ok", true, ""},
+		{"failing", false, "broken"},
+		{"slow", false, context.DeadlineExceeded.Error()},
+	}
+	for _, tt := range tests {
+		got, ok := passed[tt.name]
+		if !ok || got != tt.wantPassed {
+			t.Errorf("passed[%s] = %v (present %v), want %v", tt.name, got, ok, tt.wantPassed)
+		}
+		if failures[tt.name] != tt.wantFailure {
+			t.Errorf("failures[%s] = %q, want %q", tt.name, failures[tt.name], tt.wantFailure)
+		}
+	}
+}
+
+func TestCheckUpstreamReachable(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		netErr  bool
+		wantErr bool
+	}{
+		{"ok", http.StatusOK, false, false},
+		{"auth error still reachable", http.StatusUnauthorized, false, false},
+		{"server error", http.StatusBadGateway, false, true},
+		{"network error", 0, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := upstreamClient.Transport
+			upstreamClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				if r.Header.Get("Authorization") != "" {
+					t.Error("reachability check should not send a token")
+				}
+				if tt.netErr {
+					return nil, errors.New("connection refused")
+				}
+				rec := httptest.NewRecorder()
+				rec.WriteHeader(tt.status)
+				return rec.Result(), nil
+			})
+			t.Cleanup(func() { upstreamClient.Transport = prev })
+
+			if err := checkUpstreamReachable(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("checkUpstreamReachable() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleReadyzVerbose(t *testing.T) {
+	tests := []struct {
+		name       string
+		noManager  bool
+		noCache    bool
+		status     int
+		wantStatus int
+		wantChecks map[string]bool
+	}{
+		{"ready", false, false, http.StatusOK, http.StatusOK,
+			map[string]bool{"token_valid": true, "upstream_reachable": true, "cache_initialized": true}},
+		{"token rejected", false, false, http.StatusUnauthorized, http.StatusServiceUnavailable,
+			map[string]bool{"token_valid": false, "upstream_reachable": true, "cache_initialized": true}},
+		{"upstream down", false, false, http.StatusServiceUnavailable, http.StatusServiceUnavailable,
+			map[string]bool{"token_valid": false, "upstream_reachable": false, "cache_initialized": true}},
+		{"initializing", true, true, http.StatusOK, http.StatusServiceUnavailable,
+			map[string]bool{"token_valid": false, "upstream_reachable": true, "cache_initialized": false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			if tt.noManager {
+				tokenManager = nil
+			}
+			if tt.noCache {
+				contentCache = nil
+			}
+			networkUpstream(t, tt.status, validNetworkResponse)
+
+			rec := httptest.NewRecorder()
+			handleReadyz(rec, httptest.NewRequest(http.MethodGet, "/readyz?verbose=true", nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			var body struct {
+				Status string            `json:"status"`
+				Checks map[string]bool   `json:"checks"`
+				Errors map[string]string `json:"errors"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(body.Checks, tt.wantChecks) {
+				t.Errorf("checks = %v, want %v", body.Checks, tt.wantChecks)
+			}
+			for name, ok := range tt.wantChecks {
+				if _, hasError := body.Errors[name]; hasError == ok {
+					t.Errorf("errors[%s] present = %v, want %v", name, hasError, !ok)
+				}
+			}
+			wantReady := tt.wantStatus == http.StatusOK
+			if (body.Status == "ready") != wantReady {
+				t.Errorf("status field = %q", body.Status)
+			}
+			if (rec.Header().Get("Retry-After") != "") == wantReady {
+				t.Errorf("Retry-After = %q", rec.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// hedgeResult는 doHedged가 보낸 요청 하나의 결과입니다
+type hedgeResult struct {
+	index int
+	resp  *http.Response
+	err   error
+}
+
+// cancelOnClose는 본문을 닫을 때 요청 컨텍스트도 취소합니다.
+// 이긴 요청의 컨텍스트는 응답 본문을 다 읽을 때까지 살아 있어야 하므로 doHedged가 바로 취소하지 않습니다.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// doHedged는 newRequest로 만든 요청을 보냅니다. delay 안에 응답이 없으면 같은 요청을 한 번 더 보내고
+// 먼저 성공한 응답을 반환하며, 나머지 요청은 취소합니다. 읽기 쿼리에만 사용해야 합니다.
+// 두 요청이 모두 실패하면 나중에 끝난 요청의 에러를 반환합니다.
+func doHedged(ctx context.Context, client *http.Client, delay time.Duration, newRequest func(context.Context) (*http.Request, error)) (*http.Response, error) {
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	send := func() {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			req, err := newRequest(attemptCtx)
+			if err != nil {
+				results <- hedgeResult{index: index, err: err}
+				return
+			}
+			resp, err := client.Do(req)
+			results <- hedgeResult{index: index, resp: resp, err: err}
+		}()
+	}
+
+	send()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	pending := 1
+	for {
+		select {
+		case <-timer.C:
+			if len(cancels) == 1 {
+				pending++
+				send()
+			}
+		case r := <-results:
+			pending--
+			if r.err != nil {
+				cancels[r.index]()
+				if pending == 0 {
+					return nil, r.err
+				}
+				continue
+			}
+
+			// 진 요청은 취소하고, 그 사이 응답이 왔으면 본문을 닫습니다
+			for i, cancel := range cancels {
+				if i != r.index {
+					cancel()
+				}
+			}
+			if pending > 0 {
+				go func(n int) {
+					for ; n > 0; n-- {
+						if late := <-results; late.resp != nil {
+							late.resp.Body.Close()
+						}
+					}
+				}(pending)
+			}
+			r.resp.Body = cancelOnClose{ReadCloser: r.resp.Body, cancel: cancels[r.index]}
+			return r.resp, nil
+		}
+	}
+}
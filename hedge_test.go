@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoHedged(t *testing.T) {
+	tests := []struct {
+		name      string
+		slow      []bool // 요청 순서별로 컨텍스트가 취소될 때까지 기다리는지
+		fail      []bool // 요청 순서별로 에러를 반환하는지
+		wantBody  string
+		wantErr   bool
+		wantCalls int64
+	}{
+		{"fast response is not hedged", []bool{false, false}, []bool{false, false}, "0", false, 1},
+		{"slow response is hedged", []bool{true, false}, []bool{false, false}, "1", false, 2},
+		{"early failure is returned without hedging", []bool{false, false}, []bool{true, false}, "", true, 1},
+		{"both fail", []bool{true, true}, []bool{true, true}, "", true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int64
+			client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				i := atomic.AddInt64(&calls, 1) - 1
+				if tt.slow[i] {
+					select {
+					case <-r.Context().Done():
+						return nil, r.Context().Err()
+					case <-time.After(100 * time.Millisecond):
+					}
+				}
+				if tt.fail[i] {
+					return nil, errors.New("request " + strconv.FormatInt(i, 10) + " failed")
+				}
+				rec := httptest.NewRecorder()
+				rec.WriteString(strconv.FormatInt(i, 10))
+				return rec.Result(), nil
+			})}
+			newRequest := func(ctx context.Context) (*http.Request, error) {
+				return http.NewRequestWithContext(ctx, http.MethodPost, betterModeAPIURL, nil)
+			}
+
+			resp, err := doHedged(context.Background(), client, 20*time.Millisecond, newRequest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if string(body) != tt.wantBody {
+					t.Errorf("body = %q, want %q", body, tt.wantBody)
+				}
+			}
+			if got := atomic.LoadInt64(&calls); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDoHedgedCancelsLoser(t *testing.T) {
+	canceled := make(chan struct{})
+	var calls int64
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			<-r.Context().Done()
+			close(canceled)
+			return nil, r.Context().Err()
+		}
+		return httptest.NewRecorder().Result(), nil
+	})}
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPost, betterModeAPIURL, nil)
+	}
+
+	resp, err := doHedged(context.Background(), client, 10*time.Millisecond, newRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("slow request was not canceled after the hedge won")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// parseHTMLFragment는 게시물 본문처럼 <html>/<body>로 감싸지지 않은 HTML 조각을 파싱합니다
+func parseHTMLFragment(content string) ([]*html.Node, error) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	return html.ParseFragment(strings.NewReader(content), body)
+}
+
+// renderHTMLFragment는 파싱된 노드들을 다시 HTML 문자열로 만듭니다
+func renderHTMLFragment(nodes []*html.Node) (string, error) {
+	var b strings.Builder
+	for _, n := range nodes {
+		if err := html.Render(&b, n); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// walkHTML은 n과 그 하위 노드를 문서 순서대로 방문합니다. fn이 false를 반환하면 해당 노드의 자식은 건너뜁니다.
+func walkHTML(n *html.Node, fn func(*html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; {
+		// fn이 노드를 수정할 수 있으므로 다음 형제를 미리 기억해 둡니다
+		next := c.NextSibling
+		walkHTML(c, fn)
+		c = next
+	}
+}
+
+// textContent는 노드 하위의 모든 텍스트를 이어 붙여 반환합니다
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	walkHTML(n, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+		return true
+	})
+	return b.String()
+}
+
+// numberCodeLines는 <pre> 블록과 여러 줄짜리 <code> 블록의 각 줄 앞에 줄 번호를 붙입니다.
+// 코드 블록 밖의 본문은 그대로 둡니다.
+func numberCodeLines(content string) (string, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", err
+	}
+
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			if c.Type != html.ElementNode {
+				return true
+			}
+			if c.DataAtom != atom.Pre && !(c.DataAtom == atom.Code && strings.Contains(textContent(c), "\n")) {
+				return true
+			}
+
+			numbered := prefixLineNumbers(textContent(c))
+			for c.FirstChild != nil {
+				c.RemoveChild(c.FirstChild)
+			}
+			c.AppendChild(&html.Node{Type: html.TextNode, Data: numbered})
+			// 코드 블록 내부는 이미 처리했으므로 하위 노드는 방문하지 않습니다
+			return false
+		})
+	}
+
+	return renderHTMLFragment(nodes)
+}
+
+// prefixLineNumbers는 각 줄 앞에 "N | " 형태의 줄 번호를 붙입니다
+func prefixLineNumbers(code string) string {
+	lines := strings.Split(strings.TrimSuffix(code, "\n"), "\n")
+	width := len(fmt.Sprint(len(lines)))
+	for i, line := range lines {
+		lines[i] = fmt.Sprintf("%*d | %s", width, i+1, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,96 @@
+package main
+
+import "testing"
+
+func TestPrefixLineNumbers(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"single line", "fmt.Println()", "1 | fmt.Println()"},
+		{"trailing newline", "a\nb\n", "1 | a\n2 | b"},
+		{"pads to widest number", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10", " 1 | 1\n 2 | 2\n 3 | 3\n 4 | 4\n 5 | 5\n 6 | 6\n 7 | 7\n 8 | 8\n 9 | 9\n10 | 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prefixLineNumbers(tt.code); got != tt.want {
+				t.Errorf("prefixLineNumbers = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentToTextCodeLineNumbers(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		codeLineNumbers bool
+		want            string
+	}{
+		{"off", "<p>코드</p><pre>a := 1\nb := 2</pre>", false, "코드 a := 1\nb := 2"},
+		{"pre block", "<p>코드</p><pre>a := 1\nb := 2</pre>", true, "코드 1 | a := 1\n2 | b := 2"},
+		{"multi-line code", "<code>x &lt; y\nz</code>", true, "1 | x < y\n2 | z"},
+		{"inline code untouched", "<p>run <code>go test</code></p>", true, "run go test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := contentToText(tt.content, tt.codeLineNumbers)
+			if err != nil {
+				t.Fatalf("contentToText: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("contentToText = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripHTMLTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"plain", "hello", "hello"},
+		{"tags become spaces", "<p>a</p><p>b</p>", "a b"},
+		{"nbsp", "a&nbsp;&nbsp;b", "a b"},
+		{"unclosed tag drops the rest", "<p>a<b", "a"},
+		{"stray closing bracket", "a > b", "a b"},
+		{"blank lines collapsed", "a\n\nb", "a\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripHTMLTags(tt.content); got != tt.want {
+				t.Errorf("stripHTMLTags(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseTextFallback(t *testing.T) {
+	// HTML 파서는 잘못된 마크업도 고쳐서 읽으므로 이런 본문에서는 단순 태그 제거로 넘어가지 않습니다
+	tests := []struct {
+		name    string
+		opts    ContentOptions
+		profile string
+		content string
+		want    string
+	}{
+		{"unclosed tags", ContentOptions{Format: "text"}, "", "<p>본문<b>굵게", "본문 굵게"},
+		{"with toc", ContentOptions{Format: "text", IncludeTOC: true}, "", "<h2>제목</h2><div><p>본문", "제목 본문"},
+		{"with sanitize profile", ContentOptions{Format: "text"}, SanitizeStrict, "<p>본문</p><script>x()</script>", "본문"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.SanitizeDefaultProfile = tt.profile })
+			response := renderTestPost(t, tt.opts, newTestPost("제목", tt.content))
+			if response.Content != tt.want {
+				t.Errorf("content = %q, want %q", response.Content, tt.want)
+			}
+			if hasWarning(response.Warnings, WarningTextFallback) {
+				t.Errorf("unexpected text_fallback warning: %+v", response.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// imageProxyPath는 proxy_images가 이미지 URL을 바꿀 때 쓰는 경로입니다
+const imageProxyPath = "/api/v1/image-proxy"
+
+// 메모리 캐시에 담는 이미지 하나의 최대 바이트 수 (더 큰 이미지는 캐시하지 않고 전달만 합니다)
+const imageProxyCacheItemBytes = 1 << 20
+
+// imageHostAllowed는 rawURL이 http(s)이고 호스트가 IMAGE_PROXY_ALLOWED_HOSTS에 있는지 확인합니다.
+// 목록의 "*.example.com"은 example.com의 하위 도메인과 일치합니다 (example.com 자체는 따로 적어야 합니다).
+func imageHostAllowed(rawURL string, allowed []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return false
+	}
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// imageProxyURL은 rawURL을 이미지 프록시 주소로 바꿉니다 (IMAGE_PROXY_BASE_URL이 없으면 상대 경로)
+func imageProxyURL(rawURL, baseURL string) string {
+	return strings.TrimRight(baseURL, "/") + imageProxyPath + "?url=" + url.QueryEscape(rawURL)
+}
+
+// proxyImageURLs는 본문 <img>의 src 중 허용된 호스트의 URL을 이미지 프록시 주소로 바꿉니다.
+// 원본 주소가 드러나지 않도록 해당 이미지의 srcset은 지우고, 허용되지 않은 호스트의 이미지는 그대로 둡니다.
+func proxyImageURLs(content string, allowed []string, baseURL string) (string, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", err
+	}
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			if c.Type != html.ElementNode || c.Data != "img" {
+				return true
+			}
+			for i, attr := range c.Attr {
+				if !strings.EqualFold(attr.Key, "src") {
+					continue
+				}
+				if src := strings.TrimSpace(attr.Val); imageHostAllowed(src, allowed) {
+					c.Attr[i].Val = imageProxyURL(src, baseURL)
+					c.Attr = removeAttr(c.Attr, "srcset")
+				}
+				break
+			}
+			return true
+		})
+	}
+	return renderHTMLFragment(nodes)
+}
+
+// cachedImage는 이미지 프록시 캐시 항목입니다
+type cachedImage struct {
+	data        []byte
+	contentType string
+	expiresAt   time.Time
+}
+
+// imageProxyCache는 자주 요청되는 작은 이미지를 메모리에 보관합니다
+type imageProxyCache struct {
+	mutex   sync.Mutex
+	entries *lruMap[cachedImage]
+}
+
+func newImageProxyCache(maxEntries int) *imageProxyCache {
+	return &imageProxyCache{entries: newLRUMap[cachedImage](maxEntries)}
+}
+
+func (c *imageProxyCache) get(rawURL string) (cachedImage, bool) {
+	if c == nil {
+		return cachedImage{}, false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	image, ok := c.entries.get(rawURL)
+	if !ok || time.Now().After(image.expiresAt) {
+		return cachedImage{}, false
+	}
+	return image, true
+}
+
+func (c *imageProxyCache) set(rawURL string, image cachedImage) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries.set(rawURL, image)
+}
+
+// 이미지 프록시 메모리 캐시 (IMAGE_PROXY_CACHE_ENTRIES가 0이면 nil)
+var imageCache *imageProxyCache
+
+// imageProxyClient는 archiveClient와 같은 전송을 써서 프록시 없이 직접 연결하고 서버 내부망에는 연결하지 않으며,
+// 리다이렉트도 허용된 호스트로만 따라갑니다
+var imageProxyClient = &http.Client{
+	Timeout:   30 * time.Second,
+	Transport: archiveClient.Transport,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		if !imageHostAllowed(req.URL.String(), currentConfig().ImageProxyAllowedHosts) {
+			return fmt.Errorf("redirect to %s is not allowed", req.URL.Hostname())
+		}
+		return nil
+	},
+}
+
+// HandleImageProxy godoc
+// @Summary Proxy an image from an allowed host
+// @Description Fetches an image from a host allowed by IMAGE_PROXY_ALLOWED_HOSTS and streams it back with caching. Used by the URLs that proxy_images writes into content. Internal addresses, other hosts and non-image responses are refused.
+// @Tags content
+// @Produce image/*
+// @Param url query string true "Original image URL"
+// @Success 200 {file} file "The image"
+// @Failure 400 {string} string "Bad request"
+// @Failure 403 {string} string "Host not allowed by IMAGE_PROXY_ALLOWED_HOSTS"
+// @Failure 502 {string} string "The image could not be fetched, is too large or is not an image"
+// @Router /image-proxy [get]
+func handleImageProxy(w http.ResponseWriter, r *http.Request) {
+	cfg := currentConfig()
+	rawURL := r.URL.Query().Get("url")
+	if rawURL == "" {
+		writeError(w, "url is required", http.StatusBadRequest)
+		return
+	}
+	if !imageHostAllowed(rawURL, cfg.ImageProxyAllowedHosts) {
+		writeError(w, "url host is not allowed by IMAGE_PROXY_ALLOWED_HOSTS", http.StatusForbidden)
+		return
+	}
+
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(cfg.ImageProxyCacheTTL.Seconds()))
+	if image, ok := imageCache.get(rawURL); ok {
+		w.Header().Set("Content-Type", image.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(image.data)))
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Write(image.data)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, rawURL, nil)
+	if err != nil {
+		writeError(w, "Invalid url", http.StatusBadRequest)
+		return
+	}
+	req.Header.Set("User-Agent", "GPTers-Scraper/1.0")
+	req.Header.Set("Accept", "image/*")
+
+	resp, err := imageProxyClient.Do(req)
+	if err != nil {
+		log.Printf("Image proxy fetch of %s failed: %v", rawURL, err)
+		writeError(w, "Error fetching image", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		writeError(w, fmt.Sprintf("Error fetching image: HTTP %d", resp.StatusCode), http.StatusBadGateway)
+		return
+	}
+	// HTML 등을 우리 도메인에서 보여 주지 않도록 이미지만 전달합니다
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !strings.HasPrefix(mediaType, "image/") || mediaType == "image/svg+xml" {
+		writeError(w, fmt.Sprintf("Error fetching image: unsupported content type %q", mediaType), http.StatusBadGateway)
+		return
+	}
+	maxBytes := int64(cfg.ImageProxyMaxBytes)
+	if resp.ContentLength > maxBytes {
+		writeError(w, fmt.Sprintf("Image exceeds IMAGE_PROXY_MAX_BYTES (%d bytes)", maxBytes), http.StatusBadGateway)
+		return
+	}
+	// 길이를 모르면 한도보다 1바이트 더 읽어 보고, 넘으면 잘린 이미지를 보내거나 캐시하지 않고 502로 응답합니다
+	if resp.ContentLength < 0 {
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+		if err != nil {
+			log.Printf("Image proxy fetch of %s failed after %d bytes: %v", rawURL, len(data), err)
+			writeError(w, "Error fetching image", http.StatusBadGateway)
+			return
+		}
+		if int64(len(data)) > maxBytes {
+			writeError(w, fmt.Sprintf("Image exceeds IMAGE_PROXY_MAX_BYTES (%d bytes)", maxBytes), http.StatusBadGateway)
+			return
+		}
+		resp.ContentLength = int64(len(data))
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if resp.ContentLength >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(resp.ContentLength, 10))
+	}
+
+	// 작은 이미지는 전달하면서 메모리에도 모아 캐시합니다
+	body := io.LimitReader(resp.Body, maxBytes)
+	var buf *bytes.Buffer
+	if imageCache != nil && resp.ContentLength >= 0 && resp.ContentLength <= imageProxyCacheItemBytes {
+		buf = bytes.NewBuffer(make([]byte, 0, resp.ContentLength))
+		body = io.TeeReader(body, buf)
+	}
+	n, err := io.Copy(w, body)
+	if err != nil {
+		log.Printf("Image proxy copy of %s failed after %d bytes: %v", rawURL, n, err)
+		return
+	}
+	if buf != nil && n == resp.ContentLength {
+		imageCache.set(rawURL, cachedImage{data: buf.Bytes(), contentType: contentType, expiresAt: time.Now().Add(cfg.ImageProxyCacheTTL)})
+	}
+}
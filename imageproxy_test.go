@@ -0,0 +1,184 @@
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestImageProxyClientIgnoresProxy(t *testing.T) {
+	transport, ok := imageProxyClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("imageProxyClient transport = %T, want *http.Transport", imageProxyClient.Transport)
+	}
+	if transport.Proxy != nil {
+		t.Error("imageProxyClient uses a proxy; a proxy would bypass refuseInternalAddress")
+	}
+}
+
+func TestImageProxyRefusesInternalServer(t *testing.T) {
+	var hits int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&hits, 1)
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	}))
+	defer server.Close()
+	host, _, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	withConfig(t, func(cfg *Config) { cfg.ImageProxyAllowedHosts = []string{host} })
+
+	rec := httptest.NewRecorder()
+	handleImageProxy(rec, httptest.NewRequest(http.MethodGet, imageProxyPath+"?url="+url.QueryEscape(server.URL+"/a.png"), nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502", rec.Code)
+	}
+	if n := atomic.LoadInt64(&hits); n != 0 {
+		t.Errorf("internal server got %d requests, want 0", n)
+	}
+}
+
+func TestImageHostAllowed(t *testing.T) {
+	allowed := []string{"cdn.example.com", "*.images.example.com"}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://cdn.example.com/a.png", true},
+		{"http://CDN.example.com/a.png", true},
+		{"https://a.images.example.com/a.png", true},
+		{"https://images.example.com/a.png", false},
+		{"https://other.example.com/a.png", false},
+		{"https://user@cdn.example.com/a.png", false},
+		{"ftp://cdn.example.com/a.png", false},
+		{"/a.png", false},
+	}
+	for _, tt := range tests {
+		if got := imageHostAllowed(tt.url, allowed); got != tt.want {
+			t.Errorf("imageHostAllowed(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestProxyImageURLs(t *testing.T) {
+	content := `<img src="https://cdn.example.com/a.png" srcset="https://cdn.example.com/a@2x.png 2x"><img src="https://other.example.com/b.png" srcset="b@2x.png 2x">`
+	got, err := proxyImageURLs(content, []string{"cdn.example.com"}, "https://scraper.example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<img src="https://scraper.example.com/api/v1/image-proxy?url=https%3A%2F%2Fcdn.example.com%2Fa.png"/><img src="https://other.example.com/b.png" srcset="b@2x.png 2x"/>`
+	if got != want {
+		t.Errorf("proxyImageURLs =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHandleImageProxy(t *testing.T) {
+	withConfig(t, func(cfg *Config) {
+		cfg.ImageProxyAllowedHosts = []string{"cdn.example.com"}
+		cfg.ImageProxyMaxBytes = 10
+	})
+	prevCache, prevClient := imageCache, imageProxyClient
+	imageCache = nil
+	imageProxyClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		switch r.URL.Path {
+		case "/a.png":
+			rec.Header().Set("Content-Type", "image/png")
+			rec.WriteString("png")
+		case "/page.html":
+			rec.Header().Set("Content-Type", "text/html")
+			rec.WriteString("<p>page</p>")
+		case "/a.svg":
+			rec.Header().Set("Content-Type", "image/svg+xml")
+			rec.WriteString("<svg/>")
+		case "/big.png":
+			rec.Header().Set("Content-Type", "image/png")
+			rec.Header().Set("Content-Length", "11")
+			rec.WriteString(strings.Repeat("x", 11))
+		default:
+			rec.WriteHeader(http.StatusNotFound)
+		}
+		return rec.Result(), nil
+	})}
+	t.Cleanup(func() { imageCache, imageProxyClient = prevCache, prevClient })
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"image", "https://cdn.example.com/a.png", http.StatusOK},
+		{"missing url", "", http.StatusBadRequest},
+		{"host not allowed", "https://other.example.com/a.png", http.StatusForbidden},
+		{"not an image", "https://cdn.example.com/page.html", http.StatusBadGateway},
+		{"svg", "https://cdn.example.com/a.svg", http.StatusBadGateway},
+		{"too large", "https://cdn.example.com/big.png", http.StatusBadGateway},
+		{"upstream 404", "https://cdn.example.com/missing.png", http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handleImageProxy(rec, httptest.NewRequest(http.MethodGet, imageProxyPath+"?url="+url.QueryEscape(tt.url), nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status == http.StatusOK && (rec.Body.String() != "png" || rec.Header().Get("X-Content-Type-Options") != "nosniff") {
+				t.Errorf("body = %q, headers = %v", rec.Body.String(), rec.Header())
+			}
+		})
+	}
+}
+
+func TestHandleImageProxyUnknownLength(t *testing.T) {
+	withConfig(t, func(cfg *Config) {
+		cfg.ImageProxyAllowedHosts = []string{"cdn.example.com"}
+		cfg.ImageProxyMaxBytes = 10
+		cfg.ImageProxyCacheTTL = time.Minute
+	})
+	prevCache, prevClient := imageCache, imageProxyClient
+	imageCache = newImageProxyCache(10)
+	imageProxyClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body := "png"
+		if r.URL.Path == "/big.png" {
+			body = strings.Repeat("x", 11)
+		}
+		// Content-Length 없이 보내는 응답 (chunked 등)
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{"Content-Type": {"image/png"}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: -1,
+			Request:       r,
+		}, nil
+	})}
+	t.Cleanup(func() { imageCache, imageProxyClient = prevCache, prevClient })
+
+	tests := []struct {
+		url      string
+		status   int
+		body     string
+		wantKept bool
+	}{
+		{"https://cdn.example.com/a.png", http.StatusOK, "png", true},
+		{"https://cdn.example.com/big.png", http.StatusBadGateway, "", false},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handleImageProxy(rec, httptest.NewRequest(http.MethodGet, imageProxyPath+"?url="+url.QueryEscape(tt.url), nil))
+		if rec.Code != tt.status {
+			t.Fatalf("%s: status = %d, want %d: %s", tt.url, rec.Code, tt.status, rec.Body.String())
+		}
+		if tt.status == http.StatusOK && (rec.Body.String() != tt.body || rec.Header().Get("Content-Length") != strconv.Itoa(len(tt.body))) {
+			t.Errorf("%s: body = %q, headers = %v", tt.url, rec.Body.String(), rec.Header())
+		}
+		if _, kept := imageCache.get(tt.url); kept != tt.wantKept {
+			t.Errorf("%s: cached = %v, want %v", tt.url, kept, tt.wantKept)
+		}
+	}
+}
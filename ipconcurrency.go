@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ipConcurrency는 클라이언트 IP별로 처리 중인 요청 수를 셉니다.
+// 요청이 모두 끝난 IP는 맵에서 지우므로 맵 크기는 동시에 요청 중인 IP 수를 넘지 않습니다.
+type ipConcurrency struct {
+	active map[string]int
+	mutex  sync.Mutex
+}
+
+var clientConcurrency = &ipConcurrency{active: make(map[string]int)}
+
+// acquire는 ip의 처리 중인 요청이 limit보다 적으면 하나 늘리고 true를 반환합니다
+func (c *ipConcurrency) acquire(ip string, limit int) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.active[ip] >= limit {
+		return false
+	}
+	c.active[ip]++
+	return true
+}
+
+func (c *ipConcurrency) release(ip string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.active[ip] <= 1 {
+		delete(c.active, ip)
+		return
+	}
+	c.active[ip]--
+}
+
+// clientIP는 요청을 보낸 연결의 IP를 반환합니다 (프록시 헤더는 신뢰하지 않습니다)
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ipConcurrencyMiddleware는 한 IP가 동시에 처리 중인 요청이 PER_IP_MAX_CONCURRENT를 넘으면 429로 거절합니다.
+// 느린 요청을 많이 보내는 클라이언트 하나가 서버를 독점하지 못하게 합니다.
+func ipConcurrencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := currentConfig().PerIPMaxConcurrent
+		if limit <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := clientIP(r)
+		if !clientConcurrency.acquire(ip, limit) {
+			setRetryAfter(w, 0)
+			writeError(w, "Too many concurrent requests from this client", http.StatusTooManyRequests)
+			return
+		}
+		defer clientConcurrency.release(ip)
+		next.ServeHTTP(w, r)
+	})
+}
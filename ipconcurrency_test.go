@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestIPConcurrencyAcquireRelease(t *testing.T) {
+	c := &ipConcurrency{active: make(map[string]int)}
+	tests := []struct {
+		op   string // "acquire" 또는 "release"
+		ip   string
+		want bool
+	}{
+		{"acquire", "10.0.0.1", true},
+		{"acquire", "10.0.0.1", true},
+		{"acquire", "10.0.0.1", false}, // limit 2
+		{"acquire", "10.0.0.2", true},  // 다른 IP는 따로 셉니다
+		{"release", "10.0.0.1", true},
+		{"acquire", "10.0.0.1", true},
+	}
+	for i, tt := range tests {
+		if tt.op == "release" {
+			c.release(tt.ip)
+			continue
+		}
+		if got := c.acquire(tt.ip, 2); got != tt.want {
+			t.Errorf("step %d: acquire(%s) = %v, want %v", i, tt.ip, got, tt.want)
+		}
+	}
+
+	for _, ip := range []string{"10.0.0.1", "10.0.0.1", "10.0.0.2"} {
+		c.release(ip)
+	}
+	if len(c.active) != 0 {
+		t.Errorf("active = %v, want empty after every request released", c.active)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		remoteAddr string
+		want       string
+	}{
+		{"192.0.2.1:1234", "192.0.2.1"},
+		{"[2001:db8::1]:443", "2001:db8::1"},
+		{"192.0.2.1", "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		r.Header.Set("X-Forwarded-For", "203.0.113.9")
+		if got := clientIP(r); got != tt.want {
+			t.Errorf("clientIP(%q) = %q, want %q", tt.remoteAddr, got, tt.want)
+		}
+	}
+}
+
+func TestIPConcurrencyMiddleware(t *testing.T) {
+	withConfig(t, func(cfg *Config) { cfg.PerIPMaxConcurrent = 1 })
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := ipConcurrencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("block") != "" {
+			close(started)
+			<-release
+		}
+	}))
+	serve := func(target, remoteAddr string) int {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec.Code
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		serve("/?block=1", "192.0.2.1:1000")
+	}()
+	<-started
+
+	if code := serve("/", "192.0.2.1:2000"); code != http.StatusTooManyRequests {
+		t.Errorf("second request from the same IP: status = %d, want 429", code)
+	}
+	if code := serve("/", "192.0.2.2:1000"); code != http.StatusOK {
+		t.Errorf("request from another IP: status = %d, want 200", code)
+	}
+	close(release)
+	wg.Wait()
+	if code := serve("/", "192.0.2.1:3000"); code != http.StatusOK {
+		t.Errorf("request after the first finished: status = %d, want 200", code)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// FormatJSONLD는 본문을 schema.org Article JSON-LD 문서로 반환하는 형식입니다
+const FormatJSONLD = "jsonld"
+
+// jsonLDContentType은 /content, /url이 jsonld 형식 응답에 붙이는 Content-Type입니다
+const jsonLDContentType = "application/ld+json"
+
+// PostMember는 게시물 작성자입니다 (jsonld 형식에서만 조회)
+type PostMember struct {
+	Name string `json:"name"`
+}
+
+// PostOwner는 BetterMode 게시물의 owner 필드입니다
+type PostOwner struct {
+	Member *PostMember `json:"member"`
+}
+
+// articleJSONLD는 schema.org Article 구조화 데이터입니다
+type articleJSONLD struct {
+	Context       string        `json:"@context"`
+	Type          string        `json:"@type"`
+	Headline      string        `json:"headline"`
+	ArticleBody   string        `json:"articleBody"`
+	DatePublished string        `json:"datePublished,omitempty"`
+	DateModified  string        `json:"dateModified,omitempty"`
+	Author        *personJSONLD `json:"author,omitempty"`
+}
+
+type personJSONLD struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// includesAuthor는 작성자와 게시 시각을 조회해야 하는 요청인지 확인합니다
+func (o ContentOptions) includesAuthor() bool {
+	return o.Format == FormatJSONLD
+}
+
+// authorName은 게시물 작성자 이름을 반환합니다 (알 수 없으면 "")
+func (p *Post) authorName() string {
+	if p.Owner == nil || p.Owner.Member == nil {
+		return ""
+	}
+	return p.Owner.Member.Name
+}
+
+// articleJSONLDFor는 제목, 태그를 뺀 본문, 게시/수정 시각, 작성자로 Article JSON-LD 문서를 만듭니다
+func articleJSONLDFor(post *Post, headline, body string) (string, error) {
+	article := articleJSONLD{
+		Context:       "https://schema.org",
+		Type:          "Article",
+		Headline:      headline,
+		ArticleBody:   body,
+		DatePublished: post.PublishedAt,
+		DateModified:  post.UpdatedAt,
+	}
+	if name := post.authorName(); name != "" {
+		article.Author = &personJSONLD{Type: "Person", Name: name}
+	}
+
+	// 본문의 <, >, &를 \u003c 등으로 바꾸지 않도록 HTML 이스케이프를 끕니다
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(article); err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(buf.Bytes(), "\n")), nil
+}
+
+// jsonLDArticleBody는 JSON-LD 문서의 articleBody를 꺼냅니다 (min_chars 검사, 번역에 사용)
+func jsonLDArticleBody(document string) string {
+	var article articleJSONLD
+	if err := json.Unmarshal([]byte(document), &article); err != nil {
+		return ""
+	}
+	return article.ArticleBody
+}
+
+// writeJSONLD는 jsonld 형식 응답의 본문(JSON-LD 문서)을 그대로 씁니다
+func writeJSONLD(w http.ResponseWriter, response ContentResponse) {
+	w.Header().Set("Content-Type", jsonLDContentType)
+	w.Write([]byte(response.Content))
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestArticleJSONLDFor(t *testing.T) {
+	tests := []struct {
+		name string
+		post *Post
+		body string
+		want string
+	}{
+		{
+			name: "with author",
+			post: &Post{UpdatedAt: "2024-05-02T00:00:00Z", PublishedAt: "2024-05-01T00:00:00Z", Owner: &PostOwner{Member: &PostMember{Name: "홍길동"}}},
+			body: "a < b & c",
+			want: `{"@context":"https://schema.org","@type":"Article","headline":"제목","articleBody":"a < b & c",` +
+				`"datePublished":"2024-05-01T00:00:00Z","dateModified":"2024-05-02T00:00:00Z","author":{"@type":"Person","name":"홍길동"}}`,
+		},
+		{
+			name: "without author or dates",
+			post: &Post{Owner: &PostOwner{}},
+			body: "본문",
+			want: `{"@context":"https://schema.org","@type":"Article","headline":"제목","articleBody":"본문"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := articleJSONLDFor(tt.post, "제목", tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("articleJSONLDFor =\n%s\nwant\n%s", got, tt.want)
+			}
+			if body := jsonLDArticleBody(got); body != tt.body {
+				t.Errorf("jsonLDArticleBody = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestJSONLDArticleBodyInvalid(t *testing.T) {
+	if got := jsonLDArticleBody("not json"); got != "" {
+		t.Errorf("jsonLDArticleBody = %q, want empty", got)
+	}
+}
+
+func TestGetContentJSONLD(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		req := readGraphQLRequest(t, r)
+		if !strings.Contains(req.Query, "publishedAt") || !strings.Contains(req.Query, "owner {") {
+			t.Errorf("jsonld query does not select the author:\n%s", req.Query)
+		}
+		post := testPostJSON("제목", "<p>본문 <b>굵게</b></p>")
+		post["publishedAt"] = "2024-04-30T00:00:00Z"
+		post["owner"] = map[string]interface{}{"member": map[string]string{"name": "홍길동"}}
+		writeJSONResponse(w, http.StatusOK, postData(post))
+	})
+
+	rec := httptest.NewRecorder()
+	getContent(rec, httptest.NewRequest(http.MethodGet, "/api/v1/content?post_id=post-1&format=jsonld", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != jsonLDContentType {
+		t.Errorf("Content-Type = %q, want %q", got, jsonLDContentType)
+	}
+	var article articleJSONLD
+	if err := json.NewDecoder(rec.Body).Decode(&article); err != nil {
+		t.Fatal(err)
+	}
+	if article.Type != "Article" || article.Headline != "제목" || article.Author == nil || article.Author.Name != "홍길동" {
+		t.Errorf("article = %+v", article)
+	}
+	if strings.Contains(article.ArticleBody, "<") || !strings.Contains(article.ArticleBody, "굵게") {
+		t.Errorf("articleBody = %q, want text without tags", article.ArticleBody)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Link는 본문의 링크 하나입니다
+type Link struct {
+	URL      string `json:"url"`
+	Text     string `json:"text"`
+	Internal bool   `json:"internal"` // 상대 URL이거나 커뮤니티 도메인(TOKEN_NETWORK_DOMAINS)의 링크
+}
+
+// extractLinks는 본문의 <a href>를 문서 순서대로 반환합니다. 같은 URL은 처음 나온 것만 남기고,
+// 본문 안 anchor("#section")는 게시물 밖을 가리키지 않으므로 건너뜁니다.
+func extractLinks(content string, domains []string) ([]Link, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return nil, err
+	}
+
+	links := []Link{}
+	seen := make(map[string]bool)
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			if c.Type != html.ElementNode || c.Data != "a" {
+				return true
+			}
+			href := strings.TrimSpace(attrValue(c, "href"))
+			if href == "" || strings.HasPrefix(href, "#") || seen[href] {
+				return true
+			}
+			seen[href] = true
+			links = append(links, Link{URL: href, Text: collapseSpaces(textContent(c)), Internal: isInternalLink(href, domains)})
+			return true
+		})
+	}
+	return links, nil
+}
+
+// isInternalLink는 href가 상대 URL이거나 호스트가 domains 중 하나인지 확인합니다
+func isInternalLink(href string, domains []string) bool {
+	u, err := url.Parse(href)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		return true
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	for _, domain := range domains {
+		if strings.EqualFold(u.Hostname(), domain) {
+			return true
+		}
+	}
+	return false
+}
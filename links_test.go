@@ -0,0 +1,107 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsInternalLink(t *testing.T) {
+	domains := []string{"community.example.com"}
+	tests := []struct {
+		href string
+		want bool
+	}{
+		{"/post/123", true},
+		{"post/123", true},
+		{"?page=2", true},
+		{"https://community.example.com/post/123", true},
+		{"http://Community.Example.com/post/123", true},
+		{"https://community.example.com:8443/post/123", true},
+		{"https://other.example.com/post/123", false},
+		{"//other.example.com/a", false},
+		{"//community.example.com/a", true},
+		{"mailto:user@community.example.com", false},
+		{"javascript:alert(1)", false},
+		{"https://community.example.com%zz/", false},
+	}
+	for _, tt := range tests {
+		if got := isInternalLink(tt.href, domains); got != tt.want {
+			t.Errorf("isInternalLink(%q) = %v, want %v", tt.href, got, tt.want)
+		}
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	domains := []string{"community.example.com"}
+	tests := []struct {
+		name    string
+		content string
+		want    []Link
+	}{
+		{"no links", "<p>본문</p>", []Link{}},
+		{
+			"document order",
+			`<p><a href="https://other.example.com/a">외부</a> 그리고 <a href="/post/1">내부  <b>링크</b></a></p>`,
+			[]Link{
+				{URL: "https://other.example.com/a", Text: "외부", Internal: false},
+				{URL: "/post/1", Text: "내부 링크", Internal: true},
+			},
+		},
+		{
+			"duplicate url keeps first",
+			`<a href="/post/1">처음</a><a href=" /post/1 ">다시</a>`,
+			[]Link{{URL: "/post/1", Text: "처음", Internal: true}},
+		},
+		{
+			"skips anchors and empty href",
+			`<a href="#section">목차</a><a href="">빈 링크</a><a name="top">이름</a><a href="https://community.example.com/p">글</a>`,
+			[]Link{{URL: "https://community.example.com/p", Text: "글", Internal: true}},
+		},
+		{
+			"nested",
+			`<ul><li><a href="mailto:a@example.com">메일</a></li></ul>`,
+			[]Link{{URL: "mailto:a@example.com", Text: "메일", Internal: false}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractLinks(tt.content, domains)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractLinks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseIncludeLinks(t *testing.T) {
+	withConfig(t, func(cfg *Config) { cfg.TokenNetworkDomains = []string{"community.example.com"} })
+	post := newTestPost("제목", `<p>앞부분</p><p><a href="/post/1">내부</a></p>`)
+	tests := []struct {
+		name string
+		opts ContentOptions
+		want []Link
+	}{
+		{"disabled", ContentOptions{Format: "html"}, nil},
+		{"relative", ContentOptions{Format: "html", IncludeLinks: true}, []Link{{URL: "/post/1", Text: "내부", Internal: true}}},
+		{"absolute_links", ContentOptions{Format: "html", IncludeLinks: true, AbsoluteLinks: true},
+			[]Link{{URL: "https://community.example.com/post/1", Text: "내부", Internal: true}}},
+		{"before preview cut", ContentOptions{Format: "html", IncludeLinks: true, AutoPreview: 3},
+			[]Link{{URL: "/post/1", Text: "내부", Internal: true}}},
+		{"text format", ContentOptions{Format: "text", IncludeLinks: true}, []Link{{URL: "/post/1", Text: "내부", Internal: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderTestPost(t, tt.opts, post).Links; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Links = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	opts := ContentOptions{Format: "html", MetadataOnly: true, IncludeLinks: true}
+	if err := opts.normalize(); err == nil {
+		t.Error("normalize() should reject include_links with metadata_only")
+	}
+}
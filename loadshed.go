@@ -0,0 +1,78 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 업스트림 지연 측정값을 과부하 판단에 쓰는 기간. 이보다 오래된 값은 무시해,
+// 요청을 모두 거절하는 동안 예전 지연 값 때문에 계속 거절하지 않도록 합니다.
+const upstreamLatencyWindow = 10 * time.Second
+
+// upstreamLatencyTracker는 BetterMode 호출 지연의 지수 이동 평균(EWMA)을 추적합니다
+type upstreamLatencyTracker struct {
+	ewma     time.Duration
+	lastSeen time.Time
+	mutex    sync.Mutex
+}
+
+var upstreamLatency = &upstreamLatencyTracker{}
+
+// observe는 호출 한 번의 지연을 평균에 반영합니다
+func (t *upstreamLatencyTracker) observe(d time.Duration, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.lastSeen.IsZero() || now.Sub(t.lastSeen) > upstreamLatencyWindow {
+		t.ewma = d
+	} else {
+		t.ewma = (t.ewma*4 + d) / 5
+	}
+	t.lastSeen = now
+}
+
+// current는 최근 평균 지연을 반환합니다. 최근 측정값이 없으면 0입니다.
+func (t *upstreamLatencyTracker) current(now time.Time) time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.lastSeen.IsZero() || now.Sub(t.lastSeen) > upstreamLatencyWindow {
+		return 0
+	}
+	return t.ewma
+}
+
+// 처리 중인 /api/v1 요청 수
+var inFlightRequests atomic.Int64
+
+// loadShedder는 서버가 과부하일 때 새 요청을 503으로 거절합니다.
+// 처리 중인 요청이 SHED_MAX_IN_FLIGHT를 넘거나, 최근 BetterMode 평균 지연이 SHED_UPSTREAM_LATENCY를 넘으면 과부하로 봅니다.
+// /healthz, /readyz처럼 이 미들웨어 밖의 경로는 계속 응답합니다.
+func loadShedder(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight := inFlightRequests.Add(1)
+		defer inFlightRequests.Add(-1)
+
+		if reason := overloadReason(inFlight, time.Now()); reason != "" {
+			log.Printf("Shedding %s %s: %s", r.Method, r.URL.Path, reason)
+			setRetryAfter(w, time.Second)
+			writeError(w, "Server is overloaded, try again later", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// overloadReason은 과부하이면 그 이유를, 아니면 빈 문자열을 반환합니다
+func overloadReason(inFlight int64, now time.Time) string {
+	if max := currentConfig().ShedMaxInFlight; max > 0 && inFlight > int64(max) {
+		return "too many in-flight requests"
+	}
+	if threshold := currentConfig().ShedUpstreamLatency; threshold > 0 && upstreamLatency.current(now) > threshold {
+		return "upstream latency above threshold"
+	}
+	return ""
+}
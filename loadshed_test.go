@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// withUpstreamLatency는 테스트가 끝날 때까지 새 지연 추적기를 씁니다
+func withUpstreamLatency(t *testing.T) *upstreamLatencyTracker {
+	t.Helper()
+	prev := upstreamLatency
+	upstreamLatency = &upstreamLatencyTracker{}
+	t.Cleanup(func() { upstreamLatency = prev })
+	return upstreamLatency
+}
+
+func TestUpstreamLatencyTracker(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tracker := &upstreamLatencyTracker{}
+	if got := tracker.current(start); got != 0 {
+		t.Errorf("current before any observation = %v, want 0", got)
+	}
+
+	tracker.observe(time.Second, start)
+	tracker.observe(6*time.Second, start.Add(time.Second))
+	if got := tracker.current(start.Add(time.Second)); got != 2*time.Second {
+		t.Errorf("ewma = %v, want 2s", got)
+	}
+	if got := tracker.current(start.Add(time.Second + upstreamLatencyWindow + time.Millisecond)); got != 0 {
+		t.Errorf("current after window = %v, want 0", got)
+	}
+
+	// 기간이 지난 뒤의 측정값은 예전 평균과 섞지 않습니다
+	later := start.Add(time.Minute)
+	tracker.observe(100*time.Millisecond, later)
+	if got := tracker.current(later); got != 100*time.Millisecond {
+		t.Errorf("ewma after gap = %v, want 100ms", got)
+	}
+}
+
+func TestLoadShedderInFlight(t *testing.T) {
+	withConfig(t, func(cfg *Config) {
+		cfg.ShedMaxInFlight = 2
+		cfg.ShedUpstreamLatency = 0
+	})
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	handler := loadShedder(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("block") != "" {
+			entered <- struct{}{}
+			<-release
+		}
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/content?block=1", nil))
+		}()
+		<-entered
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/content", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("request above high-water mark = %d (Retry-After %q), want 503 with Retry-After 1", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	health := httptest.NewRecorder()
+	handleHealthz(health, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if health.Code != http.StatusOK {
+		t.Errorf("healthz while shedding = %d, want 200", health.Code)
+	}
+
+	close(release)
+	wg.Wait()
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/content", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("request after load drops = %d, want 200", rec.Code)
+	}
+}
+
+func TestOverloadReason(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		maxInFlight int
+		threshold   time.Duration
+		inFlight    int64
+		latency     time.Duration
+		wantShed    bool
+	}{
+		{"disabled", 0, 0, 1000, time.Minute, false},
+		{"at high-water mark", 5, 0, 5, 0, false},
+		{"above high-water mark", 5, 0, 6, 0, true},
+		{"latency below threshold", 0, time.Second, 1, 500 * time.Millisecond, false},
+		{"latency above threshold", 0, time.Second, 1, 2 * time.Second, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) {
+				cfg.ShedMaxInFlight = tt.maxInFlight
+				cfg.ShedUpstreamLatency = tt.threshold
+			})
+			tracker := withUpstreamLatency(t)
+			if tt.latency > 0 {
+				tracker.observe(tt.latency, now)
+			}
+			if got := overloadReason(tt.inFlight, now) != ""; got != tt.wantShed {
+				t.Errorf("shed = %v, want %v", got, tt.wantShed)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// LOG_LEVEL 값. debug는 요청마다의 진단 로그(캐시 적중 등)까지 남기고, info는 기본 로그를,
+// warn은 성공 응답의 요청 로그를 빼고 에러 응답과 운영 로그만 남깁니다.
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+)
+
+// logLevels는 로그 수준의 순서입니다 (zero value가 info)
+var logLevels = map[string]int32{
+	LogLevelDebug: -1,
+	LogLevelInfo:  0,
+	LogLevelWarn:  1,
+}
+
+// logLevel은 현재 로그 수준입니다. 시작 시와 설정을 다시 읽을 때 LOG_LEVEL로 바꿉니다.
+var logLevel atomic.Int32
+
+func setLogLevel(level string) {
+	logLevel.Store(logLevels[level])
+}
+
+// logEnabled는 level 수준의 로그를 남길지 반환합니다
+func logEnabled(level string) bool {
+	return logLevels[level] >= logLevel.Load()
+}
+
+// logDebugf는 LOG_LEVEL이 debug일 때만 로그를 남깁니다
+func logDebugf(format string, args ...interface{}) {
+	if logEnabled(LogLevelDebug) {
+		log.Printf("DEBUG "+format, args...)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// withLogLevel은 테스트가 끝날 때까지 로그 수준을 level로 둡니다
+func withLogLevel(t *testing.T, level string) {
+	t.Helper()
+	prev := logLevel.Load()
+	setLogLevel(level)
+	t.Cleanup(func() { logLevel.Store(prev) })
+}
+
+func TestLogEnabled(t *testing.T) {
+	tests := []struct {
+		current string
+		level   string
+		want    bool
+	}{
+		{LogLevelDebug, LogLevelDebug, true},
+		{LogLevelDebug, LogLevelWarn, true},
+		{LogLevelInfo, LogLevelDebug, false},
+		{LogLevelInfo, LogLevelInfo, true},
+		{LogLevelWarn, LogLevelInfo, false},
+		{LogLevelWarn, LogLevelWarn, true},
+	}
+	for _, tt := range tests {
+		withLogLevel(t, tt.current)
+		if got := logEnabled(tt.level); got != tt.want {
+			t.Errorf("at %s, logEnabled(%s) = %v, want %v", tt.current, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestLogDebugf(t *testing.T) {
+	for _, level := range []string{LogLevelDebug, LogLevelInfo} {
+		withLogLevel(t, level)
+		buf := captureLog(t)
+		logDebugf("cache hit for %s", "post-1")
+		if got, want := buf.Len() > 0, level == LogLevelDebug; got != want {
+			t.Errorf("at %s, logged %q", level, buf.String())
+		}
+	}
+}
+
+func TestSampledLogFormatterLogLevel(t *testing.T) {
+	tests := []struct {
+		level     string
+		status    int
+		wantLines int
+	}{
+		{LogLevelInfo, http.StatusOK, 1},
+		{LogLevelWarn, http.StatusOK, 0},
+		{LogLevelWarn, http.StatusInternalServerError, 1},
+	}
+	for _, tt := range tests {
+		withSampleRate(t, 1)
+		withLogLevel(t, tt.level)
+		base := &countingLogFormatter{}
+		f := &sampledLogFormatter{base: base}
+		f.NewLogEntry(httptest.NewRequest("GET", "/", nil)).Write(tt.status, 0, nil, 0, nil)
+		if base.writes != tt.wantLines {
+			t.Errorf("at %s, status %d logged %d lines, want %d", tt.level, tt.status, base.writes, tt.wantLines)
+		}
+	}
+}
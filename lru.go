@@ -0,0 +1,105 @@
+package main
+
+import "container/list"
+
+// lruMap은 최대 항목 수가 정해진 LRU 맵입니다. 가득 찬 상태에서 새 키를 넣으면 가장 오래 사용하지 않은 항목을 지웁니다.
+// 동시성 보호는 하지 않으므로 호출자가 잠금을 잡아야 합니다 (조회도 사용 순서를 바꾸므로 쓰기 잠금 필요).
+type lruMap[V any] struct {
+	items      map[string]*list.Element
+	order      *list.List // 앞쪽이 가장 최근에 사용한 항목
+	maxEntries int        // 0 이하이면 제한 없음
+}
+
+type lruItem[V any] struct {
+	key   string
+	value V
+}
+
+func newLRUMap[V any](maxEntries int) *lruMap[V] {
+	return &lruMap[V]{
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+// get은 값을 반환하고 가장 최근에 사용한 항목으로 표시합니다
+func (m *lruMap[V]) get(key string) (V, bool) {
+	elem, ok := m.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	m.order.MoveToFront(elem)
+	return elem.Value.(*lruItem[V]).value, true
+}
+
+// set은 값을 저장하고 최대 항목 수를 넘어 지운 항목 수를 반환합니다
+func (m *lruMap[V]) set(key string, value V) int {
+	if elem, ok := m.items[key]; ok {
+		elem.Value.(*lruItem[V]).value = value
+		m.order.MoveToFront(elem)
+		return 0
+	}
+	m.items[key] = m.order.PushFront(&lruItem[V]{key: key, value: value})
+	return m.trim()
+}
+
+// setMaxEntries는 최대 항목 수를 바꾸고, 줄어들었으면 넘치는 항목을 지워 그 수를 반환합니다
+func (m *lruMap[V]) setMaxEntries(maxEntries int) int {
+	m.maxEntries = maxEntries
+	return m.trim()
+}
+
+func (m *lruMap[V]) trim() int {
+	evicted := 0
+	for m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.items, oldest.Value.(*lruItem[V]).key)
+		evicted++
+	}
+	return evicted
+}
+
+// removeIf는 remove가 true를 반환하는 항목을 지우고 그 수를 반환합니다 (사용 순서는 바꾸지 않음)
+func (m *lruMap[V]) removeIf(remove func(V) bool) int {
+	removed := 0
+	for elem := m.order.Front(); elem != nil; {
+		next := elem.Next()
+		item := elem.Value.(*lruItem[V])
+		if remove(item.value) {
+			m.order.Remove(elem)
+			delete(m.items, item.key)
+			removed++
+		}
+		elem = next
+	}
+	return removed
+}
+
+// remove는 key 항목을 지우고, 있었으면 true를 반환합니다
+func (m *lruMap[V]) remove(key string) bool {
+	elem, ok := m.items[key]
+	if !ok {
+		return false
+	}
+	m.order.Remove(elem)
+	delete(m.items, key)
+	return true
+}
+
+// removeKeysIf는 remove가 true를 반환하는 key의 항목을 지우고 그 수를 반환합니다
+func (m *lruMap[V]) removeKeysIf(remove func(key string) bool) int {
+	removed := 0
+	for key := range m.items {
+		if remove(key) && m.remove(key) {
+			removed++
+		}
+	}
+	return removed
+}
+
+func (m *lruMap[V]) len() int {
+	return m.order.Len()
+}
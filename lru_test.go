@@ -0,0 +1,88 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// lruKeys는 최근에 사용한 순서대로 키를 반환합니다
+func lruKeys[V any](m *lruMap[V]) []string {
+	var keys []string
+	for elem := m.order.Front(); elem != nil; elem = elem.Next() {
+		keys = append(keys, elem.Value.(*lruItem[V]).key)
+	}
+	return keys
+}
+
+func TestLRUMap(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxEntries  int
+		ops         []string // "set:key", "get:key", "remove:key"
+		wantKeys    []string
+		wantEvicted int
+	}{
+		{"unbounded", 0, []string{"set:a", "set:b", "set:c"}, []string{"c", "b", "a"}, 0},
+		{"evicts least recently set", 2, []string{"set:a", "set:b", "set:c"}, []string{"c", "b"}, 1},
+		{"get refreshes", 2, []string{"set:a", "set:b", "get:a", "set:c"}, []string{"c", "a"}, 1},
+		{"overwrite does not evict", 2, []string{"set:a", "set:b", "set:a"}, []string{"a", "b"}, 0},
+		{"missing get changes nothing", 2, []string{"set:a", "get:z", "set:b"}, []string{"b", "a"}, 0},
+		{"remove frees a slot", 2, []string{"set:a", "set:b", "remove:a", "set:c"}, []string{"c", "b"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newLRUMap[int](tt.maxEntries)
+			evicted := 0
+			for i, op := range tt.ops {
+				action, key, _ := strings.Cut(op, ":")
+				switch action {
+				case "set":
+					evicted += m.set(key, i)
+				case "get":
+					m.get(key)
+				case "remove":
+					m.remove(key)
+				}
+			}
+			if got := lruKeys(m); !reflect.DeepEqual(got, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", got, tt.wantKeys)
+			}
+			if evicted != tt.wantEvicted {
+				t.Errorf("evicted = %d, want %d", evicted, tt.wantEvicted)
+			}
+			if m.len() != len(m.items) {
+				t.Errorf("len = %d, items = %d", m.len(), len(m.items))
+			}
+		})
+	}
+}
+
+func TestLRUMapSetMaxEntries(t *testing.T) {
+	m := newLRUMap[int](0)
+	for i, key := range []string{"a", "b", "c", "d"} {
+		m.set(key, i)
+	}
+	if evicted := m.setMaxEntries(2); evicted != 2 {
+		t.Errorf("setMaxEntries evicted %d, want 2", evicted)
+	}
+	if got := lruKeys(m); !reflect.DeepEqual(got, []string{"d", "c"}) {
+		t.Errorf("keys = %v, want [d c]", got)
+	}
+}
+
+func TestLRUMapRemoveIf(t *testing.T) {
+	m := newLRUMap[int](0)
+	for i, key := range []string{"a", "b", "c", "d"} {
+		m.set(key, i)
+	}
+	if removed := m.removeIf(func(v int) bool { return v%2 == 0 }); removed != 2 {
+		t.Errorf("removeIf removed %d, want 2", removed)
+	}
+	if removed := m.removeKeysIf(func(key string) bool { return key == "d" }); removed != 1 {
+		t.Errorf("removeKeysIf removed %d, want 1", removed)
+	}
+	if got := lruKeys(m); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("keys = %v, want [b]", got)
+	}
+}
@@ -2,16 +2,24 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	_ "gpters_scrap/docs"
 
@@ -20,6 +28,8 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/go-chi/render"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"golang.org/x/net/html"
+	"golang.org/x/sync/singleflight"
 )
 
 // @title BetterMode API Scraper
@@ -31,25 +41,38 @@ import (
 // @host localhost:8080
 // @schemes http
 
-// TokenManager 구조체는 BetterMode API 토큰을 관리합니다
+// TokenManager 구조체는 BetterMode API 토큰을 관리합니다.
+// 토큰 소스(네트워크 도메인)가 여러 개이면 활성 소스가 연속으로 실패할 때 다음 소스로 넘어갑니다.
 type TokenManager struct {
-	accessToken   string
-	expiry        time.Time
-	networkDomain string
-	mutex         sync.RWMutex
+	accessToken string
+	expiry      time.Time
+	scopes      []string // 현재 토큰에 부여된 권한 범위 (TOKEN_SCOPES를 요청했을 때만, BetterMode가 알려 준 값)
+	sources     []*tokenSource
+	active      int // 현재 토큰을 발급받는 sources의 인덱스
+	mutex       sync.RWMutex
 }
 
-// NewTokenManager는 TokenManager 인스턴스를 생성하고 초기화합니다
-func NewTokenManager(networkDomain string) *TokenManager {
-	tm := &TokenManager{
-		networkDomain: networkDomain,
+// NewTokenManager는 TokenManager 인스턴스를 생성하고 초기화합니다.
+// networkDomains는 우선순위 순서의 토큰 소스 목록이며 첫 번째가 기본 소스입니다.
+// failOnInitialError가 true이면 초기 토큰 발급 실패 시 에러를 반환하고,
+// false이면 로그만 남기고 첫 요청 때 다시 시도합니다.
+func NewTokenManager(networkDomains []string, failOnInitialError bool) (*TokenManager, error) {
+	if len(networkDomains) == 0 {
+		return nil, errors.New("at least one token network domain is required")
+	}
+	tm := &TokenManager{}
+	for _, domain := range networkDomains {
+		tm.sources = append(tm.sources, &tokenSource{networkDomain: domain})
 	}
 	// 초기 토큰 가져오기
 	err := tm.RefreshToken()
 	if err != nil {
+		if failOnInitialError {
+			return nil, fmt.Errorf("initial token fetch failed: %w", err)
+		}
 		log.Printf("Initial token fetch failed: %v. Will retry on first request.", err)
 	}
-	return tm
+	return tm, nil
 }
 
 // GetToken은 현재 유효한 액세스 토큰을 반환합니다. 필요한 경우 갱신합니다.
@@ -69,264 +92,1449 @@ func (tm *TokenManager) GetToken() (string, error) {
 	return token, nil
 }
 
-// RefreshToken은 BetterMode API에서 새 게스트 액세스 토큰을 가져옵니다
+// RefreshToken은 BetterMode API에서 새 게스트 액세스 토큰을 가져옵니다.
+// 활성 소스가 TOKEN_SOURCE_FAILURE_THRESHOLD번 연속 실패하면 다음 소스로 넘어가고,
+// 기본 소스가 마지막 실패 후 TOKEN_SOURCE_FAILBACK_AFTER만큼 지나면 다시 기본 소스를 먼저 시도합니다.
+// 갱신이 TOKEN_CIRCUIT_BREAKER_THRESHOLD번 연속 실패하면 토큰 서킷 브레이커가 열려 잠시 발급을 시도하지 않습니다.
 func (tm *TokenManager) RefreshToken() error {
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
 
+	if err := tokenBreaker.check(time.Now()); err != nil {
+		return err
+	}
+	err := tm.refreshLocked()
+	tokenBreaker.record(err == nil, time.Now())
+	return err
+}
+
+// refreshLocked는 소스를 차례로 시도해 토큰을 갱신합니다. tm.mutex를 잡은 상태에서 호출해야 합니다.
+func (tm *TokenManager) refreshLocked() error {
+	var lastErr error
+	for _, i := range tm.sourceOrder(time.Now()) {
+		source := tm.sources[i]
+		requested := currentConfig().TokenScopes
+		token, granted, err := fetchGuestToken(source.networkDomain, requested)
+		if err != nil {
+			source.recordFailure(err)
+			log.Printf("Token source %s failed (%d consecutive): %v", source.networkDomain, source.failures, err)
+			lastErr = err
+			if i == tm.active && source.failures < currentConfig().TokenSourceFailureThreshold {
+				// 아직 임계값 전이면 다른 소스로 넘어가지 않습니다
+				return err
+			}
+			continue
+		}
+
+		source.recordSuccess()
+		if i != tm.active {
+			log.Printf("Switching token source from %s to %s", tm.sources[tm.active].networkDomain, source.networkDomain)
+			tm.active = i
+		}
+
+		// 토큰 저장
+		tm.accessToken = token
+		tm.scopes = granted
+		// BetterMode가 부여 범위를 알려 주지 않으면(nil) 확인할 수 없으므로 넘어갑니다
+		if missing := missingScopes(requested, granted); granted != nil && len(missing) > 0 {
+			log.Printf("Token from %s was not granted requested scopes %v (granted %v)", source.networkDomain, missing, granted)
+		}
+
+		// JWT 토큰에서 만료 시간 추출 (선택 사항, 구현에 따라 다를 수 있음)
+		// 만료 시간을 확인할 수 없는 경우 24시간으로 설정
+		tm.expiry = time.Now().Add(24 * time.Hour)
+
+		log.Printf("Token refreshed successfully from %s, valid until %v", source.networkDomain, tm.expiry)
+		return nil
+	}
+	return lastErr
+}
+
+// guestTokenScopedQuery는 TOKEN_SCOPES를 설정했을 때 쓰는 토큰 쿼리로, 부여된 범위도 함께 받습니다.
+// 범위를 지원하지 않는 BetterMode에서 기본 쿼리가 깨지지 않도록 범위를 요청할 때만 사용합니다.
+const guestTokenScopedQuery = `
+			query GuestToken($networkDomain: String!, $scopes: [String!]) {
+				tokens(networkDomain: $networkDomain, scopes: $scopes) {
+					accessToken
+					scopes
+				}
+			}
+		`
+
+// fetchGuestToken은 networkDomain의 게스트 액세스 토큰을 발급받습니다.
+// scopes가 있으면 그 권한 범위만 요청하고, BetterMode가 부여한 범위를 두 번째 값으로 반환합니다.
+func fetchGuestToken(networkDomain string, scopes []string) (string, []string, error) {
 	// API 요청을 위한 GraphQL 쿼리
 	query := map[string]interface{}{
 		"query": `
-			query {
-				tokens(networkDomain: "www.gpters.org") {
+			query GuestToken($networkDomain: String!) {
+				tokens(networkDomain: $networkDomain) {
 					accessToken
 				}
 			}
 		`,
+		"variables": map[string]interface{}{"networkDomain": networkDomain},
+	}
+	if len(scopes) > 0 {
+		query["query"] = guestTokenScopedQuery
+		query["variables"] = map[string]interface{}{"networkDomain": networkDomain, "scopes": scopes}
 	}
 
 	jsonBody, err := json.Marshal(query)
 	if err != nil {
-		return fmt.Errorf("error marshalling token query: %w", err)
+		return "", nil, fmt.Errorf("error marshalling token query: %w", err)
 	}
 
 	// API 요청 생성
-	req, err := http.NewRequest("POST", "https://api.bettermode.com/", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequest("POST", betterModeAPIURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return fmt.Errorf("error creating token request: %w", err)
+		return "", nil, fmt.Errorf("error creating token request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	// 요청 전송
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// 요청 전송 (UPSTREAM_TLS_MIN_VERSION, UPSTREAM_CA_FILE이 적용된 클라이언트)
+	resp, err := upstreamClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("error sending token request: %w", err)
+		return "", nil, fmt.Errorf("error sending token request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// 응답 읽기
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("error reading token response: %w", err)
+		return "", nil, fmt.Errorf("error reading token response: %w", err)
 	}
 
 	// 응답 파싱
 	var tokenResponse struct {
 		Data struct {
 			Tokens struct {
-				AccessToken string `json:"accessToken"`
+				AccessToken string   `json:"accessToken"`
+				Scopes      []string `json:"scopes"`
 			} `json:"tokens"`
 		} `json:"data"`
 	}
 
 	err = json.Unmarshal(body, &tokenResponse)
 	if err != nil {
-		return fmt.Errorf("error parsing token response: %w", err)
+		return "", nil, fmt.Errorf("error parsing token response: %w", err)
 	}
 
 	if tokenResponse.Data.Tokens.AccessToken == "" {
-		return fmt.Errorf("no token returned from API")
+		return "", nil, fmt.Errorf("no token returned from API")
 	}
+	return tokenResponse.Data.Tokens.AccessToken, tokenResponse.Data.Tokens.Scopes, nil
+}
 
-	// 토큰 저장
-	tm.accessToken = tokenResponse.Data.Tokens.AccessToken
+// MappingField는 BetterMode 게시물의 매핑 필드 하나를 나타냅니다
+type MappingField struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
 
-	// JWT 토큰에서 만료 시간 추출 (선택 사항, 구현에 따라 다를 수 있음)
-	// 만료 시간을 확인할 수 없는 경우 24시간으로 설정
-	tm.expiry = time.Now().Add(24 * time.Hour)
+// PostAttachment는 게시물에 첨부된 파일 정보입니다
+type PostAttachment struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	DownloadURL string `json:"downloadUrl"`
+	Size        int64  `json:"size"`
+	Extension   string `json:"extension"`
+}
 
-	log.Printf("Token refreshed successfully, valid until %v", tm.expiry)
-	return nil
+// PostSpace는 게시물이 속한 스페이스입니다
+type PostSpace struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Post는 BetterMode API에서 가져온 게시물 데이터입니다
+type Post struct {
+	MappingFields []MappingField   `json:"mappingFields"`
+	Title         string           `json:"title"`
+	Attachments   []PostAttachment `json:"attachments"`
+	SpaceID       string           `json:"spaceId"`
+	Space         *PostSpace       `json:"space"` // 스페이스에 속하지 않았거나 볼 수 없으면 nil
+	UpdatedAt     string           `json:"updatedAt"`
+
+	// jsonld 형식 요청에서만 조회합니다
+	PublishedAt string     `json:"publishedAt"`
+	Owner       *PostOwner `json:"owner"`
+
+	// include_engagement 요청에서만 조회하며, BetterMode가 값을 주지 않으면 nil입니다
+	ReactionsCount *int64              `json:"reactionsCount"`
+	RepliesCount   *int64              `json:"repliesCount"`
+	Reactions      []PostReactionCount `json:"reactions"`
+
+	// BetterMode 응답에 잘못된 UTF-8 바이트가 있어 JSON을 해석하며 U+FFFD로 바뀐 경우
+	invalidUTF8 bool
+	// GRAPHQL_PARTIAL_DATA=allow에서 errors와 함께 받은 일부 데이터로 만든 경우 그 에러 메시지
+	partialErrors string
+}
+
+// PostReactionCount는 반응 종류별 개수입니다
+type PostReactionCount struct {
+	Reaction string `json:"reaction"`
+	Count    int64  `json:"count"`
 }
 
 type PostResponse struct {
 	Data struct {
-		Post struct {
-			MappingFields []struct {
-				Key   string `json:"key"`
-				Type  string `json:"type"`
-				Value string `json:"value"`
-			} `json:"mappingFields"`
-			Title string `json:"title"`
-		} `json:"post"`
+		Post Post `json:"post"`
 	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+// ContentOptions는 콘텐츠 요청에서 공통으로 사용하는 옵션입니다.
+// POST 요청은 JSON 본문에서, GET 요청은 같은 이름의 쿼리 파라미터에서 읽습니다.
+type ContentOptions struct {
+	Format     string   `json:"format,omitempty"`      // "html" (default), "text", "xhtml" or "jsonld"
+	Fields     []string `json:"fields,omitempty"`      // 함께 반환할 매핑 필드 key 목록
+	FieldTypes []string `json:"field_types,omitempty"` // 반환할 매핑 필드 type 목록 (예: "html", "text")
+
+	CodeLineNumbers    bool `json:"code_line_numbers,omitempty"`   // text 형식에서 코드 블록에 줄 번호 추가
+	IncludeAttachments bool `json:"include_attachments,omitempty"` // 첨부 파일 목록 포함
+	IncludeEngagement  bool `json:"include_engagement,omitempty"`  // 반응/댓글 수 포함
+	IncludeEmbeds      bool `json:"include_embeds,omitempty"`      // 본문에 삽입된 동영상/oembed 목록 포함
+	IncludeTOC         bool `json:"include_toc,omitempty"`         // 헤딩에 id를 붙이고 목차 포함
+	ExtractFootnotes   bool `json:"extract_footnotes,omitempty"`   // 각주를 찾아 footnotes에 포함
+	RemoveFootnotes    bool `json:"remove_footnotes,omitempty"`    // extract_footnotes와 함께 쓰면 본문에서 각주와 각주 표시를 지움
+	IncludeSummary     bool `json:"include_summary,omitempty"`     // 요약 필드(없으면 본문 첫 문단)를 summary에 포함
+	ExtractTables      bool `json:"extract_tables,omitempty"`      // 본문의 표를 셀 텍스트 배열로 tables, table_headers에 포함
+	Thumbnail          bool `json:"thumbnail,omitempty"`           // 본문의 첫 번째 이미지(아이콘 크기 제외) URL을 thumbnail에 포함
+	IncludeLinks       bool `json:"include_links,omitempty"`       // 본문의 링크 목록(URL, 텍스트, 내부 링크 여부)을 links에 포함
+	ProxyImages        bool `json:"proxy_images,omitempty"`        // html/xhtml의 <img src>를 /api/v1/image-proxy 주소로 바꿈
+	AbsoluteLinks      bool `json:"absolute_links,omitempty"`      // 상대 href/src를 커뮤니티 도메인 기준의 절대 URL로 바꿈
+	StripStyles        bool `json:"strip_styles,omitempty"`        // 모든 요소의 인라인 style 속성 제거
+	StripClasses       bool `json:"strip_classes,omitempty"`       // 모든 요소의 class 속성 제거
+	DedupeParagraphs   bool `json:"dedupe_paragraphs,omitempty"`   // text 형식에서 바로 앞 문단과 같은 문단을 지움
+	NoCache            bool `json:"nocache,omitempty"`             // 캐시를 사용하지 않고 새로 가져오기
+
+	// 이 요청으로 저장하는 캐시 항목의 유지 시간(초). CACHE_MAX_TTL보다 길면 줄이고, 0이면 nocache와 같이 캐시를 쓰지 않습니다
+	CacheTTLSeconds *int `json:"cache_ttl_seconds,omitempty"`
+	// 이 요청에서 게시물을 가져와 가공하는 최대 시간(ms). FETCH_TIMEOUT 대신 쓰며, FETCH_MAX_TIMEOUT보다 길면 줄입니다
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+
+	Encoding string `json:"encoding,omitempty"` // "base64"이면 content를 base64로 인코딩해 반환
+	TTS      bool   `json:"tts,omitempty"`      // text 형식에서 URL과 마크다운 기호를 정리해 음성 합성용 텍스트로 반환
+
+	TranslateTo string `json:"translate_to,omitempty"` // 설정하면 본문 텍스트를 이 언어로 번역해 translated_text에 함께 반환
+
+	// 게시물의 title 대신 제목으로 쓸 매핑 필드 key. 그 필드가 없거나 비어 있으면 title을 씁니다
+	TitleKey string `json:"title_key,omitempty"`
+
+	// content_hash 알고리즘 ("sha256", "sha1", "md5", "xxhash"). 비어 있으면 CONTENT_HASH_ALGORITHM
+	HashAlgorithm string `json:"hash_algorithm,omitempty"`
+
+	// 본문 글자 수(태그 제외)가 이보다 적으면 content_too_short 경고를, strict이면 422를 반환합니다
+	MinChars int  `json:"min_chars,omitempty"`
+	Strict   bool `json:"strict,omitempty"`
+
+	// 본문 글자 수(태그 제외)가 이보다 많으면 이 글자 수까지만 남긴 미리보기를 반환하고 has_more를 설정합니다
+	AutoPreview int `json:"auto_preview,omitempty"`
+
+	// 본문 없이 제목, 스페이스, 수정 시각(과 요청한 첨부 파일/반응 수)만 반환합니다.
+	// BetterMode에서 mappingFields를 조회하지 않아 응답이 작고 빠릅니다.
+	MetadataOnly bool `json:"metadata_only,omitempty"`
+
+	// 특정 수정 버전의 콘텐츠 요청. BetterMode 공개 API가 수정 이력을 제공하지 않아 현재는 항상 501을 반환합니다
+	RevisionID string `json:"revision_id,omitempty"`
 }
 
 type ContentRequest struct {
 	PostID string `json:"post_id"`
-	Format string `json:"format,omitempty"` // "html" (default) or "text"
+	ContentOptions
 }
 
 type ContentResponse struct {
 	Content   string `json:"content"`
 	Format    string `json:"format"`
+	Encoding  string `json:"encoding,omitempty"` // content 인코딩 ("base64"), 없으면 원문 그대로
 	PostID    string `json:"post_id"`
 	Title     string `json:"title,omitempty"`
-	CharCount int    `json:"char_count,omitempty"`
+	CharCount int64  `json:"char_count,omitempty"` // 문자(rune) 수 (합친 문서처럼 큰 값도 32비트 빌드에서 넘치지 않도록 int64)
+	HasMore   bool   `json:"has_more,omitempty"`   // auto_preview로 본문을 잘라 미리보기만 반환한 경우
+	ByteCount int64  `json:"byte_count,omitempty"` // UTF-8 바이트 수
+
+	// content를 그대로 LLM에 넣을 때의 어림 토큰 수 (한글/한자/가나는 글자당 1, 나머지는 4글자당 1, estimateTokens 참고)
+	TokenCount int64 `json:"token_count,omitempty"`
+
+	TitleTruncated bool `json:"title_truncated,omitempty"` // MAX_TITLE_LENGTH를 넘어 제목을 자른 경우
+
+	SpaceID   string `json:"space_id,omitempty"`   // 게시물이 속한 스페이스 (없으면 생략)
+	SpaceName string `json:"space_name,omitempty"` // 스페이스 이름
+
+	SanitizeProfile string `json:"sanitize_profile,omitempty"` // 적용된 정리 프로필 (없으면 정리하지 않음)
+	UpdatedAt       string `json:"updated_at,omitempty"`       // 게시물 마지막 수정 시각 (RFC 3339), Last-Modified 헤더에도 사용
+
+	// 정규화한 원본 본문의 해시 (hex, 기본 SHA-256). 공백, 속성 순서, 엔티티 표기만 다르면 같은 값이며, format 등 옵션과 관계없음
+	ContentHash          string `json:"content_hash,omitempty"`
+	ContentHashAlgorithm string `json:"content_hash_algorithm,omitempty"` // content_hash를 계산한 알고리즘
+
+	Fields      []MappingField `json:"fields,omitempty"`      // fields/field_types 요청 시 선택된 매핑 필드
+	Attachments []Attachment   `json:"attachments,omitempty"` // include_attachments 요청 시 첨부 파일 목록
+	Engagement  *Engagement    `json:"engagement,omitempty"`  // include_engagement 요청 시 반응/댓글 수
+	Embeds      []Embed        `json:"embeds,omitempty"`      // include_embeds 요청 시 본문의 임베드 목록
+	TOC         []TOCEntry     `json:"toc,omitempty"`         // include_toc 요청 시 헤딩 목차 (anchor는 헤딩 id)
+	Footnotes   []Footnote     `json:"footnotes,omitempty"`   // extract_footnotes 요청 시 본문의 각주
+
+	// include_summary 요청 시 요약 필드(summary, excerpt, description)의 텍스트. 요약 필드가 없으면 본문 첫 문단으로 만들고 summary_derived를 설정
+	Summary        string `json:"summary,omitempty"`
+	SummaryDerived bool   `json:"summary_derived,omitempty"`
+
+	// extract_tables 요청 시 본문의 표 (표마다 행 배열, 행마다 셀 텍스트). 합친 셀은 차지하는 모든 칸에 같은 텍스트가 들어감.
+	// table_headers[i]는 tables[i] 표의 머리글 행이며, 머리글이 없으면 빈 배열
+	Tables       [][][]string `json:"tables,omitempty"`
+	TableHeaders [][][]string `json:"table_headers,omitempty"`
+
+	Thumbnail string `json:"thumbnail,omitempty"` // thumbnail 요청 시 본문의 첫 번째 이미지 URL (없으면 생략)
+	Links     []Link `json:"links,omitempty"`     // include_links 요청 시 본문의 링크 (문서 순서, URL 중복 제거)
+
+	TranslatedText string `json:"translated_text,omitempty"` // translate_to 요청 시 번역된 본문 텍스트 (content는 원문 그대로)
+	TranslatedTo   string `json:"translated_to,omitempty"`
+
+	Warnings []Warning `json:"warnings,omitempty"` // 응답은 만들었지만 클라이언트가 알아야 할 문제들
+
+	// 이 요청에서 응답을 얻기까지 걸린 시간 (캐시 적중이면 거의 0). 캐시에는 저장되지 않습니다
+	FetchLatencyMs int64 `json:"fetch_latency_ms"`
+
+	partial bool // 일부 데이터만 받은 게시물로 만들어 캐시하지 않는 응답
+}
+
+// EncodingBase64는 content를 base64(표준, 패딩 포함)로 인코딩해 반환하는 encoding 옵션 값입니다
+const EncodingBase64 = "base64"
+
+// Warning은 요청은 성공했지만 결과에 영향을 줄 수 있는 상황을 알립니다.
+// 클라이언트는 Code로 종류를 구분하고, Message는 사람이 읽기 위한 설명입니다.
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Warning.Code 값
+const (
+	WarningDuplicateContentFields = "duplicate_content_fields"
+	WarningMissingTitle           = "missing_title"
+	WarningEmptyText              = "empty_text"
+	WarningCodeLineNumbersFailed  = "code_line_numbers_failed"
+	WarningNoFieldsMatched        = "no_fields_matched"
+	WarningPostProcessFailed      = "postprocess_failed"
+	WarningTranslationFailed      = "translation_failed"
+	WarningContentTooShort        = "content_too_short"
+	WarningTextFallback           = "text_fallback"
+	WarningInvalidUTF8Repaired    = "invalid_utf8_repaired"
+	WarningPartialData            = "partial_data"
+	WarningDuplicateParagraphs    = "duplicate_paragraphs_removed"
+)
+
+func newWarning(code, format string, args ...interface{}) Warning {
+	return Warning{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Engagement는 게시물의 반응/댓글 수입니다. BetterMode가 주지 않은 값은 생략합니다.
+// 조회수는 BetterMode API에서 제공하지 않아 포함하지 않습니다.
+type Engagement struct {
+	Reactions  *int64           `json:"reactions,omitempty"`   // 전체 반응 수
+	Replies    *int64           `json:"replies,omitempty"`     // 댓글 수
+	ByReaction map[string]int64 `json:"by_reaction,omitempty"` // 반응 종류별 개수
+}
+
+// Attachment는 응답에 포함되는 첨부 파일 정보입니다
+type Attachment struct {
+	Filename string `json:"filename"`
+	URL      string `json:"url"`
+	Size     int64  `json:"size"`
+	MimeType string `json:"mime_type"`
 }
 
 // URLRequest는 BetterMode URL로부터 콘텐츠를 가져오기 위한 요청 구조체입니다
 type URLRequest struct {
-	URL    string `json:"url"`
-	Format string `json:"format,omitempty"` // "html" (default) or "text"
+	URL string `json:"url"`
+	ContentOptions
 }
 
 // 전역 토큰 관리자
 var tokenManager *TokenManager
 
+// errServiceInitializing은 토큰 관리자가 아직 만들어지지 않아 BetterMode를 호출할 수 없음을 나타냅니다 (503으로 응답)
+var errServiceInitializing = errors.New("service initializing: the token manager is not ready yet")
+
+// 초기화 중이라 503으로 응답할 때 클라이언트에 알려 주는 재시도 간격
+const serviceInitializingRetryAfter = 5 * time.Second
+
+// requireTokenManager는 토큰 관리자가 없으면(초기화 전이거나 초기화 없이 핸들러를 부른 경우) 핸들러 대신 503으로 응답합니다
+func requireTokenManager(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tokenManager == nil {
+			writeServiceInitializing(w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeServiceInitializing(w http.ResponseWriter) {
+	setRetryAfter(w, serviceInitializingRetryAfter)
+	writeError(w, errServiceInitializing.Error(), http.StatusServiceUnavailable)
+}
+
+// 전역 콘텐츠 캐시
+var contentCache *ContentCache
+
+// 같은 캐시 키에 대한 동시 업스트림 요청을 하나로 합칩니다
+var contentFetchGroup singleflight.Group
+
 // GetContent godoc
 // @Summary Get content from BetterMode API
 // @Description Retrieves content value from mappingFields where key is "content"
 // @Tags content
 // @Accept json
 // @Produce json
-// @Param request body ContentRequest true "Post ID and optional format (html or text)"
+// @Produce application/ld+json
+// @Param request body ContentRequest true "Post ID and optional format (html, text, xhtml or jsonld)"
 // @Success 200 {object} ContentResponse
 // @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "Post not found (deleted or not visible)"
 // @Failure 500 {string} string "Internal server error"
+// @Failure 502 {string} string "BetterMode unavailable (non-JSON response)"
+// @Failure 504 {string} string "Timed out fetching from BetterMode"
 // @Router /content [post]
+// @Router /content [get]
 func getContent(w http.ResponseWriter, r *http.Request) {
 	var req ContentRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := decodeContentRequest(r, &req, decodeStrict); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	if req.PostID == "" {
-		http.Error(w, "Post ID is required", http.StatusBadRequest)
+		writeError(w, "Post ID is required", http.StatusBadRequest)
 		return
 	}
 
-	// Set default format to html if not specified
-	if req.Format == "" {
-		req.Format = "html"
-	} else if req.Format != "html" && req.Format != "text" {
-		http.Error(w, "Format must be 'html' or 'text'", http.StatusBadRequest)
+	if err := req.ContentOptions.normalize(); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Fetch content and title
-	content, title, err := fetchContentFromBetterMode(req.PostID)
+	response, err := buildContentResponse(r.Context(), req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error fetching content: %v", err), http.StatusInternalServerError)
+		writeFetchError(w, err)
 		return
 	}
 
-	// Clean up the content value
-	processedContent := cleanupContent(content)
+	setCacheControl(w, req.NoCache)
+	setServerTiming(w, response)
+	if checkNotModified(w, r, response.UpdatedAt) {
+		return
+	}
+	if response.Format == FormatJSONLD {
+		writeJSONLD(w, response)
+		return
+	}
+	writeContentJSON(w, r, response)
+}
 
-	// If format is text, try to strip HTML tags
-	if req.Format == "text" {
-		processedContent = stripHTMLTags(processedContent)
+// writeError는 캐시되지 않도록 Cache-Control: no-store를 붙여 에러 응답을 보냅니다
+func writeError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Cache-Control", "no-store")
+	http.Error(w, message, status)
+}
+
+// writeFetchError는 콘텐츠를 가져오지 못한 에러를 응답합니다.
+// 서킷 브레이커가 열려 있으면 다시 시도할 시점을 Retry-After로 알려 주는 503,
+// BetterMode가 입력 값(post ID 형식 등)을 거절했으면 400, 그 외에는 500입니다.
+func writeFetchError(w http.ResponseWriter, err error) {
+	var openErr *circuitOpenError
+	if errors.As(err, &openErr) {
+		setRetryAfter(w, openErr.retryAfter)
+		writeError(w, fmt.Sprintf("Error fetching content: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+	if errors.As(err, new(*inputError)) {
+		writeError(w, fmt.Sprintf("Error fetching content: %v", err), http.StatusBadRequest)
+		return
 	}
+	if errors.Is(err, errPostNotFound) {
+		writeError(w, fmt.Sprintf("Error fetching content: %v", err), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, errServiceInitializing) {
+		writeServiceInitializing(w)
+		return
+	}
+	if errors.As(err, new(*upstreamUnavailableError)) || errors.As(err, new(*truncatedResponseError)) || errors.Is(err, errInvalidUTF8) {
+		writeError(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	var shortErr *contentTooShortError
+	if errors.As(err, &shortErr) {
+		writeError(w, "Content may be incomplete: "+shortErr.message, http.StatusUnprocessableEntity)
+		return
+	}
+	if errors.Is(err, errRevisionsUnsupported) {
+		writeError(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	if errors.Is(err, errCallerTokenRejected) {
+		writeError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		// 재시도 로직에서 일반 에러와 구분할 수 있도록 504로 알립니다
+		writeError(w, fmt.Sprintf("Timed out fetching content from BetterMode: %v", err), http.StatusGatewayTimeout)
+		return
+	}
+	writeError(w, fmt.Sprintf("Error fetching content: %v", err), http.StatusInternalServerError)
+}
 
-	// Prepare the response
-	response := ContentResponse{
-		Content:   processedContent,
-		Format:    req.Format,
-		PostID:    req.PostID,
-		Title:     title,
-		CharCount: len(processedContent),
+// setCacheControl은 콘텐츠 응답에 CDN/클라이언트용 캐시 힌트를 설정합니다.
+// nocache 요청이거나 max-age가 0이면 no-store를 사용합니다.
+func setCacheControl(w http.ResponseWriter, noCache bool) {
+	maxAge := int(currentConfig().CacheControlMaxAge.Seconds())
+	if noCache || maxAge <= 0 {
+		w.Header().Set("Cache-Control", "no-store")
+		return
 	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+}
 
-	render.JSON(w, r, response)
+// setServerTiming은 응답을 얻는 데 걸린 시간을 Server-Timing 헤더로 알립니다
+func setServerTiming(w http.ResponseWriter, response ContentResponse) {
+	w.Header().Set("Server-Timing", fmt.Sprintf("fetch;dur=%d", response.FetchLatencyMs))
 }
 
-func fetchContentFromBetterMode(postID string) (string, string, error) {
-	url := "https://api.bettermode.com/"
+// buildContentResponse는 게시물을 가져와 요청한 형식으로 가공한 응답을 만듭니다.
+// 같은 요청에 대한 응답이 캐시에 있으면 BetterMode API를 호출하지 않고 캐시된 응답을 반환합니다.
+// nocache 요청은 캐시를 읽지 않고 항상 새로 가져오며, 가져온 결과로 캐시를 갱신합니다.
+// 캐시에 없는 같은 요청이 동시에 여러 개 들어오면 업스트림 호출은 한 번만 하고 결과를 공유합니다.
+//
+// ctx가 취소되면 결과를 기다리지 않고 바로 ctx.Err()를 반환합니다. 공유 중인 업스트림 호출은
+// 다른 대기자를 위해 호출자의 취소와 분리된 컨텍스트로 끝까지 진행되고 결과는 캐시에 저장됩니다.
+func buildContentResponse(ctx context.Context, req ContentRequest) (ContentResponse, error) {
+	if req.RevisionID != "" {
+		return ContentResponse{}, errRevisionsUnsupported
+	}
 
-	// 토큰 관리자에서 유효한 토큰 얻기
-	token, err := tokenManager.GetToken()
+	start := time.Now()
+	response, err := fetchContentResponse(ctx, req)
 	if err != nil {
-		return "", "", fmt.Errorf("error getting access token: %w", err)
+		return ContentResponse{}, err
+	}
+	if err := checkStrict(req, response); err != nil {
+		return ContentResponse{}, err
 	}
+	response.FetchLatencyMs = time.Since(start).Milliseconds()
+	return response, nil
+}
 
-	// Create the GraphQL query
-	query := map[string]interface{}{
-		"query": `query GetPost($id: ID!) {
-			post(id: $id) {
-				mappingFields {
-					key
-					type
-					value
+// checkStrict는 strict 요청의 응답에 content_too_short 경고가 있으면 *contentTooShortError를 반환합니다
+func checkStrict(req ContentRequest, response ContentResponse) error {
+	if !req.Strict {
+		return nil
+	}
+	for _, warning := range response.Warnings {
+		if warning.Code == WarningContentTooShort {
+			return &contentTooShortError{message: warning.Message}
+		}
+	}
+	return nil
+}
+
+// errRevisionsUnsupported는 revision_id 요청에 대한 에러입니다 (501로 응답).
+// BetterMode GraphQL API의 post는 최신 내용만 제공하고 수정 이력을 조회하는 쿼리가 없습니다.
+var errRevisionsUnsupported = errors.New("revision_id is not supported: the BetterMode API does not expose post revision history")
+
+// contentTooShortError는 strict 요청에서 본문이 min_chars보다 짧았음을 나타냅니다 (422로 응답)
+type contentTooShortError struct {
+	message string
+}
+
+func (e *contentTooShortError) Error() string {
+	return e.message
+}
+
+// fetchContentResponse는 캐시와 singleflight를 거쳐 응답을 가져옵니다 (buildContentResponse 참고)
+func fetchContentResponse(ctx context.Context, req ContentRequest) (ContentResponse, error) {
+	// 호출자 토큰으로만 볼 수 있는 콘텐츠일 수 있으므로 캐시에 넣거나 다른 요청과 합치지 않습니다
+	if _, ok := callerToken(ctx); ok {
+		loadCtx, cancel := withFetchTimeout(ctx, req.ContentOptions)
+		defer cancel()
+		return loadContentResponse(loadCtx, req)
+	}
+
+	cacheKey := contentCacheKey(req)
+	if !req.NoCache {
+		if cached, ok, stale := contentCache.Lookup(cacheKey); ok {
+			logDebugf("Content cache hit for post %s (stale: %v)", req.PostID, stale)
+			if stale {
+				revalidateContent(detachContext(ctx), cacheKey, req)
+			}
+			return cached, nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return ContentResponse{}, err
+	}
+
+	fetchCtx := detachContext(ctx)
+	ch := contentFetchGroup.DoChan(cacheKey, func() (interface{}, error) {
+		// 앞선 호출이 방금 캐시를 채웠을 수 있으므로 다시 확인합니다
+		if !req.NoCache {
+			if cached, ok := contentCache.Get(cacheKey); ok {
+				return cached, nil
+			}
+		}
+
+		loadCtx, cancel := withFetchTimeout(fetchCtx, req.ContentOptions)
+		defer cancel()
+		response, err := loadContentResponse(loadCtx, req)
+		if err != nil {
+			if errors.Is(err, errPostNotFound) {
+				// nocache 요청이 삭제를 발견한 경우에도 다른 옵션의 캐시 항목이 남지 않게 합니다
+				contentCache.InvalidatePost(req.PostID)
+			}
+			return nil, err
+		}
+		if !response.partial {
+			contentCache.Set(cacheKey, response, req.ContentOptions)
+		}
+		return response, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return ContentResponse{}, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return ContentResponse{}, res.Err
+		}
+		return res.Val.(ContentResponse), nil
+	}
+}
+
+// revalidateContent는 soft TTL이 지난 캐시 항목을 백그라운드에서 새로 가져와 교체합니다.
+// 같은 키의 업스트림 호출과 합쳐지므로 갱신은 한 번에 하나만 진행됩니다.
+func revalidateContent(ctx context.Context, cacheKey string, req ContentRequest) {
+	// 게시물 캐시에 남은 예전 데이터로 다시 만들지 않도록 BetterMode에서 새로 가져옵니다
+	req.NoCache = true
+	contentFetchGroup.DoChan(cacheKey, func() (interface{}, error) {
+		loadCtx, cancel := withFetchTimeout(ctx, req.ContentOptions)
+		defer cancel()
+		response, err := loadContentResponse(loadCtx, req)
+		if errors.Is(err, errPostNotFound) {
+			// 삭제된 게시물은 만료까지 기다리지 않고 바로 지워, 다음 요청이 404를 받게 합니다
+			removed := contentCache.InvalidatePost(req.PostID)
+			log.Printf("Background revalidation found post %s deleted upstream; purged %d cache entries", req.PostID, removed)
+			return nil, err
+		}
+		if err != nil {
+			// 갱신에 실패해도 만료 전까지는 기존 항목을 계속 사용합니다
+			log.Printf("Background revalidation of post %s failed: %v", req.PostID, err)
+			return nil, err
+		}
+		if response.partial {
+			// 일부만 받은 응답으로 온전한 기존 항목을 덮어쓰지 않습니다
+			return response, nil
+		}
+		contentCache.Set(cacheKey, response, req.ContentOptions)
+		return response, nil
+	})
+}
+
+// withFetchTimeout은 FETCH_TIMEOUT이 설정되어 있으면 그 시간이 지나면 끝나는 컨텍스트를 반환합니다.
+// 요청이 timeout_ms를 지정했으면(normalize에서 FETCH_MAX_TIMEOUT으로 줄인 값) FETCH_TIMEOUT 대신 그 시간을 씁니다.
+func withFetchTimeout(ctx context.Context, opts ContentOptions) (context.Context, context.CancelFunc) {
+	cfg := currentConfig()
+	if opts.TimeoutMs > 0 {
+		return context.WithTimeout(ctx, time.Duration(opts.TimeoutMs)*time.Millisecond)
+	}
+	if cfg.FetchTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, cfg.FetchTimeout)
+}
+
+// detachedContext는 부모 컨텍스트의 값은 그대로 전달하지만 취소와 마감 시간은 물려받지 않습니다.
+// 여러 요청이 공유하는 업스트림 호출이 한 요청의 취소 때문에 중단되지 않도록 할 때 사용합니다.
+type detachedContext struct {
+	parent context.Context
+}
+
+func detachContext(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// fetchPost는 가공 전 게시물을 post ID별 캐시에서 찾고, 없으면 BetterMode에서 가져와 저장합니다.
+// nocache 요청은 캐시를 읽지 않습니다. 같은 게시물을 동시에 가져오는 요청은 업스트림 호출 하나로 합칩니다.
+// 합쳐진 호출은 요청의 취소와 상관없이 FETCH_TIMEOUT(또는 timeout_ms)까지 진행하므로,
+// 먼저 온 요청이 취소되어도 함께 기다리는 다른 요청은 결과를 받습니다.
+func fetchPost(ctx context.Context, req ContentRequest) (*Post, error) {
+	if _, ok := callerToken(ctx); ok {
+		return fetchPostFromUpstream(ctx, req.PostID, req.ContentOptions)
+	}
+	if !req.NoCache {
+		if post, ok := contentCache.GetPost(req.PostID, req.ContentOptions); ok {
+			return post, nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	fetchCtx := detachContext(ctx)
+	ch := contentFetchGroup.DoChan(postFetchKey(req), func() (interface{}, error) {
+		// 앞선 호출이 방금 캐시를 채웠을 수 있으므로 다시 확인합니다
+		if !req.NoCache {
+			if post, ok := contentCache.GetPost(req.PostID, req.ContentOptions); ok {
+				return post, nil
+			}
+		}
+
+		loadCtx, cancel := withFetchTimeout(fetchCtx, req.ContentOptions)
+		defer cancel()
+		post, err := fetchPostFromUpstream(loadCtx, req.PostID, req.ContentOptions)
+		if err != nil {
+			return nil, err
+		}
+		if !post.isPartial() {
+			contentCache.SetPost(req.PostID, post, req.ContentOptions)
+		}
+		return post, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*Post), nil
+	}
+}
+
+// postFetchKey는 fetchPost의 singleflight 키입니다. 조회할 필드가 달라지는 옵션만 키에 넣습니다.
+func postFetchKey(req ContentRequest) string {
+	return fmt.Sprintf("post|%s|%t|%t|%t|%t", req.PostID, req.MetadataOnly, req.IncludeAttachments, req.IncludeEngagement, req.includesAuthor())
+}
+
+// errEmptyContent는 게시물은 있지만 content 필드가 비어 있음을 나타냅니다
+var errEmptyContent = errors.New("content field not found")
+
+// errPostNotFound는 BetterMode에 게시물이 없음(삭제되었거나 볼 수 없음)을 나타냅니다 (404로 응답)
+var errPostNotFound = errors.New("post not found")
+
+// fetchPostFromUpstream은 BetterMode에서 게시물을 가져오며, 다음 경우에 다시 가져옵니다.
+//   - 응답이 중간에 끊겼으면 UPSTREAM_TRUNCATED_RETRIES번까지 곧바로
+//   - 게시물은 있는데 본문이 비어 있으면 EMPTY_CONTENT_RETRIES번까지 EMPTY_CONTENT_RETRY_DELAY 간격으로
+//     (막 수정된 게시물을 읽을 때 BetterMode가 잠깐 빈 본문을 주는 경우용이며, 기본값 0은 다시 가져오지 않음)
+//
+// 다시 가져와도 비어 있으면 마지막 결과를, 계속 끊기면 몇 번 시도했는지 담은 에러를 반환합니다.
+func fetchPostFromUpstream(ctx context.Context, postID string, opts ContentOptions) (*Post, error) {
+	cfg := currentConfig()
+	emptyRetries, truncatedRetries := 0, 0
+	for {
+		post, err := fetchContentFromBetterMode(ctx, postID, opts)
+		if errors.As(err, new(*truncatedResponseError)) {
+			if truncatedRetries >= cfg.UpstreamTruncatedRetries {
+				if truncatedRetries > 0 {
+					return nil, fmt.Errorf("giving up after %d retries: %w", truncatedRetries, err)
 				}
-				title
+				return nil, err
 			}
-		}`,
-		"variables": map[string]interface{}{
-			"id": postID,
-		},
+			truncatedRetries++
+			log.Printf("Response for post %s was cut off (%v), retry %d/%d", postID, err, truncatedRetries, cfg.UpstreamTruncatedRetries)
+			continue
+		}
+
+		empty := errors.Is(err, errEmptyContent) || (err == nil && !opts.MetadataOnly && !post.isPartial() && isEmptyContent(post.ContentField()))
+		if !empty || emptyRetries >= cfg.EmptyContentRetries {
+			return post, err
+		}
+		emptyRetries++
+		log.Printf("Post %s returned empty content, retry %d/%d in %v", postID, emptyRetries, cfg.EmptyContentRetries, cfg.EmptyContentRetryDelay)
+		if err := sleepContext(ctx, cfg.EmptyContentRetryDelay); err != nil {
+			return nil, err
+		}
 	}
+}
 
-	queryJSON, err := json.Marshal(query)
+// isEmptyContent는 content 필드 값이 정리 후 비어 있는지 확인합니다 (JSON 빈 문자열 "\"\""도 포함)
+func isEmptyContent(value string) bool {
+	content := strings.TrimSpace(cleanupContent(value))
+	return content == "" || content == `""`
+}
+
+// loadContentResponse는 캐시를 거치지 않고 BetterMode API에서 게시물을 가져와 응답을 만듭니다
+func loadContentResponse(ctx context.Context, req ContentRequest) (ContentResponse, error) {
+	// Fetch content and title
+	post, err := fetchPost(ctx, req)
 	if err != nil {
-		return "", "", fmt.Errorf("error marshalling query: %w", err)
+		return ContentResponse{}, err
+	}
+	return renderContentResponse(ctx, req, post)
+}
+
+// renderContentResponse는 가져온 게시물로 req 옵션에 맞는 응답을 만듭니다. post는 수정하지 않습니다.
+func renderContentResponse(ctx context.Context, req ContentRequest, post *Post) (ContentResponse, error) {
+	cfg := currentConfig()
+	var err error
+	var warnings []Warning
+	contentField, count := selectContentField(post.MappingFields, cfg.ContentFieldPolicy)
+	if count > 1 {
+		log.Printf("Warning: post %s has %d mapping fields keyed \"content\", using %s-typed field by policy %q",
+			req.PostID, count, contentField.Type, cfg.ContentFieldPolicy)
+		warnings = append(warnings, newWarning(WarningDuplicateContentFields,
+			"post has %d content fields; selected the %s-typed field by policy %q", count, contentField.Type, cfg.ContentFieldPolicy))
+	}
+	displayTitle := postTitle(post, req.TitleKey)
+	if displayTitle == "" {
+		warnings = append(warnings, newWarning(WarningMissingTitle, "post has no title"))
+	}
+	if post.isPartial() {
+		warnings = append(warnings, newWarning(WarningPartialData,
+			"BetterMode returned only part of the post (%s); missing fields are left empty", post.partialErrors))
+	}
+	if req.MetadataOnly {
+		return metadataOnlyResponse(req, post, cfg.MaxTitleLength, warnings), nil
+	}
+
+	// Clean up the content value
+	processedContent := cleanupContent(contentField.Value)
+	// 잘못된 UTF-8은 해시와 HTML 처리 전에 고치거나(repair) 거절합니다(strict)
+	repaired, invalidUTF8 := repairUTF8(processedContent)
+	if invalidUTF8 || post.invalidUTF8 {
+		if cfg.InvalidUTF8Policy == InvalidUTF8Strict {
+			return ContentResponse{}, errInvalidUTF8
+		}
+		log.Printf("Repaired invalid UTF-8 in post %s", req.PostID)
+		warnings = append(warnings, newWarning(WarningInvalidUTF8Repaired,
+			"the post contained invalid UTF-8 byte sequences; they were replaced with U+FFFD"))
+		processedContent = repaired
+	}
+	// 변경 감지용 해시는 요청 옵션이 적용되기 전의 본문으로 계산합니다
+	hashAlgorithm := req.HashAlgorithm
+	if hashAlgorithm == "" {
+		hashAlgorithm = cfg.ContentHashAlgorithm
+	}
+	hash := contentHash(processedContent, hashAlgorithm)
+
+	// 임베드는 정리 프로필이 iframe을 지우기 전에 찾습니다
+	var embeds []Embed
+	if req.IncludeEmbeds {
+		if embeds, err = extractEmbeds(processedContent); err != nil {
+			return ContentResponse{}, fmt.Errorf("error extracting embeds: %w", err)
+		}
+	}
+
+	// 각주는 정리 프로필이 id/class를 지우기 전에 찾습니다
+	var footnotes []Footnote
+	if req.ExtractFootnotes {
+		if processedContent, footnotes, err = extractFootnotes(processedContent, req.RemoveFootnotes); err != nil {
+			return ContentResponse{}, fmt.Errorf("error extracting footnotes: %w", err)
+		}
+	}
+
+	// 스페이스별 정리 프로필 적용 (SANITIZE_SPACE_PROFILES, SANITIZE_DEFAULT_PROFILE)
+	spaceID := post.spaceID()
+	// text 형식은 어차피 태그를 모두 지우므로, HTML을 해석하는 단계가 실패해도 에러 대신 단순 태그 제거로 계속합니다
+	var textFallback error
+
+	sanitizeProfile := sanitizeProfileForSpace(spaceID)
+	if sanitizeProfile != "" {
+		sanitized, err := sanitizeHTML(processedContent, sanitizeProfile)
+		if err != nil {
+			if req.Format != "text" {
+				return ContentResponse{}, fmt.Errorf("error sanitizing content: %w", err)
+			}
+			textFallback = fmt.Errorf("sanitizing: %w", err)
+		} else {
+			processedContent = sanitized
+		}
+	}
+
+	// 인라인 style/class는 본문 크기를 키우고 클라이언트 CSS와 충돌하므로 요청하면 지웁니다 (text 형식은 태그가 남지 않으므로 건너뜀)
+	if (req.StripStyles || req.StripClasses) && req.Format != "text" && textFallback == nil {
+		if processedContent, err = stripAttrs(processedContent, styleAttrs(req.StripStyles, req.StripClasses)); err != nil {
+			return ContentResponse{}, fmt.Errorf("error stripping styles: %w", err)
+		}
 	}
 
-	// Create the request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(queryJSON))
+	// 실수로 두 번 들어간 문단은 태그가 남아 있을 때 문단 단위로 찾아 지웁니다 (text 형식만)
+	if req.DedupeParagraphs && textFallback == nil {
+		deduped, removed, err := dedupeParagraphs(processedContent)
+		if err != nil {
+			textFallback = fmt.Errorf("removing duplicate paragraphs: %w", err)
+		} else if removed > 0 {
+			processedContent = deduped
+			warnings = append(warnings, newWarning(WarningDuplicateParagraphs,
+				"%d duplicate paragraph(s) repeating the previous paragraph were removed", removed))
+		}
+	}
+
+	// 헤딩 id는 형식 변환 전에 붙여 html/xhtml 응답의 헤딩이 목차 anchor와 연결되게 합니다
+	var toc []TOCEntry
+	if req.IncludeTOC && textFallback == nil {
+		anchored, entries, err := addHeadingAnchors(processedContent)
+		if err != nil {
+			if req.Format != "text" {
+				return ContentResponse{}, fmt.Errorf("error adding heading anchors: %w", err)
+			}
+			textFallback = fmt.Errorf("building table of contents: %w", err)
+		} else {
+			processedContent, toc = anchored, entries
+		}
+	}
+
+	// BetterMode 밖에서도 링크가 동작하도록 상대 URL을 절대 URL로 바꿉니다.
+	// 이미지 프록시가 상대 경로 이미지도 원래 호스트로 판단할 수 있도록 프록시 주소로 바꾸기 전에 합니다
+	if req.AbsoluteLinks && textFallback == nil {
+		absolute, err := absolutizeLinks(processedContent, linkBaseURL(cfg))
+		if err != nil {
+			if req.Format != "text" {
+				return ContentResponse{}, fmt.Errorf("error rewriting links: %w", err)
+			}
+			textFallback = fmt.Errorf("rewriting links: %w", err)
+		} else {
+			processedContent = absolute
+		}
+	}
+
+	// 원본 이미지 주소를 드러내지 않도록 허용된 호스트의 이미지를 이미지 프록시 주소로 바꿉니다 (태그가 남는 형식만)
+	if req.ProxyImages && req.Format != "text" && req.Format != FormatJSONLD && textFallback == nil {
+		if processedContent, err = proxyImageURLs(processedContent, cfg.ImageProxyAllowedHosts, cfg.ImageProxyBaseURL); err != nil {
+			return ContentResponse{}, fmt.Errorf("error rewriting image URLs: %w", err)
+		}
+	}
+
+	// 요약이 없을 때 쓰는 첫 문단은 미리보기로 자르기 전의 본문에서 찾습니다
+	var summary string
+	var summaryDerived bool
+	if req.IncludeSummary {
+		summary, summaryDerived = postSummary(exposedFields(post.MappingFields), processedContent)
+	}
+
+	// 표도 미리보기로 자르기 전의 본문에서 모두 찾습니다
+	var tables, tableHeaders [][][]string
+	if req.ExtractTables {
+		if tables, tableHeaders, err = extractTables(processedContent); err != nil {
+			return ContentResponse{}, fmt.Errorf("error extracting tables: %w", err)
+		}
+	}
+
+	// 썸네일은 absolute_links, proxy_images로 바꾼 주소를 쓰고, 미리보기로 잘린 뒷부분의 이미지도 찾습니다
+	var thumbnail string
+	if req.Thumbnail {
+		if thumbnail, err = firstThumbnail(processedContent); err != nil {
+			return ContentResponse{}, fmt.Errorf("error extracting thumbnail: %w", err)
+		}
+	}
+
+	// 링크도 absolute_links로 바꾼 주소로, 미리보기로 자르기 전의 본문에서 모두 찾습니다
+	var links []Link
+	if req.IncludeLinks {
+		if links, err = extractLinks(processedContent, cfg.TokenNetworkDomains); err != nil {
+			return ContentResponse{}, fmt.Errorf("error extracting links: %w", err)
+		}
+	}
+
+	// 긴 본문은 미리보기로 자릅니다. HTML은 XHTML로 바꾸기 전에, text는 변환한 뒤에 자릅니다
+	var hasMore bool
+	if req.AutoPreview > 0 && req.Format != "text" {
+		if processedContent, hasMore, err = previewHTML(processedContent, req.AutoPreview); err != nil {
+			return ContentResponse{}, fmt.Errorf("error building preview: %w", err)
+		}
+	}
+
+	// If format is text, try to strip HTML tags
+	switch req.Format {
+	case "text":
+		if textFallback != nil {
+			log.Printf("Falling back to plain tag stripping for post %s: %v", req.PostID, textFallback)
+			warnings = append(warnings, newWarning(WarningTextFallback,
+				"HTML could not be parsed (%v); text was extracted by best-effort tag stripping", textFallback))
+			processedContent = stripHTMLTags(processedContent)
+		} else if processedContent, err = contentToText(processedContent, req.CodeLineNumbers); err != nil {
+			log.Printf("Failed to number code lines for post %s: %v", req.PostID, err)
+			warnings = append(warnings, newWarning(WarningCodeLineNumbersFailed, "code line numbering was skipped: %v", err))
+		}
+		if req.TTS {
+			processedContent = textForSpeech(processedContent)
+		}
+		if req.AutoPreview > 0 {
+			processedContent, hasMore = previewText(processedContent, req.AutoPreview)
+		}
+		if strings.TrimSpace(processedContent) == "" {
+			warnings = append(warnings, newWarning(WarningEmptyText, "content is empty after converting to text"))
+		}
+	case "xhtml":
+		processedContent, err = toXHTML(processedContent)
+		if err != nil {
+			return ContentResponse{}, fmt.Errorf("error converting content to XHTML: %w", err)
+		}
+	case FormatJSONLD:
+		body, err := contentToText(processedContent, req.CodeLineNumbers)
+		if err != nil {
+			log.Printf("Failed to number code lines for post %s: %v", req.PostID, err)
+			warnings = append(warnings, newWarning(WarningCodeLineNumbersFailed, "code line numbering was skipped: %v", err))
+		}
+		headline, _ := truncateTitle(displayTitle, cfg.MaxTitleLength)
+		if processedContent, err = articleJSONLDFor(post, headline, body); err != nil {
+			return ContentResponse{}, fmt.Errorf("error building JSON-LD: %w", err)
+		}
+	}
+
+	// 추출이 실패해 비었거나 자리표시자만 남은 본문을 잡기 위한 검사
+	if req.MinChars > 0 {
+		if n := visibleCharCount(processedContent, req.Format); n < req.MinChars {
+			warnings = append(warnings, newWarning(WarningContentTooShort,
+				"content has %d characters, fewer than min_chars %d; it may be incomplete", n, req.MinChars))
+		}
+	}
+
+	// 마지막 단계: 설정된 후처리 웹훅으로 콘텐츠를 변환합니다 (실패하면 원본 사용)
+	if cfg.PostProcessWebhookURL != "" {
+		transformed, err := postProcessContent(ctx, postProcessRequest{
+			PostID:  req.PostID,
+			Title:   displayTitle,
+			Format:  req.Format,
+			Content: processedContent,
+		})
+		if err != nil {
+			log.Printf("Post-processing webhook failed for post %s: %v", req.PostID, err)
+			warnings = append(warnings, newWarning(WarningPostProcessFailed, "post-processing webhook failed, returning unprocessed content: %v", err))
+		} else {
+			processedContent = transformed
+		}
+	}
+
+	title, titleTruncated := truncateTitle(displayTitle, cfg.MaxTitleLength)
+
+	// Prepare the response
+	response := ContentResponse{
+		Content:              processedContent,
+		Format:               req.Format,
+		PostID:               req.PostID,
+		Title:                title,
+		CharCount:            int64(utf8.RuneCountInString(processedContent)),
+		ByteCount:            int64(len(processedContent)),
+		TokenCount:           estimateTokens(processedContent),
+		HasMore:              hasMore,
+		TitleTruncated:       titleTruncated,
+		SpaceID:              spaceID,
+		UpdatedAt:            post.UpdatedAt,
+		ContentHash:          hash,
+		ContentHashAlgorithm: hashAlgorithm,
+		Warnings:             warnings,
+		partial:              post.isPartial(),
+	}
+	if post.Space != nil {
+		response.SpaceName = post.Space.Name
+	}
+	response.SanitizeProfile = sanitizeProfile
+
+	if len(req.Fields) > 0 || len(req.FieldTypes) > 0 {
+		response.Fields = formatFields(selectFields(post.MappingFields, req.Fields, req.FieldTypes), req.Format)
+		if len(response.Fields) == 0 {
+			response.Warnings = append(response.Warnings, newWarning(WarningNoFieldsMatched, "no mapping fields matched the requested fields/field_types"))
+		}
+	}
+
+	if req.IncludeAttachments {
+		response.Attachments = convertAttachments(post.Attachments)
+	}
+
+	if req.IncludeEngagement {
+		response.Engagement = convertEngagement(post)
+	}
+
+	response.Embeds = embeds
+	response.TOC = toc
+	response.Footnotes = footnotes
+	response.Summary = summary
+	response.SummaryDerived = summaryDerived
+	response.Tables = tables
+	response.TableHeaders = tableHeaders
+	response.Thumbnail = thumbnail
+	response.Links = links
+
+	if req.TranslateTo != "" {
+		text := processedContent
+		switch req.Format {
+		case "text":
+		case FormatJSONLD:
+			text = jsonLDArticleBody(processedContent)
+		default:
+			text = stripHTMLTags(processedContent)
+		}
+		translated, err := translateText(ctx, text, req.TranslateTo)
+		if err != nil {
+			log.Printf("Translation of post %s to %s failed: %v", req.PostID, req.TranslateTo, err)
+			response.Warnings = append(response.Warnings, newWarning(WarningTranslationFailed, "translation to %s failed: %v", req.TranslateTo, err))
+		} else {
+			response.TranslatedText = translated
+			response.TranslatedTo = req.TranslateTo
+		}
+	}
+
+	// 글자 수는 인코딩 전 content 기준입니다
+	if req.Encoding == EncodingBase64 {
+		response.Content = base64.StdEncoding.EncodeToString([]byte(response.Content))
+		response.Encoding = EncodingBase64
+	}
+
+	return response, nil
+}
+
+// errTokenRejectedAfterRefresh는 새로 발급한 게스트 토큰도 BetterMode가 401로 거절했음을 나타냅니다
+var errTokenRejectedAfterRefresh = errors.New("BetterMode rejected the access token again after refreshing it (HTTP 401)")
+
+// fetchContentFromBetterMode는 BetterMode에서 게시물을 가져옵니다.
+// 관리 중인 토큰이 401로 거절되면 토큰을 한 번만 갱신해 다시 시도하고, 그래도 거절되면 errTokenRejectedAfterRefresh를 반환합니다.
+func fetchContentFromBetterMode(ctx context.Context, postID string, opts ContentOptions) (*Post, error) {
+	return fetchContentAttempt(ctx, postID, opts, true)
+}
+
+// fetchContentAttempt는 fetchContentFromBetterMode의 한 번의 시도입니다. refreshOnUnauthorized가 false이면 401에 토큰을 갱신하지 않습니다.
+func fetchContentAttempt(ctx context.Context, postID string, opts ContentOptions, refreshOnUnauthorized bool) (*Post, error) {
+	// 호출자 토큰이 없으면 토큰 관리자에서 유효한 토큰 얻기
+	token, supplied, err := upstreamToken(ctx)
 	if err != nil {
-		return "", "", fmt.Errorf("error creating request: %w", err)
+		return nil, err
 	}
 
-	// Set headers with dynamic token
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "*/*")
-	req.Header.Set("User-Agent", "GPTers-Scraper/1.0")
-	req.Header.Set("Authorization", "Bearer "+token)
+	// 요청 옵션에 필요한 필드만 조회합니다
+	query := buildPostQuery(opts)
+	variables := map[string]interface{}{"id": postID}
+
+	// 같은 쿼리를 방금 보냈으면 받아 둔 원본 응답을 씁니다 (UPSTREAM_RESPONSE_CACHE_TTL)
+	responseKey := upstreamResponseKey(token, query, variables)
+	if !opts.NoCache {
+		if body, ok := upstreamResponses.get(responseKey); ok {
+			return parsePostResponse(postID, body, opts)
+		}
+	}
 
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	if err := upstreamBreaker.check(time.Now()); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	// CHAOS_ENABLED이면 이 요청에 장애를 주입할 수 있습니다 (chaosTransport)
+	resp, err := sendGraphQLRequest(withChaos(ctx), token, query, variables)
+	upstreamLatency.observe(time.Since(start), time.Now())
 	if err != nil {
-		return "", "", fmt.Errorf("error sending request: %w", err)
+		if ctx.Err() == nil {
+			upstreamBreaker.record(false, time.Now())
+		}
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	// 5xx와 429는 BetterMode 쪽 문제로 보고 서킷 브레이커에 실패로 기록합니다
+	upstreamBreaker.record(resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests, time.Now())
+
 	// Check for unauthorized response (token might be expired)
 	if resp.StatusCode == http.StatusUnauthorized {
+		if supplied {
+			return nil, errCallerTokenRejected
+		}
+		if !refreshOnUnauthorized {
+			return nil, errTokenRejectedAfterRefresh
+		}
 		// Force token refresh and retry once
 		log.Println("Token seems expired, refreshing and retrying...")
 		err := tokenManager.RefreshToken()
 		if err != nil {
-			return "", "", fmt.Errorf("failed to refresh token: %w", err)
+			return nil, fmt.Errorf("failed to refresh token: %w", err)
 		}
 
 		// Retry with new token
-		return fetchContentFromBetterMode(postID)
+		return fetchContentAttempt(ctx, postID, opts, false)
 	}
 
-	// Read the response
-	body, err := io.ReadAll(resp.Body)
+	// Read the response (끊긴 응답과 JSON이 아닌 응답은 여기서 걸러집니다)
+	body, err := readJSONResponse(resp)
 	if err != nil {
-		return "", "", fmt.Errorf("error reading response: %w", err)
+		return nil, err
+	}
+
+	post, err := parsePostResponse(postID, body, opts)
+	// 에러 응답과 일부 데이터만 받은 응답은 다음 요청에서 다시 받도록 저장하지 않습니다
+	if err == nil && !post.isPartial() {
+		upstreamResponses.set(responseKey, postID, body)
+	}
+	return post, err
+}
+
+// parsePostResponse는 게시물 조회 응답 본문을 해석하고, 게시물이 없거나 본문이 비어 있으면 에러를 반환합니다
+func parsePostResponse(postID string, body []byte, opts ContentOptions) (*Post, error) {
+	// encoding/json은 잘못된 UTF-8 바이트를 조용히 U+FFFD로 바꾸므로, 바꾸기 전에 확인해 둡니다
+	invalidUTF8 := !utf8.Valid(body)
+	if invalidUTF8 && currentConfig().InvalidUTF8Policy == InvalidUTF8Strict {
+		return nil, errInvalidUTF8
 	}
 
 	// Parse the response
 	var postResp PostResponse
 	if err := json.Unmarshal(body, &postResp); err != nil {
-		return "", "", fmt.Errorf("error parsing response: %w", err)
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+
+	// 잘못된 post ID 형식 같은 입력 에러는 "content field not found"로 뭉개지 않고 그대로 알립니다
+	if err := graphQLErrorsToError(postResp.Errors); errors.As(err, new(*inputError)) {
+		return nil, err
+	}
+	if len(postResp.Errors) > 0 && postResp.Errors[0].isNotFound() {
+		return nil, errPostNotFound
+	}
+
+	post := &postResp.Data.Post
+	post.invalidUTF8 = invalidUTF8
+	// 제목은 받았지만 mappingFields 같은 일부 필드가 에러로 빠진 부분 응답 (GRAPHQL_PARTIAL_DATA)
+	if len(postResp.Errors) > 0 && post.Title != "" {
+		if currentConfig().GraphQLPartialData == PartialDataAllow {
+			post.partialErrors = graphQLErrorMessages(postResp.Errors)
+			log.Printf("BetterMode returned partial data for post %s: %s", postID, post.partialErrors)
+			return post, nil
+		}
+		if !opts.MetadataOnly && post.ContentField() == "" {
+			return nil, graphQLErrorsToError(postResp.Errors)
+		}
+	}
+	if opts.MetadataOnly {
+		// mappingFields를 조회하지 않았으므로 게시물이 없을 때만 비어 있는 수정 시각으로 판단합니다
+		if post.UpdatedAt == "" {
+			return nil, errPostNotFound
+		}
+	} else if post.ContentField() == "" {
+		if post.UpdatedAt != "" {
+			return nil, errEmptyContent
+		}
+		return nil, errPostNotFound
+	}
+
+	return post, nil
+}
+
+// spaceID는 게시물이 속한 스페이스 ID를 반환합니다 (spaceId가 비어 있으면 space.id 사용)
+func (p *Post) spaceID() string {
+	if p.SpaceID == "" && p.Space != nil {
+		return p.Space.ID
+	}
+	return p.SpaceID
+}
+
+// ContentField는 key가 "content"인 매핑 필드의 값을 반환합니다.
+// 같은 key의 필드가 여러 개이면 CONTENT_FIELD_POLICY에 따라 하나를 고릅니다.
+func (p *Post) ContentField() string {
+	field, _ := selectContentField(p.MappingFields, currentConfig().ContentFieldPolicy)
+	return field.Value
+}
+
+// content 필드가 여러 개일 때 사용할 선택 정책
+const (
+	ContentFieldPreferHTML = "prefer_html" // html 타입 우선, 같으면 더 긴 값
+	ContentFieldLongest    = "longest"     // 타입과 관계없이 가장 긴 값
+	ContentFieldFirst      = "first"       // 응답 순서상 첫 번째 (기존 동작)
+)
+
+// selectContentField는 key가 "content"인 필드 중 정책에 맞는 하나와 content 필드의 개수를 반환합니다.
+// 필드 순서가 바뀌어도 같은 결과가 나오도록, 조건이 같으면 앞쪽 필드를 유지합니다.
+func selectContentField(fields []MappingField, policy string) (MappingField, int) {
+	var selected MappingField
+	count := 0
+	for _, field := range fields {
+		if field.Key != "content" {
+			continue
+		}
+		count++
+		if count == 1 || preferContentField(field, selected, policy) {
+			selected = field
+		}
+	}
+	return selected, count
+}
+
+// preferContentField는 candidate가 current보다 정책상 우선인지 판단합니다
+func preferContentField(candidate, current MappingField, policy string) bool {
+	switch policy {
+	case ContentFieldFirst:
+		return false
+	case ContentFieldLongest:
+		return len(candidate.Value) > len(current.Value)
+	default:
+		candidateHTML, currentHTML := candidate.Type == "html", current.Type == "html"
+		if candidateHTML != currentHTML {
+			return candidateHTML
+		}
+		return len(candidate.Value) > len(current.Value)
+	}
+}
+
+// selectFields는 요청한 key와 type에 맞는 매핑 필드만 골라 반환합니다.
+// keys나 types가 비어 있으면 해당 조건으로는 거르지 않습니다.
+func selectFields(fields []MappingField, keys, types []string) []MappingField {
+	selected := []MappingField{}
+	for _, field := range exposedFields(fields) {
+		if len(keys) > 0 && !containsString(keys, field.Key) {
+			continue
+		}
+		if len(types) > 0 && !containsString(types, field.Type) {
+			continue
+		}
+		selected = append(selected, field)
+	}
+	return selected
+}
+
+// exposedFields는 FIELD_DENY_LIST의 필드를 빼고, FIELD_ALLOW_LIST가 있으면 그 필드만 남깁니다 (key 대소문자 무시).
+// 요청과 관계없이 응답에 드러나면 안 되는 필드(내부 메모 등)를 막는 서버 정책이며, 본문(content)을 고르는 데는 적용하지 않습니다.
+func exposedFields(fields []MappingField) []MappingField {
+	cfg := currentConfig()
+	if len(cfg.FieldDenyList) == 0 && len(cfg.FieldAllowList) == 0 {
+		return fields
+	}
+	exposed := make([]MappingField, 0, len(fields))
+	for _, field := range fields {
+		if containsFold(cfg.FieldDenyList, field.Key) {
+			continue
+		}
+		if len(cfg.FieldAllowList) > 0 && !containsFold(cfg.FieldAllowList, field.Key) {
+			continue
+		}
+		exposed = append(exposed, field)
+	}
+	return exposed
+}
+
+// formatFields는 선택된 매핑 필드 값을 content와 같은 방식으로 정리합니다
+func formatFields(fields []MappingField, format string) []MappingField {
+	for i := range fields {
+		fields[i].Value = cleanupContent(fields[i].Value)
+		switch format {
+		case "text":
+			fields[i].Value = stripHTMLTags(fields[i].Value)
+		case "xhtml":
+			if converted, err := toXHTML(fields[i].Value); err == nil {
+				fields[i].Value = converted
+			}
+		}
 	}
+	return fields
+}
 
-	// Get the title
-	title := postResp.Data.Post.Title
+// convertAttachments는 BetterMode 첨부 파일 정보를 응답 형식으로 변환합니다
+func convertAttachments(files []PostAttachment) []Attachment {
+	attachments := make([]Attachment, 0, len(files))
+	for _, f := range files {
+		fileURL := f.DownloadURL
+		if fileURL == "" {
+			fileURL = f.URL
+		}
 
-	// Find the content field
-	var content string
-	for _, field := range postResp.Data.Post.MappingFields {
-		if field.Key == "content" {
-			content = field.Value
-			break
+		mimeType := "application/octet-stream"
+		if f.Extension != "" {
+			if t := mime.TypeByExtension("." + strings.TrimPrefix(f.Extension, ".")); t != "" {
+				mimeType = t
+			}
 		}
+
+		attachments = append(attachments, Attachment{
+			Filename: f.Name,
+			URL:      fileURL,
+			Size:     f.Size,
+			MimeType: mimeType,
+		})
 	}
+	return attachments
+}
 
-	if content == "" {
-		return "", title, fmt.Errorf("content field not found")
+// visibleCharCount는 태그와 앞뒤 공백을 뺀 본문 글자(rune) 수입니다
+func visibleCharCount(content, format string) int {
+	switch format {
+	case "text":
+	case FormatJSONLD:
+		content = jsonLDArticleBody(content)
+	default:
+		content = html.UnescapeString(stripHTMLTags(content))
 	}
+	return utf8.RuneCountInString(strings.TrimSpace(content))
+}
 
-	return content, title, nil
+// truncateTitle은 제목이 max 글자(rune)보다 길면 max 글자까지 남기고 "…"를 붙입니다.
+// max가 0 이하이면 자르지 않습니다.
+func truncateTitle(title string, max int) (string, bool) {
+	if max <= 0 || utf8.RuneCountInString(title) <= max {
+		return title, false
+	}
+	runes := []rune(title)
+	return strings.TrimRightFunc(string(runes[:max]), unicode.IsSpace) + "…", true
+}
+
+// convertEngagement는 게시물의 반응/댓글 수를 응답 형식으로 변환합니다
+func convertEngagement(post *Post) *Engagement {
+	engagement := &Engagement{Reactions: post.ReactionsCount, Replies: post.RepliesCount}
+	if len(post.Reactions) > 0 {
+		engagement.ByReaction = make(map[string]int64, len(post.Reactions))
+		for _, r := range post.Reactions {
+			engagement.ByReaction[r.Reaction] += r.Count
+		}
+	}
+	return engagement
+}
+
+// metadataOnlyResponse는 metadata_only 요청의 응답을 본문 없이 만듭니다
+func metadataOnlyResponse(req ContentRequest, post *Post, maxTitleLength int, warnings []Warning) ContentResponse {
+	title, titleTruncated := truncateTitle(post.Title, maxTitleLength)
+	response := ContentResponse{
+		Format:         req.Format,
+		PostID:         req.PostID,
+		Title:          title,
+		TitleTruncated: titleTruncated,
+		SpaceID:        post.spaceID(),
+		UpdatedAt:      post.UpdatedAt,
+		Warnings:       warnings,
+		partial:        post.isPartial(),
+	}
+	if post.Space != nil {
+		response.SpaceName = post.Space.Name
+	}
+	if req.IncludeAttachments {
+		response.Attachments = convertAttachments(post.Attachments)
+	}
+	if req.IncludeEngagement {
+		response.Engagement = convertEngagement(post)
+	}
+	return response
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold는 대소문자를 무시하고 list에 s가 있는지 확인합니다
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
 }
 
 // cleanupContent cleans up HTML and escaped characters in the content
@@ -384,6 +1592,21 @@ func unescapeUnicodeJSON(s string) (string, error) {
 	return result.Content, nil
 }
 
+// contentToText는 text 형식 응답을 위해 HTML을 일반 텍스트로 변환합니다.
+// codeLineNumbers가 true이면 태그를 제거하기 전에 코드 블록 각 줄에 줄 번호를 붙입니다.
+// 줄 번호를 붙이지 못한 경우에도 변환된 텍스트와 함께 에러를 반환하므로, 호출자는 에러를 경고로만 다루면 됩니다.
+func contentToText(content string, codeLineNumbers bool) (string, error) {
+	if codeLineNumbers {
+		numbered, err := numberCodeLines(content)
+		if err != nil {
+			return stripHTMLTags(content), err
+		}
+		// 다시 렌더링하면서 이스케이프된 엔티티(&lt; 등)를 되돌립니다
+		return html.UnescapeString(stripHTMLTags(numbered)), nil
+	}
+	return stripHTMLTags(content), nil
+}
+
 // stripHTMLTags removes HTML tags from the content to provide plain text
 func stripHTMLTags(html string) string {
 	// Basic HTML tag removal
@@ -442,93 +1665,242 @@ func extractPostIDFromURL(url string) (string, error) {
 // @Tags content
 // @Accept json
 // @Produce json
-// @Param request body URLRequest true "BetterMode URL and optional format (html or text)"
+// @Produce application/ld+json
+// @Param request body URLRequest true "BetterMode URL and optional format (html, text, xhtml or jsonld)"
 // @Success 200 {object} ContentResponse
 // @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "Post not found (deleted or not visible)"
 // @Failure 500 {string} string "Internal server error"
+// @Failure 502 {string} string "BetterMode unavailable (non-JSON response)"
+// @Failure 504 {string} string "Timed out fetching from BetterMode"
 // @Router /url [post]
+// @Router /url [get]
 func getContentFromURL(w http.ResponseWriter, r *http.Request) {
 	var req URLRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := decodeContentRequest(r, &req, decodeStrict); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	if req.URL == "" {
-		http.Error(w, "URL is required", http.StatusBadRequest)
+		writeError(w, "URL is required", http.StatusBadRequest)
 		return
 	}
 
-	// Set default format to html if not specified
-	if req.Format == "" {
-		req.Format = "html"
-	} else if req.Format != "html" && req.Format != "text" {
-		http.Error(w, "Format must be 'html' or 'text'", http.StatusBadRequest)
+	if err := req.ContentOptions.normalize(); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	// Extract post ID from URL
 	postID, err := extractPostIDFromURL(req.URL)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error extracting post ID: %v", err), http.StatusBadRequest)
+		writeError(w, fmt.Sprintf("Error extracting post ID: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	// Fetch content and title
-	content, title, err := fetchContentFromBetterMode(postID)
+	response, err := buildContentResponse(r.Context(), ContentRequest{PostID: postID, ContentOptions: req.ContentOptions})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error fetching content: %v", err), http.StatusInternalServerError)
+		writeFetchError(w, err)
 		return
 	}
 
-	// Clean up the content value
-	processedContent := cleanupContent(content)
+	setCacheControl(w, req.NoCache)
+	setServerTiming(w, response)
+	if checkNotModified(w, r, response.UpdatedAt) {
+		return
+	}
+	if response.Format == FormatJSONLD {
+		writeJSONLD(w, response)
+		return
+	}
+	writeContentJSON(w, r, response)
+}
 
-	// If format is text, try to strip HTML tags
-	if req.Format == "text" {
-		processedContent = stripHTMLTags(processedContent)
+func main() {
+	if err := applyConfigFile(); err != nil {
+		log.Fatalf("Failed to load config file: %v", err)
 	}
+	setConfig(loadConfig())
+	cfg := currentConfig()
 
-	// Prepare the response
-	response := ContentResponse{
-		Content:   processedContent,
-		Format:    req.Format,
-		PostID:    postID,
-		Title:     title,
-		CharCount: len(processedContent),
+	if err := configureUpstreamTLS(cfg); err != nil {
+		log.Fatalf("Failed to configure upstream TLS: %v", err)
 	}
 
-	render.JSON(w, r, response)
-}
+	// CLI 모드: 서버를 띄우지 않고 게시물 하나를 가져와 출력합니다
+	if len(os.Args) > 1 && os.Args[1] == "fetch" {
+		os.Exit(runFetchCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	// SIGINT/SIGTERM을 받으면 취소되는 종료 컨텍스트
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 백그라운드 고루틴 추적용 (종료 시 모두 끝날 때까지 대기)
+	var wg sync.WaitGroup
+
+	if err := checkChaosConfig(cfg); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.ChaosEnabled {
+		upstreamClient.Transport = newChaosTransport(upstreamClient.Transport)
+		log.Printf("Chaos mode enabled (APP_ENV=%s): injecting failures into content fetches", cfg.AppEnv)
+	}
 
-func main() {
 	// 토큰 관리자 초기화
-	tokenManager = NewTokenManager("www.gpters.org")
+	var err error
+	tokenManager, err = NewTokenManager(cfg.TokenNetworkDomains, cfg.FailOnInitialTokenError)
+	if err != nil {
+		log.Fatalf("Failed to initialize token manager: %v", err)
+	}
+
+	// 토큰이 실제로 BetterMode에서 통하는지 시작 시 확인 (선택)
+	if cfg.ValidateTokenOnStartup {
+		validateCtx, cancel := context.WithTimeout(ctx, readinessTimeout)
+		err := tokenManager.Validate(validateCtx)
+		cancel()
+		if err != nil {
+			if cfg.FailOnInitialTokenError {
+				log.Fatalf("Token validation failed: %v", err)
+			}
+			log.Printf("Token validation failed: %v", err)
+		} else {
+			log.Println("Token validated against BetterMode")
+		}
+	}
+
+	// 기본 스페이스가 실제로 있는지 시작 시 확인 (잘못된 ID로 모든 조회가 실패하지 않도록)
+	if cfg.DefaultSpaceID != "" {
+		validateCtx, cancel := context.WithTimeout(ctx, readinessTimeout)
+		err := checkDefaultSpace(validateCtx, cfg.DefaultSpaceID)
+		cancel()
+		if err != nil {
+			log.Fatalf("Invalid DEFAULT_SPACE_ID %s: %v", cfg.DefaultSpaceID, err)
+		}
+	}
+
+	if cfg.AdminKey == "" {
+		log.Println("ADMIN_KEY is not set; admin endpoints are not protected")
+	}
+
+	if cfg.CircuitBreakerThreshold > 0 {
+		upstreamBreaker = newCircuitBreaker("BetterMode API", cfg.CircuitBreakerThreshold, cfg.CircuitBreakerOpenDuration)
+	}
+	if cfg.TokenCircuitBreakerThreshold > 0 {
+		tokenBreaker = newCircuitBreaker("BetterMode token endpoint", cfg.TokenCircuitBreakerThreshold, cfg.TokenCircuitBreakerOpenDuration)
+	}
+
+	translator = newTranslator()
+	if cfg.ImageProxyCacheEntries > 0 {
+		imageCache = newImageProxyCache(cfg.ImageProxyCacheEntries)
+	}
+	if cfg.UpstreamResponseCacheEntries > 0 {
+		upstreamResponses = newUpstreamResponseCache(cfg.UpstreamResponseCacheEntries)
+	}
+
+	// 콘텐츠 캐시 및 만료 항목 정리 고루틴 시작
+	contentCache = NewContentCache(cfg.CacheTTL, cfg.CacheSoftTTL, cfg.CacheMaxEntries)
+	contentCache.StartJanitor(ctx, &wg, cfg.CacheCleanupInterval)
+
+	// 백그라운드 내보내기 작업 (서버 종료 시 진행 중인 작업을 기다림)
+	exports = newExportManager(ctx, &wg)
+
+	// BetterMode 웹훅으로 알게 된 수정된 게시물을 백그라운드에서 다시 가져옴
+	prefetches = newPrefetcher(cfg.PrefetchQueueSize)
+	prefetches.start(ctx, &wg)
+
+	// SIGHUP을 받으면 CONFIG_FILE과 환경 변수에서 설정을 다시 읽습니다
+	watchReloadSignal(ctx, &wg)
 
 	r := chi.NewRouter()
 
 	// Middleware
-	r.Use(middleware.Logger)
+	accessLogSampleRate.Store(int64(cfg.AccessLogSampleRate))
+	setLogLevel(cfg.LogLevel)
+	r.Use(middleware.RequestID) // X-Request-ID가 있으면 그대로, 없으면 새로 만들어 요청 로그와 BetterMode 요청에 사용
+	r.Use(requestTracing)
+	r.Use(newAccessLogger()) // 성공 응답은 ACCESS_LOG_SAMPLE_RATE 비율로, 에러는 항상 기록
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*", "https://gpters.automationpro.online"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Admin-Key", "X-Admin-Timestamp", "X-Admin-Nonce", "X-Admin-Signature", callerTokenHeader, requestIDHeader},
+		ExposedHeaders:   []string{"Link", requestIDHeader, upstreamRequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
+	applyRateLimit(cfg)
+
+	// 지원하지 않는 메서드에는 Allow 헤더와 JSON 에러로 응답 (하위 라우터에도 적용되도록 경로 등록 전에 설정)
+	r.MethodNotAllowed(methodNotAllowedHandler(r))
+
 	// API Routes
 	r.Route("/api/v1", func(r chi.Router) {
-		r.Post("/content", getContent)
-		r.Post("/url", getContentFromURL) // URL로부터 콘텐츠 가져오는 새 엔드포인트
-
-		// 토큰 관리 엔드포인트 (관리자용) 추가
-		r.Get("/token/refresh", handleTokenRefresh)
-		r.Get("/token/status", handleTokenStatus)
+		// 과부하이면 새 요청을 503으로 거절 (SHED_MAX_IN_FLIGHT, SHED_UPSTREAM_LATENCY)
+		r.Use(loadShedder)
+		// PER_IP_MAX_CONCURRENT가 설정되어 있으면 IP별 동시 요청 수 제한
+		r.Use(ipConcurrencyMiddleware)
+		// RATE_LIMIT_RPS가 설정되어 있으면 요청 속도 제한
+		r.Use(rateLimitMiddleware)
+		// X-BetterMode-Token으로 받은 호출자 토큰을 BetterMode 호출에 사용
+		r.Use(callerTokenMiddleware)
+		// 토큰 관리자가 준비되기 전에는 503 (핸들러가 nil 토큰 관리자를 쓰지 않도록)
+		r.Use(requireTokenManager)
+
+		r.Group(func(r chi.Router) {
+			// DEBUG_LOG_BODIES가 켜져 있으면 요청/응답 본문 로그
+			r.Use(debugBodyLogger)
+
+			r.Post("/content", getContent)
+			r.Get("/content", getContent)     // 쿼리 파라미터로 옵션 전달
+			r.Post("/url", getContentFromURL) // URL로부터 콘텐츠 가져오는 새 엔드포인트
+			r.Get("/url", getContentFromURL)
+		})
+		r.Post("/archive", getArchive)                                 // 게시물과 이미지/첨부 파일을 tar.gz로 받기
+		r.Get("/archive", getArchive)                                  // 쿼리 파라미터로 옵션 전달
+		r.Post("/batch", getBatchContent)                              // 여러 게시물을 한 번에 가져오기
+		r.Get("/collections/{collectionID}/posts", getCollectionPosts) // 컬렉션(시리즈)의 게시물을 순서대로 가져오기
+		r.Get("/spaces/{spaceID}/changes", getSpaceChanges)            // since 이후 수정된 게시물 목록 (증분 동기화용)
+		r.Get("/changes", getSpaceChanges)                             // space_id 쿼리 파라미터나 DEFAULT_SPACE_ID 스페이스의 변경 목록
+		r.Get("/spaces/{spaceID}/pinned", getSpacePinnedPosts)         // 스페이스에 고정된 게시물을 고정 순서대로 가져오기
+		r.Get("/spaces/{spaceID}/posts", getSpacePosts)                // 스페이스 게시물 목록 (fields로 필요한 필드만)
+		r.Get("/spaces/{spaceID}/export.csv", exportSpaceCSV)          // 스페이스 게시물의 메타데이터를 CSV로 받기
+		r.Get("/pinned", getSpacePinnedPosts)                          // space_id 쿼리 파라미터나 DEFAULT_SPACE_ID 스페이스의 고정된 게시물
+		r.Get("/posts", getSpacePosts)                                 // space_id 쿼리 파라미터나 DEFAULT_SPACE_ID 스페이스의 게시물 목록
+		r.Get("/export.csv", exportSpaceCSV)                           // space_id 쿼리 파라미터나 DEFAULT_SPACE_ID 스페이스의 CSV
+		r.Get("/image-proxy", handleImageProxy)                        // proxy_images로 바꾼 이미지 주소를 대신 가져와 전달
+		r.Post("/compile", compileContent)                             // 여러 게시물을 하나의 문서로 합치기
+		r.Post("/exports", startExport)                                // 게시물들을 파일/S3로 내보내는 작업 시작
+		r.Get("/exports/{jobID}", getExport)                           // 내보내기 작업 상태
+		r.Post("/webhooks/bettermode", handleBetterModeWebhook)        // 게시물 수정 알림을 받아 캐시를 백그라운드에서 갱신
+
+		// 관리자용 엔드포인트 (ADMIN_KEY가 설정되어 있으면 인증 필요)
+		r.Group(func(r chi.Router) {
+			r.Use(adminAuth)
+
+			// 토큰 관리 엔드포인트
+			r.Get("/token/refresh", handleTokenRefresh)
+			r.Get("/token/status", handleTokenStatus)
+			r.Get("/tokens/status", handleTokensStatus)
+
+			// 적용 중인 설정 (비밀 값은 가림)
+			r.Get("/config", handleConfig)
+
+			// 콘텐츠 캐시 크기와 설정
+			r.Get("/cache/stats", handleCacheStats)
+
+			// 요청 로그 샘플링 비율 확인/변경
+			r.Get("/logging/sample-rate", handleLogSampleRate)
+			r.Post("/logging/sample-rate", handleLogSampleRate)
+		})
 	})
 
+	// 헬스 체크
+	r.Get("/healthz", handleHealthz)
+	r.Get("/readyz", handleReadyz)
+
 	// Swagger docs
 	r.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("https://gpters.automationpro.online/swagger/doc.json"),
@@ -538,21 +1910,37 @@ func main() {
 	))
 
 	// Start the server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	srv := &http.Server{
+		Addr:    ":" + cfg.Port,
+		Handler: r,
 	}
 
-	log.Printf("Server starting on port %s...\n", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
+	go func() {
+		log.Printf("Server starting on port %s...\n", cfg.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown error: %v", err)
+	}
+
+	// 캐시 정리 고루틴 등 백그라운드 작업이 끝날 때까지 대기
+	wg.Wait()
+	log.Println("Server stopped")
 }
 
 // handleTokenRefresh는 토큰을 수동으로 갱신하는 엔드포인트입니다 (관리자용)
 func handleTokenRefresh(w http.ResponseWriter, r *http.Request) {
-	// 실제 서비스에서는 관리자 인증 추가 필요
 	err := tokenManager.RefreshToken()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to refresh token: %v", err), http.StatusInternalServerError)
+		writeError(w, fmt.Sprintf("Failed to refresh token: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -562,9 +1950,13 @@ func handleTokenRefresh(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleCacheStats는 콘텐츠 캐시의 최대 항목 수와 현재 크기를 보여 주는 엔드포인트입니다 (관리자용)
+func handleCacheStats(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, contentCache.Stats())
+}
+
 // handleTokenStatus는 현재 토큰 상태를 확인하는 엔드포인트입니다 (관리자용)
 func handleTokenStatus(w http.ResponseWriter, r *http.Request) {
-	// 실제 서비스에서는 관리자 인증 추가 필요
 	tokenManager.mutex.RLock()
 	defer tokenManager.mutex.RUnlock()
 
@@ -574,11 +1966,29 @@ func handleTokenStatus(w http.ResponseWriter, r *http.Request) {
 		tokenPreview = tokenManager.accessToken[:10] + "..."
 	}
 
+	render.JSON(w, r, map[string]interface{}{
+		"status":           "success",
+		"token_preview":    tokenPreview,
+		"expiry":           tokenManager.expiry,
+		"is_valid":         time.Now().Before(tokenManager.expiry),
+		"expires_in":       time.Until(tokenManager.expiry).String(),
+		"active_source":    tokenManager.sources[tokenManager.active].networkDomain,
+		"sources":          tokenManager.sourceStatuses(),
+		"breaker":          tokenBreaker.status(time.Now()),
+		"requested_scopes": currentConfig().TokenScopes,
+		"granted_scopes":   tokenManager.scopes,
+	})
+}
+
+// handleTokensStatus는 TOKEN_NETWORK_DOMAINS의 모든 도메인의 토큰 상태를 한 번에 보여 주는 엔드포인트입니다 (관리자용)
+func handleTokensStatus(w http.ResponseWriter, r *http.Request) {
+	tokenManager.mutex.RLock()
+	defer tokenManager.mutex.RUnlock()
+
 	render.JSON(w, r, map[string]interface{}{
 		"status":        "success",
-		"token_preview": tokenPreview,
-		"expiry":        tokenManager.expiry,
-		"is_valid":      time.Now().Before(tokenManager.expiry),
-		"expires_in":    time.Until(tokenManager.expiry).String(),
+		"active_source": tokenManager.sources[tokenManager.active].networkDomain,
+		"domains":       tokenManager.domainStatuses(time.Now()),
+		"breaker":       tokenBreaker.status(time.Now()),
 	})
 }
@@ -0,0 +1,1010 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetContentCountsRunesAndBytes(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantChars int64
+		wantBytes int64
+	}{
+		{"ascii", "hello", 5, 5},
+		{"korean", "안녕하세요", 5, 15},
+		{"mixed", "GPT 스터디", 7, 13},
+		{"emoji", "좋아요👍", 4, 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", tt.content)))
+			})
+			rec := httptest.NewRecorder()
+			getContent(rec, httptest.NewRequest(http.MethodPost, "/api/v1/content", strings.NewReader(`{"post_id":"post-1","format":"text"}`)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+			}
+			var response struct {
+				CharCount int64 `json:"char_count"`
+				ByteCount int64 `json:"byte_count"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.CharCount != tt.wantChars {
+				t.Errorf("char_count = %d, want %d", response.CharCount, tt.wantChars)
+			}
+			if response.ByteCount != tt.wantBytes {
+				t.Errorf("byte_count = %d, want %d", response.ByteCount, tt.wantBytes)
+			}
+		})
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	fields := []MappingField{
+		{Key: "content", Type: "html", Value: "<p>본문</p>"},
+		{Key: "summary", Type: "text", Value: "요약"},
+		{Key: "cover", Type: "image", Value: "https://example.com/a.png"},
+		{Key: "note", Type: "html", Value: "<p>메모</p>"},
+	}
+	tests := []struct {
+		name     string
+		keys     []string
+		types    []string
+		wantKeys []string
+	}{
+		{"no filter", nil, nil, []string{"content", "summary", "cover", "note"}},
+		{"by key", []string{"summary", "cover"}, nil, []string{"summary", "cover"}},
+		{"by type", nil, []string{"html"}, []string{"content", "note"}},
+		{"key and type", []string{"content", "summary"}, []string{"text"}, []string{"summary"}},
+		{"no match", []string{"missing"}, nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectFields(fields, tt.keys, tt.types)
+			keys := []string{}
+			for _, field := range got {
+				keys = append(keys, field.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("selectFields keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestExposedFields(t *testing.T) {
+	fields := []MappingField{
+		{Key: "content", Type: "html", Value: "<p>본문</p>"},
+		{Key: "summary", Type: "text", Value: "요약"},
+		{Key: "Internal_Notes", Type: "text", Value: "메모"},
+	}
+	tests := []struct {
+		name     string
+		deny     []string
+		allow    []string
+		wantKeys []string
+	}{
+		{"no policy", nil, nil, []string{"content", "summary", "Internal_Notes"}},
+		{"deny ignores case", []string{"internal_notes"}, nil, []string{"content", "summary"}},
+		{"allow only", nil, []string{"SUMMARY", "content"}, []string{"content", "summary"}},
+		{"deny wins over allow", []string{"summary"}, []string{"summary", "content"}, []string{"content"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) {
+				cfg.FieldDenyList = tt.deny
+				cfg.FieldAllowList = tt.allow
+			})
+			keys := []string{}
+			for _, field := range exposedFields(fields) {
+				keys = append(keys, field.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("exposedFields keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseFieldDenyList(t *testing.T) {
+	withConfig(t, func(cfg *Config) { cfg.FieldDenyList = []string{"summary", "internal_notes"} })
+	post := newTestPost("제목", "<p>첫 문단</p>")
+	post.MappingFields = append(post.MappingFields,
+		MappingField{Key: "summary", Type: "text", Value: "숨길 요약"},
+		MappingField{Key: "internal_notes", Type: "text", Value: "내부 메모"})
+
+	response := renderTestPost(t, ContentOptions{Format: "html", FieldTypes: []string{"text"}, IncludeSummary: true}, post)
+	if len(response.Fields) != 0 {
+		t.Errorf("fields = %+v, want denied fields removed", response.Fields)
+	}
+	if response.Summary == "숨길 요약" || !response.SummaryDerived {
+		t.Errorf("summary = %q (derived %v), want derived from content", response.Summary, response.SummaryDerived)
+	}
+	if !strings.Contains(response.Content, "첫 문단") {
+		t.Errorf("content = %q, deny list must not affect the content field", response.Content)
+	}
+}
+
+func TestNewTokenManagerInitialTokenError(t *testing.T) {
+	tests := []struct {
+		name        string
+		tokenStatus int
+		failOnError bool
+		wantErr     bool
+		wantToken   string
+	}{
+		{"token fetched", http.StatusOK, true, false, "guest-token"},
+		{"fetch fails, fail fast", http.StatusInternalServerError, true, true, ""},
+		{"fetch fails, retry later", http.StatusInternalServerError, false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.tokenStatus != http.StatusOK {
+					writeJSONResponse(w, tt.tokenStatus, map[string]interface{}{"errors": []map[string]string{{"message": "down"}}})
+					return
+				}
+				writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+					"data": map[string]interface{}{"tokens": map[string]string{"accessToken": "guest-token"}},
+				})
+			})
+
+			tm, err := NewTokenManager([]string{"www.gpters.org"}, tt.failOnError)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTokenManager error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tm == nil {
+				t.Fatal("NewTokenManager returned nil token manager")
+			}
+			if tm.accessToken != tt.wantToken {
+				t.Errorf("access token = %q, want %q", tm.accessToken, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestConvertAttachments(t *testing.T) {
+	tests := []struct {
+		name string
+		file PostAttachment
+		want Attachment
+	}{
+		{
+			"download url preferred",
+			PostAttachment{Name: "slides.pdf", URL: "https://cdn/view", DownloadURL: "https://cdn/download", Size: 1024, Extension: "pdf"},
+			Attachment{Filename: "slides.pdf", URL: "https://cdn/download", Size: 1024, MimeType: "application/pdf"},
+		},
+		{
+			"falls back to url",
+			PostAttachment{Name: "a.png", URL: "https://cdn/a.png", Extension: ".png"},
+			Attachment{Filename: "a.png", URL: "https://cdn/a.png", MimeType: "image/png"},
+		},
+		{
+			"unknown extension",
+			PostAttachment{Name: "data", URL: "https://cdn/data"},
+			Attachment{Filename: "data", URL: "https://cdn/data", MimeType: "application/octet-stream"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := convertAttachments([]PostAttachment{tt.file})
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("convertAttachments = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseIncludeAttachments(t *testing.T) {
+	post := newTestPost("제목", "<p>본문</p>")
+	post.Attachments = []PostAttachment{{Name: "a.pdf", URL: "https://cdn/a.pdf", Extension: "pdf"}}
+
+	tests := []struct {
+		name    string
+		include bool
+		want    int
+	}{
+		{"omitted by default", false, 0},
+		{"included on request", true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := renderTestPost(t, ContentOptions{IncludeAttachments: tt.include}, post)
+			if len(response.Attachments) != tt.want {
+				t.Errorf("attachments = %d, want %d", len(response.Attachments), tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildContentResponseCoalescesConcurrentFetches(t *testing.T) {
+	tests := []struct {
+		name    string
+		callers int
+		noCache bool
+	}{
+		{"cached requests", 10, false},
+		{"nocache requests", 10, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			release := make(chan struct{})
+			upstream := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				<-release
+				writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>본문</p>")))
+			})
+
+			var wg sync.WaitGroup
+			errs := make(chan error, tt.callers)
+			for i := 0; i < tt.callers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					req := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html", NoCache: tt.noCache}}
+					if _, err := buildContentResponse(context.Background(), req); err != nil {
+						errs <- err
+					}
+				}()
+			}
+			// 모든 호출이 진행 중인 업스트림 호출에 합류할 시간을 준 뒤 응답합니다
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				t.Errorf("buildContentResponse: %v", err)
+			}
+			if got := upstream.count(); got != 1 {
+				t.Errorf("upstream calls = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestSelectContentField(t *testing.T) {
+	fields := []MappingField{
+		{Key: "title", Type: "text", Value: "제목이 가장 깁니다 제목이 가장 깁니다"},
+		{Key: "content", Type: "text", Value: "텍스트 본문"},
+		{Key: "content", Type: "html", Value: "<p>짧음</p>"},
+		{Key: "content", Type: "html", Value: "<p>조금 더 긴 html</p>"},
+	}
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{ContentFieldPreferHTML, "<p>조금 더 긴 html</p>"},
+		{ContentFieldLongest, "<p>조금 더 긴 html</p>"},
+		{ContentFieldFirst, "텍스트 본문"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			got, count := selectContentField(fields, tt.policy)
+			if got.Value != tt.want {
+				t.Errorf("selected %q, want %q", got.Value, tt.want)
+			}
+			if count != 3 {
+				t.Errorf("count = %d, want 3", count)
+			}
+		})
+	}
+}
+
+func TestSelectContentFieldIgnoresOrder(t *testing.T) {
+	a := MappingField{Key: "content", Type: "text", Value: "텍스트 본문이 훨씬 더 깁니다"}
+	b := MappingField{Key: "content", Type: "html", Value: "<p>html</p>"}
+	for _, policy := range []string{ContentFieldPreferHTML, ContentFieldLongest} {
+		first, _ := selectContentField([]MappingField{a, b}, policy)
+		second, _ := selectContentField([]MappingField{b, a}, policy)
+		if first != second {
+			t.Errorf("policy %s: selection depends on order (%q vs %q)", policy, first.Value, second.Value)
+		}
+	}
+}
+
+func TestSetCacheControl(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxAge  time.Duration
+		noCache bool
+		want    string
+	}{
+		{"max-age", 5 * time.Minute, false, "public, max-age=300"},
+		{"nocache request", 5 * time.Minute, true, "no-store"},
+		{"max-age disabled", 0, false, "no-store"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.CacheControlMaxAge = tt.maxAge })
+			w := httptest.NewRecorder()
+			setCacheControl(w, tt.noCache)
+			if got := w.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetContentNoCacheBypassesCache(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	upstream := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>본문</p>")))
+	})
+
+	tests := []struct {
+		target    string
+		wantCalls int
+	}{
+		{"/api/v1/content?post_id=p1", 1},
+		{"/api/v1/content?post_id=p1", 1},         // 캐시 적중
+		{"/api/v1/content?post_id=p1&nocache", 2}, // 캐시를 읽지 않음
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		getContent(w, httptest.NewRequest("GET", tt.target, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d: %s", tt.target, w.Code, w.Body.String())
+		}
+		if got := upstream.count(); got != tt.wantCalls {
+			t.Errorf("GET %s: upstream calls = %d, want %d", tt.target, got, tt.wantCalls)
+		}
+	}
+}
+
+func TestRenderContentResponseWarnings(t *testing.T) {
+	tests := []struct {
+		name string
+		post *Post
+		opts ContentOptions
+		want []string
+	}{
+		{"clean post", newTestPost("제목", "<p>본문</p>"), ContentOptions{}, nil},
+		{"missing title", newTestPost("", "<p>본문</p>"), ContentOptions{}, []string{WarningMissingTitle}},
+		{"empty text", newTestPost("제목", "<img src=\"a.png\">"), ContentOptions{Format: "text"}, []string{WarningEmptyText}},
+		{"no fields matched", newTestPost("제목", "<p>본문</p>"), ContentOptions{Fields: []string{"missing"}}, []string{WarningNoFieldsMatched}},
+		{
+			"duplicate content fields",
+			&Post{Title: "제목", MappingFields: []MappingField{
+				{Key: "content", Type: "html", Value: "<p>a</p>"},
+				{Key: "content", Type: "text", Value: "a"},
+			}},
+			ContentOptions{},
+			[]string{WarningDuplicateContentFields},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := renderTestPost(t, tt.opts, tt.post)
+			var codes []string
+			for _, w := range response.Warnings {
+				codes = append(codes, w.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.want) {
+				t.Errorf("warnings = %v, want %v", codes, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateTitle(t *testing.T) {
+	tests := []struct {
+		name          string
+		title         string
+		max           int
+		want          string
+		wantTruncated bool
+	}{
+		{"no limit", "아주 긴 제목입니다", 0, "아주 긴 제목입니다", false},
+		{"fits", "짧은 제목", 5, "짧은 제목", false},
+		{"korean runes", "가나다라마바사", 3, "가나다…", true},
+		{"trailing space trimmed", "GPT 스터디 모임", 4, "GPT…", true},
+		{"emoji not split", "👍👍👍", 2, "👍👍…", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := truncateTitle(tt.title, tt.max)
+			if got != tt.want || truncated != tt.wantTruncated {
+				t.Errorf("truncateTitle(%q, %d) = %q, %v; want %q, %v", tt.title, tt.max, got, truncated, tt.want, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseBase64(t *testing.T) {
+	post := newTestPost("제목", "<p>안녕하세요</p>")
+	plain := renderTestPost(t, ContentOptions{}, post)
+	encoded := renderTestPost(t, ContentOptions{Encoding: EncodingBase64}, post)
+
+	if encoded.Encoding != EncodingBase64 {
+		t.Errorf("encoding = %q, want base64", encoded.Encoding)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded.Content)
+	if err != nil {
+		t.Fatalf("content is not standard base64: %v", err)
+	}
+	if string(decoded) != plain.Content {
+		t.Errorf("decoded content = %q, want %q", decoded, plain.Content)
+	}
+	if plain.Encoding != "" {
+		t.Errorf("plain encoding = %q, want empty", plain.Encoding)
+	}
+}
+
+func TestNormalizeEncoding(t *testing.T) {
+	tests := []struct {
+		encoding string
+		wantErr  bool
+	}{
+		{"", false},
+		{"base64", false},
+		{"gzip", true},
+	}
+	for _, tt := range tests {
+		opts := ContentOptions{Encoding: tt.encoding}
+		if err := opts.normalize(); (err != nil) != tt.wantErr {
+			t.Errorf("normalize(encoding %q) error = %v, wantErr %v", tt.encoding, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRenderContentResponseSpace(t *testing.T) {
+	tests := []struct {
+		name     string
+		spaceID  string
+		space    *PostSpace
+		wantID   string
+		wantName string
+	}{
+		{"space object", "", &PostSpace{ID: "s1", Name: "자유게시판"}, "s1", "자유게시판"},
+		{"spaceId preferred", "s2", &PostSpace{ID: "s1", Name: "자유게시판"}, "s2", "자유게시판"},
+		{"id only", "s3", nil, "s3", ""},
+		{"no space", "", nil, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := newTestPost("제목", "<p>본문</p>")
+			post.SpaceID, post.Space = tt.spaceID, tt.space
+			response := renderTestPost(t, ContentOptions{}, post)
+			if response.SpaceID != tt.wantID || response.SpaceName != tt.wantName {
+				t.Errorf("space = %q/%q, want %q/%q", response.SpaceID, response.SpaceName, tt.wantID, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestBuildContentResponseFetchLatency(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	const upstreamDelay = 30 * time.Millisecond
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(upstreamDelay)
+		writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>본문</p>")))
+	})
+	req := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html"}}
+
+	tests := []struct {
+		name     string
+		check    func(ms int64) bool
+		describe string
+	}{
+		{"live fetch", func(ms int64) bool { return ms >= upstreamDelay.Milliseconds() }, "at least the upstream delay"},
+		{"cache hit", func(ms int64) bool { return ms < upstreamDelay.Milliseconds() }, "below the upstream delay"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := buildContentResponse(context.Background(), req)
+			if err != nil {
+				t.Fatalf("buildContentResponse: %v", err)
+			}
+			if !tt.check(response.FetchLatencyMs) {
+				t.Errorf("FetchLatencyMs = %d, want %s", response.FetchLatencyMs, tt.describe)
+			}
+
+			rec := httptest.NewRecorder()
+			setServerTiming(rec, response)
+			if got, want := rec.Header().Get("Server-Timing"), "fetch;dur="+strconv.FormatInt(response.FetchLatencyMs, 10); got != want {
+				t.Errorf("Server-Timing = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestBuildContentResponseEngagement(t *testing.T) {
+	int64p := func(v int64) *int64 { return &v }
+	tests := []struct {
+		name   string
+		fields map[string]interface{}
+		want   *Engagement
+	}{
+		{
+			name: "all counts",
+			fields: map[string]interface{}{
+				"reactionsCount": 5,
+				"repliesCount":   2,
+				"reactions": []map[string]interface{}{
+					{"reaction": "like", "count": 3},
+					{"reaction": "heart", "count": 2},
+				},
+			},
+			want: &Engagement{Reactions: int64p(5), Replies: int64p(2), ByReaction: map[string]int64{"like": 3, "heart": 2}},
+		},
+		{
+			name:   "fields not returned",
+			fields: map[string]interface{}{"reactionsCount": nil},
+			want:   &Engagement{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				if query := readGraphQLRequest(t, r).Query; !strings.Contains(query, "reactionsCount") {
+					t.Errorf("query does not select engagement fields:\n%s", query)
+				}
+				post := testPostJSON("제목", "<p>본문</p>")
+				for k, v := range tt.fields {
+					post[k] = v
+				}
+				writeJSONResponse(w, http.StatusOK, postData(post))
+			})
+
+			req := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html", IncludeEngagement: true}}
+			response, err := buildContentResponse(context.Background(), req)
+			if err != nil {
+				t.Fatalf("buildContentResponse: %v", err)
+			}
+			if !reflect.DeepEqual(response.Engagement, tt.want) {
+				t.Errorf("engagement = %+v, want %+v", response.Engagement, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchPostSharedFetchSurvivesCallerCancel(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	release := make(chan struct{})
+	upstream := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>본문</p>")))
+	})
+	req := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := fetchPost(ctx, req)
+		first <- err
+	}()
+	for upstream.count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	second := make(chan error, 1)
+	go func() {
+		post, err := fetchPost(context.Background(), req)
+		if err == nil && post.Title != "제목" {
+			err = fmt.Errorf("title = %q", post.Title)
+		}
+		second <- err
+	}()
+	time.Sleep(20 * time.Millisecond) // 두 번째 호출이 진행 중인 호출에 합류할 시간
+
+	// 먼저 시작한 요청을 취소해도 공유 호출은 계속되고, 취소한 요청만 곧바로 끝납니다
+	cancel()
+	select {
+	case err := <-first:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("cancelled caller err = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelled caller did not return")
+	}
+
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("waiting caller: %v", err)
+	}
+	waitForPostFetch(req)
+	if got := upstream.count(); got != 1 {
+		t.Errorf("upstream calls = %d, want 1", got)
+	}
+}
+
+func TestFetchPostAppliesFetchTimeout(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	withConfig(t, func(cfg *Config) { cfg.FetchTimeout = 30 * time.Millisecond })
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	req := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html"}}
+
+	start := time.Now()
+	_, err := fetchPost(context.Background(), req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("fetchPost took %v, want FETCH_TIMEOUT to stop the shared fetch", elapsed)
+	}
+	waitForPostFetch(req)
+}
+
+func TestGetContentTimeoutReturns504(t *testing.T) {
+	tests := []struct {
+		name         string
+		fetchTimeout time.Duration
+		timeoutMs    int
+		slow         bool
+		wantStatus   int
+	}{
+		{"FETCH_TIMEOUT exceeded", 30 * time.Millisecond, 0, true, http.StatusGatewayTimeout},
+		{"timeout_ms exceeded", 0, 30, true, http.StatusGatewayTimeout},
+		{"unavailable upstream is not a timeout", 30 * time.Millisecond, 0, false, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			withConfig(t, func(cfg *Config) { cfg.FetchTimeout = tt.fetchTimeout })
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.slow {
+					<-r.Context().Done()
+					return
+				}
+				w.Write([]byte("not json"))
+			})
+
+			body := `{"post_id":"post-1","nocache":true,"timeout_ms":` + strconv.Itoa(tt.timeoutMs) + `}`
+			rec := httptest.NewRecorder()
+			getContent(rec, httptest.NewRequest(http.MethodPost, "/api/v1/content", strings.NewReader(body)))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusGatewayTimeout && !strings.Contains(rec.Body.String(), "Timed out") {
+				t.Errorf("body = %q, want a timeout message", rec.Body.String())
+			}
+
+			req := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{NoCache: true, TimeoutMs: tt.timeoutMs}}
+			if err := req.normalize(); err != nil {
+				t.Fatal(err)
+			}
+			// 응답을 만드는 공유 호출과 그 안에서 게시물을 가져오는 공유 호출이 모두 끝난 뒤 정리합니다
+			waitForContentFetch(req)
+			waitForPostFetch(req)
+		})
+	}
+}
+
+func TestGetContentRevisionIDReturns501(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+	}{
+		{"GET query", http.MethodGet, "/api/v1/content?post_id=post-1&revision_id=rev-1", ""},
+		{"POST body", http.MethodPost, "/api/v1/content", `{"post_id":"post-1","revision_id":"rev-1"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			fake := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>본문</p>")))
+			})
+
+			rec := httptest.NewRecorder()
+			getContent(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			if rec.Code != http.StatusNotImplemented {
+				t.Errorf("status = %d, want 501: %s", rec.Code, rec.Body.String())
+			}
+			if fake.count() != 0 {
+				t.Errorf("upstream calls = %d, want 0", fake.count())
+			}
+		})
+	}
+}
+
+func TestFetchBatchItemFormatsRevisionID(t *testing.T) {
+	result := fetchBatchItemFormats(context.Background(), "post-1", []string{"html"}, ContentOptions{RevisionID: "rev-1"})
+	if result.Error != errRevisionsUnsupported.Error() || result.Result != nil {
+		t.Errorf("result = %+v, want the revisions unsupported error", result)
+	}
+}
+
+func TestVisibleCharCount(t *testing.T) {
+	tests := []struct {
+		content string
+		format  string
+		want    int
+	}{
+		{"", "html", 0},
+		{"<p>  </p>", "html", 0},
+		{"<p>안녕하세요</p>", "html", 5},
+		{"<p>a &amp; b</p>", "html", 5},
+		{"<p>a</p>", "text", 8},
+		{"  본문 \n", "text", 2},
+	}
+	for _, tt := range tests {
+		if got := visibleCharCount(tt.content, tt.format); got != tt.want {
+			t.Errorf("visibleCharCount(%q, %q) = %d, want %d", tt.content, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestGetContentMinChars(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		wantStatus  int
+		wantWarning bool
+	}{
+		{"long enough", "min_chars=5", http.StatusOK, false},
+		{"too short warns", "min_chars=6", http.StatusOK, true},
+		{"too short strict", "min_chars=6&strict=true", http.StatusUnprocessableEntity, false},
+		{"long enough strict", "min_chars=5&strict=true", http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>안녕하세요</p>")))
+			})
+
+			rec := httptest.NewRecorder()
+			getContent(rec, httptest.NewRequest(http.MethodGet, "/api/v1/content?post_id=post-1&nocache=true&"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var response ContentResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if got := hasWarning(response.Warnings, WarningContentTooShort); got != tt.wantWarning {
+				t.Errorf("content_too_short warning = %v, want %v", got, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestGetContentMetadataOnly(t *testing.T) {
+	tests := []struct {
+		name       string
+		post       map[string]interface{}
+		wantStatus int
+	}{
+		{"metadata without content", map[string]interface{}{"title": "제목", "updatedAt": "2024-05-01T10:00:00Z", "spaceId": "space-1"}, http.StatusOK},
+		{"missing post", map[string]interface{}{}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				if query := readGraphQLRequest(t, r).Query; strings.Contains(query, "mappingFields") {
+					t.Errorf("metadata_only query selects mappingFields:\n%s", query)
+				}
+				writeJSONResponse(w, http.StatusOK, postData(tt.post))
+			})
+
+			rec := httptest.NewRecorder()
+			getContent(rec, httptest.NewRequest(http.MethodGet, "/api/v1/content?post_id=post-1&metadata_only=true", nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var response ContentResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Title != "제목" || response.SpaceID != "space-1" || response.Content != "" {
+				t.Errorf("response = %+v, want title and space without content", response)
+			}
+		})
+	}
+}
+
+func TestIsEmptyContent(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", true},
+		{"   ", true},
+		{`""`, true},
+		{"<p>본문</p>", false},
+	}
+	for _, tt := range tests {
+		if got := isEmptyContent(tt.value); got != tt.want {
+			t.Errorf("isEmptyContent(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestFetchPostFromUpstreamEmptyContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		retries     int
+		emptyCalls  int // 처음 몇 번 빈 본문을 돌려주는지
+		wantCalls   int
+		wantContent bool
+	}{
+		{"disabled", 0, 1, 1, false},
+		{"filled on retry", 2, 1, 2, true},
+		{"still empty after retries", 2, 5, 3, false},
+		{"not empty", 2, 0, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withConfig(t, func(cfg *Config) {
+				cfg.EmptyContentRetries = tt.retries
+				cfg.EmptyContentRetryDelay = time.Millisecond
+			})
+			calls := 0
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				content := "<p>본문</p>"
+				if calls <= tt.emptyCalls {
+					content = ""
+				}
+				writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", content)))
+			})
+
+			post, err := fetchPostFromUpstream(context.Background(), "post-1", ContentOptions{Format: "html"})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantContent {
+				if err != nil || post.ContentField() == "" {
+					t.Errorf("post = %+v, err = %v; want content", post, err)
+				}
+			} else if !errors.Is(err, errEmptyContent) && (err != nil || !isEmptyContent(post.ContentField())) {
+				t.Errorf("post = %+v, err = %v; want empty content", post, err)
+			}
+		})
+	}
+}
+
+func TestFetchPostFromUpstreamEmptyContentMetadataOnly(t *testing.T) {
+	withTestToken(t)
+	withConfig(t, func(cfg *Config) { cfg.EmptyContentRetries = 3 })
+	fake := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, postData(map[string]interface{}{"title": "제목", "updatedAt": "2024-05-01T10:00:00Z"}))
+	})
+	if _, err := fetchPostFromUpstream(context.Background(), "post-1", ContentOptions{Format: "html", MetadataOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+	if fake.count() != 1 {
+		t.Errorf("calls = %d, want 1: metadata_only posts have no content to wait for", fake.count())
+	}
+}
+
+func TestRequireTokenManager(t *testing.T) {
+	tests := []struct {
+		name       string
+		noManager  bool
+		wantStatus int
+	}{
+		{"ready", false, http.StatusNoContent},
+		{"initializing", true, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			if tt.noManager {
+				tokenManager = nil
+			}
+			handler := requireTokenManager(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/content?post_id=post-1", nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if wantRetry := tt.noManager; (rec.Header().Get("Retry-After") != "") != wantRetry {
+				t.Errorf("Retry-After = %q, want set %v", rec.Header().Get("Retry-After"), wantRetry)
+			}
+		})
+	}
+}
+
+func TestGetContentWithoutTokenManager(t *testing.T) {
+	withContentCache(t)
+	prev := tokenManager
+	tokenManager = nil
+	t.Cleanup(func() { tokenManager = prev })
+	fake := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>본문</p>")))
+	})
+
+	tests := []struct {
+		name        string
+		callerToken string
+		wantStatus  int
+	}{
+		{"service token", "", http.StatusServiceUnavailable},
+		{"caller token", "caller-token", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/content?post_id=post-1&nocache=true", nil)
+			if tt.callerToken != "" {
+				r = r.WithContext(context.WithValue(r.Context(), callerTokenKey{}, tt.callerToken))
+			}
+			rec := httptest.NewRecorder()
+			getContent(rec, r)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+	if fake.count() != 1 {
+		t.Errorf("upstream calls = %d, want 1 (only the caller token request)", fake.count())
+	}
+}
+
+func TestWithFetchTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		fetchTimeout time.Duration
+		timeoutMs    int
+		want         time.Duration // 0이면 제한 시간 없음
+	}{
+		{"no timeout", 0, 0, 0},
+		{"FETCH_TIMEOUT", time.Minute, 0, time.Minute},
+		{"timeout_ms replaces FETCH_TIMEOUT", time.Minute, 200, 200 * time.Millisecond},
+		{"timeout_ms without FETCH_TIMEOUT", 0, 5000, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.FetchTimeout = tt.fetchTimeout })
+			start := time.Now()
+			ctx, cancel := withFetchTimeout(context.Background(), ContentOptions{TimeoutMs: tt.timeoutMs})
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if ok != (tt.want > 0) {
+				t.Fatalf("has deadline = %v, want %v", ok, tt.want > 0)
+			}
+			if ok {
+				if got := deadline.Sub(start); got < tt.want-time.Second/10 || got > tt.want+time.Second/10 {
+					t.Errorf("deadline in %v, want about %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
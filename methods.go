@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// routeMethods는 Allow 헤더를 계산할 때 확인하는 HTTP 메서드입니다
+var routeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// methodNotAllowedHandler는 경로는 있지만 메서드가 맞지 않는 요청에 JSON 에러와 Allow 헤더를 반환합니다.
+// 허용 메서드는 routes에서 같은 경로를 메서드별로 찾아 계산합니다.
+func methodNotAllowedHandler(routes chi.Routes) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		allowed := allowedMethods(routes, r.URL.Path)
+
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		w.Header().Set("Cache-Control", "no-store")
+		render.Status(r, http.StatusMethodNotAllowed)
+		render.JSON(w, r, map[string]interface{}{
+			"error":           "Method " + r.Method + " not allowed",
+			"allowed_methods": allowed,
+		})
+	}
+}
+
+// allowedMethods는 path에 등록된 메서드 목록을 반환합니다
+func allowedMethods(routes chi.Routes, path string) []string {
+	allowed := []string{}
+	for _, method := range routeMethods {
+		if routes.Match(chi.NewRouteContext(), method, path) {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
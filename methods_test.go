@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// methodsTestRouter는 main()과 같은 방식으로 405 처리기를 설정한, 하위 라우터와 그룹이 있는 라우터입니다
+func methodsTestRouter() chi.Router {
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	r := chi.NewRouter()
+	r.MethodNotAllowed(methodNotAllowedHandler(r))
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Post("/content", ok)
+			r.Get("/content", ok)
+		})
+		r.Post("/compile", ok)
+		r.Delete("/cache/{postID}", ok)
+	})
+	r.Get("/healthz", ok)
+	return r
+}
+
+func TestMethodNotAllowedHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		wantStatus  int
+		wantAllowed []string
+	}{
+		{"content", http.MethodDelete, "/api/v1/content", http.StatusMethodNotAllowed, []string{"GET", "POST"}},
+		{"post only", http.MethodGet, "/api/v1/compile", http.StatusMethodNotAllowed, []string{"POST"}},
+		{"path parameter", http.MethodGet, "/api/v1/cache/post-1", http.StatusMethodNotAllowed, []string{"DELETE"}},
+		{"top level", http.MethodPost, "/healthz", http.StatusMethodNotAllowed, []string{"GET"}},
+		{"allowed method", http.MethodPost, "/api/v1/content", http.StatusOK, nil},
+		{"unknown path", http.MethodGet, "/api/v1/missing", http.StatusNotFound, nil},
+	}
+	router := methodsTestRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusMethodNotAllowed {
+				return
+			}
+
+			if got, want := rec.Header().Get("Allow"), strings.Join(tt.wantAllowed, ", "); got != want {
+				t.Errorf("Allow = %q, want %q", got, want)
+			}
+			var body struct {
+				Error          string   `json:"error"`
+				AllowedMethods []string `json:"allowed_methods"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body is not JSON: %v: %s", err, rec.Body.String())
+			}
+			if body.Error != "Method "+tt.method+" not allowed" || !reflect.DeepEqual(body.AllowedMethods, tt.wantAllowed) {
+				t.Errorf("body = %+v", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// 디버그 본문 로그에 남기는 최대 바이트 수
+const debugBodyLogLimit = 2048
+
+// 로그에 값을 남기지 않을 민감한 헤더
+var redactedHeaders = map[string]bool{
+	"Authorization":      true,
+	"Cookie":             true,
+	"X-Admin-Key":        true,
+	"X-Bettermode-Token": true, // X-BetterMode-Token의 정규화된 이름
+}
+
+// debugBodyLogger는 DEBUG_LOG_BODIES가 켜져 있으면 요청 본문과 응답 본문 앞부분을 로그에 남깁니다.
+// 민감한 헤더 값은 가리고, 읽은 요청 본문은 다시 채워 넣어 핸들러가 그대로 읽을 수 있게 합니다.
+func debugBodyLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !currentConfig().DebugLogBodies {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var reqBody []byte
+		if r.Body != nil {
+			var err error
+			reqBody, err = io.ReadAll(io.LimitReader(r.Body, debugBodyLogLimit))
+			if err != nil {
+				log.Printf("[debug] error reading request body: %v", err)
+			}
+			// 로그용으로 읽은 앞부분과 나머지 본문을 이어 붙여 핸들러에 넘깁니다
+			r.Body = readCloser{io.MultiReader(bytes.NewReader(reqBody), r.Body), r.Body}
+		}
+		log.Printf("[debug] request %s %s headers=%s body=%q", r.Method, r.URL.RequestURI(), formatHeaders(r.Header), reqBody)
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		respBody := &truncatingBuffer{limit: debugBodyLogLimit}
+		ww.Tee(respBody)
+
+		next.ServeHTTP(ww, r)
+
+		log.Printf("[debug] response %s %s status=%d bytes=%d body=%q", r.Method, r.URL.RequestURI(), ww.Status(), ww.BytesWritten(), respBody.String())
+	})
+}
+
+// formatHeaders는 민감한 헤더 값을 가린 헤더 목록을 문자열로 만듭니다
+func formatHeaders(h http.Header) string {
+	parts := make([]string, 0, len(h))
+	for name, values := range h {
+		value := strings.Join(values, ",")
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			value = "[REDACTED]"
+		}
+		parts = append(parts, name+"="+value)
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
+
+// readCloser는 Reader는 바꾸되 Close는 원래 본문에 위임합니다
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// truncatingBuffer는 처음 limit 바이트만 보관하고 나머지는 버립니다
+type truncatingBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *truncatingBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.buf.Write(p[:remaining])
+			b.truncated = true
+		} else {
+			b.buf.Write(p)
+		}
+	} else if len(p) > 0 {
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+func (b *truncatingBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "...(truncated)"
+	}
+	return b.buf.String()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// captureLog는 테스트가 끝날 때까지 로그를 버퍼에 모읍니다
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(io.Discard) })
+	return &buf
+}
+
+func TestDebugBodyLogger(t *testing.T) {
+	longBody := strings.Repeat("가", debugBodyLogLimit)
+	tests := []struct {
+		name      string
+		enabled   bool
+		body      string
+		wantLog   []string
+		forbidLog []string
+	}{
+		{"disabled", false, `{"post_id":"p1"}`, nil, []string{"[debug]"}},
+		{"logs bodies and redacts secrets", true, `{"post_id":"p1"}`, []string{`post_id`, "Authorization=[REDACTED]", "X-Admin-Key=[REDACTED]", "status=200"}, []string{"secret-token", "admin-secret"}},
+		{"truncates long response", true, longBody, []string{"...(truncated)"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.DebugLogBodies = tt.enabled })
+			logs := captureLog(t)
+
+			var received string
+			handler := debugBodyLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				received = string(b)
+				w.Write(b)
+			}))
+			r := httptest.NewRequest("POST", "/api/v1/content", strings.NewReader(tt.body))
+			r.Header.Set("Authorization", "Bearer secret-token")
+			r.Header.Set("X-Admin-Key", "admin-secret")
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			// 로그용으로 읽은 뒤에도 핸들러는 본문 전체를 받아야 합니다
+			if received != tt.body {
+				t.Errorf("handler received %d bytes, want %d", len(received), len(tt.body))
+			}
+			for _, want := range tt.wantLog {
+				if !strings.Contains(logs.String(), want) {
+					t.Errorf("log missing %q:\n%s", want, logs.String())
+				}
+			}
+			for _, forbidden := range tt.forbidLog {
+				if strings.Contains(logs.String(), forbidden) {
+					t.Errorf("log contains %q:\n%s", forbidden, logs.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// decodeMode는 요청 본문에 모르는 필드가 있을 때의 처리 방식으로, 핸들러마다 정합니다
+type decodeMode int
+
+const (
+	// decodeLenient는 모르는 필드를 무시합니다 (새 옵션을 보내는 이후 버전의 클라이언트와 호환, 배치 등)
+	decodeLenient decodeMode = iota
+	// decodeStrict는 모르는 필드가 있으면 에러를 반환합니다 (옵션 이름 오타를 바로 알림, 단일 콘텐츠 조회)
+	decodeStrict
+)
+
+// decodeContentRequest는 요청에서 dst(ContentRequest, URLRequest 등)를 채웁니다.
+// POST 요청은 JSON 본문을 먼저 읽고, 쿼리 파라미터가 있으면 본문 값보다 우선합니다.
+// GET 요청은 쿼리 파라미터만 사용합니다. 필드 이름은 JSON 태그와 같습니다.
+// mode가 decodeStrict이면 본문에 dst에 없는 필드가 있을 때 그 이름을 담은 에러를 반환합니다.
+func decodeContentRequest(r *http.Request, dst interface{}, mode decodeMode) error {
+	if r.Method != http.MethodGet && r.Body != nil && r.Body != http.NoBody {
+		dec := json.NewDecoder(r.Body)
+		if mode == decodeStrict {
+			dec.DisallowUnknownFields()
+		}
+		if err := dec.Decode(dst); err != nil {
+			// encoding/json은 모르는 필드 에러를 따로 구분할 타입 없이 메시지로만 알려 줍니다
+			if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+				return fmt.Errorf("Invalid request body: unknown field %s", field)
+			}
+			return errors.New("Invalid request body")
+		}
+	}
+	return applyQueryParams(r.URL.Query(), dst)
+}
+
+// normalize는 기본값을 채우고 옵션 값을 검증합니다
+func (o *ContentOptions) normalize() error {
+	// Set default format to html if not specified
+	if o.Format == "" {
+		o.Format = "html"
+	} else if !isValidFormat(o.Format) {
+		return errors.New("Format must be 'html', 'text', 'xhtml' or 'jsonld'")
+	}
+	if o.TTS && o.Format != "text" {
+		return errors.New("tts requires format 'text'")
+	}
+	if o.DedupeParagraphs && o.Format != "text" {
+		return errors.New("dedupe_paragraphs requires format 'text'")
+	}
+	// 해시 알고리즘을 여기서 정해 두어, 설정이 바뀌어도 캐시된 응답과 알고리즘이 섞이지 않게 합니다
+	if o.HashAlgorithm == "" {
+		o.HashAlgorithm = currentConfig().ContentHashAlgorithm
+	} else if !isValidHashAlgorithm(o.HashAlgorithm) {
+		return errors.New("hash_algorithm must be 'sha256', 'sha1', 'md5' or 'xxhash'")
+	}
+	if o.TranslateTo != "" && !languageCodePattern.MatchString(o.TranslateTo) {
+		return errors.New("translate_to must be a language code such as 'en'")
+	}
+	if o.MinChars < 0 {
+		return errors.New("min_chars must not be negative")
+	}
+	if o.Strict && o.MinChars == 0 {
+		return errors.New("strict requires min_chars")
+	}
+	if o.AutoPreview < 0 {
+		return errors.New("auto_preview must not be negative")
+	}
+	if o.RemoveFootnotes && !o.ExtractFootnotes {
+		return errors.New("remove_footnotes requires extract_footnotes")
+	}
+	if o.MetadataOnly && (len(o.Fields) > 0 || len(o.FieldTypes) > 0 || o.IncludeEmbeds || o.IncludeTOC || o.ExtractFootnotes ||
+		o.IncludeSummary || o.ExtractTables || o.Thumbnail || o.IncludeLinks || o.TitleKey != "" || o.TranslateTo != "" || o.MinChars > 0 || o.AutoPreview > 0) {
+		return errors.New("metadata_only cannot be combined with fields, field_types, include_embeds, include_toc, extract_footnotes, include_summary, extract_tables, thumbnail, include_links, title_key, translate_to, min_chars or auto_preview")
+	}
+	if o.CacheTTLSeconds != nil {
+		if *o.CacheTTLSeconds < 0 {
+			return errors.New("cache_ttl_seconds must not be negative")
+		}
+		if maxTTL := int(currentConfig().CacheMaxTTL / time.Second); *o.CacheTTLSeconds > maxTTL {
+			o.CacheTTLSeconds = &maxTTL
+		}
+		if *o.CacheTTLSeconds == 0 {
+			o.NoCache = true
+		}
+	}
+	if o.TimeoutMs < 0 {
+		return errors.New("timeout_ms must not be negative")
+	}
+	if maxTimeout := int(currentConfig().FetchMaxTimeout / time.Millisecond); o.TimeoutMs > maxTimeout {
+		o.TimeoutMs = maxTimeout
+	}
+	if o.ProxyImages && len(currentConfig().ImageProxyAllowedHosts) == 0 {
+		return errors.New("proxy_images requires IMAGE_PROXY_ALLOWED_HOSTS to be configured")
+	}
+	if o.Encoding != "" && o.Encoding != EncodingBase64 {
+		return errors.New("Encoding must be 'base64' if specified")
+	}
+	if o.Format == FormatJSONLD && (o.Encoding != "" || o.MetadataOnly) {
+		return errors.New("format 'jsonld' cannot be combined with encoding or metadata_only")
+	}
+	return nil
+}
+
+// isValidFormat은 지원하는 응답 형식인지 확인합니다
+func isValidFormat(format string) bool {
+	return format == "html" || format == "text" || format == "xhtml" || format == FormatJSONLD
+}
+
+// applyQueryParams는 쿼리 파라미터를 JSON 태그 이름이 같은 구조체 필드에 넣습니다.
+// 임베드된 구조체(ContentOptions)의 필드도 처리하며, []string은 "a,b" 또는 반복된 파라미터로 받습니다.
+func applyQueryParams(query url.Values, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("query destination must be a pointer to struct")
+	}
+	return applyQueryParamsToStruct(query, v.Elem())
+}
+
+func applyQueryParamsToStruct(query url.Values, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+
+		if field.Anonymous && fv.Kind() == reflect.Struct {
+			if err := applyQueryParamsToStruct(query, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		values, ok := query[name]
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		if err := setQueryValue(fv, values); err != nil {
+			return fmt.Errorf("Invalid query parameter %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func setQueryValue(fv reflect.Value, values []string) error {
+	last := values[len(values)-1]
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(last)
+	case reflect.Bool:
+		// "?flag"처럼 값 없이 전달하면 true로 처리합니다
+		if last == "" {
+			fv.SetBool(true)
+			return nil
+		}
+		b, err := strconv.ParseBool(last)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Ptr:
+		// 값이 없는 것과 0을 구분해야 하는 옵션(cache_ttl_seconds)은 포인터 필드입니다
+		value := reflect.New(fv.Type().Elem())
+		if err := setQueryValue(value.Elem(), values); err != nil {
+			return err
+		}
+		fv.Set(value)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", fv.Type())
+		}
+		var items []string
+		for _, value := range values {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		fv.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
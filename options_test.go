@@ -0,0 +1,235 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeContentRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   ContentRequest
+	}{
+		{
+			"json body",
+			"POST", "/api/v1/content",
+			`{"post_id":"p1","format":"text","fields":["summary"]}`,
+			ContentRequest{PostID: "p1", ContentOptions: ContentOptions{Format: "text", Fields: []string{"summary"}}},
+		},
+		{
+			"query params",
+			"GET", "/api/v1/content?post_id=p1&format=xhtml&include_attachments&fields=a,b&fields=c",
+			"",
+			ContentRequest{PostID: "p1", ContentOptions: ContentOptions{Format: "xhtml", IncludeAttachments: true, Fields: []string{"a", "b", "c"}}},
+		},
+		{
+			"query overrides body",
+			"POST", "/api/v1/content?format=html",
+			`{"post_id":"p1","format":"text"}`,
+			ContentRequest{PostID: "p1", ContentOptions: ContentOptions{Format: "html"}},
+		},
+		{
+			"get ignores body",
+			"GET", "/api/v1/content?post_id=p1",
+			`{"format":"text"}`,
+			ContentRequest{PostID: "p1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			var got ContentRequest
+			if err := decodeContentRequest(r, &got, decodeLenient); err != nil {
+				t.Fatalf("decodeContentRequest: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeContentRequest = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeContentRequestInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		body   string
+	}{
+		{"malformed json", "/api/v1/content", `{"post_id":`},
+		{"bad bool", "/api/v1/content?nocache=maybe", `{}`},
+		{"bad int", "/api/v1/content?min_chars=many", `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tt.target, strings.NewReader(tt.body))
+			var got ContentRequest
+			if err := decodeContentRequest(r, &got, decodeLenient); err == nil {
+				t.Errorf("decodeContentRequest succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestDecodeContentRequestMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		mode    decodeMode
+		wantErr string // 비어 있으면 에러가 없어야 합니다
+	}{
+		{"lenient ignores unknown field", `{"post_id":"p1","fromat":"text"}`, decodeLenient, ""},
+		{"strict rejects unknown field", `{"post_id":"p1","fromat":"text"}`, decodeStrict, `Invalid request body: unknown field "fromat"`},
+		{"strict accepts known fields", `{"post_id":"p1","format":"text","nocache":true}`, decodeStrict, ""},
+		{"strict malformed json", `{"post_id":`, decodeStrict, "Invalid request body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v1/content", strings.NewReader(tt.body))
+			var got ContentRequest
+			err := decodeContentRequest(r, &got, tt.mode)
+			if tt.wantErr == "" {
+				if err != nil || got.PostID != "p1" {
+					t.Errorf("decodeContentRequest = %+v, %v", got, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandlerDecodeModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     func(http.ResponseWriter, *http.Request)
+		target      string
+		wantUnknown bool // 모르는 필드 때문에 거절하는지
+	}{
+		{"content is strict", getContent, "/api/v1/content", true},
+		{"url is strict", getContentFromURL, "/api/v1/url", true},
+		// 모르는 필드는 무시하고 post_ids가 없어서 거절합니다
+		{"batch is lenient", getBatchContent, "/api/v1/batch", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler(rec, httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(`{"unknown_option":true}`)))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400", rec.Code)
+			}
+			if unknown := strings.Contains(rec.Body.String(), "unknown field"); unknown != tt.wantUnknown {
+				t.Errorf("body = %q, want unknown field error %v", rec.Body.String(), tt.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestNormalizeCacheTTLSeconds(t *testing.T) {
+	withConfig(t, func(cfg *Config) { cfg.CacheMaxTTL = 10 * time.Minute })
+	seconds := func(n int) *int { return &n }
+	tests := []struct {
+		name        string
+		requested   *int
+		wantTTL     *int
+		wantNoCache bool
+		wantErr     bool
+	}{
+		{"not set", nil, nil, false, false},
+		{"within limit", seconds(60), seconds(60), false, false},
+		{"capped by CACHE_MAX_TTL", seconds(3600), seconds(600), false, false},
+		{"zero disables cache", seconds(0), seconds(0), true, false},
+		{"negative", seconds(-1), nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ContentOptions{Format: "html", CacheTTLSeconds: tt.requested}
+			err := opts.normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalize = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(opts.CacheTTLSeconds, tt.wantTTL) || opts.NoCache != tt.wantNoCache {
+				t.Errorf("cache_ttl_seconds = %v, nocache = %v; want %v, %v", opts.CacheTTLSeconds, opts.NoCache, tt.wantTTL, tt.wantNoCache)
+			}
+		})
+	}
+}
+
+func TestApplyQueryParamsPointer(t *testing.T) {
+	var opts ContentOptions
+	if err := applyQueryParams(url.Values{"cache_ttl_seconds": {"30"}}, &opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.CacheTTLSeconds == nil || *opts.CacheTTLSeconds != 30 {
+		t.Errorf("cache_ttl_seconds = %v, want 30", opts.CacheTTLSeconds)
+	}
+	if err := applyQueryParams(url.Values{"cache_ttl_seconds": {"soon"}}, &opts); err == nil {
+		t.Error("invalid cache_ttl_seconds accepted")
+	}
+}
+
+func TestNormalizeMinChars(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ContentOptions
+		wantErr string
+	}{
+		{"unset", ContentOptions{}, ""},
+		{"min_chars only", ContentOptions{MinChars: 10}, ""},
+		{"strict with min_chars", ContentOptions{MinChars: 10, Strict: true}, ""},
+		{"negative", ContentOptions{MinChars: -1}, "min_chars must not be negative"},
+		{"strict without min_chars", ContentOptions{Strict: true}, "strict requires min_chars"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.normalize()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("normalize: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("normalize = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeTimeoutMs(t *testing.T) {
+	tests := []struct {
+		name       string
+		timeoutMs  int
+		maxTimeout time.Duration
+		want       int
+		wantErr    bool
+	}{
+		{"unset", 0, time.Minute, 0, false},
+		{"within max", 1500, time.Minute, 1500, false},
+		{"clamped to max", 90000, time.Minute, 60000, false},
+		{"max disabled", 1500, 0, 0, false},
+		{"negative", -1, time.Minute, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.FetchMaxTimeout = tt.maxTimeout })
+			opts := ContentOptions{Format: "html", TimeoutMs: tt.timeoutMs}
+			err := opts.normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalize() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && opts.TimeoutMs != tt.want {
+				t.Errorf("TimeoutMs = %d, want %d", opts.TimeoutMs, tt.want)
+			}
+		})
+	}
+}
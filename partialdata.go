@@ -0,0 +1,24 @@
+package main
+
+import "strings"
+
+// BetterMode가 data와 함께 errors를 보냈을 때(일부 필드만 해석에 실패)의 처리 정책 (GRAPHQL_PARTIAL_DATA)
+const (
+	PartialDataAllow  = "allow"  // 받은 데이터로 응답하고 partial_data 경고
+	PartialDataReject = "reject" // 본문을 받지 못했으면 BetterMode 에러로 실패
+)
+
+// graphQLErrorMessages는 errors 항목의 메시지를 "; "로 이어 붙입니다
+func graphQLErrorMessages(errs []graphQLError) string {
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, e.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// isPartial은 BetterMode가 errors와 함께 일부 데이터만 보내 그대로 사용한 게시물인지 확인합니다.
+// 다음 요청에서 온전한 데이터를 받을 수 있도록 이런 게시물과 응답은 캐시하지 않습니다.
+func (p *Post) isPartial() bool {
+	return p.partialErrors != ""
+}
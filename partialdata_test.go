@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestGraphQLErrorMessages(t *testing.T) {
+	tests := []struct {
+		errs []graphQLError
+		want string
+	}{
+		{nil, ""},
+		{[]graphQLError{{Message: "a failed"}}, "a failed"},
+		{[]graphQLError{{Message: "a failed"}, {Message: "b failed"}}, "a failed; b failed"},
+	}
+	for _, tt := range tests {
+		if got := graphQLErrorMessages(tt.errs); got != tt.want {
+			t.Errorf("graphQLErrorMessages(%+v) = %q, want %q", tt.errs, got, tt.want)
+		}
+	}
+}
+
+// partialUpstream은 post와 함께 mappingFields 해석 에러를 돌려주는 가짜 업스트림입니다
+func partialUpstream(t *testing.T, post map[string]interface{}) *fakeUpstream {
+	t.Helper()
+	return withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		resp := postData(post)
+		resp["errors"] = []map[string]interface{}{{"message": "mappingFields failed"}}
+		writeJSONResponse(w, http.StatusOK, resp)
+	})
+}
+
+func TestFetchContentFromBetterModePartialData(t *testing.T) {
+	withoutContent := map[string]interface{}{"title": "제목", "updatedAt": "2024-05-01T10:00:00Z"}
+	tests := []struct {
+		name        string
+		policy      string
+		post        map[string]interface{}
+		wantErr     bool
+		wantPartial bool
+	}{
+		{"allow without content", PartialDataAllow, withoutContent, false, true},
+		{"allow with content", PartialDataAllow, testPostJSON("제목", "<p>본문</p>"), false, true},
+		{"reject without content", PartialDataReject, withoutContent, true, false},
+		{"reject with content", PartialDataReject, testPostJSON("제목", "<p>본문</p>"), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withConfig(t, func(cfg *Config) { cfg.GraphQLPartialData = tt.policy })
+			partialUpstream(t, tt.post)
+
+			post, err := fetchContentFromBetterMode(context.Background(), "post-1", ContentOptions{Format: "html"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if post.isPartial() != tt.wantPartial {
+				t.Errorf("isPartial() = %v, want %v", post.isPartial(), tt.wantPartial)
+			}
+			if tt.wantPartial && post.partialErrors != "mappingFields failed" {
+				t.Errorf("partialErrors = %q", post.partialErrors)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponsePartialWarning(t *testing.T) {
+	tests := []struct {
+		name          string
+		partialErrors string
+		metadataOnly  bool
+		want          bool
+	}{
+		{"complete", "", false, false},
+		{"partial", "mappingFields failed", false, true},
+		{"partial metadata_only", "mappingFields failed", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := newTestPost("제목", "<p>본문</p>")
+			post.partialErrors = tt.partialErrors
+			response := renderTestPost(t, ContentOptions{Format: "html", MetadataOnly: tt.metadataOnly}, post)
+			if got := hasWarning(response.Warnings, WarningPartialData); got != tt.want {
+				t.Errorf("partial_data warning = %v, want %v (warnings %+v)", got, tt.want, response.Warnings)
+			}
+			if response.partial != tt.want {
+				t.Errorf("partial = %v, want %v", response.partial, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchContentResponseDoesNotCachePartialData(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	withConfig(t, func(cfg *Config) { cfg.GraphQLPartialData = PartialDataAllow })
+	fake := partialUpstream(t, testPostJSON("제목", "<p>본문</p>"))
+
+	req := ContentRequest{PostID: "post-1", ContentOptions: ContentOptions{Format: "html"}}
+	for i := 0; i < 2; i++ {
+		response, err := fetchContentResponse(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !hasWarning(response.Warnings, WarningPartialData) {
+			t.Errorf("call %d: warnings = %+v, want partial_data", i, response.Warnings)
+		}
+	}
+	if fake.count() != 2 {
+		t.Errorf("upstream calls = %d, want 2: partial responses must not be cached", fake.count())
+	}
+}
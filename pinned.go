@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// PinnedPostsRequest는 스페이스에 고정된 게시물들을 가져오기 위한 요청입니다
+type PinnedPostsRequest struct {
+	SpaceID string `json:"-"`
+	ContentOptions
+}
+
+// PinnedPostsResponse는 스페이스에 고정된 게시물의 콘텐츠를 고정 순서대로 담은 응답입니다
+type PinnedPostsResponse struct {
+	SpaceID   string            `json:"space_id"`
+	Results   []BatchItemResult `json:"results"`             // 고정된 게시물이 없으면 빈 배열
+	Truncated bool              `json:"truncated,omitempty"` // 고정된 게시물이 BATCH_MAX_ITEMS보다 많아 앞부분만 가져온 경우
+	Cancelled bool              `json:"cancelled,omitempty"`
+}
+
+const spacePinnedPostsQuery = `
+	query ListSpacePinnedPosts($spaceId: ID!) {
+		spacePinnedPosts(spaceId: $spaceId) {
+			id
+			title
+			spaceId
+			createdAt
+		}
+	}
+`
+
+// GetSpacePinnedPosts godoc
+// @Summary Get content for the pinned posts of a space
+// @Description Fetches the posts pinned (featured) in a BetterMode space, in pin order, with the same options as /content. A space without pinned posts returns an empty results array.
+// @Tags content
+// @Produce json
+// @Param spaceID path string true "Space ID"
+// @Param space_id query string false "Space ID for the route without spaceID (default: DEFAULT_SPACE_ID)"
+// @Param format query string false "Response format (html, text, xhtml or jsonld)"
+// @Success 200 {object} PinnedPostsResponse
+// @Failure 400 {string} string "Bad request"
+// @Failure 502 {string} string "BetterMode API error"
+// @Router /spaces/{spaceID}/pinned [get]
+// @Router /pinned [get]
+func getSpacePinnedPosts(w http.ResponseWriter, r *http.Request) {
+	var req PinnedPostsRequest
+	if err := decodeContentRequest(r, &req, decodeLenient); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.SpaceID = requestSpaceID(r); req.SpaceID == "" {
+		writeError(w, errNoSpaceID, http.StatusBadRequest)
+		return
+	}
+
+	if err := req.ContentOptions.normalize(); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cfg := currentConfig()
+	posts, err := listSpacePinnedPosts(r.Context(), req.SpaceID)
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.As(err, new(*inputError)) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, errCallerTokenRejected) {
+			status = http.StatusUnauthorized
+		}
+		writeError(w, "Error fetching pinned posts: "+err.Error(), status)
+		return
+	}
+
+	truncated := len(posts) > cfg.BatchMaxItems
+	if truncated {
+		posts = posts[:cfg.BatchMaxItems]
+	}
+	postIDs := make([]string, len(posts))
+	for i, p := range posts {
+		postIDs[i] = p.ID
+	}
+	results, cancelled := fetchBatch(r.Context(), postIDs, nil, req.ContentOptions, cfg.BatchConcurrency)
+
+	setCacheControl(w, req.NoCache)
+	render.JSON(w, r, PinnedPostsResponse{
+		SpaceID:   req.SpaceID,
+		Results:   results,
+		Truncated: truncated,
+		Cancelled: cancelled,
+	})
+}
+
+// listSpacePinnedPosts는 스페이스에 고정된 게시물을 고정 순서대로 반환합니다 (BetterMode가 한 번에 모두 돌려줌)
+func listSpacePinnedPosts(ctx context.Context, spaceID string) ([]PostSummary, error) {
+	var data struct {
+		SpacePinnedPosts []struct {
+			ID        string `json:"id"`
+			Title     string `json:"title"`
+			SpaceID   string `json:"spaceId"`
+			CreatedAt string `json:"createdAt"`
+		} `json:"spacePinnedPosts"`
+	}
+	if err := queryBetterMode(ctx, spacePinnedPostsQuery, map[string]interface{}{"spaceId": spaceID}, &data); err != nil {
+		return nil, err
+	}
+
+	posts := make([]PostSummary, 0, len(data.SpacePinnedPosts))
+	for _, node := range data.SpacePinnedPosts {
+		posts = append(posts, PostSummary{ID: node.ID, Title: node.Title, SpaceID: node.SpaceID, CreatedAt: node.CreatedAt})
+	}
+	return posts, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// pinnedUpstream은 고정된 게시물 목록으로 pinned를, 게시물 조회에는 제목이 "제목 <id>"인 게시물을 돌려줍니다
+func pinnedUpstream(t *testing.T, pinned []string) {
+	t.Helper()
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		req := readGraphQLRequest(t, r)
+		if strings.Contains(req.Query, "spacePinnedPosts") {
+			nodes := []map[string]string{}
+			for _, id := range pinned {
+				nodes = append(nodes, map[string]string{"id": id, "title": "제목 " + id, "spaceId": "space-1", "createdAt": "2024-05-01T00:00:00Z"})
+			}
+			writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"spacePinnedPosts": nodes}})
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목 "+req.Variables["id"].(string), "<p>본문</p>")))
+	})
+}
+
+func TestListSpacePinnedPosts(t *testing.T) {
+	withTestToken(t)
+	pinnedUpstream(t, []string{"c", "a", "b"})
+	posts, err := listSpacePinnedPosts(context.Background(), "space-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{}
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"c", "a", "b"}) {
+		t.Errorf("ids = %v, want pin order [c a b]", ids)
+	}
+}
+
+func TestGetSpacePinnedPosts(t *testing.T) {
+	tests := []struct {
+		name          string
+		pinned        []string
+		maxItems      int
+		target        string
+		wantStatus    int
+		wantIDs       []string
+		wantTruncated bool
+	}{
+		{"pin order", []string{"c", "a", "b"}, 50, "/spaces/space-1/pinned?format=text", http.StatusOK, []string{"c", "a", "b"}, false},
+		{"truncated", []string{"c", "a", "b"}, 2, "/spaces/space-1/pinned", http.StatusOK, []string{"c", "a"}, true},
+		{"none pinned", nil, 50, "/spaces/space-1/pinned", http.StatusOK, []string{}, false},
+		{"invalid format", []string{"a"}, 50, "/spaces/space-1/pinned?format=pdf", http.StatusBadRequest, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			withConfig(t, func(cfg *Config) { cfg.BatchMaxItems = tt.maxItems })
+			pinnedUpstream(t, tt.pinned)
+
+			router := chi.NewRouter()
+			router.Get("/spaces/{spaceID}/pinned", getSpacePinnedPosts)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var response PinnedPostsResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Results == nil {
+				t.Fatalf("results = null, want an array: %s", rec.Body.String())
+			}
+			ids := []string{}
+			for _, item := range response.Results {
+				ids = append(ids, item.PostID)
+				if item.Result == nil || item.Result.Title != "제목 "+item.PostID {
+					t.Errorf("result for %s = %+v", item.PostID, item.Result)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if response.SpaceID != "space-1" || response.Truncated != tt.wantTruncated {
+				t.Errorf("space_id = %q, truncated = %v; want space-1, %v", response.SpaceID, response.Truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestGetSpacePinnedPostsUpstreamError(t *testing.T) {
+	withTestToken(t)
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+			"errors": []map[string]interface{}{{"message": "Internal error", "extensions": map[string]string{"code": "INTERNAL_SERVER_ERROR"}}},
+		})
+	})
+	router := chi.NewRouter()
+	router.Get("/spaces/{spaceID}/pinned", getSpacePinnedPosts)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/spaces/space-1/pinned", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502: %s", rec.Code, rec.Body.String())
+	}
+}
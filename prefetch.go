@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// BetterMode 웹훅 요청의 타임스탬프 허용 범위. 이보다 오래되었거나 앞선 요청은 재전송 공격으로 보고 거절합니다
+const betterModeWebhookMaxAge = 5 * time.Minute
+
+// BetterMode 웹훅 요청 본문의 최대 크기
+const betterModeWebhookMaxBytes = 1 << 20
+
+// BetterMode 웹훅 서명 헤더
+const (
+	betterModeSignatureHeader = "X-Bettermode-Signature"
+	betterModeTimestampHeader = "X-Bettermode-Request-Timestamp"
+)
+
+// prefetcher는 웹훅으로 수정을 알게 된 게시물을 백그라운드에서 다시 가져와 캐시를 미리 채웁니다.
+// 큐에 이미 있는 게시물은 다시 넣지 않으며, 큐가 가득 차면 새 요청은 버립니다 (다음 조회 때 평소처럼 가져옴).
+type prefetcher struct {
+	queue   chan string
+	pending map[string]bool
+	mutex   sync.Mutex
+}
+
+func newPrefetcher(size int) *prefetcher {
+	if size < 1 {
+		size = 1
+	}
+	return &prefetcher{queue: make(chan string, size), pending: make(map[string]bool)}
+}
+
+// 전역 미리 가져오기 큐
+var prefetches *prefetcher
+
+// enqueue는 postID를 큐에 넣습니다. 이미 큐에 있으면 true, 큐가 가득 차 넣지 못했으면 false를 반환합니다.
+func (p *prefetcher) enqueue(postID string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.pending[postID] {
+		return true
+	}
+	select {
+	case p.queue <- postID:
+		p.pending[postID] = true
+		return true
+	default:
+		return false
+	}
+}
+
+// start는 ctx가 끝날 때까지 큐의 게시물을 하나씩 다시 가져오는 고루틴을 시작합니다
+func (p *prefetcher) start(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case postID := <-p.queue:
+				p.mutex.Lock()
+				delete(p.pending, postID)
+				p.mutex.Unlock()
+				p.refresh(ctx, postID)
+			}
+		}
+	}()
+}
+
+// refresh는 게시물의 캐시 항목을 모두 지우고 기본 옵션(html) 응답을 새로 가져와 캐시에 저장합니다
+func (p *prefetcher) refresh(ctx context.Context, postID string) {
+	removed := contentCache.InvalidatePost(postID)
+	req := ContentRequest{PostID: postID}
+	if err := req.normalize(); err != nil {
+		log.Printf("Prefetch of post %s skipped: %v", postID, err)
+		return
+	}
+	if _, err := fetchContentResponse(ctx, req); err != nil {
+		log.Printf("Prefetch of post %s failed (purged %d cache entries): %v", postID, removed, err)
+		return
+	}
+	log.Printf("Prefetched post %s after webhook (replaced %d cache entries)", postID, removed)
+}
+
+// betterModeWebhook은 BetterMode 웹훅 요청 본문입니다. 구독 이벤트는 data.name(예: "post.updated")과
+// data.object(게시물)로, 웹훅 등록 시의 확인 요청은 type "TEST"와 data.challenge로 옵니다.
+type betterModeWebhook struct {
+	Type string `json:"type"`
+	Data struct {
+		Challenge string `json:"challenge"`
+		Name      string `json:"name"`
+		Object    struct {
+			ID string `json:"id"`
+		} `json:"object"`
+	} `json:"data"`
+}
+
+// verifyBetterModeSignature는 "타임스탬프:본문"을 secret으로 HMAC-SHA256한 hex 값이 서명과 같은지 확인합니다.
+// 타임스탬프는 밀리초 단위 유닉스 시각입니다. 실패하면 이유와 false를 반환합니다.
+func verifyBetterModeSignature(r *http.Request, body []byte, secret string, now time.Time) (string, bool) {
+	timestamp := r.Header.Get(betterModeTimestampHeader)
+	signature := r.Header.Get(betterModeSignatureHeader)
+	if timestamp == "" || signature == "" {
+		return betterModeTimestampHeader + " and " + betterModeSignatureHeader + " are required", false
+	}
+
+	millis, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "Invalid " + betterModeTimestampHeader, false
+	}
+	if age := now.Sub(time.UnixMilli(millis)); age > betterModeWebhookMaxAge || age < -betterModeWebhookMaxAge {
+		return "Webhook timestamp expired", false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + ":"))
+	mac.Write(body)
+	got, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(got, mac.Sum(nil)) {
+		return "Invalid signature", false
+	}
+	return "", true
+}
+
+// HandleBetterModeWebhook godoc
+// @Summary Receive BetterMode webhooks
+// @Description Verifies the webhook signature (BETTERMODE_WEBHOOK_SECRET). post.published and post.updated events queue a background refetch of the post's cache entry; post.deleted purges it. TEST requests are answered with their challenge.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {string} string "Bad request"
+// @Failure 401 {string} string "Invalid signature"
+// @Failure 404 {string} string "Webhook receiver is not configured"
+// @Router /webhooks/bettermode [post]
+func handleBetterModeWebhook(w http.ResponseWriter, r *http.Request) {
+	cfg := currentConfig()
+	if cfg.BetterModeWebhookSecret == "" {
+		writeError(w, "BetterMode webhook receiver is not configured (BETTERMODE_WEBHOOK_SECRET)", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, betterModeWebhookMaxBytes+1))
+	if err != nil {
+		writeError(w, "Error reading request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > betterModeWebhookMaxBytes {
+		writeError(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if msg, ok := verifyBetterModeSignature(r, body, cfg.BetterModeWebhookSecret, time.Now()); !ok {
+		writeError(w, msg, http.StatusUnauthorized)
+		return
+	}
+
+	var event betterModeWebhook
+	if err := json.Unmarshal(body, &event); err != nil {
+		writeError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if event.Type == "TEST" {
+		render.JSON(w, r, map[string]interface{}{
+			"type":   event.Type,
+			"status": "SUCCEEDED",
+			"data":   map[string]string{"challenge": event.Data.Challenge},
+		})
+		return
+	}
+
+	postID := event.Data.Object.ID
+	result := map[string]interface{}{"type": event.Type, "status": "SUCCEEDED", "event": event.Data.Name}
+	switch event.Data.Name {
+	case "post.published", "post.updated":
+		if postID == "" {
+			writeError(w, "Event has no post ID", http.StatusBadRequest)
+			return
+		}
+		queued := prefetches.enqueue(postID)
+		if !queued {
+			log.Printf("Prefetch queue is full, dropped webhook refresh of post %s", postID)
+		}
+		result["post_id"] = postID
+		result["queued"] = queued
+	case "post.deleted":
+		if postID == "" {
+			writeError(w, "Event has no post ID", http.StatusBadRequest)
+			return
+		}
+		result["post_id"] = postID
+		result["purged"] = contentCache.InvalidatePost(postID)
+	default:
+		// 구독했지만 처리하지 않는 이벤트는 BetterMode가 다시 보내지 않도록 성공으로 응답합니다
+		result["ignored"] = true
+	}
+	render.JSON(w, r, result)
+}
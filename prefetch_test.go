@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// signBetterModeWebhook은 BetterMode처럼 body에 서명한 웹훅 요청을 만듭니다
+func signBetterModeWebhook(secret, body string, at time.Time) *http.Request {
+	timestamp := strconv.FormatInt(at.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + ":" + body))
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/bettermode", strings.NewReader(body))
+	r.Header.Set(betterModeTimestampHeader, timestamp)
+	r.Header.Set(betterModeSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	return r
+}
+
+func TestVerifyBetterModeSignature(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	body := `{"type":"TEST"}`
+	tests := []struct {
+		name   string
+		modify func(r *http.Request)
+		at     time.Time
+		want   bool
+	}{
+		{"valid", func(r *http.Request) {}, now, true},
+		{"within clock skew", func(r *http.Request) {}, now.Add(time.Minute), true},
+		{"expired", func(r *http.Request) {}, now.Add(-betterModeWebhookMaxAge - time.Second), false},
+		{"from the future", func(r *http.Request) {}, now.Add(betterModeWebhookMaxAge + time.Second), false},
+		{"missing signature", func(r *http.Request) { r.Header.Del(betterModeSignatureHeader) }, now, false},
+		{"missing timestamp", func(r *http.Request) { r.Header.Del(betterModeTimestampHeader) }, now, false},
+		{"invalid timestamp", func(r *http.Request) { r.Header.Set(betterModeTimestampHeader, "yesterday") }, now, false},
+		{"not hex", func(r *http.Request) { r.Header.Set(betterModeSignatureHeader, "zz") }, now, false},
+		{"wrong signature", func(r *http.Request) { r.Header.Set(betterModeSignatureHeader, strings.Repeat("00", 32)) }, now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := signBetterModeWebhook("secret", body, tt.at)
+			tt.modify(r)
+			if msg, ok := verifyBetterModeSignature(r, []byte(body), "secret", now); ok != tt.want {
+				t.Errorf("verifyBetterModeSignature() = (%q, %v), want ok %v", msg, ok, tt.want)
+			}
+		})
+	}
+
+	// 다른 본문이나 다른 비밀 값으로는 통과하지 않습니다
+	r := signBetterModeWebhook("secret", body, now)
+	if _, ok := verifyBetterModeSignature(r, []byte(`{"type":"OTHER"}`), "secret", now); ok {
+		t.Error("signature should not match a different body")
+	}
+	if _, ok := verifyBetterModeSignature(r, []byte(body), "other-secret", now); ok {
+		t.Error("signature should not match a different secret")
+	}
+}
+
+func TestPrefetcherEnqueue(t *testing.T) {
+	p := newPrefetcher(2)
+	tests := []struct {
+		postID string
+		want   bool
+	}{
+		{"post-1", true},
+		{"post-1", true}, // 이미 큐에 있음
+		{"post-2", true},
+		{"post-3", false}, // 큐가 가득 참
+	}
+	for _, tt := range tests {
+		if got := p.enqueue(tt.postID); got != tt.want {
+			t.Errorf("enqueue(%q) = %v, want %v", tt.postID, got, tt.want)
+		}
+	}
+	if len(p.queue) != 2 {
+		t.Errorf("queue length = %d, want 2", len(p.queue))
+	}
+	if newPrefetcher(0).enqueue("post-1") != true {
+		t.Error("a non-positive size should still queue one post")
+	}
+}
+
+func TestPrefetcherRefreshesCache(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	fake := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, postData(testPostJSON("제목", "<p>본문</p>")))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	p := newPrefetcher(1)
+	p.start(ctx, &wg)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	p.enqueue("post-1")
+	req := ContentRequest{PostID: "post-1"}
+	if err := req.normalize(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := contentCache.Get(contentCacheKey(req)); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("prefetch did not fill the cache")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if fake.count() != 1 {
+		t.Errorf("upstream calls = %d, want 1", fake.count())
+	}
+}
+
+func TestHandleBetterModeWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     string
+		body       string
+		badSig     bool
+		wantStatus int
+		want       map[string]interface{}
+		wantQueued string
+	}{
+		{"not configured", "", `{"type":"TEST"}`, false, http.StatusNotFound, nil, ""},
+		{"bad signature", "secret", `{"type":"TEST"}`, true, http.StatusUnauthorized, nil, ""},
+		{"invalid body", "secret", `{`, false, http.StatusBadRequest, nil, ""},
+		{"challenge", "secret", `{"type":"TEST","data":{"challenge":"abc"}}`, false, http.StatusOK,
+			map[string]interface{}{"status": "SUCCEEDED", "data": map[string]interface{}{"challenge": "abc"}}, ""},
+		{"post updated", "secret", `{"type":"SUBSCRIPTION","data":{"name":"post.updated","object":{"id":"post-1"}}}`, false, http.StatusOK,
+			map[string]interface{}{"post_id": "post-1", "queued": true}, "post-1"},
+		{"post published", "secret", `{"type":"SUBSCRIPTION","data":{"name":"post.published","object":{"id":"post-2"}}}`, false, http.StatusOK,
+			map[string]interface{}{"post_id": "post-2", "queued": true}, "post-2"},
+		{"post deleted", "secret", `{"type":"SUBSCRIPTION","data":{"name":"post.deleted","object":{"id":"post-1"}}}`, false, http.StatusOK,
+			map[string]interface{}{"post_id": "post-1", "purged": float64(0)}, ""},
+		{"missing post id", "secret", `{"type":"SUBSCRIPTION","data":{"name":"post.updated","object":{}}}`, false, http.StatusBadRequest, nil, ""},
+		{"ignored event", "secret", `{"type":"SUBSCRIPTION","data":{"name":"member.verified"}}`, false, http.StatusOK,
+			map[string]interface{}{"ignored": true}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.BetterModeWebhookSecret = tt.secret })
+			withContentCache(t)
+			prev := prefetches
+			prefetches = newPrefetcher(10)
+			t.Cleanup(func() { prefetches = prev })
+
+			r := signBetterModeWebhook("secret", tt.body, time.Now())
+			if tt.badSig {
+				r.Header.Set(betterModeSignatureHeader, strings.Repeat("00", 32))
+			}
+			rec := httptest.NewRecorder()
+			handleBetterModeWebhook(rec, r)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			if tt.want != nil {
+				var got map[string]interface{}
+				if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+					t.Fatal(err)
+				}
+				for key, value := range tt.want {
+					gotJSON, _ := json.Marshal(got[key])
+					wantJSON, _ := json.Marshal(value)
+					if string(gotJSON) != string(wantJSON) {
+						t.Errorf("%s = %s, want %s", key, gotJSON, wantJSON)
+					}
+				}
+			}
+
+			select {
+			case postID := <-prefetches.queue:
+				if postID != tt.wantQueued {
+					t.Errorf("queued %q, want %q", postID, tt.wantQueued)
+				}
+			default:
+				if tt.wantQueued != "" {
+					t.Errorf("nothing queued, want %q", tt.wantQueued)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleBetterModeWebhookDeletePurgesCache(t *testing.T) {
+	withConfig(t, func(cfg *Config) { cfg.BetterModeWebhookSecret = "secret" })
+	withContentCache(t)
+	opts := ContentOptions{Format: "html"}
+	contentCache.Set(contentCacheKey(ContentRequest{PostID: "post-1", ContentOptions: opts}), ContentResponse{PostID: "post-1"}, opts)
+	contentCache.SetPost("post-1", newTestPost("제목", "<p>본문</p>"), opts)
+
+	rec := httptest.NewRecorder()
+	handleBetterModeWebhook(rec, signBetterModeWebhook("secret",
+		`{"type":"SUBSCRIPTION","data":{"name":"post.deleted","object":{"id":"post-1"}}}`, time.Now()))
+	var got struct {
+		Purged int `json:"purged"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Purged != 2 {
+		t.Errorf("purged = %d, want 2", got.Purged)
+	}
+	if _, ok := contentCache.GetPost("post-1", opts); ok {
+		t.Error("post is still cached after post.deleted")
+	}
+}
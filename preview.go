@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// previewEllipsis는 미리보기로 자른 본문 끝에 붙입니다
+const previewEllipsis = "…"
+
+// previewText는 텍스트가 maxChars 글자(rune)보다 길면 maxChars 글자까지 남기고 "…"를 붙입니다.
+// 가능하면 단어 중간이 아니라 앞의 공백에서 자릅니다. 두 번째 값은 잘랐는지 여부입니다.
+func previewText(text string, maxChars int) (string, bool) {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) <= maxChars {
+		return text, false
+	}
+	return cutRunes(text, maxChars) + previewEllipsis, true
+}
+
+// previewHTML은 보이는 글자 수가 maxChars를 넘는 HTML을 maxChars 글자까지만 남깁니다.
+// 태그 구조는 유지하므로 결과는 잘 닫힌 HTML이며, 자른 지점 뒤의 요소(이미지 포함)는 버립니다.
+func previewHTML(content string, maxChars int) (string, bool, error) {
+	if visibleCharCount(content, "html") <= maxChars {
+		return content, false, nil
+	}
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", false, err
+	}
+	root := &html.Node{Type: html.ElementNode, Data: "body"}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+
+	remaining := maxChars
+	cutHTMLChildren(root, &remaining)
+
+	var kept []*html.Node
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		kept = append(kept, c)
+	}
+	for _, c := range kept {
+		root.RemoveChild(c)
+	}
+	rendered, err := renderHTMLFragment(kept)
+	if err != nil {
+		return "", false, err
+	}
+	return rendered, true, nil
+}
+
+// cutHTMLChildren은 n의 자식을 차례로 보며 remaining 글자를 다 쓴 텍스트 노드 끝에 "…"를 붙이고 그 뒤의 노드를 지웁니다.
+// 글자를 다 썼으면 true를 반환합니다.
+func cutHTMLChildren(n *html.Node, remaining *int) bool {
+	done := false
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch {
+		case done:
+			n.RemoveChild(c)
+		case c.Type == html.TextNode:
+			count := utf8.RuneCountInString(strings.TrimSpace(c.Data))
+			if count >= *remaining {
+				// 앞 공백은 인라인 요소 사이의 띄어쓰기일 수 있으므로 남깁니다
+				text := strings.TrimLeftFunc(c.Data, unicode.IsSpace)
+				c.Data = c.Data[:len(c.Data)-len(text)] + cutRunes(text, *remaining) + previewEllipsis
+				*remaining = 0
+				done = true
+			} else {
+				*remaining -= count
+			}
+		case c.Type == html.ElementNode:
+			done = cutHTMLChildren(c, remaining)
+		}
+		c = next
+	}
+	return done
+}
+
+// cutRunes는 s의 앞 max 글자를 반환합니다. 마지막 단어가 잘리면 그 앞의 공백에서 자르되,
+// 남는 글자가 절반보다 적어지면(공백 없이 긴 한 단어 등) 그냥 max 글자에서 자릅니다.
+func cutRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return string(runes)
+	}
+	cut := max
+	if !unicode.IsSpace(runes[max]) {
+		for i := max; i > max/2; i-- {
+			if unicode.IsSpace(runes[i-1]) {
+				cut = i - 1
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace)
+}
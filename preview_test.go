@@ -0,0 +1,88 @@
+package main
+
+import "testing"
+
+func TestCutRunes(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"hello world", 5, "hello"},
+		{"hello world", 8, "hello"},
+		{"안녕하세요 반갑습니다", 8, "안녕하세요"},
+		{"supercalifragilistic", 6, "superc"},
+		{"a bcdefghij", 6, "a bcde"}, // 공백에서 자르면 절반보다 적게 남습니다
+	}
+	for _, tt := range tests {
+		if got := cutRunes(tt.s, tt.max); got != tt.want {
+			t.Errorf("cutRunes(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestPreviewText(t *testing.T) {
+	tests := []struct {
+		text        string
+		max         int
+		want        string
+		wantHasMore bool
+	}{
+		{"  짧은 본문 ", 10, "짧은 본문", false},
+		{"hello world", 11, "hello world", false},
+		{"hello world again", 11, "hello world…", true},
+	}
+	for _, tt := range tests {
+		got, hasMore := previewText(tt.text, tt.max)
+		if got != tt.want || hasMore != tt.wantHasMore {
+			t.Errorf("previewText(%q, %d) = %q, %v; want %q, %v", tt.text, tt.max, got, hasMore, tt.want, tt.wantHasMore)
+		}
+	}
+}
+
+func TestPreviewHTML(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		max         int
+		want        string
+		wantHasMore bool
+	}{
+		{"fits", "<p>hello</p>", 5, "<p>hello</p>", false},
+		{"cut inside paragraph", "<p>hello world</p><p>more</p>", 8, "<p>hello…</p>", true},
+		{"cut inside inline element", "<p>a <b>bold text here</b> tail</p>", 6, "<p>a <b>bold…</b></p>", true},
+		{"keeps earlier images", `<p>hello</p><img src="x.png"><p>world</p>`, 7, `<p>hello</p><img src="x.png"/><p>wo…</p>`, true},
+		{"drops later images", `<p>hello world</p><img src="x.png">`, 5, "<p>hello…</p>", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, hasMore, err := previewHTML(tt.content, tt.max)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || hasMore != tt.wantHasMore {
+				t.Errorf("previewHTML = %q, %v; want %q, %v", got, hasMore, tt.want, tt.wantHasMore)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseAutoPreview(t *testing.T) {
+	post := newTestPost("제목", "<p>hello world again</p>")
+	tests := []struct {
+		opts        ContentOptions
+		want        string
+		wantHasMore bool
+	}{
+		{ContentOptions{Format: "html", AutoPreview: 11}, "<p>hello world…</p>", true},
+		{ContentOptions{Format: "text", AutoPreview: 11}, "hello world…", true},
+		{ContentOptions{Format: "text", AutoPreview: 100}, "hello world again", false},
+	}
+	for _, tt := range tests {
+		response := renderTestPost(t, tt.opts, post)
+		if response.Content != tt.want || response.HasMore != tt.wantHasMore {
+			t.Errorf("%s: content = %q, has_more = %v; want %q, %v", tt.opts.Format, response.Content, response.HasMore, tt.want, tt.wantHasMore)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gqlField는 GraphQL 선택 집합(selection set)의 필드 하나입니다. children이 있으면 하위 필드를 선택합니다.
+type gqlField struct {
+	name     string
+	children []gqlField
+}
+
+// gqlFields는 하위 필드가 없는 필드 목록을 만듭니다
+func gqlFields(names ...string) []gqlField {
+	fields := make([]gqlField, len(names))
+	for i, name := range names {
+		fields[i] = gqlField{name: name}
+	}
+	return fields
+}
+
+// writeSelection은 fields를 들여쓰기한 선택 집합 문자열로 씁니다
+func writeSelection(b *strings.Builder, fields []gqlField, depth int) {
+	indent := strings.Repeat("\t", depth)
+	for _, f := range fields {
+		b.WriteString(indent)
+		b.WriteString(f.name)
+		if len(f.children) > 0 {
+			b.WriteString(" {\n")
+			writeSelection(b, f.children, depth+1)
+			b.WriteString(indent)
+			b.WriteString("}")
+		}
+		b.WriteString("\n")
+	}
+}
+
+// postContentField는 본문이 들어 있는 매핑 필드로, metadata_only 요청에서는 조회하지 않습니다
+var postContentField = gqlField{name: "mappingFields", children: gqlFields("key", "type", "value")}
+
+// postBaseFields는 모든 콘텐츠 요청에서 조회하는 게시물 필드입니다
+var postBaseFields = []gqlField{
+	{name: "title"},
+	{name: "spaceId"},
+	{name: "updatedAt"},
+	{name: "space", children: gqlFields("id", "name")},
+}
+
+// postAttachmentsField는 include_attachments 요청에서만 조회합니다
+var postAttachmentsField = gqlField{name: "attachments", children: gqlFields("name", "url", "downloadUrl", "size", "extension")}
+
+// postAuthorFields는 jsonld 형식 요청에서만 조회합니다
+var postAuthorFields = []gqlField{
+	{name: "publishedAt"},
+	{name: "owner", children: []gqlField{{name: "member", children: gqlFields("name")}}},
+}
+
+// postEngagementFields는 include_engagement 요청에서만 조회합니다
+var postEngagementFields = []gqlField{
+	{name: "reactionsCount"},
+	{name: "repliesCount"},
+	{name: "reactions", children: gqlFields("reaction", "count")},
+}
+
+// postSelection은 요청 옵션에 필요한 게시물 필드만 골라 반환합니다
+func postSelection(opts ContentOptions) []gqlField {
+	var fields []gqlField
+	if !opts.MetadataOnly {
+		fields = append(fields, postContentField)
+	}
+	fields = append(fields, postBaseFields...)
+	if opts.IncludeAttachments {
+		fields = append(fields, postAttachmentsField)
+	}
+	if opts.IncludeEngagement {
+		fields = append(fields, postEngagementFields...)
+	}
+	if opts.includesAuthor() {
+		fields = append(fields, postAuthorFields...)
+	}
+	return fields
+}
+
+// buildPostQuery는 요청 옵션에 필요한 필드만 선택하는 게시물 조회 쿼리를 만듭니다.
+// 변수는 $id(게시물 ID) 하나입니다.
+func buildPostQuery(opts ContentOptions) string {
+	var b strings.Builder
+	b.WriteString("query GetPost($id: ID!) {\n\tpost(id: $id) {\n")
+	writeSelection(&b, postSelection(opts), 2)
+	b.WriteString("\t}\n}")
+	return b.String()
+}
+
+// listOrder는 게시물 목록의 정렬 방향입니다. BetterMode posts 쿼리는 orderByString 필드의 오름차순(오래된 순)이
+// 기본이고 reverse: true이면 내림차순(최신순)입니다. 목록 쿼리는 모두 buildPostListQuery로 만들어 이 값으로 reverse를 정합니다.
+type listOrder int
+
+const (
+	oldestFirst listOrder = iota // reverse: false
+	newestFirst                  // reverse: true
+)
+
+// postListPageInfo는 목록 쿼리의 커서 정보 선택입니다
+var postListPageInfo = gqlField{name: "pageInfo", children: gqlFields("hasNextPage", "endCursor")}
+
+// buildPostListQuery는 스페이스 게시물을 orderBy 필드 기준 order 순서로 가져오는 목록 쿼리를 만듭니다.
+// 게시물마다 nodes에 있는 필드만 선택하며, 변수는 $spaceIds, $limit, $after입니다.
+func buildPostListQuery(name, orderBy string, order listOrder, nodes []gqlField) string {
+	var b strings.Builder
+	b.WriteString("query " + name + "($spaceIds: [ID!], $limit: Int!, $after: String) {\n")
+	fmt.Fprintf(&b, "\tposts(spaceIds: $spaceIds, limit: $limit, after: $after, orderByString: %q, reverse: %t) {\n", orderBy, order == newestFirst)
+	writeSelection(&b, []gqlField{{name: "nodes", children: nodes}, postListPageInfo}, 2)
+	b.WriteString("\t}\n}")
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildPostQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        ContentOptions
+		wantFields  []string
+		wantMissing []string
+	}{
+		{
+			name:        "default",
+			opts:        ContentOptions{Format: "html"},
+			wantFields:  []string{"mappingFields {", "title", "spaceId", "updatedAt", "space {"},
+			wantMissing: []string{"attachments", "reactionsCount", "owner", "publishedAt"},
+		},
+		{
+			name:        "metadata only skips content",
+			opts:        ContentOptions{Format: "html", MetadataOnly: true},
+			wantFields:  []string{"title", "updatedAt"},
+			wantMissing: []string{"mappingFields"},
+		},
+		{
+			name:       "attachments",
+			opts:       ContentOptions{Format: "html", IncludeAttachments: true},
+			wantFields: []string{"attachments {\n\t\t\tname\n\t\t\turl\n\t\t\tdownloadUrl\n\t\t\tsize\n\t\t\textension\n\t\t}"},
+		},
+		{
+			name:        "engagement",
+			opts:        ContentOptions{Format: "html", IncludeEngagement: true},
+			wantFields:  []string{"reactionsCount", "repliesCount", "reactions {"},
+			wantMissing: []string{"attachments"},
+		},
+		{
+			name:       "jsonld adds author",
+			opts:       ContentOptions{Format: FormatJSONLD},
+			wantFields: []string{"publishedAt", "owner {\n\t\t\tmember {\n\t\t\t\tname\n\t\t\t}\n\t\t}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := buildPostQuery(tt.opts)
+			if !strings.HasPrefix(query, "query GetPost($id: ID!) {\n\tpost(id: $id) {\n") {
+				t.Errorf("unexpected query header:\n%s", query)
+			}
+			for _, want := range tt.wantFields {
+				if !strings.Contains(query, want) {
+					t.Errorf("query missing %q:\n%s", want, query)
+				}
+			}
+			for _, unwanted := range tt.wantMissing {
+				if strings.Contains(query, unwanted) {
+					t.Errorf("query should not select %q:\n%s", unwanted, query)
+				}
+			}
+			if strings.Count(query, "{") != strings.Count(query, "}") {
+				t.Errorf("unbalanced braces:\n%s", query)
+			}
+		})
+	}
+}
+
+func TestBuildPostListQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		orderBy     string
+		order       listOrder
+		wantReverse string
+	}{
+		{"oldest first", "createdAt", oldestFirst, `orderByString: "createdAt", reverse: false`},
+		{"newest first", "updatedAt", newestFirst, `orderByString: "updatedAt", reverse: true`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := buildPostListQuery("ListTest", tt.orderBy, tt.order, gqlFields("id", "title"))
+			for _, want := range []string{
+				"query ListTest($spaceIds: [ID!], $limit: Int!, $after: String) {",
+				tt.wantReverse,
+				"\t\tnodes {\n\t\t\tid\n\t\t\ttitle\n\t\t}",
+				"\t\tpageInfo {\n\t\t\thasNextPage\n\t\t\tendCursor\n\t\t}",
+			} {
+				if !strings.Contains(query, want) {
+					t.Errorf("query missing %q:\n%s", want, query)
+				}
+			}
+			if strings.Count(query, "{") != strings.Count(query, "}") {
+				t.Errorf("unbalanced braces:\n%s", query)
+			}
+		})
+	}
+}
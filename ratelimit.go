@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// tokenBucket은 초당 rate개씩 채워지고 최대 burst개까지 쌓이는 토큰 버킷입니다
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mutex  sync.Mutex
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// take는 토큰이 있으면 하나를 쓰고 0을 반환합니다.
+// 토큰이 없으면 아무것도 쓰지 않고 다음 토큰이 생길 때까지 남은 시간을 반환합니다.
+func (b *tokenBucket) take(now time.Time) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// rateLimiter는 요청 속도를 제한합니다. 한도를 넘은 요청은 최대 maxWait 동안 대기열에서 기다렸다가
+// 처리하고, 대기열이 가득 찼거나 maxWait 안에 차례가 오지 않으면 429를 반환합니다.
+type rateLimiter struct {
+	bucket  *tokenBucket
+	maxWait time.Duration
+	queue   chan struct{} // 대기 중인 요청 수를 제한하는 세마포어
+}
+
+func newRateLimiter(rate float64, burst int, maxWait time.Duration, queueDepth int) *rateLimiter {
+	if queueDepth < 0 {
+		queueDepth = 0
+	}
+	return &rateLimiter{
+		bucket:  newTokenBucket(rate, burst),
+		maxWait: maxWait,
+		queue:   make(chan struct{}, queueDepth),
+	}
+}
+
+// wait는 요청을 처리해도 되면 true를 반환합니다. 필요하면 대기열에서 기다리며,
+// 대기열이 가득 찼거나 maxWait를 넘기게 되거나 ctx가 취소되면 false와 함께
+// 다음 토큰이 생길 때까지 남은 시간을 반환합니다(Retry-After에 사용).
+func (l *rateLimiter) wait(ctx context.Context) (bool, time.Duration) {
+	delay := l.bucket.take(time.Now())
+	if delay == 0 {
+		return true, 0
+	}
+	if l.maxWait <= 0 {
+		return false, delay
+	}
+
+	select {
+	case l.queue <- struct{}{}:
+		defer func() { <-l.queue }()
+	default:
+		return false, delay // 대기열이 가득 참
+	}
+
+	deadline := time.Now().Add(l.maxWait)
+	for delay > 0 {
+		if time.Now().Add(delay).After(deadline) {
+			return false, delay
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false, delay
+		case <-timer.C:
+		}
+		delay = l.bucket.take(time.Now())
+	}
+	return true, 0
+}
+
+// 전역 요청 속도 제한기 (RATE_LIMIT_RPS가 0이면 nil). 설정을 다시 읽으면 새 제한기로 바뀝니다
+var requestLimiter atomic.Pointer[rateLimiter]
+
+// applyRateLimit은 설정에 맞는 제한기로 교체합니다. 기다리던 요청은 이전 제한기에서 계속 기다립니다
+func applyRateLimit(cfg *Config) {
+	if cfg.RateLimitRPS <= 0 {
+		requestLimiter.Store(nil)
+		return
+	}
+	requestLimiter.Store(newRateLimiter(cfg.RateLimitRPS, cfg.RateLimitBurst, cfg.RateLimitQueueWait, cfg.RateLimitQueueDepth))
+}
+
+// rateLimitMiddleware는 RATE_LIMIT_RPS를 넘는 요청을 잠시 대기시키거나 429로 거절합니다
+func rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limiter := requestLimiter.Load(); limiter != nil {
+			if ok, retryAfter := limiter.wait(r.Context()); !ok {
+				setRetryAfter(w, retryAfter)
+				writeError(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setRetryAfter는 d를 올림한 초 단위 Retry-After 헤더를 설정합니다 (최소 1초)
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
+
+// parseRetryAfter는 Retry-After 헤더 값(초 또는 HTTP 날짜)을 대기 시간으로 바꿉니다
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
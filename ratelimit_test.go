@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketTake(t *testing.T) {
+	start := time.Now()
+	b := newTokenBucket(2, 2) // 초당 2개, 최대 2개
+	b.last = start
+
+	steps := []struct {
+		at        time.Duration
+		wantDelay time.Duration
+	}{
+		{0, 0},
+		{0, 0},
+		{0, 500 * time.Millisecond}, // 버스트를 다 썼으므로 0.5초 뒤 다음 토큰
+		{500 * time.Millisecond, 0},
+		{10 * time.Second, 0}, // 오래 쉬어도 burst 이상 쌓이지 않습니다
+		{10 * time.Second, 0},
+		{10 * time.Second, 500 * time.Millisecond},
+	}
+	for i, step := range steps {
+		if got := b.take(start.Add(step.at)); got != step.wantDelay {
+			t.Errorf("step %d: take = %v, want %v", i, got, step.wantDelay)
+		}
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	tests := []struct {
+		name       string
+		rate       float64
+		maxWait    time.Duration
+		queueDepth int
+		wantOK     bool
+	}{
+		{"no queue rejects", 10, 0, 0, false},
+		{"queued request proceeds", 20, time.Second, 1, true},
+		{"wait longer than max rejects", 0.1, 100 * time.Millisecond, 1, false},
+		{"full queue rejects", 20, time.Second, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRateLimiter(tt.rate, 1, tt.maxWait, tt.queueDepth)
+			if ok, _ := l.wait(context.Background()); !ok {
+				t.Fatal("first request rejected")
+			}
+			ok, retryAfter := l.wait(context.Background())
+			if ok != tt.wantOK {
+				t.Errorf("second request ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok && retryAfter <= 0 {
+				t.Errorf("retryAfter = %v, want > 0", retryAfter)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddlewareSetsRetryAfter(t *testing.T) {
+	prev := requestLimiter.Load()
+	t.Cleanup(func() { requestLimiter.Store(prev) })
+	requestLimiter.Store(newRateLimiter(0.5, 1, 0, 0))
+
+	handler := rateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	codes := []int{}
+	var last *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		last = httptest.NewRecorder()
+		handler.ServeHTTP(last, httptest.NewRequest("GET", "/", nil))
+		codes = append(codes, last.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+		t.Errorf("status codes = %v, want [200 429]", codes)
+	}
+	if got := last.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"Wed, 01 May 2024 10:00:30 GMT", 30 * time.Second, true},
+		{"Wed, 01 May 2024 09:00:00 GMT", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSendGraphQLRequestRetriesUpstream429(t *testing.T) {
+	tests := []struct {
+		name       string
+		queueWait  time.Duration
+		retryAfter string
+		wantCalls  int
+		wantStatus int
+	}{
+		{"retried within queue wait", time.Second, "0", 2, http.StatusOK},
+		{"retry-after too long", time.Second, "60", 1, http.StatusTooManyRequests},
+		{"retry disabled", 0, "0", 1, http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.RateLimitQueueWait = tt.queueWait })
+			var calls int64
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt64(&calls, 1) == 1 {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					writeJSONResponse(w, http.StatusTooManyRequests, map[string]interface{}{})
+					return
+				}
+				writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{}})
+			})
+
+			resp, err := sendGraphQLRequest(context.Background(), "tok", "query { __typename }", nil)
+			if err != nil {
+				t.Fatalf("sendGraphQLRequest: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := int(atomic.LoadInt64(&calls)); got != tt.wantCalls {
+				t.Errorf("upstream calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
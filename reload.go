@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// 전역 서버 설정. 설정을 다시 읽으면 새 Config로 통째로 바뀌며, 한 번 공개된 Config는 수정하지 않습니다.
+// 포인터 하나만 원자적으로 바꾸므로, 읽는 쪽은 잠금 없이 항상 이전 설정이나 새 설정 중 하나를 온전히 봅니다.
+var activeConfig atomic.Pointer[Config]
+
+// currentConfig는 현재 적용 중인 설정을 반환합니다.
+// 요청 처리 중 여러 값을 함께 써야 하면 한 번만 호출해 같은 설정을 사용하세요.
+// 다시 읽기 전에 받은 설정은 바뀌지 않으므로, 처리 중인 요청은 끝날 때까지 같은 설정을 씁니다.
+func currentConfig() *Config {
+	return activeConfig.Load()
+}
+
+func setConfig(cfg *Config) {
+	activeConfig.Store(cfg)
+}
+
+// staticSettings는 시작할 때만 사용하거나 시작 시 만든 객체에 들어가 있어, 바꾸려면 재시작이 필요한 설정입니다.
+// 다시 읽을 때 값이 바뀌었으면 로그만 남기고 이전 값을 유지합니다.
+var staticSettings = []string{
+	"Port",
+	"ShutdownTimeout",
+	"CacheCleanupInterval",
+	"TokenNetworkDomains",
+	"FailOnInitialTokenError",
+	"ValidateTokenOnStartup",
+	"DefaultSpaceID",
+	// 캐시된 응답에 이미 들어간 필드가 바뀐 정책과 어긋나지 않도록 재시작(캐시 비움)이 필요합니다
+	"FieldDenyList",
+	"FieldAllowList",
+	"CircuitBreakerThreshold",
+	"CircuitBreakerOpenDuration",
+	"TokenCircuitBreakerThreshold",
+	"TokenCircuitBreakerOpenDuration",
+	"AppEnv",
+	"ChaosEnabled",
+	"ImageProxyCacheEntries",
+	"UpstreamResponseCacheEntries",
+	"TranslatorURL",
+	"TranslatorAPIKey",
+}
+
+// reloadConfig는 CONFIG_FILE과 환경 변수에서 설정을 다시 읽어 적용합니다.
+// 재시작이 필요한 설정은 이전 값을 유지하고, 캐시 TTL과 최대 항목 수, 로그 수준과 샘플링, 요청 속도 제한처럼
+// 시작 시 만든 객체에 들어간 값은 새 설정으로 다시 적용합니다.
+func reloadConfig() error {
+	if err := applyConfigFile(); err != nil {
+		return err
+	}
+
+	prev := currentConfig()
+	next := loadConfig()
+	keepStaticSettings(next, prev)
+	setConfig(next)
+
+	if contentCache != nil {
+		contentCache.SetTTL(next.CacheTTL, next.CacheSoftTTL)
+		contentCache.SetMaxEntries(next.CacheMaxEntries)
+	}
+	if next.AccessLogSampleRate != prev.AccessLogSampleRate {
+		accessLogSampleRate.Store(int64(next.AccessLogSampleRate))
+	}
+	if next.LogLevel != prev.LogLevel {
+		setLogLevel(next.LogLevel)
+		log.Printf("Log level set to %s", next.LogLevel)
+	}
+	if next.RateLimitRPS != prev.RateLimitRPS || next.RateLimitBurst != prev.RateLimitBurst ||
+		next.RateLimitQueueWait != prev.RateLimitQueueWait || next.RateLimitQueueDepth != prev.RateLimitQueueDepth {
+		applyRateLimit(next)
+	}
+	return nil
+}
+
+// keepStaticSettings는 next의 재시작이 필요한 설정을 prev 값으로 되돌립니다
+func keepStaticSettings(next, prev *Config) {
+	nv := reflect.ValueOf(next).Elem()
+	pv := reflect.ValueOf(prev).Elem()
+	for _, name := range staticSettings {
+		nf, pf := nv.FieldByName(name), pv.FieldByName(name)
+		if reflect.DeepEqual(nf.Interface(), pf.Interface()) {
+			continue
+		}
+		log.Printf("Config reload: %s changed but requires a restart; keeping the current value", name)
+		nf.Set(pf)
+	}
+}
+
+// watchReloadSignal은 SIGHUP을 받을 때마다 설정을 다시 읽는 고루틴을 시작합니다
+func watchReloadSignal(ctx context.Context, wg *sync.WaitGroup) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer signal.Stop(signals)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				if err := reloadConfig(); err != nil {
+					log.Printf("Config reload failed, keeping the current configuration: %v", err)
+					continue
+				}
+				log.Println("Configuration reloaded")
+			}
+		}
+	}()
+}
+
+// CONFIG_FILE에서 읽어 환경 변수로 설정한 값의 기록
+var (
+	configFileMutex sync.Mutex
+	configFileKeys  = make(map[string]bool)    // 마지막으로 파일에서 설정한 키
+	originalEnv     = make(map[string]*string) // 파일이 덮어쓰기 전의 환경 변수 값 (nil이면 없던 값)
+)
+
+// applyConfigFile은 CONFIG_FILE이 설정되어 있으면 그 파일의 KEY=VALUE 줄을 환경 변수로 설정합니다.
+// 파일 값이 프로세스 환경 변수보다 우선하며, 파일에서 빠진 키는 원래 환경 변수 값으로 되돌립니다.
+func applyConfigFile() error {
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		return nil
+	}
+	values, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	configFileMutex.Lock()
+	defer configFileMutex.Unlock()
+
+	for key := range configFileKeys {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		if original := originalEnv[key]; original != nil {
+			os.Setenv(key, *original)
+		} else {
+			os.Unsetenv(key)
+		}
+		delete(configFileKeys, key)
+	}
+	for key, value := range values {
+		if _, recorded := originalEnv[key]; !recorded {
+			if original, ok := os.LookupEnv(key); ok {
+				originalEnv[key] = &original
+			} else {
+				originalEnv[key] = nil
+			}
+		}
+		os.Setenv(key, value)
+		configFileKeys[key] = true
+	}
+	return nil
+}
+
+// readConfigFile은 systemd EnvironmentFile과 같은 형식(KEY=VALUE, # 주석, 값의 따옴표 허용)의 파일을 읽습니다
+func readConfigFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNo)
+		}
+		if key == "CONFIG_FILE" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
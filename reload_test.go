@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// withReloadTestEnv는 reloadConfig가 바꾸는 전역 상태와 keys 환경 변수를 테스트가 끝나면 되돌리고,
+// 설정 파일 경로를 CONFIG_FILE로 설정해 반환합니다
+func withReloadTestEnv(t *testing.T, keys ...string) string {
+	t.Helper()
+	withConfig(t, func(cfg *Config) {})
+	withSampleRate(t, accessLogSampleRate.Load())
+	withContentCache(t)
+	for _, key := range keys {
+		t.Setenv(key, "")
+	}
+
+	configFileMutex.Lock()
+	prevKeys, prevOriginal := configFileKeys, originalEnv
+	configFileKeys, originalEnv = make(map[string]bool), make(map[string]*string)
+	configFileMutex.Unlock()
+	t.Cleanup(func() {
+		configFileMutex.Lock()
+		configFileKeys, originalEnv = prevKeys, prevOriginal
+		configFileMutex.Unlock()
+	})
+
+	path := filepath.Join(t.TempDir(), "scraper.env")
+	t.Setenv("CONFIG_FILE", path)
+	return path
+}
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	path := withReloadTestEnv(t, "ACCESS_LOG_SAMPLE_RATE", "CACHE_TTL", "PORT", "MAX_TITLE_LENGTH")
+	port := currentConfig().Port
+
+	writeConfigFile(t, path, "ACCESS_LOG_SAMPLE_RATE=5\nCACHE_TTL=2m\nPORT=9999\nMAX_TITLE_LENGTH='40'\n")
+	if err := reloadConfig(); err != nil {
+		t.Fatalf("reloadConfig: %v", err)
+	}
+	cfg := currentConfig()
+	if cfg.AccessLogSampleRate != 5 || accessLogSampleRate.Load() != 5 {
+		t.Errorf("sample rate = %d (logger %d), want 5", cfg.AccessLogSampleRate, accessLogSampleRate.Load())
+	}
+	if cfg.MaxTitleLength != 40 {
+		t.Errorf("MaxTitleLength = %d, want 40", cfg.MaxTitleLength)
+	}
+	if got := contentCache.Stats().TTL; got != (2 * time.Minute).String() {
+		t.Errorf("cache TTL = %s, want 2m0s", got)
+	}
+	if cfg.Port != port {
+		t.Errorf("Port = %q, want %q kept until restart", cfg.Port, port)
+	}
+
+	// 파일에서 빠진 키는 원래 환경 변수 값(여기서는 기본값)으로 돌아갑니다
+	writeConfigFile(t, path, "CACHE_TTL=2m\n")
+	if err := reloadConfig(); err != nil {
+		t.Fatalf("reloadConfig: %v", err)
+	}
+	if got := currentConfig().AccessLogSampleRate; got != 1 {
+		t.Errorf("sample rate after removing key = %d, want default 1", got)
+	}
+}
+
+func TestReloadConfigKeepsCurrentOnError(t *testing.T) {
+	path := withReloadTestEnv(t, "ACCESS_LOG_SAMPLE_RATE")
+	prev := currentConfig()
+
+	writeConfigFile(t, path, "ACCESS_LOG_SAMPLE_RATE=5\nnot a setting\n")
+	if err := reloadConfig(); err == nil {
+		t.Fatal("reloadConfig succeeded with an invalid file")
+	}
+	if currentConfig() != prev {
+		t.Error("configuration replaced after a failed reload")
+	}
+}
+
+func TestWatchReloadSignal(t *testing.T) {
+	path := withReloadTestEnv(t, "ACCESS_LOG_SAMPLE_RATE")
+	writeConfigFile(t, path, "ACCESS_LOG_SAMPLE_RATE=7\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	watchReloadSignal(ctx, &wg)
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for currentConfig().AccessLogSampleRate != 7 {
+		if time.Now().After(deadline) {
+			t.Fatal("configuration not reloaded after SIGHUP")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scraper.env")
+	writeConfigFile(t, path, `# 주석
+CACHE_TTL=5m
+export PORT = 9090
+TITLE="따옴표 값"
+SINGLE='x'
+EMPTY=
+CONFIG_FILE=/other.env
+`)
+	got, err := readConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"CACHE_TTL": "5m", "PORT": "9090", "TITLE": "따옴표 값", "SINGLE": "x", "EMPTY": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readConfigFile = %v, want %v", got, want)
+	}
+
+	writeConfigFile(t, path, "CACHE_TTL=5m\n=value\n")
+	if _, err := readConfigFile(path); err == nil {
+		t.Error("readConfigFile accepted a line without a key")
+	}
+}
+
+func TestKeepStaticSettings(t *testing.T) {
+	prev := &Config{Port: "8080", DefaultSpaceID: "space-1", CacheTTL: time.Minute}
+	next := &Config{Port: "9090", DefaultSpaceID: "space-2", CacheTTL: time.Hour}
+	keepStaticSettings(next, prev)
+	if next.Port != "8080" || next.DefaultSpaceID != "space-1" {
+		t.Errorf("static settings changed: port %q, default space %q", next.Port, next.DefaultSpaceID)
+	}
+	if next.CacheTTL != time.Hour {
+		t.Errorf("CacheTTL = %v, want reloadable value kept", next.CacheTTL)
+	}
+}
+
+func TestStaticSettingsAreConfigFields(t *testing.T) {
+	configType := reflect.TypeOf(Config{})
+	for _, name := range staticSettings {
+		if _, ok := configType.FieldByName(name); !ok {
+			t.Errorf("staticSettings lists %q, which is not a Config field", name)
+		}
+	}
+}
+
+// readConfigsConcurrently는 stop이 닫힐 때까지 readers개의 고루틴에서 currentConfig를 읽어 check로 검사합니다.
+// 읽은 횟수를 reads에 더하고, check가 실패를 반환한 설정 수를 반환합니다.
+func readConfigsConcurrently(readers int, stop <-chan struct{}, reads *int64, check func(*Config) bool) int {
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	torn := 0
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				atomic.AddInt64(reads, 1)
+				if !check(currentConfig()) {
+					mutex.Lock()
+					torn++
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return torn
+}
+
+func TestSetConfigConcurrentReaders(t *testing.T) {
+	withConfig(t, func(cfg *Config) {})
+	// 두 설정은 함께 바뀌어야 하는 값들이 서로 맞습니다. 읽는 쪽이 섞인 값을 보면 실패합니다.
+	configs := make([]*Config, 2)
+	for i := range configs {
+		cfg := *currentConfig()
+		n := (i + 1) * 10
+		cfg.MaxTitleLength, cfg.BatchMaxItems, cfg.BatchConcurrency = n, n, n
+		cfg.CacheTTL, cfg.CacheControlMaxAge = time.Duration(n)*time.Second, time.Duration(n)*time.Second
+		configs[i] = &cfg
+	}
+	setConfig(configs[0])
+
+	stop := make(chan struct{})
+	done := make(chan int)
+	var reads int64
+	go func() {
+		done <- readConfigsConcurrently(8, stop, &reads, func(cfg *Config) bool {
+			n := cfg.MaxTitleLength
+			return cfg.BatchMaxItems == n && cfg.BatchConcurrency == n &&
+				cfg.CacheTTL == time.Duration(n)*time.Second && cfg.CacheControlMaxAge == cfg.CacheTTL
+		})
+	}()
+	// 읽는 쪽이 충분히 읽는 동안 계속 바꿉니다
+	for i := 0; i < 10000 || atomic.LoadInt64(&reads) < 10000; i++ {
+		setConfig(configs[i%2])
+	}
+	close(stop)
+	if torn := <-done; torn != 0 {
+		t.Errorf("%d reads saw a mix of two configs", torn)
+	}
+	if configs[0].MaxTitleLength != 10 || configs[1].MaxTitleLength != 20 {
+		t.Error("a published config was modified")
+	}
+}
+
+func TestReloadConfigConcurrentReaders(t *testing.T) {
+	path := withReloadTestEnv(t, "MAX_TITLE_LENGTH", "BATCH_MAX_ITEMS")
+	writeConfigFile(t, path, "MAX_TITLE_LENGTH=40\nBATCH_MAX_ITEMS=40\n")
+	if err := reloadConfig(); err != nil {
+		t.Fatalf("reloadConfig: %v", err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan int)
+	var reads int64
+	go func() {
+		done <- readConfigsConcurrently(4, stop, &reads, func(cfg *Config) bool {
+			return cfg.MaxTitleLength == cfg.BatchMaxItems
+		})
+	}()
+	for i := 0; i < 50 || atomic.LoadInt64(&reads) < 1000; i++ {
+		n := 40 + 40*(i%2)
+		writeConfigFile(t, path, fmt.Sprintf("MAX_TITLE_LENGTH=%d\nBATCH_MAX_ITEMS=%d\n", n, n))
+		if err := reloadConfig(); err != nil {
+			t.Fatalf("reloadConfig: %v", err)
+		}
+	}
+	close(stop)
+	if torn := <-done; torn != 0 {
+		t.Errorf("%d reads saw settings from two reloads", torn)
+	}
+}
+
+func TestReloadConfigLogLevel(t *testing.T) {
+	path := withReloadTestEnv(t, "LOG_LEVEL")
+	withLogLevel(t, LogLevelInfo)
+	buf := captureLog(t)
+
+	tests := []struct {
+		file      string
+		wantDebug bool
+		wantInfo  bool
+	}{
+		{"LOG_LEVEL=debug\n", true, true},
+		{"LOG_LEVEL=warn\n", false, false},
+		{"", false, true}, // 파일에서 빠지면 기본값 info
+	}
+	for _, tt := range tests {
+		writeConfigFile(t, path, tt.file)
+		if err := reloadConfig(); err != nil {
+			t.Fatalf("reloadConfig: %v", err)
+		}
+		if logEnabled(LogLevelDebug) != tt.wantDebug || logEnabled(LogLevelInfo) != tt.wantInfo {
+			t.Errorf("after %q: debug %v, info %v; want %v, %v", tt.file,
+				logEnabled(LogLevelDebug), logEnabled(LogLevelInfo), tt.wantDebug, tt.wantInfo)
+		}
+		buf.Reset()
+		logDebugf("probe")
+		if got := buf.Len() > 0; got != tt.wantDebug {
+			t.Errorf("after %q: debug log written = %v, want %v", tt.file, got, tt.wantDebug)
+		}
+	}
+}
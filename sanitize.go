@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// 콘텐츠 정리(sanitize) 프로필
+const (
+	SanitizeStrict        = "strict"         // 스크립트, 스타일, iframe 등 능동적인 요소를 모두 제거
+	SanitizeEmbedFriendly = "embed-friendly" // strict와 같지만 https iframe(동영상 등 임베드)은 유지
+	SanitizePermissive    = "permissive"     // 스크립트와 이벤트 핸들러 속성만 제거
+)
+
+// sanitizeProfiles는 프로필별로 제거할 요소입니다
+var sanitizeProfiles = map[string]map[string]bool{
+	SanitizeStrict: {
+		"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+		"form": true, "input": true, "button": true, "textarea": true, "select": true, "link": true, "meta": true, "base": true,
+	},
+	SanitizeEmbedFriendly: {
+		"script": true, "style": true, "object": true, "embed": true,
+		"form": true, "input": true, "button": true, "textarea": true, "select": true, "link": true, "meta": true, "base": true,
+	},
+	SanitizePermissive: {
+		"script": true,
+	},
+}
+
+func isSanitizeProfile(name string) bool {
+	_, ok := sanitizeProfiles[name]
+	return ok
+}
+
+// sanitizeProfileForSpace는 SANITIZE_SPACE_PROFILES에서 스페이스에 지정된 프로필을, 없으면 기본 프로필을 반환합니다.
+// 빈 문자열이면 정리하지 않습니다.
+func sanitizeProfileForSpace(spaceID string) string {
+	cfg := currentConfig()
+	if profile, ok := cfg.SanitizeSpaceProfiles[spaceID]; ok && spaceID != "" {
+		return profile
+	}
+	return cfg.SanitizeDefaultProfile
+}
+
+// sanitizeHTML은 프로필에 따라 위험한 요소와 속성(on* 이벤트 핸들러, javascript: URL)을 제거합니다
+func sanitizeHTML(content, profile string) (string, error) {
+	removed, ok := sanitizeProfiles[profile]
+	if !ok {
+		return content, nil
+	}
+
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", err
+	}
+
+	var kept []*html.Node
+	for _, n := range nodes {
+		if shouldRemoveNode(n, removed, profile) {
+			continue
+		}
+		kept = append(kept, n)
+		walkHTML(n, func(c *html.Node) bool {
+			for child := c.FirstChild; child != nil; {
+				next := child.NextSibling
+				if shouldRemoveNode(child, removed, profile) {
+					c.RemoveChild(child)
+				}
+				child = next
+			}
+			if c.Type == html.ElementNode {
+				c.Attr = sanitizeAttrs(c.Attr)
+			}
+			return true
+		})
+	}
+	return renderHTMLFragment(kept)
+}
+
+func shouldRemoveNode(n *html.Node, removed map[string]bool, profile string) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	tag := strings.ToLower(n.Data)
+	if tag == "iframe" && profile == SanitizeEmbedFriendly {
+		return !strings.HasPrefix(strings.ToLower(strings.TrimSpace(attrValue(n, "src"))), "https://")
+	}
+	return removed[tag]
+}
+
+// sanitizeAttrs는 이벤트 핸들러 속성과 javascript: URL 속성을 제거합니다
+func sanitizeAttrs(attrs []html.Attribute) []html.Attribute {
+	kept := attrs[:0]
+	for _, attr := range attrs {
+		key := strings.ToLower(attr.Key)
+		if strings.HasPrefix(key, "on") {
+			continue
+		}
+		if (key == "href" || key == "src" || key == "action" || key == "formaction") &&
+			strings.HasPrefix(strings.ToLower(strings.TrimSpace(attr.Val)), "javascript:") {
+			continue
+		}
+		kept = append(kept, attr)
+	}
+	return kept
+}
+
+func attrValue(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if strings.EqualFold(attr.Key, key) {
+			return attr.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package main
+
+import "testing"
+
+func TestSanitizeHTML(t *testing.T) {
+	const content = `<p onclick="x()">본문 <a href="javascript:alert(1)">링크</a></p>` +
+		`<iframe src="https://www.youtube.com/embed/abc"></iframe>` +
+		`<iframe src="http://example.com/"></iframe>` +
+		`<script>alert(1)</script><style>p{}</style>`
+	tests := []struct {
+		profile string
+		want    string
+	}{
+		{SanitizeStrict, `<p>본문 <a>링크</a></p>`},
+		{SanitizeEmbedFriendly, `<p>본문 <a>링크</a></p><iframe src="https://www.youtube.com/embed/abc"></iframe>`},
+		{SanitizePermissive, `<p>본문 <a>링크</a></p><iframe src="https://www.youtube.com/embed/abc"></iframe><iframe src="http://example.com/"></iframe><style>p{}</style>`},
+		{"", content},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			got, err := sanitizeHTML(content, tt.profile)
+			if err != nil {
+				t.Fatalf("sanitizeHTML: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("sanitizeHTML(%s) =\n%s\nwant\n%s", tt.profile, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeHTMLRemovesNestedElements(t *testing.T) {
+	got, err := sanitizeHTML(`<div><p>a<script>x</script>b</p><form><input></form></div>`, SanitizeStrict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<div><p>ab</p></div>`; got != want {
+		t.Errorf("sanitizeHTML = %q, want %q", got, want)
+	}
+}
+
+func TestRenderContentResponseSanitizeProfilePerSpace(t *testing.T) {
+	const content = `<p>영상</p><iframe src="https://player.example.com/v/1"></iframe>`
+	tests := []struct {
+		name        string
+		spaceID     string
+		nestedSpace bool // spaceId 대신 space.id로만 스페이스를 받은 경우
+		wantProfile string
+		wantContent string
+	}{
+		{"embed-friendly space", "marketing", false, SanitizeEmbedFriendly, content},
+		{"strict space", "docs", false, SanitizeStrict, `<p>영상</p>`},
+		{"space from space object", "marketing", true, SanitizeEmbedFriendly, content},
+		{"unmapped space uses default", "other", false, SanitizeStrict, `<p>영상</p>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) {
+				cfg.SanitizeDefaultProfile = SanitizeStrict
+				cfg.SanitizeSpaceProfiles = map[string]string{"marketing": SanitizeEmbedFriendly, "docs": SanitizeStrict}
+			})
+			post := newTestPost("제목", content)
+			if tt.nestedSpace {
+				post.Space = &PostSpace{ID: tt.spaceID}
+			} else {
+				post.SpaceID = tt.spaceID
+			}
+
+			response := renderTestPost(t, ContentOptions{Format: "html"}, post)
+			if response.SanitizeProfile != tt.wantProfile {
+				t.Errorf("sanitize_profile = %q, want %q", response.SanitizeProfile, tt.wantProfile)
+			}
+			if response.Content != tt.wantContent {
+				t.Errorf("content = %q, want %q", response.Content, tt.wantContent)
+			}
+			if response.SpaceID != tt.spaceID {
+				t.Errorf("space_id = %q, want %q", response.SpaceID, tt.spaceID)
+			}
+		})
+	}
+}
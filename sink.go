@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Sink는 내보내기 결과를 HTTP 응답 대신 저장할 대상입니다
+type Sink interface {
+	// Write는 r을 끝까지 읽어 대상에 저장합니다. 실패하면 대상에 일부만 남지 않도록 정리합니다.
+	Write(ctx context.Context, r io.Reader) error
+	// String은 로그와 작업 상태에 표시할 대상 위치입니다
+	String() string
+}
+
+// newSink는 "file:경로" 또는 "s3://버킷/키" 형식의 destination에 맞는 Sink를 만듭니다.
+// 로컬 파일은 EXPORT_LOCAL_DIR 아래에만, S3는 S3_* 설정이 있을 때만 허용합니다.
+func newSink(destination string) (Sink, error) {
+	cfg := currentConfig()
+	u, err := url.Parse(destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination: %w", err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		if cfg.ExportLocalDir == "" {
+			return nil, errors.New("local file export is disabled (EXPORT_LOCAL_DIR is not set)")
+		}
+		name := u.Opaque
+		if name == "" {
+			name = strings.TrimPrefix(u.Path, "/")
+		}
+		return newLocalFileSink(cfg.ExportLocalDir, name)
+	case "s3":
+		if cfg.S3Endpoint == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			return nil, errors.New("S3 export is disabled (S3_ENDPOINT and credentials are not set)")
+		}
+		key := strings.TrimPrefix(u.Path, "/")
+		if u.Host == "" || key == "" {
+			return nil, errors.New("S3 destination must be s3://bucket/key")
+		}
+		return &s3Sink{
+			endpoint:  strings.TrimSuffix(cfg.S3Endpoint, "/"),
+			region:    cfg.S3Region,
+			accessKey: cfg.S3AccessKeyID,
+			secretKey: cfg.S3SecretAccessKey,
+			bucket:    u.Host,
+			key:       key,
+			client:    upstreamClient,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported destination scheme %q (use file: or s3://)", u.Scheme)
+	}
+}
+
+// localFileSink는 dir 아래의 파일에 씁니다
+type localFileSink struct {
+	path string
+}
+
+// newLocalFileSink는 dir을 벗어나지 않는 상대 경로 name에 쓰는 Sink를 만듭니다
+func newLocalFileSink(dir, name string) (*localFileSink, error) {
+	if name == "" || filepath.IsAbs(name) {
+		return nil, errors.New("file destination must be a relative path, e.g. file:exports/posts.ndjson")
+	}
+	path := filepath.Join(dir, filepath.Clean(name))
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, errors.New("file destination must stay inside EXPORT_LOCAL_DIR")
+	}
+	return &localFileSink{path: path}, nil
+}
+
+func (s *localFileSink) Write(ctx context.Context, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	// 다 쓴 뒤에 이름을 바꿔, 실패하거나 쓰는 중인 파일이 최종 경로에 보이지 않게 합니다
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *localFileSink) String() string {
+	return "file:" + s.path
+}
+
+// s3Sink는 S3 호환 스토리지에 path-style URL(엔드포인트/버킷/키)과 SigV4 서명으로 업로드합니다
+type s3Sink struct {
+	endpoint  string
+	region    string
+	accessKey string
+	secretKey string
+	bucket    string
+	key       string
+	client    *http.Client
+}
+
+func (s *s3Sink) Write(ctx context.Context, r io.Reader) error {
+	// PUT에는 Content-Length가 필요하므로 임시 파일에 모은 뒤 한 번에 올립니다
+	tmp, err := os.CreateTemp("", "export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(), tmp)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	s.sign(req, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error uploading to S3: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("S3 upload failed with HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (s *s3Sink) String() string {
+	return "s3://" + s.bucket + "/" + s.key
+}
+
+func (s *s3Sink) objectURL() string {
+	return s.endpoint + s.canonicalURI()
+}
+
+func (s *s3Sink) canonicalURI() string {
+	segments := strings.Split(s.key, "/")
+	for i, seg := range segments {
+		segments[i] = awsURIEncode(seg)
+	}
+	return "/" + awsURIEncode(s.bucket) + "/" + strings.Join(segments, "/")
+}
+
+// sign은 AWS Signature Version 4로 요청에 서명합니다. 본문은 서명하지 않습니다(UNSIGNED-PAYLOAD).
+func (s *s3Sink) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	const payloadHash = "UNSIGNED-PAYLOAD"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s.canonicalURI(),
+		"",
+		"host:" + req.URL.Host + "\n" + "x-amz-content-sha256:" + payloadHash + "\n" + "x-amz-date:" + amzDate + "\n",
+		"host;x-amz-content-sha256;x-amz-date",
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=%s",
+		s.accessKey, scope, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsURIEncode는 SigV4 규칙대로 unreserved 문자(A-Z a-z 0-9 - _ . ~)를 제외하고 모두 퍼센트 인코딩합니다
+func awsURIEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewSink(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name        string
+		localDir    string
+		s3          bool
+		destination string
+		want        string // Sink.String(), 비어 있으면 에러를 기대합니다
+	}{
+		{"file opaque", dir, false, "file:exports/posts.ndjson", "file:" + filepath.Join(dir, "exports/posts.ndjson")},
+		{"file path", dir, false, "file:///posts.ndjson", "file:" + filepath.Join(dir, "posts.ndjson")},
+		{"file disabled", "", false, "file:posts.ndjson", ""},
+		{"file escapes dir", dir, false, "file:../posts.ndjson", ""},
+		{"file rooted path stays inside dir", dir, false, "file:/etc/passwd", "file:" + filepath.Join(dir, "etc/passwd")},
+		{"s3", "", true, "s3://bucket/exports/posts.ndjson", "s3://bucket/exports/posts.ndjson"},
+		{"s3 disabled", "", false, "s3://bucket/posts.ndjson", ""},
+		{"s3 missing key", "", true, "s3://bucket", ""},
+		{"unknown scheme", dir, true, "ftp://host/posts.ndjson", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) {
+				cfg.ExportLocalDir = tt.localDir
+				cfg.S3Endpoint, cfg.S3AccessKeyID, cfg.S3SecretAccessKey = "", "", ""
+				if tt.s3 {
+					cfg.S3Endpoint, cfg.S3AccessKeyID, cfg.S3SecretAccessKey = "https://s3.example.com", "AKID", "secret"
+				}
+			})
+			sink, err := newSink(tt.destination)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("newSink(%q) = %v, want error", tt.destination, sink)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newSink(%q): %v", tt.destination, err)
+			}
+			if got := sink.String(); got != tt.want {
+				t.Errorf("sink = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalFileSinkWrite(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := newLocalFileSink(dir, "nested/posts.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(context.Background(), strings.NewReader("{\"post_id\":\"a\"}\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "nested/posts.ndjson"))
+	if err != nil || string(data) != "{\"post_id\":\"a\"}\n" {
+		t.Errorf("file = %q, %v", data, err)
+	}
+	assertNoTempFiles(t, filepath.Join(dir, "nested"))
+}
+
+func TestLocalFileSinkWriteFailureLeavesNoFile(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := newLocalFileSink(dir, "posts.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("partial"))
+		pw.CloseWithError(io.ErrUnexpectedEOF)
+	}()
+	if err := sink.Write(context.Background(), pr); err == nil {
+		t.Fatal("Write succeeded, want error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "posts.ndjson")); !os.IsNotExist(err) {
+		t.Errorf("destination exists after failed write: %v", err)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+// assertNoTempFiles는 dir에 쓰다 남은 임시 파일이 없는지 확인합니다
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, _ := filepath.Glob(filepath.Join(dir, ".export-*"))
+	if len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestS3SinkWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"uploaded", http.StatusOK, false},
+		{"rejected", http.StatusForbidden, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				method, path, body, length, auth, payload string
+			}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				got.method, got.path, got.body = r.Method, r.URL.EscapedPath(), string(body)
+				got.length = r.Header.Get("Content-Length")
+				got.auth, got.payload = r.Header.Get("Authorization"), r.Header.Get("X-Amz-Content-Sha256")
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			sink := &s3Sink{endpoint: srv.URL, region: "us-east-1", accessKey: "AKID", secretKey: "secret",
+				bucket: "bucket", key: "exports/a b.ndjson", client: srv.Client()}
+			err := sink.Write(context.Background(), strings.NewReader("line\n"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Write error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got.method != http.MethodPut || got.path != "/bucket/exports/a%20b.ndjson" {
+				t.Errorf("request = %s %s, want PUT /bucket/exports/a%%20b.ndjson", got.method, got.path)
+			}
+			if got.body != "line\n" || got.length != "5" {
+				t.Errorf("body = %q (Content-Length %s), want uploaded content", got.body, got.length)
+			}
+			if got.payload != "UNSIGNED-PAYLOAD" {
+				t.Errorf("X-Amz-Content-Sha256 = %q", got.payload)
+			}
+			if !strings.HasPrefix(got.auth, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+				!strings.Contains(got.auth, "/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+				t.Errorf("Authorization = %q", got.auth)
+			}
+		})
+	}
+}
+
+func TestAWSURIEncode(t *testing.T) {
+	tests := map[string]string{
+		"posts.ndjson": "posts.ndjson",
+		"a b":          "a%20b",
+		"a+b/c~d_e-f":  "a%2Bb%2Fc~d_e-f",
+		"한":            "%ED%95%9C",
+	}
+	for in, want := range tests {
+		if got := awsURIEncode(in); got != want {
+			t.Errorf("awsURIEncode(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
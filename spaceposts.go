@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+)
+
+// spacePostFields는 게시물 목록에서 fields로 고를 수 있는 필드와 각 필드에 필요한 GraphQL 선택입니다.
+// fields를 지정하지 않으면 이 순서로 모두 반환합니다.
+var spacePostFields = []struct {
+	name      string
+	selection gqlField
+}{
+	{"id", gqlField{name: "id"}},
+	{"title", gqlField{name: "title"}},
+	{"spaceId", gqlField{name: "spaceId"}},
+	{"createdAt", gqlField{name: "createdAt"}},
+	{"updatedAt", gqlField{name: "updatedAt"}},
+	{"publishedAt", gqlField{name: "publishedAt"}},
+	{"author", gqlField{name: "owner", children: []gqlField{{name: "member", children: gqlFields("name")}}}},
+	{"reactionsCount", gqlField{name: "reactionsCount"}},
+	{"repliesCount", gqlField{name: "repliesCount"}},
+}
+
+// SpacePostsResponse는 스페이스 게시물 목록의 한 페이지입니다 (최신순)
+type SpacePostsResponse struct {
+	SpaceID    string                   `json:"space_id"`
+	Fields     []string                 `json:"fields"` // 각 게시물에 들어 있는 필드
+	Posts      []map[string]interface{} `json:"posts"`
+	NextCursor string                   `json:"next_cursor,omitempty"` // 더 남아 있으면 다음 페이지를 cursor로 요청
+}
+
+// spacePostsParams는 게시물 목록의 쿼리 파라미터입니다
+type spacePostsParams struct {
+	Fields []string `json:"fields"` // 반환할 필드 (비어 있으면 모든 필드)
+	Limit  int      `json:"limit"`
+	Cursor string   `json:"cursor"`
+}
+
+// listedPost는 목록 쿼리로 받은 게시물 하나입니다. 선택하지 않은 필드는 비어 있습니다.
+type listedPost struct {
+	ID             string     `json:"id"`
+	Title          string     `json:"title"`
+	SpaceID        string     `json:"spaceId"`
+	CreatedAt      string     `json:"createdAt"`
+	UpdatedAt      string     `json:"updatedAt"`
+	PublishedAt    string     `json:"publishedAt"`
+	Owner          *PostOwner `json:"owner"`
+	ReactionsCount *int       `json:"reactionsCount"`
+	RepliesCount   *int       `json:"repliesCount"`
+}
+
+// value는 field 필드의 값을 반환합니다
+func (p listedPost) value(field string) interface{} {
+	switch field {
+	case "id":
+		return p.ID
+	case "title":
+		return p.Title
+	case "spaceId":
+		return p.SpaceID
+	case "createdAt":
+		return p.CreatedAt
+	case "updatedAt":
+		return p.UpdatedAt
+	case "publishedAt":
+		return p.PublishedAt
+	case "author":
+		if p.Owner != nil && p.Owner.Member != nil {
+			return p.Owner.Member.Name
+		}
+		return ""
+	case "reactionsCount":
+		return p.ReactionsCount
+	case "repliesCount":
+		return p.RepliesCount
+	}
+	return nil
+}
+
+// validateSpacePostFields는 요청한 필드 목록을 검증합니다 (비어 있으면 모든 필드)
+func validateSpacePostFields(requested []string) ([]string, error) {
+	allowed := make([]string, len(spacePostFields))
+	for i, f := range spacePostFields {
+		allowed[i] = f.name
+	}
+	if len(requested) == 0 {
+		return allowed, nil
+	}
+	seen := make(map[string]bool, len(requested))
+	for _, field := range requested {
+		if !containsString(allowed, field) {
+			return nil, fmt.Errorf("fields must contain only %s", strings.Join(allowed, ", "))
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("field %q is listed more than once", field)
+		}
+		seen[field] = true
+	}
+	return requested, nil
+}
+
+// buildSpacePostsQuery는 fields에 필요한 게시물 필드만 선택해 스페이스 게시물을 최신순(createdAt 내림차순)으로
+// 가져오는 목록 쿼리를 만듭니다. 변수는 $spaceIds, $limit, $after입니다.
+func buildSpacePostsQuery(fields []string) string {
+	var selection []gqlField
+	for _, f := range spacePostFields {
+		if containsString(fields, f.name) {
+			selection = append(selection, f.selection)
+		}
+	}
+	return buildPostListQuery("ListSpacePostFields", "createdAt", newestFirst, selection)
+}
+
+// GetSpacePosts godoc
+// @Summary List posts of a space
+// @Description Returns posts of a space newest first, one page at a time. fields selects which post fields to return (and to query from BetterMode); by default all of id, title, spaceId, createdAt, updatedAt, publishedAt, author, reactionsCount and repliesCount are returned. Use next_cursor to fetch the next page.
+// @Tags content
+// @Produce json
+// @Param spaceID path string true "Space ID"
+// @Param space_id query string false "Space ID for the route without spaceID (default: DEFAULT_SPACE_ID)"
+// @Param fields query string false "Comma-separated fields to return (default: all)"
+// @Param limit query int false "Maximum posts per page (default 50, max BATCH_MAX_ITEMS)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} SpacePostsResponse
+// @Failure 400 {string} string "Bad request"
+// @Failure 502 {string} string "BetterMode API error"
+// @Router /spaces/{spaceID}/posts [get]
+// @Router /posts [get]
+func getSpacePosts(w http.ResponseWriter, r *http.Request) {
+	var params spacePostsParams
+	if err := applyQueryParams(r.URL.Query(), &params); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fields, err := validateSpacePostFields(params.Fields)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if params.Limit == 0 {
+		params.Limit = listPageSize
+	} else if maxItems := currentConfig().BatchMaxItems; params.Limit < 1 || params.Limit > maxItems {
+		writeError(w, fmt.Sprintf("limit must be between 1 and %d", maxItems), http.StatusBadRequest)
+		return
+	}
+
+	spaceID := requestSpaceID(r)
+	if spaceID == "" {
+		writeError(w, errNoSpaceID, http.StatusBadRequest)
+		return
+	}
+	posts, next, err := listSpacePostFields(r.Context(), spaceID, fields, params.Limit, params.Cursor)
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.As(err, new(*inputError)) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, errCallerTokenRejected) {
+			status = http.StatusUnauthorized
+		}
+		writeError(w, "Error fetching space posts: "+err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	render.JSON(w, r, SpacePostsResponse{
+		SpaceID:    spaceID,
+		Fields:     fields,
+		Posts:      posts,
+		NextCursor: next,
+	})
+}
+
+// listSpacePostFields는 스페이스의 게시물을 최신순으로 최대 limit개 가져와 fields만 담아 반환합니다.
+// limit개를 채웠고 더 남아 있으면 다음 페이지용 커서를 반환합니다.
+func listSpacePostFields(ctx context.Context, spaceID string, fields []string, limit int, after string) ([]map[string]interface{}, string, error) {
+	query := buildSpacePostsQuery(fields)
+	posts := []map[string]interface{}{}
+	for {
+		// 받은 페이지를 모두 쓰도록 남은 개수만 요청해, BetterMode 커서를 그대로 다음 페이지 커서로 씁니다
+		pageSize := limit - len(posts)
+		if pageSize > listPageSize {
+			pageSize = listPageSize
+		}
+		variables := map[string]interface{}{
+			"spaceIds": []string{spaceID},
+			"limit":    pageSize,
+		}
+		if after != "" {
+			variables["after"] = after
+		}
+
+		var data struct {
+			Posts struct {
+				Nodes    []listedPost `json:"nodes"`
+				PageInfo pageInfo     `json:"pageInfo"`
+			} `json:"posts"`
+		}
+		if err := queryBetterMode(ctx, query, variables, &data); err != nil {
+			return nil, "", err
+		}
+
+		for _, node := range data.Posts.Nodes {
+			post := make(map[string]interface{}, len(fields))
+			for _, field := range fields {
+				post[field] = node.value(field)
+			}
+			posts = append(posts, post)
+		}
+
+		page := data.Posts.PageInfo
+		if !page.HasNextPage || page.EndCursor == "" {
+			return posts, "", nil
+		}
+		if len(posts) >= limit {
+			return posts, page.EndCursor, nil
+		}
+		after = page.EndCursor
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildSpacePostsQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		fields      []string
+		wantFields  []string
+		wantMissing []string
+	}{
+		{
+			name:        "selected fields only",
+			fields:      []string{"id", "title"},
+			wantFields:  []string{"\t\tnodes {\n\t\t\tid\n\t\t\ttitle\n\t\t}"},
+			wantMissing: []string{"owner", "reactionsCount", "createdAt\n"},
+		},
+		{
+			name:       "author selects owner name",
+			fields:     []string{"author"},
+			wantFields: []string{"owner {\n\t\t\t\tmember {\n\t\t\t\t\tname\n\t\t\t\t}\n\t\t\t}"},
+		},
+		{
+			name:       "spacePostFields order",
+			fields:     []string{"repliesCount", "id"},
+			wantFields: []string{"\t\t\tid\n\t\t\trepliesCount\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := buildSpacePostsQuery(tt.fields)
+			for _, want := range append([]string{
+				"query ListSpacePostFields($spaceIds: [ID!], $limit: Int!, $after: String) {",
+				`orderByString: "createdAt", reverse: true`,
+				"pageInfo {\n\t\t\thasNextPage\n\t\t\tendCursor",
+			}, tt.wantFields...) {
+				if !strings.Contains(query, want) {
+					t.Errorf("query missing %q:\n%s", want, query)
+				}
+			}
+			for _, unwanted := range tt.wantMissing {
+				if strings.Contains(query, unwanted) {
+					t.Errorf("query should not select %q:\n%s", unwanted, query)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSpacePostFields(t *testing.T) {
+	all := []string{"id", "title", "spaceId", "createdAt", "updatedAt", "publishedAt", "author", "reactionsCount", "repliesCount"}
+	tests := []struct {
+		requested []string
+		want      []string
+		wantErr   bool
+	}{
+		{nil, all, false},
+		{[]string{"title", "id"}, []string{"title", "id"}, false},
+		{[]string{"content"}, nil, true},
+		{[]string{"id", "id"}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := validateSpacePostFields(tt.requested)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateSpacePostFields(%v) err = %v, wantErr %v", tt.requested, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("validateSpacePostFields(%v) = %v, want %v", tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestListedPostValue(t *testing.T) {
+	reactions := 3
+	post := listedPost{ID: "post-1", Title: "제목", Owner: &PostOwner{Member: &PostMember{Name: "작성자"}}, ReactionsCount: &reactions}
+	tests := []struct {
+		field string
+		post  listedPost
+		want  interface{}
+	}{
+		{"id", post, "post-1"},
+		{"title", post, "제목"},
+		{"author", post, "작성자"},
+		{"author", listedPost{}, ""},
+		{"reactionsCount", post, &reactions},
+		{"repliesCount", post, (*int)(nil)},
+		{"unknown", post, nil},
+	}
+	for _, tt := range tests {
+		if got := tt.post.value(tt.field); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("value(%q) = %#v, want %#v", tt.field, got, tt.want)
+		}
+	}
+}
+
+// spacePostsUpstream은 total개의 게시물을 요청받은 limit만큼 "cursor-N" 커서로 나눠 돌려주고 요청 변수를 기록합니다
+func spacePostsUpstream(t *testing.T, total int, requests *[]map[string]interface{}) {
+	t.Helper()
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		req := readGraphQLRequest(t, r)
+		*requests = append(*requests, req.Variables)
+		start := 0
+		if after, ok := req.Variables["after"].(string); ok {
+			fmt.Sscanf(after, "cursor-%d", &start)
+		}
+		limit := int(req.Variables["limit"].(float64))
+		var nodes []map[string]interface{}
+		for i := start; i < total && i < start+limit; i++ {
+			nodes = append(nodes, map[string]interface{}{"id": fmt.Sprintf("post-%d", i+1), "title": fmt.Sprintf("Post %d", i+1)})
+		}
+		end := start + len(nodes)
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"posts": map[string]interface{}{
+			"nodes":    nodes,
+			"pageInfo": map[string]interface{}{"hasNextPage": end < total, "endCursor": fmt.Sprintf("cursor-%d", end)},
+		}}})
+	})
+}
+
+func TestGetSpacePosts(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		total      int
+		wantStatus int
+		wantIDs    []string
+		wantNext   string
+		wantCalls  int
+	}{
+		{"single page", "fields=id,title", 2, http.StatusOK, []string{"post-1", "post-2"}, "", 1},
+		{"limit leaves cursor", "fields=id&limit=2", 5, http.StatusOK, []string{"post-1", "post-2"}, "cursor-2", 1},
+		{"cursor continues", "fields=id&limit=2&cursor=cursor-2", 5, http.StatusOK, []string{"post-3", "post-4"}, "cursor-4", 1},
+		{"limit above page size", fmt.Sprintf("fields=id&limit=%d", listPageSize+5), listPageSize + 10, http.StatusOK, nil, fmt.Sprintf("cursor-%d", listPageSize+5), 2},
+		{"empty space", "", 0, http.StatusOK, []string{}, "", 1},
+		{"unknown field", "fields=content", 1, http.StatusBadRequest, nil, "", 0},
+		{"limit too large", "limit=1000", 1, http.StatusBadRequest, nil, "", 0},
+		{"negative limit", "limit=-1", 1, http.StatusBadRequest, nil, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withConfig(t, func(cfg *Config) { cfg.BatchMaxItems = 100 })
+			var requests []map[string]interface{}
+			spacePostsUpstream(t, tt.total, &requests)
+
+			rec := httptest.NewRecorder()
+			getSpacePosts(rec, httptest.NewRequest(http.MethodGet, "/api/v1/posts?space_id=space-1&"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if len(requests) != tt.wantCalls {
+				t.Errorf("upstream calls = %d, want %d", len(requests), tt.wantCalls)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got SpacePostsResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.SpaceID != "space-1" || got.NextCursor != tt.wantNext {
+				t.Errorf("space_id = %q, next_cursor = %q, want space-1 and %q", got.SpaceID, got.NextCursor, tt.wantNext)
+			}
+			if tt.wantIDs != nil {
+				ids := []string{}
+				for _, post := range got.Posts {
+					ids = append(ids, post["id"].(string))
+					if len(post) != len(got.Fields) {
+						t.Errorf("post %v has fields other than %v", post, got.Fields)
+					}
+				}
+				if !reflect.DeepEqual(ids, tt.wantIDs) {
+					t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestGetSpacePostsUpstreamError(t *testing.T) {
+	withTestToken(t)
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{"errors": []map[string]string{{"message": "space not found"}}})
+	})
+	rec := httptest.NewRecorder()
+	getSpacePosts(rec, httptest.NewRequest(http.MethodGet, "/api/v1/posts?space_id=space-1", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502: %s", rec.Code, rec.Body)
+	}
+}
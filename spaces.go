@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const spaceQuery = `
+	query GetSpace($id: ID!) {
+		space(id: $id) {
+			id
+			name
+		}
+	}
+`
+
+// resolveSpaceID는 요청에서 지정한 스페이스를, 없으면 DEFAULT_SPACE_ID를 반환합니다 (둘 다 없으면 "")
+func resolveSpaceID(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return currentConfig().DefaultSpaceID
+}
+
+// errNoSpaceID는 스페이스 기준 조회에 스페이스를 정할 수 없을 때의 에러 메시지입니다
+const errNoSpaceID = "space_id is required (no DEFAULT_SPACE_ID is configured)"
+
+// requestSpaceID는 경로의 스페이스, space_id 쿼리 파라미터, DEFAULT_SPACE_ID 순서로 요청의 스페이스를 정합니다 (모두 없으면 "")
+func requestSpaceID(r *http.Request) string {
+	if spaceID := chi.URLParam(r, "spaceID"); spaceID != "" {
+		return spaceID
+	}
+	return resolveSpaceID(r.URL.Query().Get("space_id"))
+}
+
+// errSpaceNotFound는 BetterMode가 스페이스가 없다고 확실히 답했음을 나타냅니다
+var errSpaceNotFound = errors.New("space not found")
+
+// validateSpace는 BetterMode에 spaceID 스페이스가 있는지 확인하고 이름을 반환합니다.
+// 스페이스가 없거나 ID 형식이 잘못되었으면 errSpaceNotFound를 감싼 에러를 반환합니다.
+func validateSpace(ctx context.Context, spaceID string) (string, error) {
+	var data struct {
+		Space *struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"space"`
+	}
+	if err := queryBetterMode(ctx, spaceQuery, map[string]interface{}{"id": spaceID}, &data); err != nil {
+		var notFound *notFoundError
+		var badInput *inputError
+		if errors.As(err, &notFound) || errors.As(err, &badInput) {
+			return "", fmt.Errorf("%w: %s (%v)", errSpaceNotFound, spaceID, err)
+		}
+		return "", err
+	}
+	if data.Space == nil || data.Space.ID == "" {
+		return "", fmt.Errorf("%w: %s", errSpaceNotFound, spaceID)
+	}
+	return data.Space.Name, nil
+}
+
+// checkDefaultSpace는 시작 시 DEFAULT_SPACE_ID 스페이스가 있는지 확인합니다.
+// 스페이스가 없다고 확인된 경우에만 에러를 반환하고, 네트워크 오류 같은 일시적인 실패는 경고만 남깁니다.
+func checkDefaultSpace(ctx context.Context, spaceID string) error {
+	name, err := validateSpace(ctx, spaceID)
+	if errors.Is(err, errSpaceNotFound) {
+		return err
+	}
+	if err != nil {
+		log.Printf("Warning: could not validate DEFAULT_SPACE_ID %s, continuing: %v", spaceID, err)
+		return nil
+	}
+	log.Printf("Using default space %s (%s)", spaceID, name)
+	return nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestResolveSpaceID(t *testing.T) {
+	tests := []struct {
+		requested string
+		defaultID string
+		want      string
+	}{
+		{"space-1", "default", "space-1"},
+		{"", "default", "default"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		withConfig(t, func(cfg *Config) { cfg.DefaultSpaceID = tt.defaultID })
+		if got := resolveSpaceID(tt.requested); got != tt.want {
+			t.Errorf("resolveSpaceID(%q) with default %q = %q, want %q", tt.requested, tt.defaultID, got, tt.want)
+		}
+	}
+}
+
+// spaceRoutesUpstream은 스페이스 기준 조회에 빈 결과로 응답하고, 요청받은 스페이스 ID를 기록합니다
+func spaceRoutesUpstream(t *testing.T, spaceIDs *[]string) {
+	t.Helper()
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		req := readGraphQLRequest(t, r)
+		if id, ok := req.Variables["spaceId"].(string); ok {
+			*spaceIDs = append(*spaceIDs, id)
+		}
+		if ids, ok := req.Variables["spaceIds"].([]interface{}); ok && len(ids) == 1 {
+			*spaceIDs = append(*spaceIDs, ids[0].(string))
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{
+			"spacePinnedPosts": []interface{}{},
+			"posts":            map[string]interface{}{"nodes": []interface{}{}, "pageInfo": map[string]interface{}{"hasNextPage": false}},
+		}})
+	})
+}
+
+func TestSpaceRoutesUseDefaultSpace(t *testing.T) {
+	router := chi.NewRouter()
+	for name, handler := range map[string]http.HandlerFunc{
+		"changes":    getSpaceChanges,
+		"pinned":     getSpacePinnedPosts,
+		"posts":      getSpacePosts,
+		"export.csv": exportSpaceCSV,
+	} {
+		router.Get("/spaces/{spaceID}/"+name, handler)
+		router.Get("/"+name, handler)
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		defaultID  string
+		wantStatus int
+		wantSpace  string
+	}{
+		{"pinned default", "/pinned", "default", http.StatusOK, "default"},
+		{"pinned query", "/pinned?space_id=space-1", "default", http.StatusOK, "space-1"},
+		{"pinned path", "/spaces/space-2/pinned?space_id=space-1", "default", http.StatusOK, "space-2"},
+		{"pinned no space", "/pinned", "", http.StatusBadRequest, ""},
+		{"posts default", "/posts", "default", http.StatusOK, "default"},
+		{"posts query", "/posts?space_id=space-1", "default", http.StatusOK, "space-1"},
+		{"posts no space", "/posts", "", http.StatusBadRequest, ""},
+		{"csv default", "/export.csv?metadata_only=true", "default", http.StatusOK, "default"},
+		{"csv path", "/spaces/space-2/export.csv?metadata_only=true", "", http.StatusOK, "space-2"},
+		{"csv no space", "/export.csv", "", http.StatusBadRequest, ""},
+		{"changes default", "/changes?since=2024-05-01T00:00:00Z", "default", http.StatusOK, "default"},
+		{"changes query", "/changes?since=2024-05-01T00:00:00Z&space_id=space-1", "default", http.StatusOK, "space-1"},
+		{"changes path", "/spaces/space-2/changes?since=2024-05-01T00:00:00Z&space_id=space-1", "default", http.StatusOK, "space-2"},
+		{"changes no space", "/changes?since=2024-05-01T00:00:00Z", "", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withConfig(t, func(cfg *Config) { cfg.DefaultSpaceID = tt.defaultID })
+			var spaceIDs []string
+			spaceRoutesUpstream(t, &spaceIDs)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantSpace == "" {
+				if len(spaceIDs) != 0 {
+					t.Errorf("queried spaces %v, want none", spaceIDs)
+				}
+				return
+			}
+			if len(spaceIDs) == 0 || spaceIDs[0] != tt.wantSpace {
+				t.Errorf("queried spaces %v, want %s", spaceIDs, tt.wantSpace)
+			}
+		})
+	}
+}
+
+func TestValidateSpace(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         map[string]interface{}
+		wantName     string
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{"found", http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"space": map[string]interface{}{"id": "space-1", "name": "공지"}}}, "공지", false, false},
+		{"null space", http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"space": nil}}, "", true, true},
+		{"not found error", http.StatusOK, map[string]interface{}{"errors": []interface{}{
+			map[string]interface{}{"message": "Space not found", "extensions": map[string]interface{}{"code": "NOT_FOUND"}},
+		}}, "", true, true},
+		{"invalid id", http.StatusOK, map[string]interface{}{"errors": []interface{}{
+			map[string]interface{}{"message": "Invalid id", "extensions": map[string]interface{}{"code": "BAD_USER_INPUT"}},
+		}}, "", true, true},
+		{"server error", http.StatusOK, map[string]interface{}{"errors": []interface{}{
+			map[string]interface{}{"message": "Internal server error"},
+		}}, "", true, false},
+		{"unavailable", http.StatusServiceUnavailable, map[string]interface{}{"message": "down"}, "", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				if id := readGraphQLRequest(t, r).Variables["id"]; id != "space-1" {
+					t.Errorf("id = %v, want space-1", id)
+				}
+				writeJSONResponse(w, tt.status, tt.body)
+			})
+			name, err := validateSpace(context.Background(), "space-1")
+			if (err != nil) != tt.wantErr || name != tt.wantName {
+				t.Errorf("validateSpace = %q, %v; want %q, error %v", name, err, tt.wantName, tt.wantErr)
+			}
+			if got := errors.Is(err, errSpaceNotFound); got != tt.wantNotFound {
+				t.Errorf("errors.Is(%v, errSpaceNotFound) = %v, want %v", err, got, tt.wantNotFound)
+			}
+		})
+	}
+}
+
+func TestCheckDefaultSpaceContinuesOnTransientError(t *testing.T) {
+	withTestToken(t)
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusServiceUnavailable, map[string]interface{}{"message": "down"})
+	})
+	logs := captureLog(t)
+	if err := checkDefaultSpace(context.Background(), "space-1"); err != nil {
+		t.Fatalf("checkDefaultSpace = %v, want nil on a transient error", err)
+	}
+	if !strings.Contains(logs.String(), "could not validate DEFAULT_SPACE_ID space-1") {
+		t.Errorf("log = %q, want a warning about the skipped validation", logs.String())
+	}
+}
+
+func TestCheckDefaultSpaceFailsWhenNotFound(t *testing.T) {
+	withTestToken(t)
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"space": nil}})
+	})
+	if err := checkDefaultSpace(context.Background(), "space-1"); !errors.Is(err, errSpaceNotFound) {
+		t.Errorf("checkDefaultSpace = %v, want errSpaceNotFound", err)
+	}
+}
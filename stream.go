@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/go-chi/render"
+)
+
+// 큰 응답을 스트리밍할 때 한 번에 이스케이프해 내보내는 본문 크기
+const streamChunkBytes = 32 << 10
+
+// contentJSONPrefix는 content를 비운 ContentResponse JSON의 시작 부분입니다 (content가 첫 필드)
+const contentJSONPrefix = `{"content":""`
+
+// writeContentJSON은 콘텐츠 응답을 JSON으로 씁니다. content가 RESPONSE_STREAM_THRESHOLD 바이트보다 작으면
+// render.JSON으로 한 번에 쓰고, 크면 content를 조각내 JSON 문자열로 이스케이프하는 대로 내보냅니다.
+// 스트리밍하면 Content-Length 없이 chunked로 전송되고 본문의 JSON 사본 전체를 메모리에 만들지 않으며, 결과 JSON은 같습니다.
+func writeContentJSON(w http.ResponseWriter, r *http.Request, response ContentResponse) {
+	threshold := currentConfig().ResponseStreamThreshold
+	if threshold <= 0 || len(response.Content) < threshold {
+		render.JSON(w, r, response)
+		return
+	}
+
+	content := response.Content
+	response.Content = ""
+	rest, err := json.Marshal(response)
+	if err != nil || !bytes.HasPrefix(rest, []byte(contentJSONPrefix)) {
+		response.Content = content
+		render.JSON(w, r, response)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	rc := http.NewResponseController(w)
+	if _, err := io.WriteString(w, `{"content":"`); err != nil {
+		return
+	}
+	for len(content) > 0 {
+		n := streamChunkBytes
+		if n >= len(content) {
+			n = len(content)
+		} else {
+			// 이스케이프 결과가 한 번에 할 때와 같도록 UTF-8 문자 중간에서 자르지 않습니다
+			for n > 0 && !utf8.RuneStart(content[n]) {
+				n--
+			}
+			if n == 0 {
+				n = streamChunkBytes
+			}
+		}
+		escaped, _ := json.Marshal(content[:n])
+		if _, err := w.Write(escaped[1 : len(escaped)-1]); err != nil {
+			return
+		}
+		rc.Flush()
+		content = content[n:]
+	}
+	w.Write([]byte(`"`))
+	w.Write(rest[len(contentJSONPrefix):])
+	w.Write([]byte("\n"))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/render"
+)
+
+func TestWriteContentJSON(t *testing.T) {
+	// 조각 경계가 한글 문자 중간에 오도록 1바이트를 앞에 둡니다
+	korean := "a" + strings.Repeat("한글", streamChunkBytes/3)
+	escaped := strings.Repeat(`<p class="x">"quote" & \ back`+"\n\t</p>", streamChunkBytes/20)
+	tests := []struct {
+		name       string
+		content    string
+		threshold  int
+		wantStream bool
+	}{
+		{"below threshold", "<p>짧은 본문</p>", 1 << 20, false},
+		{"disabled", korean, 0, false},
+		{"at threshold", "<p>본문</p>", len("<p>본문</p>"), true},
+		{"multiple chunks", strings.Repeat("x", 3*streamChunkBytes+7), 1, true},
+		{"utf-8 across chunks", korean, 1, true},
+		{"escaped characters", escaped, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.ResponseStreamThreshold = tt.threshold })
+			response := ContentResponse{
+				PostID:   "post-1",
+				Title:    "제목",
+				Content:  tt.content,
+				Format:   "html",
+				Warnings: []Warning{{Code: WarningMissingTitle, Message: "post has no title"}},
+			}
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/content?post_id=post-1", nil)
+
+			want := httptest.NewRecorder()
+			render.JSON(want, r, response)
+			got := httptest.NewRecorder()
+			writeContentJSON(got, r, response)
+
+			if got.Body.String() != want.Body.String() {
+				t.Errorf("streamed JSON differs from render.JSON (lengths %d and %d)", got.Body.Len(), want.Body.Len())
+			}
+			if ct := got.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			if got.Flushed != tt.wantStream {
+				t.Errorf("flushed = %v, want %v", got.Flushed, tt.wantStream)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// styleAttrs는 strip_styles/strip_classes로 지울 속성을 반환합니다
+func styleAttrs(stripStyles, stripClasses bool) map[string]bool {
+	attrs := map[string]bool{}
+	if stripStyles {
+		attrs["style"] = true
+	}
+	if stripClasses {
+		attrs["class"] = true
+	}
+	return attrs
+}
+
+// stripAttrs는 본문 모든 요소에서 attrs에 있는 속성(인라인 style, class)을 지웁니다.
+// 태그와 텍스트는 그대로 두므로 클라이언트 CSS로 다시 꾸밀 수 있는 HTML이 됩니다.
+func stripAttrs(content string, attrs map[string]bool) (string, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", err
+	}
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			if c.Type != html.ElementNode {
+				return true
+			}
+			kept := c.Attr[:0]
+			for _, attr := range c.Attr {
+				if !attrs[strings.ToLower(attr.Key)] {
+					kept = append(kept, attr)
+				}
+			}
+			c.Attr = kept
+			return true
+		})
+	}
+	return renderHTMLFragment(nodes)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripAttrs(t *testing.T) {
+	content := `<p style="color:red" class="lead" id="p1">본문 <span STYLE="x" Class="y">강조</span></p>`
+	tests := []struct {
+		name         string
+		stripStyles  bool
+		stripClasses bool
+		want         string
+	}{
+		{"styles", true, false, `<p class="lead" id="p1">본문 <span class="y">강조</span></p>`},
+		{"classes", false, true, `<p style="color:red" id="p1">본문 <span style="x">강조</span></p>`},
+		{"both", true, true, `<p id="p1">본문 <span>강조</span></p>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stripAttrs(content, styleAttrs(tt.stripStyles, tt.stripClasses))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("stripAttrs =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseStripStyles(t *testing.T) {
+	post := newTestPost("제목", `<p style="color:red" class="lead">본문</p>`)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"html", `<p>본문</p>`},
+		{"xhtml", `<p>본문</p>`},
+		{"text", "본문"},
+	}
+	for _, tt := range tests {
+		response := renderTestPost(t, ContentOptions{Format: tt.format, StripStyles: true, StripClasses: true}, post)
+		if strings.TrimSpace(response.Content) != tt.want {
+			t.Errorf("%s content = %q, want %q", tt.format, response.Content, tt.want)
+		}
+	}
+
+	kept := renderTestPost(t, ContentOptions{Format: "html"}, post)
+	if !strings.Contains(kept.Content, `style="color:red"`) {
+		t.Errorf("content without strip options = %q", kept.Content)
+	}
+}
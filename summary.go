@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// summaryFieldKeys는 요약이 들어 있는 매핑 필드의 key입니다 (앞에 있는 것을 우선)
+var summaryFieldKeys = []string{"summary", "excerpt", "description"}
+
+// postSummary는 게시물의 요약 필드를 태그를 뺀 텍스트로 반환합니다.
+// 요약 필드가 없거나 비어 있으면 본문(content, HTML)의 첫 문단으로 만들고 두 번째 값을 true로 반환합니다.
+func postSummary(fields []MappingField, content string) (string, bool) {
+	for _, key := range summaryFieldKeys {
+		for _, field := range fields {
+			// JSON 빈 문자열("\"\"")로 오는 빈 요약 필드도 건너뜁니다
+			if !strings.EqualFold(field.Key, key) || isEmptyContent(field.Value) {
+				continue
+			}
+			if summary := summaryText(cleanupContent(field.Value)); summary != "" {
+				return summary, false
+			}
+		}
+	}
+	return firstParagraph(content), true
+}
+
+// firstParagraph는 본문에서 텍스트가 있는 첫 <p>의 텍스트를 반환합니다.
+// <p>가 없거나 HTML을 해석하지 못하면 태그를 뺀 본문의 첫 줄을 사용합니다.
+func firstParagraph(content string) string {
+	nodes, err := parseHTMLFragment(content)
+	if err == nil {
+		var paragraph string
+		for _, n := range nodes {
+			walkHTML(n, func(c *html.Node) bool {
+				if paragraph != "" {
+					return false
+				}
+				if c.Type == html.ElementNode && c.Data == "p" {
+					paragraph = collapseSpaces(textContent(c))
+					return false
+				}
+				return true
+			})
+		}
+		if paragraph != "" {
+			return paragraph
+		}
+	}
+
+	for _, line := range strings.Split(html.UnescapeString(stripHTMLTags(content)), "\n") {
+		if line = collapseSpaces(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// summaryText는 요약 필드 값의 태그를 빼고 공백을 정리합니다
+func summaryText(value string) string {
+	return collapseSpaces(html.UnescapeString(stripHTMLTags(value)))
+}
+
+// collapseSpaces는 연속된 공백을 하나로 줄이고 앞뒤 공백을 지웁니다
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestPostSummary(t *testing.T) {
+	tests := []struct {
+		name        string
+		fields      []MappingField
+		content     string
+		want        string
+		wantDerived bool
+	}{
+		{"summary field", []MappingField{{Key: "summary", Value: "<p>요약 &amp; 정리</p>"}}, "<p>본문</p>", "요약 & 정리", false},
+		{"excerpt field", []MappingField{{Key: "Excerpt", Value: "발췌"}}, "<p>본문</p>", "발췌", false},
+		{"summary preferred over description", []MappingField{{Key: "description", Value: "설명"}, {Key: "summary", Value: "요약"}}, "", "요약", false},
+		{"empty summary falls back", []MappingField{{Key: "summary", Value: `""`}}, "<h1>제목</h1><p>  첫   문단 </p><p>둘째</p>", "첫 문단", true},
+		{"no paragraph uses first line", nil, "<div>첫 줄</div>\n<div>둘째 줄</div>", "첫 줄", true},
+		{"skips empty paragraphs", nil, "<p> </p><p>내용</p>", "내용", true},
+		{"empty content", nil, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, derived := postSummary(tt.fields, tt.content)
+			if got != tt.want || derived != tt.wantDerived {
+				t.Errorf("postSummary = %q, %v; want %q, %v", got, derived, tt.want, tt.wantDerived)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseIncludeSummary(t *testing.T) {
+	post := newTestPost("제목", "<p>첫 문단</p><p>둘째 문단</p>")
+	response := renderTestPost(t, ContentOptions{Format: "text", IncludeSummary: true, AutoPreview: 2}, post)
+	if response.Summary != "첫 문단" || !response.SummaryDerived {
+		t.Errorf("summary = %q, derived = %v; want the first paragraph before the preview cut", response.Summary, response.SummaryDerived)
+	}
+
+	response = renderTestPost(t, ContentOptions{Format: "html"}, post)
+	if response.Summary != "" {
+		t.Errorf("summary = %q without include_summary", response.Summary)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// rowspan/colspan 값의 상한 (잘못된 큰 값으로 표가 지나치게 커지지 않도록)
+const (
+	maxTableColspan = 100
+	maxTableRowspan = 1000
+)
+
+// extractTables는 본문의 <table>을 문서 순서대로 행과 셀 텍스트의 배열로 만듭니다.
+// 두 번째 값은 각 표의 머리글 행(<thead>의 행, 또는 본문 행보다 앞에 나온 <th>로만 된 행)이고,
+// 첫 번째 값에는 나머지 행이 같은 순서로 들어갑니다. 행이 없는 표는 건너뜁니다.
+// rowspan/colspan으로 합친 셀은 차지하는 모든 칸에 같은 텍스트를 넣어 각 행의 열이 맞게 합니다.
+func extractTables(content string) ([][][]string, [][][]string, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tables, headers := [][][]string{}, [][][]string{}
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			if c.Type != html.ElementNode || c.DataAtom != atom.Table {
+				return true
+			}
+			// 셀 안의 표도 따로 찾을 수 있도록 하위 노드는 계속 방문합니다
+			if rows, header := tableGrid(c); len(rows)+len(header) > 0 {
+				tables = append(tables, rows)
+				headers = append(headers, header)
+			}
+			return true
+		})
+	}
+	return tables, headers, nil
+}
+
+// tableGrid는 표 하나를 머리글 행과 나머지 행으로 나눈 셀 텍스트 배열로 만듭니다
+func tableGrid(table *html.Node) (rows, header [][]string) {
+	rows, header = [][]string{}, [][]string{}
+	// spans[i]는 위 행의 rowspan이 i번째 열을 아직 몇 행 더 차지하는지와 그 텍스트입니다
+	type span struct {
+		remaining int
+		text      string
+	}
+	var spans []span
+
+	for _, tr := range tableRows(table) {
+		var cells []string
+		allTH := true
+		col := 0
+		// 위 행의 rowspan이 차지한 열은 건너뛰면서 채웁니다
+		fillSpans := func() {
+			for col < len(spans) && spans[col].remaining > 0 {
+				cells = append(cells, spans[col].text)
+				spans[col].remaining--
+				col++
+			}
+		}
+
+		for cell := tr.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.Type != html.ElementNode || (cell.DataAtom != atom.Td && cell.DataAtom != atom.Th) {
+				continue
+			}
+			if cell.DataAtom == atom.Td {
+				allTH = false
+			}
+			fillSpans()
+			text := cellText(cell)
+			colspan := spanAttr(cell, "colspan", maxTableColspan)
+			rowspan := spanAttr(cell, "rowspan", maxTableRowspan)
+			for i := 0; i < colspan; i++ {
+				cells = append(cells, text)
+				for len(spans) <= col {
+					spans = append(spans, span{})
+				}
+				spans[col] = span{remaining: rowspan - 1, text: text}
+				col++
+			}
+		}
+		// 행 끝에 남은 rowspan 열도 채웁니다
+		for ; col < len(spans); col++ {
+			if spans[col].remaining > 0 {
+				for len(cells) < col {
+					cells = append(cells, "")
+				}
+				cells = append(cells, spans[col].text)
+				spans[col].remaining--
+			}
+		}
+		if len(cells) == 0 {
+			continue
+		}
+
+		inHead := tr.Parent != nil && tr.Parent.DataAtom == atom.Thead
+		if inHead || (allTH && len(rows) == 0) {
+			header = append(header, cells)
+		} else {
+			rows = append(rows, cells)
+		}
+	}
+	return rows, header
+}
+
+// tableRows는 표에 직접 속한 <tr>을 <thead>, <tbody>, <tfoot> 순서와 관계없이 문서 순서대로 반환합니다 (안쪽 표의 행은 제외)
+func tableRows(table *html.Node) []*html.Node {
+	var rows []*html.Node
+	for c := table.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.DataAtom {
+		case atom.Tr:
+			rows = append(rows, c)
+		case atom.Thead, atom.Tbody, atom.Tfoot:
+			for tr := c.FirstChild; tr != nil; tr = tr.NextSibling {
+				if tr.Type == html.ElementNode && tr.DataAtom == atom.Tr {
+					rows = append(rows, tr)
+				}
+			}
+		}
+	}
+	return rows
+}
+
+// cellText는 셀의 텍스트를 공백을 정리해 반환합니다 (셀 안의 표는 따로 추출하므로 제외)
+func cellText(cell *html.Node) string {
+	var b strings.Builder
+	walkHTML(cell, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+		return c.Type != html.ElementNode || c.DataAtom != atom.Table
+	})
+	return collapseSpaces(b.String())
+}
+
+// spanAttr은 셀의 colspan/rowspan 값을 1에서 limit 사이로 읽습니다 (없거나 잘못된 값은 1)
+func spanAttr(cell *html.Node, key string, limit int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(attrValue(cell, key)))
+	if err != nil || n < 1 {
+		return 1
+	}
+	if n > limit {
+		return limit
+	}
+	return n
+}
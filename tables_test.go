@@ -0,0 +1,113 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractTables(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantTables  [][][]string
+		wantHeaders [][][]string
+	}{
+		{
+			name:        "no tables",
+			content:     "<p>본문</p>",
+			wantTables:  [][][]string{},
+			wantHeaders: [][][]string{},
+		},
+		{
+			name:        "thead and tbody",
+			content:     "<table><thead><tr><th>이름</th><th>값</th></tr></thead><tbody><tr><td>a</td><td> 1 </td></tr></tbody></table>",
+			wantTables:  [][][]string{{{"a", "1"}}},
+			wantHeaders: [][][]string{{{"이름", "값"}}},
+		},
+		{
+			name:        "leading th row is header",
+			content:     "<table><tr><th>h</th></tr><tr><td>a</td></tr><tr><th>not header</th></tr></table>",
+			wantTables:  [][][]string{{{"a"}, {"not header"}}},
+			wantHeaders: [][][]string{{{"h"}}},
+		},
+		{
+			name:        "no header",
+			content:     "<table><tr><td>a</td><td>b</td></tr></table>",
+			wantTables:  [][][]string{{{"a", "b"}}},
+			wantHeaders: [][][]string{{}},
+		},
+		{
+			name:        "colspan",
+			content:     `<table><tr><td colspan="2">wide</td><td>c</td></tr><tr><td>a</td><td>b</td><td>c</td></tr></table>`,
+			wantTables:  [][][]string{{{"wide", "wide", "c"}, {"a", "b", "c"}}},
+			wantHeaders: [][][]string{{}},
+		},
+		{
+			name:        "rowspan",
+			content:     `<table><tr><td rowspan="2">tall</td><td>b1</td></tr><tr><td>b2</td></tr></table>`,
+			wantTables:  [][][]string{{{"tall", "b1"}, {"tall", "b2"}}},
+			wantHeaders: [][][]string{{}},
+		},
+		{
+			name:        "rowspan in last column",
+			content:     `<table><tr><td>a1</td><td rowspan="2">tall</td></tr><tr><td>a2</td></tr></table>`,
+			wantTables:  [][][]string{{{"a1", "tall"}, {"a2", "tall"}}},
+			wantHeaders: [][][]string{{}},
+		},
+		{
+			name:        "invalid span values",
+			content:     `<table><tr><td colspan="0">a</td><td rowspan="x">b</td></tr></table>`,
+			wantTables:  [][][]string{{{"a", "b"}}},
+			wantHeaders: [][][]string{{}},
+		},
+		{
+			name:        "nested table",
+			content:     "<table><tr><td>outer<table><tr><td>inner</td></tr></table></td></tr></table>",
+			wantTables:  [][][]string{{{"outer"}}, {{"inner"}}},
+			wantHeaders: [][][]string{{}, {}},
+		},
+		{
+			name:        "empty table skipped",
+			content:     "<table></table><table><tr><td>a</td></tr></table>",
+			wantTables:  [][][]string{{{"a"}}},
+			wantHeaders: [][][]string{{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, headers, err := extractTables(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tables, tt.wantTables) {
+				t.Errorf("tables = %q, want %q", tables, tt.wantTables)
+			}
+			if !reflect.DeepEqual(headers, tt.wantHeaders) {
+				t.Errorf("headers = %q, want %q", headers, tt.wantHeaders)
+			}
+		})
+	}
+}
+
+func TestSpanAttrLimit(t *testing.T) {
+	tables, _, err := extractTables(`<table><tr><td colspan="100000">a</td></tr></table>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables[0][0]) != maxTableColspan {
+		t.Errorf("cells = %d, want %d", len(tables[0][0]), maxTableColspan)
+	}
+}
+
+func TestRenderContentResponseExtractTables(t *testing.T) {
+	post := newTestPost("제목", "<table><tr><th>h</th></tr><tr><td>a</td></tr></table>")
+	response := renderTestPost(t, ContentOptions{Format: "text", ExtractTables: true}, post)
+	if !reflect.DeepEqual(response.Tables, [][][]string{{{"a"}}}) || !reflect.DeepEqual(response.TableHeaders, [][][]string{{{"h"}}}) {
+		t.Errorf("tables = %q, headers = %q", response.Tables, response.TableHeaders)
+	}
+
+	opts := ContentOptions{Format: "html", MetadataOnly: true, ExtractTables: true}
+	if err := opts.normalize(); err == nil {
+		t.Error("metadata_only with extract_tables should be rejected")
+	}
+}
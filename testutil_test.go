@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 테스트에서 공유하는 도우미입니다. 전역 상태(설정, 토큰 관리자, 캐시, HTTP 전송)를 바꾸는
+// 도우미는 t.Cleanup으로 원래 값을 되돌리므로, 이 도우미를 쓰는 테스트는 t.Parallel()을 쓰지 않습니다.
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	setConfig(loadConfig())
+	os.Exit(m.Run())
+}
+
+// withConfig는 현재 설정의 복사본을 change로 바꿔 테스트가 끝날 때까지 적용합니다
+func withConfig(t *testing.T, change func(cfg *Config)) {
+	t.Helper()
+	prev := currentConfig()
+	next := *prev
+	change(&next)
+	setConfig(&next)
+	t.Cleanup(func() { setConfig(prev) })
+}
+
+// withTestToken은 BetterMode를 호출하지 않고 유효한 토큰을 돌려주는 토큰 관리자를 설정합니다
+func withTestToken(t *testing.T) {
+	t.Helper()
+	prev := tokenManager
+	tokenManager = &TokenManager{
+		accessToken: "test-token",
+		expiry:      time.Now().Add(time.Hour),
+		sources:     []*tokenSource{{networkDomain: "test.bettermode.io"}},
+	}
+	t.Cleanup(func() { tokenManager = prev })
+}
+
+// withContentCache는 테스트마다 빈 콘텐츠 캐시를 씁니다
+func withContentCache(t *testing.T) {
+	t.Helper()
+	prev := contentCache
+	contentCache = NewContentCache(time.Minute, 0, 100)
+	t.Cleanup(func() { contentCache = prev })
+}
+
+// waitForContentFetch는 요청이 취소된 뒤에도 계속 진행되는 req의 업스트림 호출(singleflight)이 끝날 때까지 기다립니다.
+// 같은 키로 합류하면 진행 중인 호출이 캐시에 저장까지 마친 뒤에 결과를 받습니다.
+func waitForContentFetch(req ContentRequest) {
+	<-contentFetchGroup.DoChan(contentCacheKey(req), func() (interface{}, error) { return nil, nil })
+}
+
+// waitForPostFetch는 fetchPost가 req의 게시물을 가져오는 공유 호출이 끝날 때까지 기다립니다 (waitForContentFetch 참고)
+func waitForPostFetch(req ContentRequest) {
+	<-contentFetchGroup.DoChan(postFetchKey(req), func() (interface{}, error) { return nil, nil })
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// graphQLTestRequest는 가짜 업스트림이 받은 GraphQL 요청입니다
+type graphQLTestRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// fakeUpstream은 upstreamClient 대신 BetterMode처럼 응답하고 받은 요청 수를 셉니다
+type fakeUpstream struct {
+	calls int64
+}
+
+func (f *fakeUpstream) count() int {
+	return int(atomic.LoadInt64(&f.calls))
+}
+
+// withFakeUpstream은 BetterMode로 가는 요청(기본 HTTP 전송)을 handler로 보냅니다. 토큰 발급 요청도 handler가 받습니다.
+func withFakeUpstream(t *testing.T, handler http.HandlerFunc) *fakeUpstream {
+	t.Helper()
+	fake := &fakeUpstream{}
+	prev := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		// BetterMode가 아닌 곳(후처리 웹훅 등)으로 가는 요청은 그대로 보냅니다
+		if !strings.HasPrefix(r.URL.String(), betterModeAPIURL) {
+			return prev.RoundTrip(r)
+		}
+		atomic.AddInt64(&fake.calls, 1)
+		rec := httptest.NewRecorder()
+		handler(rec, r)
+		if err := r.Context().Err(); err != nil {
+			return nil, err
+		}
+		resp := rec.Result()
+		resp.Request = r
+		return resp, nil
+	})
+	t.Cleanup(func() { http.DefaultTransport = prev })
+	return fake
+}
+
+// readGraphQLRequest는 가짜 업스트림에서 요청 본문을 읽습니다
+func readGraphQLRequest(t *testing.T, r *http.Request) graphQLTestRequest {
+	t.Helper()
+	var req graphQLTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		t.Errorf("decoding upstream request: %v", err)
+	}
+	return req
+}
+
+// writeJSONResponse는 가짜 업스트림 응답을 JSON으로 씁니다
+func writeJSONResponse(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// postData는 게시물 조회 응답의 data 값입니다
+func postData(post map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"data": map[string]interface{}{"post": post}}
+}
+
+// testPostJSON은 html 본문을 가진 게시물 응답 값입니다
+func testPostJSON(title, content string) map[string]interface{} {
+	return map[string]interface{}{
+		"title":     title,
+		"updatedAt": "2024-05-01T10:00:00Z",
+		"mappingFields": []map[string]string{
+			{"key": "content", "type": "html", "value": content},
+		},
+	}
+}
+
+// newTestPost는 html 본문을 가진 게시물입니다
+func newTestPost(title, content string) *Post {
+	return &Post{
+		Title:         title,
+		UpdatedAt:     "2024-05-01T10:00:00Z",
+		MappingFields: []MappingField{{Key: "content", Type: "html", Value: content}},
+	}
+}
+
+// renderTestPost는 opts를 검증한 뒤 post로 응답을 만듭니다
+func renderTestPost(t *testing.T, opts ContentOptions, post *Post) ContentResponse {
+	t.Helper()
+	if err := opts.normalize(); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	response, err := renderContentResponse(context.Background(), ContentRequest{PostID: "post-1", ContentOptions: opts}, post)
+	if err != nil {
+		t.Fatalf("renderContentResponse: %v", err)
+	}
+	return response
+}
+
+// hasWarning은 warnings에 code 경고가 있는지 확인합니다
+func hasWarning(warnings []Warning, code string) bool {
+	for _, w := range warnings {
+		if w.Code == code {
+			return true
+		}
+	}
+	return false
+}
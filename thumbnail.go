@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// width/height 속성을 알 수 있는 이미지 중 어느 한 변이 이보다 작으면 아이콘이나 이모지로 보고 썸네일로 쓰지 않습니다
+const thumbnailMinSize = 64
+
+// firstThumbnail은 본문에서 썸네일로 쓸 첫 번째 이미지의 URL을 반환합니다 (없으면 "").
+// src가 없으면 srcset의 첫 후보를 쓰고, width/height 속성이 thumbnailMinSize보다 작은 이미지는 건너뜁니다.
+// 크기를 알 수 없는 이미지는 썸네일로 씁니다.
+func firstThumbnail(content string) (string, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", err
+	}
+	var thumbnail string
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			if thumbnail != "" {
+				return false
+			}
+			if c.Type != html.ElementNode || c.Data != "img" || isTinyImage(c) {
+				return true
+			}
+			thumbnail = imageURL(c)
+			return thumbnail == ""
+		})
+		if thumbnail != "" {
+			break
+		}
+	}
+	return thumbnail, nil
+}
+
+// imageURL은 <img>의 src를, 없으면 srcset의 첫 후보 URL을 반환합니다
+func imageURL(n *html.Node) string {
+	if src := strings.TrimSpace(attrValue(n, "src")); src != "" {
+		return src
+	}
+	for _, candidate := range strings.Split(attrValue(n, "srcset"), ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+// isTinyImage는 width나 height 속성("32", "32px")이 thumbnailMinSize보다 작은지 확인합니다.
+// 속성이 없거나 "50%"처럼 픽셀 값이 아니면 크기를 모르는 것으로 보고 false를 반환합니다.
+func isTinyImage(n *html.Node) bool {
+	for _, key := range []string{"width", "height"} {
+		value := strings.TrimSuffix(strings.TrimSpace(attrValue(n, key)), "px")
+		if size, err := strconv.Atoi(value); err == nil && size < thumbnailMinSize {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+func TestFirstThumbnail(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"no image", `<p>본문</p>`, ""},
+		{"first image", `<p><img src="https://cdn.example.com/a.png"><img src="https://cdn.example.com/b.png"></p>`, "https://cdn.example.com/a.png"},
+		{"skips icon", `<img src="https://cdn.example.com/icon.png" width="16"><img src="https://cdn.example.com/a.png">`, "https://cdn.example.com/a.png"},
+		{"skips px height", `<img src="https://cdn.example.com/emoji.png" height="32px"><img src="https://cdn.example.com/a.png" width="640">`, "https://cdn.example.com/a.png"},
+		{"unknown size", `<img src="https://cdn.example.com/a.png" width="50%">`, "https://cdn.example.com/a.png"},
+		{"srcset fallback", `<img srcset=" https://cdn.example.com/a-1x.png 1x, https://cdn.example.com/a-2x.png 2x">`, "https://cdn.example.com/a-1x.png"},
+		{"skips image without url", `<img alt="빈 이미지"><img src="https://cdn.example.com/a.png">`, "https://cdn.example.com/a.png"},
+		{"nested", `<figure><div><img src="https://cdn.example.com/a.png"></div></figure><img src="https://cdn.example.com/b.png">`, "https://cdn.example.com/a.png"},
+		{"only icons", `<img src="https://cdn.example.com/icon.png" width="24" height="24">`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := firstThumbnail(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("firstThumbnail() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseThumbnail(t *testing.T) {
+	post := newTestPost("제목", `<p>앞부분</p><p>`+
+		`<img src="https://cdn.example.com/icon.png" width="16"><img src="https://cdn.example.com/photo.png"></p>`)
+	tests := []struct {
+		name string
+		opts ContentOptions
+		want string
+	}{
+		{"disabled", ContentOptions{}, ""},
+		{"enabled", ContentOptions{Thumbnail: true}, "https://cdn.example.com/photo.png"},
+		{"after preview cut", ContentOptions{Thumbnail: true, AutoPreview: 3}, "https://cdn.example.com/photo.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderTestPost(t, tt.opts, post).Thumbnail; got != tt.want {
+				t.Errorf("Thumbnail = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import "strings"
+
+// postTitle은 titleKey 매핑 필드의 값을 태그를 뺀 텍스트로 반환합니다.
+// titleKey가 비어 있거나, 그런 필드가 없거나(FIELD_DENY_LIST/FIELD_ALLOW_LIST로 숨긴 필드 포함) 값이 비어 있으면 게시물의 title을 씁니다.
+func postTitle(post *Post, titleKey string) string {
+	if titleKey == "" {
+		return post.Title
+	}
+	for _, field := range exposedFields(post.MappingFields) {
+		if !strings.EqualFold(field.Key, titleKey) {
+			continue
+		}
+		if title := summaryText(cleanupContent(field.Value)); title != "" {
+			return title
+		}
+	}
+	return post.Title
+}
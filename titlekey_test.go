@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestPostTitle(t *testing.T) {
+	post := newTestPost("원래 제목", "<p>본문</p>")
+	post.MappingFields = append(post.MappingFields,
+		MappingField{Key: "seo_title", Type: "text", Value: "<b>검색용</b> 제목"},
+		MappingField{Key: "empty_title", Type: "text", Value: "  "},
+	)
+	tests := []struct {
+		name     string
+		titleKey string
+		deny     []string
+		want     string
+	}{
+		{"no title_key", "", nil, "원래 제목"},
+		{"mapping field", "seo_title", nil, "검색용 제목"},
+		{"key ignores case", "SEO_Title", nil, "검색용 제목"},
+		{"missing field", "subtitle", nil, "원래 제목"},
+		{"empty field", "empty_title", nil, "원래 제목"},
+		{"denied field", "seo_title", []string{"seo_title"}, "원래 제목"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.FieldDenyList = tt.deny })
+			if got := postTitle(post, tt.titleKey); got != tt.want {
+				t.Errorf("postTitle(%q) = %q, want %q", tt.titleKey, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseTitleKey(t *testing.T) {
+	tests := []struct {
+		name             string
+		postTitle        string
+		titleKey         string
+		maxTitleLength   int
+		wantTitle        string
+		wantTruncated    bool
+		wantMissingTitle bool
+	}{
+		{"post title", "원래 제목", "", 0, "원래 제목", false, false},
+		{"title from field", "원래 제목", "seo_title", 0, "검색용 제목", false, false},
+		{"field fills missing title", "", "seo_title", 0, "검색용 제목", false, false},
+		{"missing title", "", "subtitle", 0, "", false, true},
+		{"field title is truncated", "원래 제목", "seo_title", 3, "검색용…", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.MaxTitleLength = tt.maxTitleLength })
+			post := newTestPost(tt.postTitle, "<p>본문</p>")
+			post.MappingFields = append(post.MappingFields, MappingField{Key: "seo_title", Type: "text", Value: "검색용 제목"})
+
+			response := renderTestPost(t, ContentOptions{Format: "html", TitleKey: tt.titleKey}, post)
+			if response.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", response.Title, tt.wantTitle)
+			}
+			if response.TitleTruncated != tt.wantTruncated {
+				t.Errorf("TitleTruncated = %v, want %v", response.TitleTruncated, tt.wantTruncated)
+			}
+			if got := hasWarning(response.Warnings, WarningMissingTitle); got != tt.wantMissingTitle {
+				t.Errorf("missing_title warning = %v, want %v", got, tt.wantMissingTitle)
+			}
+		})
+	}
+}
+
+func TestTitleKeyRejectsMetadataOnly(t *testing.T) {
+	opts := ContentOptions{Format: "html", MetadataOnly: true, TitleKey: "seo_title"}
+	if err := opts.normalize(); err == nil {
+		t.Error("normalize() should reject title_key with metadata_only")
+	}
+}
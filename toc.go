@@ -0,0 +1,144 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// TOCEntry는 본문 목차의 한 항목입니다
+type TOCEntry struct {
+	Level  int    `json:"level"`  // 1-6 (h1-h6)
+	Text   string `json:"text"`   // 헤딩 텍스트
+	Anchor string `json:"anchor"` // 헤딩의 id ("#" 없이)
+}
+
+// addHeadingAnchors는 h1-h6 헤딩에 id를 붙이고 문서 순서대로 목차를 반환합니다.
+// id는 헤딩 텍스트로만 만들기 때문에 같은 본문이면 요청이나 프로세스가 달라도 항상 같습니다.
+// 이미 id가 있는 헤딩은 그대로 사용하고, 겹치는 id에는 문서 순서대로 -1, -2를 붙입니다.
+func addHeadingAnchors(content string) (string, []TOCEntry, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// 뒤에 나오는 기존 id와도 겹치지 않도록 먼저 모두 모읍니다
+	used := make(map[string]bool)
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			if c.Type == html.ElementNode {
+				if id := attrValue(c, "id"); id != "" {
+					used[id] = true
+				}
+			}
+			return true
+		})
+	}
+
+	toc := []TOCEntry{}
+	for _, n := range nodes {
+		walkHTML(n, func(c *html.Node) bool {
+			level := headingLevel(c)
+			if level == 0 {
+				return true
+			}
+			text := strings.Join(strings.Fields(textContent(c)), " ")
+			anchor := attrValue(c, "id")
+			if anchor == "" {
+				anchor = uniqueAnchor(slugify(text), used)
+				c.Attr = append(c.Attr, html.Attribute{Key: "id", Val: anchor})
+			}
+			toc = append(toc, TOCEntry{Level: level, Text: text, Anchor: anchor})
+			return false
+		})
+	}
+
+	rendered, err := renderHTMLFragment(nodes)
+	if err != nil {
+		return "", nil, err
+	}
+	return rendered, toc, nil
+}
+
+// headingLevel은 h1-h6 요소이면 단계를, 아니면 0을 반환합니다
+func headingLevel(n *html.Node) int {
+	if n.Type != html.ElementNode || len(n.Data) != 2 || n.Data[0] != 'h' {
+		return 0
+	}
+	if level := int(n.Data[1] - '0'); level >= 1 && level <= 6 {
+		return level
+	}
+	return 0
+}
+
+// uniqueAnchor는 used에 없는 anchor를 고르고 사용한 것으로 기록합니다
+func uniqueAnchor(anchor string, used map[string]bool) string {
+	candidate := anchor
+	for i := 1; used[candidate]; i++ {
+		candidate = anchor + "-" + strconv.Itoa(i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// slugify는 헤딩 텍스트를 URL 조각으로 쓸 수 있는 id로 바꿉니다.
+// 한글 등 유니코드 문자와 숫자는 그대로 두고(소문자화), 공백과 -, _는 하나의 -로, 나머지 기호는 제거합니다.
+// 자모가 분리된(NFD) 한글은 먼저 완성형으로 합쳐 입력 방식과 관계없이 같은 id가 나오게 합니다.
+func slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range composeHangul(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
+
+// 한글 자모 조합(유니코드 표준 3.12절) 상수
+const (
+	hangulSBase  = 0xAC00
+	hangulLBase  = 0x1100
+	hangulVBase  = 0x1161
+	hangulTBase  = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+// composeHangul은 초성+중성(+종성) 조합형 자모를 완성형 음절로 합칩니다. 그 외 문자는 그대로 둡니다.
+func composeHangul(s string) []rune {
+	in := []rune(s)
+	out := make([]rune, 0, len(in))
+	for _, r := range in {
+		if n := len(out); n > 0 {
+			last := out[n-1]
+			// 초성 + 중성 → LV 음절
+			if l, v := last-hangulLBase, r-hangulVBase; 0 <= l && l < hangulLCount && 0 <= v && v < hangulVCount {
+				out[n-1] = hangulSBase + (l*hangulVCount+v)*hangulTCount
+				continue
+			}
+			// LV 음절 + 종성 → LVT 음절
+			if sIndex, t := last-hangulSBase, r-hangulTBase; 0 <= sIndex && sIndex < hangulSCount && sIndex%hangulTCount == 0 && 0 < t && t < hangulTCount {
+				out[n-1] = last + t
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
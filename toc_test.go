@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"시작하기", "시작하기"},
+		{"1. 설치 방법", "1-설치-방법"},
+		{"Getting Started!", "getting-started"},
+		{"  API   키_발급 -- 안내  ", "api-키-발급-안내"},
+		{"?!", "section"},
+		// 자모가 분리된(NFD) 입력도 완성형과 같은 anchor가 됩니다
+		{"\u1112\u1161\u11ab\u1100\u1173\u11af", "한글"},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.in); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAddHeadingAnchors(t *testing.T) {
+	const content = `<h1>소개</h1><p>본문</p><h2>설치 <em>방법</em></h2><h2>소개</h2><h3 id="custom">직접 지정</h3><h2>Custom</h2>`
+	wantContent := `<h1 id="소개">소개</h1><p>본문</p><h2 id="설치-방법">설치 <em>방법</em></h2><h2 id="소개-1">소개</h2><h3 id="custom">직접 지정</h3><h2 id="custom-1">Custom</h2>`
+	wantTOC := []TOCEntry{
+		{Level: 1, Text: "소개", Anchor: "소개"},
+		{Level: 2, Text: "설치 방법", Anchor: "설치-방법"},
+		{Level: 2, Text: "소개", Anchor: "소개-1"},
+		{Level: 3, Text: "직접 지정", Anchor: "custom"},
+		{Level: 2, Text: "Custom", Anchor: "custom-1"},
+	}
+
+	// 같은 본문은 몇 번을 처리해도 같은 anchor를 얻습니다
+	for i := 0; i < 3; i++ {
+		got, toc, err := addHeadingAnchors(content)
+		if err != nil {
+			t.Fatalf("addHeadingAnchors: %v", err)
+		}
+		if got != wantContent {
+			t.Errorf("content =\n%s\nwant\n%s", got, wantContent)
+		}
+		if !reflect.DeepEqual(toc, wantTOC) {
+			t.Errorf("toc = %+v, want %+v", toc, wantTOC)
+		}
+	}
+}
+
+func TestRenderContentResponseTOC(t *testing.T) {
+	post := newTestPost("제목", `<h2>첫 번째 단계</h2><p>본문</p>`)
+	response := renderTestPost(t, ContentOptions{Format: "html", IncludeTOC: true}, post)
+	if response.Content != `<h2 id="첫-번째-단계">첫 번째 단계</h2><p>본문</p>` {
+		t.Errorf("content = %q", response.Content)
+	}
+	if want := []TOCEntry{{Level: 2, Text: "첫 번째 단계", Anchor: "첫-번째-단계"}}; !reflect.DeepEqual(response.TOC, want) {
+		t.Errorf("toc = %+v, want %+v", response.TOC, want)
+	}
+}
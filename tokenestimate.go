@@ -0,0 +1,22 @@
+package main
+
+import "unicode"
+
+// 한글/한자/가나가 아닌 문자는 이 글자 수마다 토큰 하나로 셉니다 (영어 BPE 토크나이저의 평균)
+const charsPerToken = 4
+
+// estimateTokens는 LLM 토크나이저 없이 s의 토큰 수를 어림합니다.
+// 한글, 한자, 가나는 BPE 토크나이저에서 대체로 글자 하나가 토큰 하나 이상이므로 글자마다 1토큰으로,
+// 나머지(영문, 숫자, 기호, 공백)는 4글자마다 1토큰으로 세고 올림합니다.
+// 실제 토큰 수는 모델의 토크나이저에 따라 다르므로 컨텍스트 예산을 잡는 어림값으로만 씁니다.
+func estimateTokens(s string) int64 {
+	var cjk, other int64
+	for _, r := range s {
+		if unicode.In(r, unicode.Hangul, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+charsPerToken-1)/charsPerToken
+}
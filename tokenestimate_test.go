@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int64
+	}{
+		{"", 0},
+		{"a", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"안녕하세요", 5},
+		{"漢字かなカナ", 6},
+		{"안녕 hi", 3},      // 한글 2 + " hi" 3글자 → 1
+		{"Hello, 세계!", 4}, // 한글 2 + "Hello, " 7글자와 "!" → 8글자 2
+	}
+	for _, tt := range tests {
+		if got := estimateTokens(tt.s); got != tt.want {
+			t.Errorf("estimateTokens(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTokenCountInResponses(t *testing.T) {
+	response := renderTestPost(t, ContentOptions{Format: "text"}, newTestPost("제목", "<p>본문 text</p>"))
+	if want := estimateTokens(response.Content); response.TokenCount != want || want == 0 {
+		t.Errorf("content token_count = %d, want %d", response.TokenCount, want)
+	}
+
+	compiled := compileDocument([]BatchItemResult{
+		{PostID: "a", Result: &ContentResponse{Content: "하나"}},
+		{PostID: "b", Result: &ContentResponse{Content: "둘"}},
+	}, "text", "\n\n", false)
+	if want := estimateTokens(compiled.Content); compiled.TokenCount != want {
+		t.Errorf("compile token_count = %d, want %d", compiled.TokenCount, want)
+	}
+}
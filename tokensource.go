@@ -0,0 +1,116 @@
+package main
+
+import "time"
+
+// tokenSource는 게스트 토큰을 발급받는 네트워크 도메인 하나와 그 상태입니다
+type tokenSource struct {
+	networkDomain string
+	failures      int // 연속 실패 횟수 (성공하면 0)
+	lastFailure   time.Time
+	lastError     string
+	lastRefresh   time.Time // 마지막으로 토큰을 발급받은 시각
+}
+
+// TokenSourceStatus는 /token/status에 표시하는 토큰 소스 상태입니다
+type TokenSourceStatus struct {
+	NetworkDomain       string     `json:"network_domain"`
+	Active              bool       `json:"active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastFailure         *time.Time `json:"last_failure,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastRefresh         *time.Time `json:"last_refresh,omitempty"`
+}
+
+// TokenDomainStatus는 /tokens/status에 표시하는 도메인별 토큰 상태입니다.
+// 토큰은 한 번에 하나만 보관하므로 유효 여부와 만료 시각은 현재 토큰을 발급한 활성 도메인에만 표시됩니다.
+type TokenDomainStatus struct {
+	TokenSourceStatus
+	IsValid   bool       `json:"is_valid"`
+	Expiry    *time.Time `json:"expiry,omitempty"`
+	ExpiresIn string     `json:"expires_in,omitempty"`
+}
+
+func (s *tokenSource) recordFailure(err error) {
+	s.failures++
+	s.lastFailure = time.Now()
+	s.lastError = err.Error()
+}
+
+func (s *tokenSource) recordSuccess() {
+	s.failures = 0
+	s.lastError = ""
+	s.lastRefresh = time.Now()
+}
+
+// sourceOrder는 토큰 갱신 시 시도할 소스 인덱스를 순서대로 반환합니다. tm.mutex를 잡은 상태에서 호출해야 합니다.
+// 기본적으로 활성 소스부터 시도하고, 다른 소스로 넘어가 있는 동안 기본 소스의 마지막 실패가
+// TOKEN_SOURCE_FAILBACK_AFTER보다 오래되었으면 기본 소스를 먼저 시도합니다.
+func (tm *TokenManager) sourceOrder(now time.Time) []int {
+	start := tm.active
+	if tm.active != 0 && now.Sub(tm.sources[0].lastFailure) >= currentConfig().TokenSourceFailbackAfter {
+		start = 0
+	}
+
+	order := make([]int, 0, len(tm.sources))
+	order = append(order, start)
+	if start != tm.active {
+		order = append(order, tm.active)
+	}
+	for i := 1; i < len(tm.sources); i++ {
+		next := (tm.active + i) % len(tm.sources)
+		if next != start {
+			order = append(order, next)
+		}
+	}
+	return order
+}
+
+// sourceStatuses는 모든 토큰 소스의 상태를 반환합니다. tm.mutex를 잡은 상태에서 호출해야 합니다.
+func (tm *TokenManager) sourceStatuses() []TokenSourceStatus {
+	statuses := make([]TokenSourceStatus, len(tm.sources))
+	for i, s := range tm.sources {
+		statuses[i] = TokenSourceStatus{
+			NetworkDomain:       s.networkDomain,
+			Active:              i == tm.active,
+			ConsecutiveFailures: s.failures,
+			LastError:           s.lastError,
+		}
+		if !s.lastFailure.IsZero() {
+			lastFailure := s.lastFailure
+			statuses[i].LastFailure = &lastFailure
+		}
+		if !s.lastRefresh.IsZero() {
+			lastRefresh := s.lastRefresh
+			statuses[i].LastRefresh = &lastRefresh
+		}
+	}
+	return statuses
+}
+
+// domainStatuses는 모든 토큰 도메인의 상태를 TOKEN_NETWORK_DOMAINS 순서대로 반환합니다. tm.mutex를 잡은 상태에서 호출해야 합니다.
+func (tm *TokenManager) domainStatuses(now time.Time) []TokenDomainStatus {
+	sources := tm.sourceStatuses()
+	statuses := make([]TokenDomainStatus, len(sources))
+	for i, source := range sources {
+		statuses[i] = TokenDomainStatus{TokenSourceStatus: source}
+		if i != tm.active || tm.accessToken == "" {
+			continue
+		}
+		expiry := tm.expiry
+		statuses[i].IsValid = now.Before(expiry)
+		statuses[i].Expiry = &expiry
+		statuses[i].ExpiresIn = expiry.Sub(now).String()
+	}
+	return statuses
+}
+
+// missingScopes는 requested 중 granted에 없는 권한 범위를 반환합니다
+func missingScopes(requested, granted []string) []string {
+	var missing []string
+	for _, scope := range requested {
+		if !containsString(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSourceOrder(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name            string
+		active          int
+		primaryFailedAt time.Time
+		want            []int
+	}{
+		{"primary active", 0, time.Time{}, []int{0, 1, 2}},
+		{"secondary active, primary failed recently", 1, now.Add(-time.Minute), []int{1, 2, 0}},
+		{"secondary active, failback due", 1, now.Add(-time.Hour), []int{0, 1, 2}},
+		{"last source active, failback due", 2, now.Add(-time.Hour), []int{0, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.TokenSourceFailbackAfter = 10 * time.Minute })
+			tm := &TokenManager{active: tt.active}
+			for _, domain := range []string{"a", "b", "c"} {
+				tm.sources = append(tm.sources, &tokenSource{networkDomain: domain})
+			}
+			tm.sources[0].lastFailure = tt.primaryFailedAt
+			if got := tm.sourceOrder(now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sourceOrder = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// tokenDomainUpstream은 down에 있는 도메인의 토큰 요청을 실패시키고, 나머지는 "token-<도메인>"을 발급합니다
+func tokenDomainUpstream(t *testing.T, down map[string]bool) {
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		domain, _ := readGraphQLRequest(t, r).Variables["networkDomain"].(string)
+		if down[domain] {
+			writeJSONResponse(w, http.StatusOK, map[string]interface{}{"errors": []map[string]string{{"message": "unavailable"}}})
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{"tokens": map[string]string{"accessToken": "token-" + domain}},
+		})
+	})
+}
+
+func TestRefreshTokenFailover(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  int
+		down       map[string]bool
+		refreshes  int
+		wantErr    bool
+		wantActive string
+		wantToken  string
+	}{
+		{"primary healthy", 2, nil, 1, false, "primary", "token-primary"},
+		{"below threshold stays on primary", 2, map[string]bool{"primary": true}, 1, true, "primary", ""},
+		{"fails over at threshold", 2, map[string]bool{"primary": true}, 2, false, "backup", "token-backup"},
+		{"all sources down", 1, map[string]bool{"primary": true, "backup": true}, 1, true, "primary", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) {
+				cfg.TokenSourceFailureThreshold = tt.threshold
+				cfg.TokenSourceFailbackAfter = time.Hour
+			})
+			tokenDomainUpstream(t, tt.down)
+			tm := &TokenManager{sources: []*tokenSource{{networkDomain: "primary"}, {networkDomain: "backup"}}}
+
+			var err error
+			for i := 0; i < tt.refreshes; i++ {
+				err = tm.RefreshToken()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RefreshToken error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := tm.sources[tm.active].networkDomain; got != tt.wantActive {
+				t.Errorf("active source = %s, want %s", got, tt.wantActive)
+			}
+			if tm.accessToken != tt.wantToken {
+				t.Errorf("token = %q, want %q", tm.accessToken, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenFailsBackToPrimary(t *testing.T) {
+	withConfig(t, func(cfg *Config) {
+		cfg.TokenSourceFailureThreshold = 1
+		cfg.TokenSourceFailbackAfter = time.Minute
+	})
+	tokenDomainUpstream(t, nil)
+	tm := &TokenManager{sources: []*tokenSource{{networkDomain: "primary"}, {networkDomain: "backup"}}, active: 1}
+	// 기본 소스의 마지막 실패가 TOKEN_SOURCE_FAILBACK_AFTER보다 오래되었습니다
+	tm.sources[0].lastFailure = time.Now().Add(-time.Hour)
+
+	if err := tm.RefreshToken(); err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if tm.active != 0 || tm.accessToken != "token-primary" {
+		t.Errorf("active = %d, token = %q; want primary", tm.active, tm.accessToken)
+	}
+}
+
+func TestMissingScopes(t *testing.T) {
+	tests := []struct {
+		requested, granted []string
+		want               []string
+	}{
+		{nil, nil, nil},
+		{[]string{"read"}, []string{"read", "write"}, nil},
+		{[]string{"read", "write"}, []string{"read"}, []string{"write"}},
+		{[]string{"read"}, []string{}, []string{"read"}},
+	}
+	for _, tt := range tests {
+		if got := missingScopes(tt.requested, tt.granted); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("missingScopes(%v, %v) = %v, want %v", tt.requested, tt.granted, got, tt.want)
+		}
+	}
+}
+
+func TestFetchGuestTokenScopes(t *testing.T) {
+	tests := []struct {
+		name        string
+		scopes      []string
+		granted     []string
+		wantScoped  bool
+		wantGranted []string
+	}{
+		{"default query", nil, nil, false, nil},
+		{"scoped query", []string{"read:posts"}, []string{"read:posts"}, true, []string{"read:posts"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				req := readGraphQLRequest(t, r)
+				_, hasScopes := req.Variables["scopes"]
+				if scoped := strings.Contains(req.Query, "scopes"); scoped != tt.wantScoped || hasScopes != tt.wantScoped {
+					t.Errorf("query scoped = %v, scopes variable = %v; want %v:\n%s", scoped, hasScopes, tt.wantScoped, req.Query)
+				}
+				tokens := map[string]interface{}{"accessToken": "token-1"}
+				if tt.granted != nil {
+					tokens["scopes"] = tt.granted
+				}
+				writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"tokens": tokens}})
+			})
+
+			token, granted, err := fetchGuestToken("test.bettermode.io", tt.scopes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != "token-1" || !reflect.DeepEqual(granted, tt.wantGranted) {
+				t.Errorf("fetchGuestToken = %q, %v; want token-1, %v", token, granted, tt.wantGranted)
+			}
+		})
+	}
+}
+
+func TestDomainStatuses(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		token       string
+		expiry      time.Time
+		wantValid   bool
+		wantExpires string
+	}{
+		{"valid", "token", now.Add(time.Hour), true, "1h0m0s"},
+		{"expired", "token", now.Add(-time.Minute), false, "-1m0s"},
+		{"no token", "", time.Time{}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := &TokenManager{
+				accessToken: tt.token,
+				expiry:      tt.expiry,
+				active:      1,
+				sources: []*tokenSource{
+					{networkDomain: "primary.example.com", failures: 2, lastFailure: now, lastError: "boom"},
+					{networkDomain: "backup.example.com", lastRefresh: now},
+				},
+			}
+			statuses := tm.domainStatuses(now)
+			if len(statuses) != 2 || statuses[0].NetworkDomain != "primary.example.com" || statuses[1].NetworkDomain != "backup.example.com" {
+				t.Fatalf("statuses = %+v, want both domains in order", statuses)
+			}
+			if primary := statuses[0]; primary.IsValid || primary.Expiry != nil || primary.ConsecutiveFailures != 2 || primary.LastError != "boom" {
+				t.Errorf("primary = %+v, want failures and no token", primary)
+			}
+			active := statuses[1]
+			if active.IsValid != tt.wantValid || active.ExpiresIn != tt.wantExpires || (active.Expiry != nil) != (tt.token != "") {
+				t.Errorf("active = %+v, want valid %v, expires_in %q", active, tt.wantValid, tt.wantExpires)
+			}
+			if active.LastRefresh == nil || !active.LastRefresh.Equal(now) {
+				t.Errorf("last_refresh = %v, want %v", active.LastRefresh, now)
+			}
+		})
+	}
+}
+
+func TestHandleTokensStatus(t *testing.T) {
+	withTestToken(t)
+	rec := httptest.NewRecorder()
+	handleTokensStatus(rec, httptest.NewRequest(http.MethodGet, "/admin/tokens/status", nil))
+
+	var body struct {
+		ActiveSource string              `json:"active_source"`
+		Domains      []TokenDomainStatus `json:"domains"`
+		Breaker      json.RawMessage     `json:"breaker"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.ActiveSource != "test.bettermode.io" || len(body.Domains) != 1 || !body.Domains[0].IsValid || len(body.Breaker) == 0 {
+		t.Errorf("body = %s", rec.Body.String())
+	}
+}
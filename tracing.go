@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	// requestIDHeader는 우리 요청 ID를 응답에 싣고 BetterMode 요청에 전달하는 헤더입니다
+	requestIDHeader = "X-Request-ID"
+	// upstreamRequestIDHeader는 BetterMode가 알려 준 요청/추적 ID를 응답에 싣는 헤더입니다
+	upstreamRequestIDHeader = "X-Upstream-Request-ID"
+	// 한 요청의 응답 헤더에 싣는 업스트림 ID의 최대 개수 (배치처럼 BetterMode를 여러 번 부르는 경우)
+	maxUpstreamRequestIDs = 10
+)
+
+// upstreamTraceHeaders는 BetterMode(와 앞단 CDN) 응답에서 요청/추적 ID를 찾는 헤더입니다 (앞에 있는 것을 우선)
+var upstreamTraceHeaders = []string{"X-Request-Id", "X-Trace-Id", "X-Amzn-Trace-Id", "Cf-Ray"}
+
+// upstreamTrace는 요청 하나를 처리하는 동안 받은 BetterMode 요청 ID를 모읍니다
+type upstreamTrace struct {
+	mutex sync.Mutex
+	ids   []string
+}
+
+type upstreamTraceKey struct{}
+
+func (t *upstreamTrace) add(id string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.ids) < maxUpstreamRequestIDs && !containsString(t.ids, id) {
+		t.ids = append(t.ids, id)
+	}
+}
+
+func (t *upstreamTrace) header() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return strings.Join(t.ids, ", ")
+}
+
+// upstreamRequestID는 BetterMode 응답 헤더의 요청/추적 ID를 반환합니다 (없으면 "")
+func upstreamRequestID(h http.Header) string {
+	for _, name := range upstreamTraceHeaders {
+		if id := h.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// recordUpstreamRequestID는 BetterMode 응답의 요청 ID를 ctx의 요청에 기록하고 반환합니다
+func recordUpstreamRequestID(ctx context.Context, resp *http.Response) string {
+	id := upstreamRequestID(resp.Header)
+	if trace, ok := ctx.Value(upstreamTraceKey{}).(*upstreamTrace); ok && id != "" {
+		trace.add(id)
+	}
+	return id
+}
+
+// requestTracing은 middleware.RequestID가 정한 요청 ID를 X-Request-ID 응답 헤더로 알려 주고,
+// 처리 중 받은 BetterMode 요청 ID를 X-Upstream-Request-ID 응답 헤더에 싣습니다.
+func requestTracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := middleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set(requestIDHeader, id)
+		}
+		trace := &upstreamTrace{}
+		ctx := context.WithValue(r.Context(), upstreamTraceKey{}, trace)
+		next.ServeHTTP(&traceResponseWriter{ResponseWriter: w, trace: trace}, r.WithContext(ctx))
+	})
+}
+
+// traceResponseWriter는 응답 헤더를 보내기 직전에 모은 업스트림 요청 ID를 헤더에 넣습니다
+type traceResponseWriter struct {
+	http.ResponseWriter
+	trace       *upstreamTrace
+	wroteHeader bool
+}
+
+func (w *traceResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if ids := w.trace.header(); ids != "" {
+			w.Header().Set(upstreamRequestIDHeader, ids)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *traceResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *traceResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap은 http.ResponseController가 원래 ResponseWriter에 접근할 수 있게 합니다
+func (w *traceResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestUpstreamRequestID(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"none", nil, ""},
+		{"request id", map[string]string{"X-Request-Id": "req-1", "Cf-Ray": "ray-1"}, "req-1"},
+		{"trace id before amzn", map[string]string{"X-Amzn-Trace-Id": "amzn-1", "X-Trace-Id": "trace-1"}, "trace-1"},
+		{"cloudflare only", map[string]string{"Cf-Ray": "ray-1"}, "ray-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for name, value := range tt.headers {
+				h.Set(name, value)
+			}
+			if got := upstreamRequestID(h); got != tt.want {
+				t.Errorf("upstreamRequestID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpstreamTraceAdd(t *testing.T) {
+	trace := &upstreamTrace{}
+	trace.add("a")
+	trace.add("b")
+	trace.add("a")
+	if got := trace.header(); got != "a, b" {
+		t.Errorf("header = %q, want %q", got, "a, b")
+	}
+	for i := 0; i < 2*maxUpstreamRequestIDs; i++ {
+		trace.add(fmt.Sprintf("id-%d", i))
+	}
+	if got := len(strings.Split(trace.header(), ", ")); got != maxUpstreamRequestIDs {
+		t.Errorf("ids = %d, want %d", got, maxUpstreamRequestIDs)
+	}
+}
+
+func TestRecordUpstreamRequestIDWithoutTrace(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"X-Request-Id": {"req-1"}}}
+	if got := recordUpstreamRequestID(context.Background(), resp); got != "req-1" {
+		t.Errorf("recordUpstreamRequestID = %q, want req-1", got)
+	}
+}
+
+func TestRequestTracing(t *testing.T) {
+	withTestToken(t)
+	var forwarded []string
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		forwarded = append(forwarded, r.Header.Get(requestIDHeader))
+		w.Header().Set("X-Request-Id", fmt.Sprintf("upstream-%d", len(forwarded)))
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": nil})
+	})
+
+	handler := middleware.RequestID(requestTracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 2; i++ {
+			resp, err := sendGraphQLRequest(r.Context(), "test-token", "query { ping }", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+		}
+		w.Write([]byte("ok"))
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(requestIDHeader, "client-id")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(requestIDHeader); got != "client-id" {
+		t.Errorf("%s = %q, want client-id", requestIDHeader, got)
+	}
+	if got := rec.Header().Get(upstreamRequestIDHeader); got != "upstream-1, upstream-2" {
+		t.Errorf("%s = %q, want %q", upstreamRequestIDHeader, got, "upstream-1, upstream-2")
+	}
+	for i, id := range forwarded {
+		if id != "client-id" {
+			t.Errorf("upstream request %d %s = %q, want client-id", i, requestIDHeader, id)
+		}
+	}
+}
+
+func TestRequestTracingWithoutUpstream(t *testing.T) {
+	handler := middleware.RequestID(requestTracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Header().Get(requestIDHeader) == "" {
+		t.Errorf("%s missing", requestIDHeader)
+	}
+	if _, ok := rec.Header()[upstreamRequestIDHeader]; ok {
+		t.Errorf("%s set without upstream calls", upstreamRequestIDHeader)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Translator는 텍스트를 대상 언어로 번역합니다
+type Translator interface {
+	Translate(ctx context.Context, text, targetLang string) (string, error)
+}
+
+// noopTranslator는 번역기를 설정하지 않았을 때 쓰는 기본값으로, 텍스트를 그대로 돌려줍니다
+type noopTranslator struct{}
+
+func (noopTranslator) Translate(ctx context.Context, text, targetLang string) (string, error) {
+	return text, nil
+}
+
+// httpTranslator는 LibreTranslate 호환 API(POST {q, source, target, format} → {translatedText})를 호출합니다
+type httpTranslator struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func (t *httpTranslator) Translate(ctx context.Context, text, targetLang string) (string, error) {
+	payload := map[string]string{"q": text, "source": "auto", "target": targetLang, "format": "text"}
+	if t.apiKey != "" {
+		payload["api_key"] = t.apiKey
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error calling translation API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("translation API returned HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	var result struct {
+		TranslatedText *string `json:"translatedText"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("error parsing translation response: %w", err)
+	}
+	if result.TranslatedText == nil {
+		return "", errors.New("translation response has no translatedText")
+	}
+	return *result.TranslatedText, nil
+}
+
+// newTranslator는 TRANSLATOR_URL이 설정되어 있으면 HTTP 번역기를, 아니면 no-op 번역기를 만듭니다
+func newTranslator() Translator {
+	cfg := currentConfig()
+	if cfg.TranslatorURL == "" {
+		return noopTranslator{}
+	}
+	return &httpTranslator{url: cfg.TranslatorURL, apiKey: cfg.TranslatorAPIKey, client: upstreamClient}
+}
+
+// 전역 번역기와 번역 캐시
+var (
+	translator   Translator = noopTranslator{}
+	translations            = newTranslationCache(1000)
+)
+
+// translationCache는 (텍스트 해시, 대상 언어)별 번역 결과를 보관합니다.
+// 같은 게시물을 다른 형식으로 요청해도 텍스트가 같으면 번역 API를 다시 호출하지 않습니다.
+type translationCache struct {
+	entries    map[string]translationEntry
+	maxEntries int
+	mutex      sync.Mutex
+}
+
+type translationEntry struct {
+	text      string
+	expiresAt time.Time
+}
+
+func newTranslationCache(maxEntries int) *translationCache {
+	return &translationCache{entries: make(map[string]translationEntry), maxEntries: maxEntries}
+}
+
+func translationCacheKey(text, targetLang string) string {
+	sum := sha256.Sum256([]byte(text))
+	return targetLang + "|" + hex.EncodeToString(sum[:])
+}
+
+func (c *translationCache) get(key string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return "", false
+	}
+	return entry.text, true
+}
+
+// set은 번역 결과를 CACHE_TTL 동안 저장합니다. 가득 차면 만료된 항목을 지우고, 그래도 가득 차 있으면 저장하지 않습니다.
+func (c *translationCache) set(key, text string, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= c.maxEntries {
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+	c.entries[key] = translationEntry{text: text, expiresAt: now.Add(ttl)}
+}
+
+// translateText는 캐시를 확인한 뒤 번역기로 text를 번역합니다
+func translateText(ctx context.Context, text, targetLang string) (string, error) {
+	key := translationCacheKey(text, targetLang)
+	if cached, ok := translations.get(key); ok {
+		return cached, nil
+	}
+	translated, err := translator.Translate(ctx, text, targetLang)
+	if err != nil {
+		return "", err
+	}
+	translations.set(key, translated, currentConfig().CacheTTL)
+	return translated, nil
+}
+
+// 언어 코드 형식 ("en", "ko", "zh-Hans" 등)
+var languageCodePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
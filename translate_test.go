@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mockTranslator는 텍스트 앞에 대상 언어를 붙여 돌려주고 호출 수를 셉니다
+type mockTranslator struct {
+	calls int64
+	err   error
+}
+
+func (m *mockTranslator) Translate(ctx context.Context, text, targetLang string) (string, error) {
+	atomic.AddInt64(&m.calls, 1)
+	if m.err != nil {
+		return "", m.err
+	}
+	return "[" + targetLang + "] " + text, nil
+}
+
+// withTranslator는 tr과 빈 번역 캐시를 테스트가 끝날 때까지 씁니다
+func withTranslator(t *testing.T, tr Translator) {
+	t.Helper()
+	prevTranslator, prevCache := translator, translations
+	translator, translations = tr, newTranslationCache(100)
+	t.Cleanup(func() { translator, translations = prevTranslator, prevCache })
+}
+
+func TestTranslateTextCachesByTextAndLanguage(t *testing.T) {
+	mock := &mockTranslator{}
+	withTranslator(t, mock)
+
+	requests := []struct{ text, lang string }{
+		{"안녕하세요", "en"},
+		{"안녕하세요", "en"},
+		{"안녕하세요", "ja"},
+		{"반갑습니다", "en"},
+	}
+	for _, r := range requests {
+		got, err := translateText(context.Background(), r.text, r.lang)
+		if err != nil || got != "["+r.lang+"] "+r.text {
+			t.Errorf("translateText(%q, %q) = %q, %v", r.text, r.lang, got, err)
+		}
+	}
+	if got := atomic.LoadInt64(&mock.calls); got != 3 {
+		t.Errorf("translator calls = %d, want 3", got)
+	}
+}
+
+func TestTranslationCacheFull(t *testing.T) {
+	cache := newTranslationCache(1)
+	cache.set("a", "A", time.Minute)
+	cache.set("b", "B", time.Minute)
+	if _, ok := cache.get("b"); ok {
+		t.Error("entry stored beyond maxEntries")
+	}
+
+	cache = newTranslationCache(1)
+	cache.set("a", "A", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	cache.set("b", "B", time.Minute)
+	if got, ok := cache.get("b"); !ok || got != "B" {
+		t.Errorf("expired entry not evicted: get(b) = %q, %v", got, ok)
+	}
+}
+
+func TestRenderContentResponseTranslation(t *testing.T) {
+	tests := []struct {
+		name           string
+		format         string
+		translatorErr  error
+		wantTranslated string
+		wantWarning    bool
+	}{
+		{"html is stripped before translating", "html", nil, "[en] 안녕하세요", false},
+		{"text", "text", nil, "[en] 안녕하세요", false},
+		{"failure keeps original", "html", errors.New("quota exceeded"), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTranslator(t, &mockTranslator{err: tt.translatorErr})
+			response := renderTestPost(t, ContentOptions{Format: tt.format, TranslateTo: "en"}, newTestPost("제목", "<p>안녕하세요</p>"))
+			if response.TranslatedText != tt.wantTranslated {
+				t.Errorf("translated_text = %q, want %q", response.TranslatedText, tt.wantTranslated)
+			}
+			if !strings.Contains(response.Content, "안녕하세요") || strings.Contains(response.Content, "[en]") {
+				t.Errorf("content = %q, want the original text", response.Content)
+			}
+			if got := hasWarning(response.Warnings, WarningTranslationFailed); got != tt.wantWarning {
+				t.Errorf("translation_failed warning = %v, want %v", got, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestHTTPTranslator(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"translated", http.StatusOK, `{"translatedText":"Hello"}`, "Hello", false},
+		{"api error", http.StatusForbidden, `invalid api key`, "", true},
+		{"missing field", http.StatusOK, `{}`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload map[string]string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&payload)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			tr := &httpTranslator{url: srv.URL, apiKey: "key", client: srv.Client()}
+			got, err := tr.Translate(context.Background(), "안녕하세요", "en")
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("Translate = %q, %v, want %q (wantErr %v)", got, err, tt.want, tt.wantErr)
+			}
+			if payload["q"] != "안녕하세요" || payload["target"] != "en" || payload["source"] != "auto" || payload["api_key"] != "key" {
+				t.Errorf("payload = %v", payload)
+			}
+		})
+	}
+}
+
+func TestNormalizeTranslateTo(t *testing.T) {
+	tests := []struct {
+		lang    string
+		wantErr bool
+	}{
+		{"en", false},
+		{"zh-Hans", false},
+		{"e", true},
+		{"en_US!", true},
+	}
+	for _, tt := range tests {
+		opts := ContentOptions{TranslateTo: tt.lang}
+		if err := opts.normalize(); (err != nil) != tt.wantErr {
+			t.Errorf("normalize(translate_to %q) error = %v, wantErr %v", tt.lang, err, tt.wantErr)
+		}
+	}
+}
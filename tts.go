@@ -0,0 +1,38 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// ![대체 텍스트](url) → 대체 텍스트, [텍스트](url) → 텍스트
+	markdownImagePattern = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLinkPattern  = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	rawURLPattern        = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"')\]]+`)
+	// 줄 앞의 헤딩(#), 인용(>), 목록 기호(-, *, +)
+	lineMarkerPattern = regexp.MustCompile(`(?m)^[ \t]*(?:#{1,6}[ \t]+|>+[ \t]?|[-*+][ \t]+)`)
+	// 강조 기호 (**굵게**, __굵게__, ~~취소선~~, `코드`)
+	emphasisPattern = regexp.MustCompile("\\*\\*|__|~~|`+")
+	horizontalSpace = regexp.MustCompile(`[ \t\x{00A0}]+`)
+	extraBlankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// textForSpeech는 text 형식 콘텐츠를 TTS에 읽히기 좋게 다듬습니다.
+// 링크 주소는 "link"로 바꾸고, 마크다운 기호를 지우고, 공백을 정리합니다.
+// 의미가 바뀌지 않도록 문장 부호나 숫자, 약어는 건드리지 않습니다.
+func textForSpeech(text string) string {
+	text = markdownImagePattern.ReplaceAllString(text, "$1")
+	text = markdownLinkPattern.ReplaceAllString(text, "$1")
+	text = rawURLPattern.ReplaceAllString(text, "link")
+	text = lineMarkerPattern.ReplaceAllString(text, "")
+	text = emphasisPattern.ReplaceAllString(text, "")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(horizontalSpace.ReplaceAllString(line, " "))
+	}
+	text = strings.Join(lines, "\n")
+	text = extraBlankLines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestTextForSpeech(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"raw url", "자세한 내용은 https://example.com/a?b=1 에서 보세요.", "자세한 내용은 link 에서 보세요."},
+		{"www url", "www.example.com 참고", "link 참고"},
+		{"url before closing paren", "(https://example.com)", "(link)"},
+		{"markdown link keeps text", "[공식 문서](https://example.com)를 읽으세요", "공식 문서를 읽으세요"},
+		{"markdown image keeps alt", "![다이어그램](https://example.com/a.png)", "다이어그램"},
+		{"heading and list markers", "# 제목\n- 첫째\n* 둘째\n> 인용", "제목\n첫째\n둘째\n인용"},
+		{"emphasis", "**굵게** __밑줄__ ~~취소~~ `코드`", "굵게 밑줄 취소 코드"},
+		{"whitespace", "  여러   칸\t공백  \n\n\n\n다음 문단 ", "여러 칸 공백\n\n다음 문단"},
+		{"keeps numbers and punctuation", "가격은 3.5% 인상, e.g. 1-2일 소요!", "가격은 3.5% 인상, e.g. 1-2일 소요!"},
+		{"keeps inline hyphen and asterisk", "A-B 테스트, 2*3=6", "A-B 테스트, 2*3=6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := textForSpeech(tt.in); got != tt.want {
+				t.Errorf("textForSpeech(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponseTTS(t *testing.T) {
+	post := newTestPost("제목", `<h2>소개</h2><p><strong>중요:</strong> <a href="https://example.com">https://example.com</a> 참고</p>`)
+	response := renderTestPost(t, ContentOptions{Format: "text", TTS: true}, post)
+	if want := "소개 중요: link 참고"; response.Content != want {
+		t.Errorf("content = %q, want %q", response.Content, want)
+	}
+}
+
+func TestNormalizeTTSRequiresText(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{"text", false},
+		{"html", true},
+		{"xhtml", true},
+	}
+	for _, tt := range tests {
+		opts := ContentOptions{Format: tt.format, TTS: true}
+		if err := opts.normalize(); (err != nil) != tt.wantErr {
+			t.Errorf("normalize(format %q, tts) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const betterModeAPIURL = "https://api.bettermode.com/"
+
+// 업스트림 호출에 공유하는 HTTP 클라이언트
+var upstreamClient = &http.Client{}
+
+// graphQLError는 GraphQL 응답의 errors 항목입니다
+type graphQLError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
+}
+
+// isInputError는 요청 값(변수 형식 등)이 잘못되어 생긴 에러인지 판단합니다
+func (e graphQLError) isInputError() bool {
+	switch e.Extensions.Code {
+	case "GRAPHQL_VALIDATION_FAILED", "BAD_USER_INPUT", "GRAPHQL_PARSE_FAILED":
+		return true
+	}
+	// 변수 타입 변환(coercion) 에러는 코드 없이 메시지로만 오는 경우가 있습니다
+	return strings.HasPrefix(e.Message, "Variable \"$")
+}
+
+// isNotFound는 요청한 게시물 등이 없어서 생긴 에러인지 판단합니다
+func (e graphQLError) isNotFound() bool {
+	return e.Extensions.Code == "NOT_FOUND" || strings.EqualFold(e.Message, "not found")
+}
+
+// inputError는 클라이언트가 보낸 값 때문에 BetterMode가 요청을 거절했음을 나타냅니다 (400으로 응답)
+type inputError struct {
+	message string
+}
+
+func (e *inputError) Error() string {
+	return "invalid request: " + e.message
+}
+
+// notFoundError는 BetterMode가 요청한 대상이 없다고 답했음을 나타냅니다 (일시적인 장애와 구분)
+type notFoundError struct {
+	message string
+}
+
+func (e *notFoundError) Error() string {
+	return "BetterMode API error: " + e.message
+}
+
+// graphQLErrorsToError는 GraphQL errors를 에러로 바꿉니다. 입력 값 문제이면 *inputError를, 대상이 없으면 *notFoundError를 반환합니다.
+func graphQLErrorsToError(errs []graphQLError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	if errs[0].isInputError() {
+		return &inputError{message: errs[0].Message}
+	}
+	if errs[0].isNotFound() {
+		return &notFoundError{message: errs[0].Message}
+	}
+	return fmt.Errorf("BetterMode API error: %s", errs[0].Message)
+}
+
+// upstreamUnavailableError는 BetterMode가 JSON 대신 점검 페이지 같은 HTML을 보냈음을 나타냅니다 (502로 응답)
+type upstreamUnavailableError struct {
+	status      int
+	contentType string
+}
+
+func (e *upstreamUnavailableError) Error() string {
+	return fmt.Sprintf("BetterMode is unavailable: got a non-JSON %q response (HTTP %d), it may be down for maintenance; try again later",
+		e.contentType, e.status)
+}
+
+// truncatedResponseError는 연결이 끊기는 등으로 BetterMode 응답 본문을 끝까지 받지 못했음을 나타냅니다.
+// 잘못 만들어진(malformed) JSON과 달리 다시 요청하면 성공할 수 있습니다.
+type truncatedResponseError struct {
+	received int // 받은 바이트 수
+	err      error
+}
+
+func (e *truncatedResponseError) Error() string {
+	return fmt.Sprintf("BetterMode response was cut off after %d bytes (connection dropped mid-response): %v", e.received, e.err)
+}
+
+func (e *truncatedResponseError) Unwrap() error {
+	return e.err
+}
+
+// readJSONResponse는 BetterMode 응답 본문을 끝까지 읽고 JSON인지 확인합니다.
+// 본문이 중간에 끊겼으면(읽기 에러, 또는 JSON이 도중에 끝남) *truncatedResponseError를,
+// JSON이 아니면 *upstreamUnavailableError를, 끝까지 받았는데 JSON 문법이 틀렸으면 일반 에러를 반환합니다.
+func readJSONResponse(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || isRetryableNetworkError(err) {
+			return nil, &truncatedResponseError{received: len(body), err: err}
+		}
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	if err := checkJSONResponse(resp, body); err != nil {
+		return nil, err
+	}
+
+	var value json.RawMessage
+	if err := json.Unmarshal(body, &value); err != nil {
+		// 문법 에러 위치가 본문 끝이면 JSON이 도중에 끝난 것입니다 (Content-Length 없이 연결이 닫힌 경우)
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) && syntaxErr.Offset >= int64(len(bytes.TrimRight(body, " \t\r\n"))) {
+			return nil, &truncatedResponseError{received: len(body), err: err}
+		}
+		return nil, fmt.Errorf("error parsing response (HTTP %d): malformed JSON: %w", resp.StatusCode, err)
+	}
+	return body, nil
+}
+
+// checkJSONResponse는 BetterMode 응답이 JSON인지 확인합니다. 아니면 진단을 위해 본문 앞부분을 로그에 남기고
+// *upstreamUnavailableError를 반환합니다. Content-Type이 없거나 잘못 붙어 있어도 본문이 JSON 객체로 시작하면 JSON으로 봅니다.
+func checkJSONResponse(resp *http.Response, body []byte) error {
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		return nil
+	}
+
+	snippet := strings.Join(strings.Fields(string(body)), " ")
+	if len(snippet) > 200 {
+		snippet = snippet[:200] + "..."
+	}
+	log.Printf("BetterMode returned a non-JSON response (HTTP %d, Content-Type %q, upstream request ID %q): %s",
+		resp.StatusCode, contentType, upstreamRequestID(resp.Header), snippet)
+	if mediaType == "" {
+		mediaType = "unknown"
+	}
+	return &upstreamUnavailableError{status: resp.StatusCode, contentType: mediaType}
+}
+
+// sendGraphQLRequest는 토큰을 붙여 BetterMode GraphQL API에 쿼리를 보냅니다.
+// 호출자가 응답 본문을 닫아야 합니다.
+func sendGraphQLRequest(ctx context.Context, token, query string, variables map[string]interface{}) (*http.Response, error) {
+	cfg := currentConfig()
+	payload := map[string]interface{}{
+		"query": query,
+	}
+	if variables != nil {
+		payload["variables"] = variables
+	}
+
+	queryJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling query: %w", err)
+	}
+
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", betterModeAPIURL, bytes.NewReader(queryJSON))
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+
+		// Set headers with dynamic token
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "*/*")
+		req.Header.Set("User-Agent", "GPTers-Scraper/1.0")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		// BetterMode 로그와 대조할 수 있도록 우리 요청 ID를 전달합니다
+		if id := middleware.GetReqID(ctx); id != "" {
+			req.Header.Set(requestIDHeader, id)
+		}
+		return req, nil
+	}
+
+	// BetterMode가 429를 보내면 RATE_LIMIT_QUEUE_WAIT 안에서 한 번만 기다렸다가 다시 보냅니다.
+	// 연결이 끊기는 등 네트워크 에러는 UPSTREAM_NETWORK_RETRIES번까지 다시 보냅니다 (읽기 쿼리라 여러 번 보내도 안전)
+	networkRetries := 0
+	for attempt := 0; ; attempt++ {
+		// Send the request (UPSTREAM_HEDGE_DELAY가 설정되어 있으면 응답이 늦을 때 같은 요청을 한 번 더 보냅니다)
+		var resp *http.Response
+		if cfg.UpstreamHedgeDelay > 0 {
+			resp, err = doHedged(ctx, upstreamClient, cfg.UpstreamHedgeDelay, newRequest)
+		} else {
+			var req *http.Request
+			if req, err = newRequest(ctx); err != nil {
+				return nil, err
+			}
+			resp, err = upstreamClient.Do(req)
+		}
+		if err != nil {
+			if networkRetries < cfg.UpstreamNetworkRetries && ctx.Err() == nil && isRetryableNetworkError(err) {
+				networkRetries++
+				delay := time.Duration(networkRetries) * networkRetryBackoff
+				log.Printf("Network error talking to BetterMode (%v), retry %d/%d in %v", err, networkRetries, cfg.UpstreamNetworkRetries, delay)
+				if err := sleepContext(ctx, delay); err != nil {
+					return nil, fmt.Errorf("error sending request: %w", err)
+				}
+				attempt--
+				continue
+			}
+			return nil, fmt.Errorf("error sending request: %w", err)
+		}
+		if upstreamID := recordUpstreamRequestID(ctx, resp); resp.StatusCode >= http.StatusInternalServerError {
+			log.Printf("BetterMode returned HTTP %d (request ID %q, upstream request ID %q)", resp.StatusCode, middleware.GetReqID(ctx), upstreamID)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt > 0 || cfg.RateLimitQueueWait <= 0 {
+			return resp, nil
+		}
+
+		wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok {
+			wait = time.Second
+		}
+		if wait > cfg.RateLimitQueueWait {
+			return resp, nil
+		}
+		resp.Body.Close()
+		log.Printf("BetterMode rate limit hit, retrying in %v", wait)
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, fmt.Errorf("error sending request: %w", err)
+		}
+	}
+}
+
+// 네트워크 에러 재시도 간격 (n번째 재시도는 n배)
+const networkRetryBackoff = 200 * time.Millisecond
+
+// isRetryableNetworkError는 연결 실패, 연결 끊김(reset, EOF)처럼 BetterMode가 요청을 처리하지 못했을 수 있는
+// 일시적인 네트워크 에러인지 확인합니다. 컨텍스트 취소나 마감 시간 초과는 다시 시도하지 않습니다.
+func isRetryableNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && urlErr.Timeout()
+}
+
+// sleepContext는 d만큼 기다리거나 ctx가 끝나면 ctx.Err()를 반환합니다
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// queryBetterMode는 관리 중인 토큰(또는 호출자 토큰)으로 GraphQL 쿼리를 보내고 data 부분을 out에 디코딩합니다.
+// 관리 중인 토큰이 401을 받으면 한 번 갱신해 다시 시도하고, GraphQL errors가 있으면 에러로 반환합니다.
+func queryBetterMode(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	token, supplied, err := upstreamToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	cfg := currentConfig()
+	truncatedRetries := 0
+	for attempt := 0; ; attempt++ {
+		resp, err := sendGraphQLRequest(ctx, token, query, variables)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && supplied {
+			resp.Body.Close()
+			return errCallerTokenRejected
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			resp.Body.Close()
+			log.Println("Token seems expired, refreshing and retrying...")
+			if err := tokenManager.RefreshToken(); err != nil {
+				return fmt.Errorf("failed to refresh token: %w", err)
+			}
+			if token, err = tokenManager.GetToken(); err != nil {
+				return fmt.Errorf("error getting access token: %w", err)
+			}
+			continue
+		}
+
+		body, err := readJSONResponse(resp)
+		resp.Body.Close()
+		if errors.As(err, new(*truncatedResponseError)) && truncatedRetries < cfg.UpstreamTruncatedRetries {
+			truncatedRetries++
+			log.Printf("BetterMode response was cut off (%v), retry %d/%d", err, truncatedRetries, cfg.UpstreamTruncatedRetries)
+			attempt--
+			continue
+		}
+		if err != nil {
+			if truncatedRetries > 0 {
+				return fmt.Errorf("giving up after %d retries: %w", truncatedRetries, err)
+			}
+			return err
+		}
+		return parseGraphQLData(body, resp.StatusCode, out)
+	}
+}
+
+// parseGraphQLData는 GraphQL 응답 본문의 data를 out에 풀고, errors가 있거나 200이 아니면 에러를 반환합니다
+func parseGraphQLData(body []byte, statusCode int, out interface{}) error {
+	var gqlResp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &gqlResp); err != nil {
+		return fmt.Errorf("error parsing response (HTTP %d): %w", statusCode, err)
+	}
+	if err := graphQLErrorsToError(gqlResp.Errors); err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("BetterMode API returned HTTP %d", statusCode)
+	}
+	if err := json.Unmarshal(gqlResp.Data, out); err != nil {
+		return fmt.Errorf("error parsing response data: %w", err)
+	}
+	return nil
+}
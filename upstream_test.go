@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestGraphQLErrorsToError(t *testing.T) {
+	withCode := func(message, code string) graphQLError {
+		e := graphQLError{Message: message}
+		e.Extensions.Code = code
+		return e
+	}
+	tests := []struct {
+		name      string
+		errs      []graphQLError
+		wantNil   bool
+		wantInput bool
+	}{
+		{"no errors", nil, true, false},
+		{"validation failed", []graphQLError{withCode("Cannot query field", "GRAPHQL_VALIDATION_FAILED")}, false, true},
+		{"bad user input", []graphQLError{withCode("Invalid ID", "BAD_USER_INPUT")}, false, true},
+		{"variable coercion without code", []graphQLError{{Message: `Variable "$id" got invalid value 123; ID cannot represent value`}}, false, true},
+		{"server error", []graphQLError{withCode("Internal error", "INTERNAL_SERVER_ERROR")}, false, false},
+		{"only the first error counts", []graphQLError{withCode("Not found", "NOT_FOUND"), withCode("Invalid ID", "BAD_USER_INPUT")}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := graphQLErrorsToError(tt.errs)
+			if (err == nil) != tt.wantNil {
+				t.Fatalf("err = %v, wantNil %v", err, tt.wantNil)
+			}
+			if got := errors.As(err, new(*inputError)); got != tt.wantInput {
+				t.Errorf("inputError = %v, want %v (err %v)", got, tt.wantInput, err)
+			}
+		})
+	}
+}
+
+func TestBuildContentResponseGraphQLValidationError(t *testing.T) {
+	const message = `Variable "$id" got invalid value {}; ID cannot represent value: {}`
+	withTestToken(t)
+	withContentCache(t)
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+			"errors": []map[string]interface{}{{"message": message, "extensions": map[string]string{"code": "BAD_USER_INPUT"}}},
+		})
+	})
+
+	_, err := buildContentResponse(context.Background(), ContentRequest{PostID: "{}", ContentOptions: ContentOptions{Format: "html", NoCache: true}})
+	if !errors.As(err, new(*inputError)) {
+		t.Fatalf("err = %v, want inputError", err)
+	}
+
+	rec := httptest.NewRecorder()
+	writeFetchError(rec, err)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), message) {
+		t.Errorf("body = %q, want upstream message", rec.Body.String())
+	}
+}
+
+func TestIsRetryableNetworkError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"EOF in url error", &url.Error{Op: "Post", URL: betterModeAPIURL, Err: io.EOF}, true},
+		{"canceled", &url.Error{Op: "Post", URL: betterModeAPIURL, Err: context.Canceled}, false},
+		{"deadline exceeded", context.DeadlineExceeded, false},
+		{"other error", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableNetworkError(tt.err); got != tt.want {
+				t.Errorf("isRetryableNetworkError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendGraphQLRequestNetworkRetries(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	tests := []struct {
+		name      string
+		retries   int
+		failures  int // 처음 몇 번 요청이 실패하는지
+		err       error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"succeeds after a retry", 2, 1, refused, 2, false},
+		{"gives up after retries", 1, 5, refused, 2, true},
+		{"retries disabled", 0, 1, refused, 1, true},
+		{"non-network error is not retried", 2, 1, errors.New("boom"), 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.UpstreamNetworkRetries = tt.retries })
+			calls := 0
+			prev := upstreamClient.Transport
+			upstreamClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				calls++
+				if calls <= tt.failures {
+					return nil, tt.err
+				}
+				rec := httptest.NewRecorder()
+				writeJSONResponse(rec, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{}})
+				return rec.Result(), nil
+			})
+			t.Cleanup(func() { upstreamClient.Transport = prev })
+
+			resp, err := sendGraphQLRequest(context.Background(), "test-token", "query { ok }", nil)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSleepContext(t *testing.T) {
+	if err := sleepContext(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("sleepContext = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sleepContext(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("sleepContext after cancel = %v, want context.Canceled", err)
+	}
+}
+
+func TestCheckJSONResponse(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentType     string
+		body            string
+		wantContentType string // 비어 있으면 에러가 없어야 합니다
+	}{
+		{"json", "application/json; charset=utf-8", `{"data":{}}`, ""},
+		{"graphql json", "application/graphql-response+json", `{"data":{}}`, ""},
+		{"mislabeled json", "text/plain", ` {"data":{}}`, ""},
+		{"maintenance page", "text/html", "<html><body>Down for maintenance</body></html>", "text/html"},
+		{"no content type", "", "Service Unavailable", "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if tt.contentType != "" {
+				rec.Header().Set("Content-Type", tt.contentType)
+			}
+			rec.WriteHeader(http.StatusServiceUnavailable)
+			err := checkJSONResponse(rec.Result(), []byte(tt.body))
+			if tt.wantContentType == "" {
+				if err != nil {
+					t.Errorf("checkJSONResponse: %v", err)
+				}
+				return
+			}
+			var unavailable *upstreamUnavailableError
+			if !errors.As(err, &unavailable) {
+				t.Fatalf("err = %v, want *upstreamUnavailableError", err)
+			}
+			if unavailable.contentType != tt.wantContentType || unavailable.status != http.StatusServiceUnavailable {
+				t.Errorf("err = %+v, want content type %q and status 503", unavailable, tt.wantContentType)
+			}
+		})
+	}
+}
+
+func TestGetContentMaintenancePageReturns502(t *testing.T) {
+	withTestToken(t)
+	withContentCache(t)
+	withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("<html><body>점검 중입니다</body></html>"))
+	})
+
+	rec := httptest.NewRecorder()
+	getContent(rec, httptest.NewRequest(http.MethodGet, "/api/v1/content?post_id=post-1", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "maintenance") {
+		t.Errorf("body = %q, want a maintenance hint", rec.Body.String())
+	}
+}
+
+func TestReadJSONResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          io.Reader
+		wantTruncated bool
+		wantErr       bool
+	}{
+		{"complete", strings.NewReader(`{"data":{"post":null}}`), false, false},
+		{"trailing whitespace", strings.NewReader("{\"data\":{}}\n"), false, false},
+		{"json ends early", strings.NewReader(`{"data":{"post":{"title":"제`), true, true},
+		{"connection dropped", io.MultiReader(strings.NewReader(`{"data":`), iotest.ErrReader(io.ErrUnexpectedEOF)), true, true},
+		{"connection reset", io.MultiReader(strings.NewReader(`{"data":`), iotest.ErrReader(syscall.ECONNRESET)), true, true},
+		{"malformed json", strings.NewReader(`{"data":{"post":nul}}`), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(tt.body),
+			}
+			_, err := readJSONResponse(resp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if truncated := errors.As(err, new(*truncatedResponseError)); truncated != tt.wantTruncated {
+				t.Errorf("err = %v, want truncated %v", err, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestQueryBetterModeTruncatedRetries(t *testing.T) {
+	tests := []struct {
+		name           string
+		retries        int
+		truncatedCalls int // 처음 몇 번 끊긴 응답을 돌려주는지
+		wantCalls      int
+		wantTruncated  bool
+	}{
+		{"disabled", 0, 1, 1, true},
+		{"complete on retry", 1, 1, 2, false},
+		{"cut off every time", 2, 5, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withConfig(t, func(cfg *Config) { cfg.UpstreamTruncatedRetries = tt.retries })
+			calls := 0
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				if calls <= tt.truncatedCalls {
+					w.Write([]byte(`{"data":{"value":`))
+					return
+				}
+				w.Write([]byte(`{"data":{"value":1}}`))
+			})
+
+			var out struct {
+				Value int `json:"value"`
+			}
+			err := queryBetterMode(context.Background(), "query { value }", nil, &out)
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if truncated := errors.As(err, new(*truncatedResponseError)); truncated != tt.wantTruncated {
+				t.Errorf("err = %v, want truncated %v", err, tt.wantTruncated)
+			}
+			if !tt.wantTruncated && out.Value != 1 {
+				t.Errorf("value = %d, want 1", out.Value)
+			}
+		})
+	}
+}
+
+func TestFetchPostFromUpstreamTruncatedRetries(t *testing.T) {
+	withTestToken(t)
+	withConfig(t, func(cfg *Config) { cfg.UpstreamTruncatedRetries = 2 })
+	fake := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"post":{"title":"제`))
+	})
+
+	_, err := fetchPostFromUpstream(context.Background(), "post-1", ContentOptions{Format: "html"})
+	if !errors.As(err, new(*truncatedResponseError)) || !strings.Contains(err.Error(), "giving up after 2 retries") {
+		t.Errorf("err = %v, want truncated response after 2 retries", err)
+	}
+	if fake.count() != 3 {
+		t.Errorf("calls = %d, want 3", fake.count())
+	}
+
+	rec := httptest.NewRecorder()
+	writeFetchError(rec, err)
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("writeFetchError status = %d, want 502", rec.Code)
+	}
+}
+
+func TestGraphQLErrorIsNotFound(t *testing.T) {
+	tests := []struct {
+		message string
+		code    string
+		want    bool
+	}{
+		{"Post does not exist", "NOT_FOUND", true},
+		{"Not Found", "", true},
+		{"Forbidden", "FORBIDDEN", false},
+		{"not found in cache, try again", "", false},
+	}
+	for _, tt := range tests {
+		e := graphQLError{Message: tt.message}
+		e.Extensions.Code = tt.code
+		if got := e.isNotFound(); got != tt.want {
+			t.Errorf("isNotFound(%q, %q) = %v, want %v", tt.message, tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// cachedUpstreamResponse는 BetterMode가 보낸 GraphQL 응답 본문 그대로입니다
+type cachedUpstreamResponse struct {
+	body      []byte
+	postID    string // 조회한 게시물 ID (웹훅 등으로 게시물 캐시를 지울 때 함께 지움)
+	expiresAt time.Time
+}
+
+// upstreamResponseCache는 짧은 시간 안에 같은 게시물을 같은 쿼리로 다시 조회할 때 받아 둔 응답 본문을
+// 재사용합니다. 가공한 응답을 옵션별로 저장하는 contentCache와 달리 형식(format)만 다른 요청들도
+// 같은 원본 응답을 함께 씁니다. 게시물 ID로 지울 수 없는 목록 조회 응답은 저장하지 않습니다.
+type upstreamResponseCache struct {
+	mutex   sync.Mutex
+	entries *lruMap[cachedUpstreamResponse]
+}
+
+func newUpstreamResponseCache(maxEntries int) *upstreamResponseCache {
+	return &upstreamResponseCache{entries: newLRUMap[cachedUpstreamResponse](maxEntries)}
+}
+
+// BetterMode 원본 응답 캐시 (UPSTREAM_RESPONSE_CACHE_ENTRIES가 0이면 nil)
+var upstreamResponses *upstreamResponseCache
+
+// upstreamResponseKey는 토큰, 쿼리, 변수로 캐시 키를 만듭니다. 호출자 토큰마다 볼 수 있는 데이터가
+// 다를 수 있으므로 토큰도 키에 넣습니다 (토큰 원문은 남기지 않도록 해시).
+func upstreamResponseKey(token, query string, variables map[string]interface{}) string {
+	// json.Marshal은 map 키를 정렬하므로 같은 변수는 항상 같은 문자열이 됩니다
+	vars, _ := json.Marshal(variables)
+	sum := sha256.Sum256([]byte(token + "\x00" + query + "\x00" + string(vars)))
+	return hex.EncodeToString(sum[:])
+}
+
+// get은 만료되지 않은 응답 본문을 반환합니다. UPSTREAM_RESPONSE_CACHE_TTL이 0이면 항상 없음입니다.
+func (c *upstreamResponseCache) get(key string) ([]byte, bool) {
+	if c == nil || currentConfig().UpstreamResponseCacheTTL <= 0 {
+		return nil, false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries.get(key)
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// set은 응답 본문을 UPSTREAM_RESPONSE_CACHE_TTL 동안 저장합니다
+func (c *upstreamResponseCache) set(key, postID string, body []byte) {
+	if c == nil {
+		return
+	}
+	ttl := currentConfig().UpstreamResponseCacheTTL
+	if ttl <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries.set(key, cachedUpstreamResponse{body: body, postID: postID, expiresAt: time.Now().Add(ttl)})
+}
+
+// invalidatePost는 postID 게시물 조회 응답을 모두 지우고 지운 개수를 반환합니다
+func (c *upstreamResponseCache) invalidatePost(postID string) int {
+	if c == nil {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.entries.removeIf(func(entry cachedUpstreamResponse) bool { return entry.postID == postID })
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withUpstreamResponseCache는 테스트 동안 ttl로 원본 응답을 캐시합니다
+func withUpstreamResponseCache(t *testing.T, ttl time.Duration) {
+	t.Helper()
+	withConfig(t, func(cfg *Config) { cfg.UpstreamResponseCacheTTL = ttl })
+	prev := upstreamResponses
+	upstreamResponses = newUpstreamResponseCache(10)
+	t.Cleanup(func() { upstreamResponses = prev })
+}
+
+func TestUpstreamResponseKey(t *testing.T) {
+	base := upstreamResponseKey("token-a", "query Q", map[string]interface{}{"id": "post-1", "limit": 10})
+	tests := []struct {
+		name      string
+		token     string
+		query     string
+		variables map[string]interface{}
+		wantSame  bool
+	}{
+		{"same request", "token-a", "query Q", map[string]interface{}{"limit": 10, "id": "post-1"}, true},
+		{"other token", "token-b", "query Q", map[string]interface{}{"id": "post-1", "limit": 10}, false},
+		{"other query", "token-a", "query R", map[string]interface{}{"id": "post-1", "limit": 10}, false},
+		{"other variables", "token-a", "query Q", map[string]interface{}{"id": "post-2", "limit": 10}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := upstreamResponseKey(tt.token, tt.query, tt.variables)
+			if (key == base) != tt.wantSame {
+				t.Errorf("key == base is %v, want %v", key == base, tt.wantSame)
+			}
+			if strings.Contains(key, tt.token) {
+				t.Errorf("key %q contains the token", key)
+			}
+		})
+	}
+}
+
+func TestUpstreamResponseCacheGetSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		expired bool
+		want    bool
+	}{
+		{"disabled", 0, false, false},
+		{"fresh", time.Minute, false, true},
+		{"expired", time.Minute, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withUpstreamResponseCache(t, tt.ttl)
+			upstreamResponses.set("key", "post-1", []byte(`{"data":{}}`))
+			if tt.expired {
+				upstreamResponses.entries.set("key", cachedUpstreamResponse{body: []byte(`{}`), expiresAt: time.Now().Add(-time.Second)})
+			}
+			body, ok := upstreamResponses.get("key")
+			if ok != tt.want {
+				t.Fatalf("get ok = %v, want %v", ok, tt.want)
+			}
+			if ok && string(body) != `{"data":{}}` {
+				t.Errorf("body = %s", body)
+			}
+		})
+	}
+
+	var disabled *upstreamResponseCache
+	disabled.set("key", "post-1", []byte(`{}`))
+	if _, ok := disabled.get("key"); ok {
+		t.Error("nil cache should never hit")
+	}
+	if disabled.invalidatePost("post-1") != 0 {
+		t.Error("nil cache should have nothing to invalidate")
+	}
+}
+
+func TestUpstreamResponseCacheInvalidatePost(t *testing.T) {
+	withUpstreamResponseCache(t, time.Minute)
+	upstreamResponses.set("post-1-html", "post-1", []byte(`{}`))
+	upstreamResponses.set("post-1-meta", "post-1", []byte(`{}`))
+	upstreamResponses.set("post-2", "post-2", []byte(`{}`))
+	upstreamResponses.set("list", "", []byte(`{}`))
+
+	if removed := upstreamResponses.invalidatePost("post-1"); removed != 2 {
+		t.Errorf("removed = %d, want 2", removed)
+	}
+	for key, want := range map[string]bool{"post-1-html": false, "post-1-meta": false, "post-2": true, "list": true} {
+		if _, ok := upstreamResponses.get(key); ok != want {
+			t.Errorf("get(%q) ok = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestFetchContentFromBetterModeUpstreamResponseCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		second    ContentOptions
+		partial   bool
+		invalid   bool
+		wantCalls int
+	}{
+		{"reused across formats", time.Minute, ContentOptions{Format: "text"}, false, false, 1},
+		{"disabled", 0, ContentOptions{Format: "text"}, false, false, 2},
+		{"nocache", time.Minute, ContentOptions{Format: "text", NoCache: true}, false, false, 2},
+		{"partial data not cached", time.Minute, ContentOptions{Format: "text"}, true, false, 2},
+		{"invalidated post", time.Minute, ContentOptions{Format: "text"}, false, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			withUpstreamResponseCache(t, tt.ttl)
+			withConfig(t, func(cfg *Config) { cfg.GraphQLPartialData = PartialDataAllow })
+			fake := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				resp := postData(testPostJSON("제목", "<p>본문</p>"))
+				if tt.partial {
+					resp["errors"] = []map[string]string{{"message": "reactions failed"}}
+				}
+				writeJSONResponse(w, http.StatusOK, resp)
+			})
+
+			if _, err := fetchContentFromBetterMode(context.Background(), "post-1", ContentOptions{Format: "html"}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.invalid {
+				contentCache.InvalidatePost("post-1")
+			}
+			post, err := fetchContentFromBetterMode(context.Background(), "post-1", tt.second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if post.Title != "제목" || post.ContentField() != "<p>본문</p>" {
+				t.Errorf("post = %+v", post)
+			}
+			if fake.count() != tt.wantCalls {
+				t.Errorf("upstream calls = %d, want %d", fake.count(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestQueryBetterModeSkipsUpstreamResponseCache(t *testing.T) {
+	withTestToken(t)
+	withUpstreamResponseCache(t, time.Minute)
+	fake := withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": map[string]string{"value": "v"}})
+	})
+
+	// 목록 조회는 게시물 ID로 지울 수 없으므로 원본 응답 캐시를 쓰지 않습니다
+	for i := 0; i < 2; i++ {
+		var out struct {
+			Value string `json:"value"`
+		}
+		if err := queryBetterMode(context.Background(), spaceChangesQuery, map[string]interface{}{"spaceIds": []string{"space-1"}}, &out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fake.count() != 2 {
+		t.Errorf("upstream calls = %d, want 2", fake.count())
+	}
+}
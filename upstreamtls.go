@@ -0,0 +1,55 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// UPSTREAM_TLS_MIN_VERSION 값과 tls 패키지 버전 상수
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// upstreamTLSConfig는 UPSTREAM_TLS_MIN_VERSION 미만으로는 연결하지 않는 TLS 설정을 만듭니다.
+// UPSTREAM_CA_FILE이 있으면 그 PEM 인증서들을 시스템 루트 인증서에 더해 신뢰합니다 (TLS를 검사하는 프록시용).
+func upstreamTLSConfig(cfg *Config) (*tls.Config, error) {
+	minVersion, ok := tlsVersions[cfg.UpstreamTLSMinVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported UPSTREAM_TLS_MIN_VERSION %q", cfg.UpstreamTLSMinVersion)
+	}
+	tlsConfig := &tls.Config{MinVersion: minVersion}
+	if cfg.UpstreamCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(cfg.UpstreamCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading UPSTREAM_CA_FILE: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("UPSTREAM_CA_FILE %s contains no PEM certificates", cfg.UpstreamCAFile)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// configureUpstreamTLS는 BetterMode, 웹훅, 번역, 내보내기 요청이 함께 쓰는 upstreamClient에 TLS 설정을 적용합니다.
+// 시작 시에만 적용되며, 설정을 다시 읽어도 바뀌지 않습니다.
+func configureUpstreamTLS(cfg *Config) error {
+	tlsConfig, err := upstreamTLSConfig(cfg)
+	if err != nil {
+		return err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	upstreamClient.Transport = transport
+	return nil
+}
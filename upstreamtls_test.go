@@ -0,0 +1,107 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeServerCA는 httptest TLS 서버의 인증서를 PEM 파일로 써서 경로를 반환합니다
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUpstreamTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	caFile := writeServerCA(t, server)
+
+	tests := []struct {
+		name       string
+		minVersion string
+		caFile     string
+		wantMin    uint16
+		wantRoots  bool
+		wantErr    bool
+	}{
+		{"tls 1.2", "1.2", "", tls.VersionTLS12, false, false},
+		{"tls 1.3", "1.3", "", tls.VersionTLS13, false, false},
+		{"unsupported version", "1.1", "", 0, false, true},
+		{"custom ca", "1.2", caFile, tls.VersionTLS12, true, false},
+		{"missing ca file", "1.2", filepath.Join(dir, "missing.pem"), 0, false, true},
+		{"ca file without certificates", "1.2", notPEM, 0, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := upstreamTLSConfig(&Config{UpstreamTLSMinVersion: tt.minVersion, UpstreamCAFile: tt.caFile})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.MinVersion != tt.wantMin {
+				t.Errorf("MinVersion = %x, want %x", got.MinVersion, tt.wantMin)
+			}
+			if (got.RootCAs != nil) != tt.wantRoots {
+				t.Errorf("RootCAs set = %v, want %v", got.RootCAs != nil, tt.wantRoots)
+			}
+		})
+	}
+}
+
+func TestConfigureUpstreamTLS(t *testing.T) {
+	tests := []struct {
+		name             string
+		minVersion       string
+		serverMaxVersion uint16
+		trustServer      bool
+		wantOK           bool
+	}{
+		{"trusted ca", "1.2", 0, true, true},
+		{"untrusted certificate", "1.2", 0, false, false},
+		{"server below minimum", "1.3", tls.VersionTLS12, true, false},
+		{"server meets minimum", "1.3", tls.VersionTLS13, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			server.TLS = &tls.Config{MaxVersion: tt.serverMaxVersion}
+			server.StartTLS()
+			defer server.Close()
+
+			cfg := &Config{UpstreamTLSMinVersion: tt.minVersion}
+			if tt.trustServer {
+				cfg.UpstreamCAFile = writeServerCA(t, server)
+			}
+			prev := upstreamClient.Transport
+			t.Cleanup(func() { upstreamClient.Transport = prev })
+			if err := configureUpstreamTLS(cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := upstreamClient.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err == nil) != tt.wantOK {
+				t.Errorf("request err = %v, want ok %v", err, tt.wantOK)
+			}
+		})
+	}
+}
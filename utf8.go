@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// 본문에 잘못된 UTF-8 바이트가 있을 때의 처리 정책 (INVALID_UTF8)
+const (
+	InvalidUTF8Repair = "repair" // 잘못된 바이트열을 U+FFFD로 바꾸고 invalid_utf8_repaired 경고
+	InvalidUTF8Strict = "strict" // 응답하지 않고 502
+)
+
+// errInvalidUTF8은 INVALID_UTF8=strict에서 본문에 잘못된 UTF-8이 있음을 나타냅니다 (502로 응답)
+var errInvalidUTF8 = errors.New("BetterMode returned content that is not valid UTF-8")
+
+// repairUTF8은 s의 잘못된 UTF-8 바이트열을 각각 U+FFFD 하나로 바꾸고, 바꾼 것이 있으면 true를 반환합니다.
+// BetterMode 응답 본문의 잘못된 바이트는 JSON을 해석할 때 이미 바뀌므로(Post.invalidUTF8로 기록),
+// 여기서는 그 뒤 본문을 정리하는 단계에서 생긴 것을 막습니다.
+func repairUTF8(s string) (string, bool) {
+	if utf8.ValidString(s) {
+		return s, false
+	}
+	return strings.ToValidUTF8(s, string(utf8.RuneError)), true
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRepairUTF8(t *testing.T) {
+	tests := []struct {
+		in          string
+		want        string
+		wantChanged bool
+	}{
+		{"", "", false},
+		{"한글 text", "한글 text", false},
+		{"a\xffb", "a�b", true},
+		{"a\xff\xfeb", "a�b", true}, // 연속된 잘못된 바이트는 U+FFFD 하나
+		{"\xed\x95", "�", true},     // 잘린 한글
+	}
+	for _, tt := range tests {
+		got, changed := repairUTF8(tt.in)
+		if got != tt.want || changed != tt.wantChanged {
+			t.Errorf("repairUTF8(%q) = %q, %v; want %q, %v", tt.in, got, changed, tt.want, tt.wantChanged)
+		}
+	}
+}
+
+func TestRenderContentResponseInvalidUTF8(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		content     string
+		upstreamBad bool // JSON을 해석하며 이미 고쳐진 경우
+		wantErr     bool
+		wantWarning bool
+	}{
+		{"valid", InvalidUTF8Repair, "<p>본문</p>", false, false, false},
+		{"repair content", InvalidUTF8Repair, "<p>본\xff문</p>", false, false, true},
+		{"repair flagged by upstream", InvalidUTF8Repair, "<p>본�문</p>", true, false, true},
+		{"strict content", InvalidUTF8Strict, "<p>본\xff문</p>", false, true, false},
+		{"strict flagged by upstream", InvalidUTF8Strict, "<p>본�문</p>", true, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withConfig(t, func(cfg *Config) { cfg.InvalidUTF8Policy = tt.policy })
+			post := newTestPost("제목", tt.content)
+			post.invalidUTF8 = tt.upstreamBad
+			opts := ContentOptions{Format: "html"}
+			if err := opts.normalize(); err != nil {
+				t.Fatal(err)
+			}
+
+			response, err := renderContentResponse(context.Background(), ContentRequest{PostID: "post-1", ContentOptions: opts}, post)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidUTF8) {
+					t.Errorf("err = %v, want errInvalidUTF8", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hasWarning(response.Warnings, WarningInvalidUTF8Repaired) != tt.wantWarning {
+				t.Errorf("warnings = %+v, want %s %v", response.Warnings, WarningInvalidUTF8Repaired, tt.wantWarning)
+			}
+			if !strings.Contains(response.Content, "본") || strings.Contains(response.Content, "\xff") {
+				t.Errorf("content = %q", response.Content)
+			}
+		})
+	}
+}
+
+func TestGetContentInvalidUTF8FromUpstream(t *testing.T) {
+	tests := []struct {
+		policy     string
+		wantStatus int
+	}{
+		{InvalidUTF8Repair, http.StatusOK},
+		{InvalidUTF8Strict, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			withTestToken(t)
+			withContentCache(t)
+			withConfig(t, func(cfg *Config) { cfg.InvalidUTF8Policy = tt.policy })
+			withFakeUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"data":{"post":{"title":"제목","updatedAt":"2024-05-01T10:00:00Z",` +
+					`"mappingFields":[{"key":"content","type":"html","value":"<p>본` + "\xff" + `문</p>"}]}}}`))
+			})
+
+			rec := httptest.NewRecorder()
+			getContent(rec, httptest.NewRequest(http.MethodPost, "/api/v1/content", strings.NewReader(`{"post_id":"post-1"}`)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && !strings.Contains(rec.Body.String(), WarningInvalidUTF8Repaired) {
+				t.Errorf("body = %s, want %s warning", rec.Body.String(), WarningInvalidUTF8Repaired)
+			}
+		})
+	}
+}
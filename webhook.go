@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// postProcessRequest는 후처리 웹훅에 보내는 본문입니다
+type postProcessRequest struct {
+	PostID  string `json:"post_id"`
+	Title   string `json:"title"`
+	Format  string `json:"format"`
+	Content string `json:"content"`
+}
+
+// postProcessResponse는 후처리 웹훅이 돌려주는 본문입니다
+type postProcessResponse struct {
+	Content *string `json:"content"`
+}
+
+// 후처리 웹훅 응답 본문의 최대 크기
+const postProcessMaxResponseBytes = 16 << 20
+
+// postProcessContent는 POSTPROCESS_WEBHOOK_URL로 정리된 콘텐츠를 보내고, 웹훅이 돌려준 content를 반환합니다.
+// 호출은 POSTPROCESS_WEBHOOK_TIMEOUT 안에 끝나야 하며, 실패하면 에러를 반환하므로 호출자는 원본을 그대로 씁니다.
+func postProcessContent(ctx context.Context, payload postProcessRequest) (string, error) {
+	cfg := currentConfig()
+	ctx, cancel := context.WithTimeout(ctx, cfg.PostProcessWebhookTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.PostProcessWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := upstreamClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+
+	var result postProcessResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, postProcessMaxResponseBytes)).Decode(&result); err != nil {
+		return "", fmt.Errorf("error parsing webhook response: %w", err)
+	}
+	if result.Content == nil {
+		return "", errors.New("webhook response has no content")
+	}
+	return *result.Content, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withPostProcessWebhook은 handler를 후처리 웹훅으로 설정합니다
+func withPostProcessWebhook(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	withConfig(t, func(cfg *Config) {
+		cfg.PostProcessWebhookURL = srv.URL
+		cfg.PostProcessWebhookTimeout = 200 * time.Millisecond
+	})
+}
+
+// upperCaseWebhook은 받은 content를 대문자로 바꿔 돌려주는 후처리 웹훅입니다
+func upperCaseWebhook(w http.ResponseWriter, r *http.Request) {
+	var req postProcessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, map[string]string{"content": strings.ToUpper(req.Content)})
+}
+
+func TestPostProcessContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+		wantErr string
+	}{
+		{"transforms content", upperCaseWebhook, "<P>HELLO</P>", ""},
+		{"empty content is allowed", func(w http.ResponseWriter, r *http.Request) {
+			writeJSONResponse(w, http.StatusOK, map[string]string{"content": ""})
+		}, "", ""},
+		{"non-200 status", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}, "", "HTTP 500"},
+		{"missing content", func(w http.ResponseWriter, r *http.Request) {
+			writeJSONResponse(w, http.StatusOK, map[string]string{})
+		}, "", "no content"},
+		{"invalid json", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("not json"))
+		}, "", "error parsing webhook response"},
+		{"timeout", func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second / 2):
+			}
+		}, "", "deadline exceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withPostProcessWebhook(t, tt.handler)
+			got, err := postProcessContent(context.Background(), postProcessRequest{PostID: "post-1", Format: "html", Content: "<p>hello</p>"})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("postProcessContent = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderContentResponsePostProcess(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		wantContent string
+		wantWarning bool
+	}{
+		{"webhook result replaces content", upperCaseWebhook, "<P>HELLO</P>", false},
+		{"failure falls back to original", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}, "<p>hello</p>", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withPostProcessWebhook(t, tt.handler)
+			response := renderTestPost(t, ContentOptions{Format: "html"}, newTestPost("제목", "<p>hello</p>"))
+			if response.Content != tt.wantContent {
+				t.Errorf("content = %q, want %q", response.Content, tt.wantContent)
+			}
+			if got := hasWarning(response.Warnings, WarningPostProcessFailed); got != tt.wantWarning {
+				t.Errorf("postprocess_failed warning = %v, want %v", got, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// XHTML에서 "<br />"처럼 스스로 닫아야 하는 void 요소
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// toXHTML은 HTML을 파싱한 뒤 EPUB 등에서 쓸 수 있는 well-formed XHTML로 다시 직렬화합니다.
+// 닫히지 않은 태그 같은 잘못된 입력은 HTML 파서가 바로잡은 결과를 기준으로 출력합니다.
+func toXHTML(content string) (string, error) {
+	nodes, err := parseHTMLFragment(content)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, n := range nodes {
+		writeXHTML(&b, n)
+	}
+	return b.String(), nil
+}
+
+func writeXHTML(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(escapeXML(n.Data))
+	case html.CommentNode:
+		// XML 주석에는 "--"가 들어갈 수 없습니다
+		b.WriteString("<!--")
+		b.WriteString(strings.ReplaceAll(stripInvalidXMLChars(n.Data), "--", "- -"))
+		b.WriteString("-->")
+	case html.ElementNode:
+		tag := strings.ToLower(n.Data)
+		b.WriteString("<")
+		b.WriteString(tag)
+
+		seen := make(map[string]bool, len(n.Attr))
+		for _, attr := range n.Attr {
+			name := strings.ToLower(attr.Key)
+			if attr.Namespace != "" {
+				name = attr.Namespace + ":" + name
+			}
+			// 중복 속성이나 XML 이름으로 쓸 수 없는 속성은 버립니다
+			if seen[name] || !isValidXMLName(name) {
+				continue
+			}
+			seen[name] = true
+
+			b.WriteString(" ")
+			b.WriteString(name)
+			b.WriteString(`="`)
+			b.WriteString(escapeXMLAttr(attr.Val))
+			b.WriteString(`"`)
+		}
+
+		if voidElements[tag] {
+			b.WriteString(" />")
+			return
+		}
+		b.WriteString(">")
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			writeXHTML(b, c)
+		}
+		b.WriteString("</")
+		b.WriteString(tag)
+		b.WriteString(">")
+	case html.DocumentNode:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			writeXHTML(b, c)
+		}
+	}
+}
+
+var xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+var xmlAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "\t", "&#9;", "\n", "&#10;", "\r", "&#13;")
+
+func escapeXML(s string) string {
+	return xmlTextEscaper.Replace(stripInvalidXMLChars(s))
+}
+
+func escapeXMLAttr(s string) string {
+	return xmlAttrEscaper.Replace(stripInvalidXMLChars(s))
+}
+
+// stripInvalidXMLChars는 XML 1.0에서 허용하지 않는 제어 문자를 제거합니다
+func stripInvalidXMLChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return r
+		}
+		if r < 0x20 || r == 0xFFFE || r == 0xFFFF {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func isValidXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if unicode.IsLetter(r) || r == '_' || r == ':' {
+			continue
+		}
+		if i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.') {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestToXHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"void element closed", "<p>한 줄<br>두 줄</p>", "<p>한 줄<br />두 줄</p>"},
+		{"unclosed tags fixed", "<p>a<p>b", "<p>a</p><p>b</p>"},
+		{"image self-closed", `<img src="a.png" alt="그림">`, `<img src="a.png" alt="그림" />`},
+		{"text escaped", "<p>a &amp; b &lt; c</p>", "<p>a &amp; b &lt; c</p>"},
+		{"comment dashes", "<!-- a -- b -->", "<!-- a - - b -->"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toXHTML(tt.content)
+			if err != nil {
+				t.Fatalf("toXHTML: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("toXHTML = %q, want %q", got, tt.want)
+			}
+			assertWellFormedXML(t, got)
+		})
+	}
+}
+
+// assertWellFormedXML은 s를 하나의 루트 아래에 넣어 XML 파서로 끝까지 읽을 수 있는지 확인합니다
+func assertWellFormedXML(t *testing.T, s string) {
+	t.Helper()
+	dec := xml.NewDecoder(strings.NewReader("<root>" + s + "</root>"))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("not well-formed XML: %v\n%s", err, s)
+		}
+	}
+}